@@ -175,6 +175,20 @@ func (d *DefaultDispatcher) getLink(ctx context.Context) (*transport.Link, *tran
 		}
 	}
 
+	if rm, ok := d.policy.(policy.RateLimitManager); ok {
+		var inboundTag string
+		if sessionInbound != nil {
+			inboundTag = sessionInbound.Tag
+		}
+		limiters := rm.ForConnection(inboundTag, user)
+		if len(limiters.Uplink) > 0 {
+			inboundLink.Writer = NewRateLimitedWriter(ctx, limiters.Uplink, inboundLink.Writer)
+		}
+		if len(limiters.Downlink) > 0 {
+			outboundLink.Writer = NewRateLimitedWriter(ctx, limiters.Downlink, outboundLink.Writer)
+		}
+	}
+
 	return inboundLink, outboundLink
 }
 
@@ -210,6 +224,7 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 	ctx = session.ContextWithOutbound(ctx, ob)
 
 	inbound, outbound := d.getLink(ctx)
+	conn := d.trackConnection(ctx, destination, inbound, outbound)
 	content := session.ContentFromContext(ctx)
 	if content == nil {
 		content = new(session.Content)
@@ -217,7 +232,7 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 	}
 	sniffingRequest := content.SniffingRequest
 	if !sniffingRequest.Enabled {
-		go d.routedDispatch(ctx, outbound, destination)
+		go d.routedDispatch(ctx, outbound, destination, conn)
 	} else {
 		go func() {
 			cReader := &cachedReader{
@@ -227,6 +242,12 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 			result, err := sniffer(ctx, cReader, sniffingRequest.MetadataOnly, destination.Network)
 			if err == nil {
 				content.Protocol = result.Protocol()
+				if conn != nil {
+					conn.Update(func(info *stats.ConnectionInfo) {
+						info.Protocol = result.Protocol()
+						info.Domain = result.Domain()
+					})
+				}
 			}
 			if err == nil && shouldOverride(result, sniffingRequest.OverrideDestinationForProtocol) {
 				if domain, err := strmatcher.ToDomain(result.Domain()); err == nil {
@@ -235,7 +256,7 @@ func (d *DefaultDispatcher) Dispatch(ctx context.Context, destination net.Destin
 					ob.Target = destination
 				}
 			}
-			d.routedDispatch(ctx, outbound, destination)
+			d.routedDispatch(ctx, outbound, destination, conn)
 		}()
 	}
 
@@ -297,7 +318,7 @@ func sniffer(ctx context.Context, cReader *cachedReader, metadataOnly bool, netw
 	return contentResult, contentErr
 }
 
-func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.Link, destination net.Destination) {
+func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.Link, destination net.Destination, conn stats.TrackedConnection) {
 	var handler outbound.Handler
 
 	if forcedOutboundTag := session.GetForcedOutboundTagFromContext(ctx); forcedOutboundTag != "" {
@@ -346,5 +367,37 @@ func (d *DefaultDispatcher) routedDispatch(ctx context.Context, link *transport.
 		log.Record(accessMessage)
 	}
 
+	if conn != nil {
+		conn.Update(func(info *stats.ConnectionInfo) {
+			info.Target = destination.String()
+			info.OutboundTag = handler.Tag()
+		})
+	}
+
+	for _, counter := range d.activeConnectionCounters(ctx, handler.Tag()) {
+		counter.Add(1)
+		defer counter.Add(-1)
+	}
+
 	handler.Dispatch(ctx, link)
 }
+
+// activeConnectionCounters returns the counters of active connections of the
+// inbound and the outbound of a connection, as enabled by the system policy.
+func (d *DefaultDispatcher) activeConnectionCounters(ctx context.Context, outboundTag string) []stats.Counter {
+	var counters []stats.Counter
+	p := d.policy.ForSystem()
+	if sessionInbound := session.InboundFromContext(ctx); sessionInbound != nil && sessionInbound.Tag != "" && p.Stats.InboundConnection {
+		name := "inbound>>>" + sessionInbound.Tag + ">>>connection>>>active"
+		if c, _ := stats.GetOrRegisterCounter(d.stats, name); c != nil {
+			counters = append(counters, c)
+		}
+	}
+	if outboundTag != "" && p.Stats.OutboundConnection {
+		name := "outbound>>>" + outboundTag + ">>>connection>>>active"
+		if c, _ := stats.GetOrRegisterCounter(d.stats, name); c != nil {
+			counters = append(counters, c)
+		}
+	}
+	return counters
+}
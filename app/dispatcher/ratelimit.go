@@ -0,0 +1,50 @@
+package dispatcher
+
+import (
+	"context"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+)
+
+// RateLimitedWriter is a buf.Writer that waits for all its rate limiters
+// before writing.
+type RateLimitedWriter struct {
+	Limiters []policy.RateLimiter
+	Writer   buf.Writer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewRateLimitedWriter(ctx context.Context, limiters []policy.RateLimiter, writer buf.Writer) *RateLimitedWriter {
+	ctx, cancel := context.WithCancel(ctx)
+	return &RateLimitedWriter{
+		Limiters: limiters,
+		Writer:   writer,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (w *RateLimitedWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	n := int(mb.Len())
+	for _, limiter := range w.Limiters {
+		if err := limiter.WaitN(w.ctx, n); err != nil {
+			buf.ReleaseMulti(mb)
+			return newError("rate limiter stopped").Base(err)
+		}
+	}
+	return w.Writer.WriteMultiBuffer(mb)
+}
+
+func (w *RateLimitedWriter) Close() error {
+	w.cancel()
+	return common.Close(w.Writer)
+}
+
+func (w *RateLimitedWriter) Interrupt() {
+	w.cancel()
+	common.Interrupt(w.Writer)
+}
@@ -0,0 +1,41 @@
+package dispatcher_test
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/frogwall/f2ray-core/v5/app/dispatcher"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+)
+
+type TestRateLimiter int
+
+func (l *TestRateLimiter) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	*l += TestRateLimiter(n)
+	return nil
+}
+
+func TestRateLimitedWriter(t *testing.T) {
+	var l1, l2 TestRateLimiter
+	writer := NewRateLimitedWriter(context.Background(), []policy.RateLimiter{&l1, &l2}, buf.Discard)
+
+	mb := buf.MultiBuffer{buf.New(), buf.New()}
+	common.Must2(mb[0].WriteString("abcd"))
+	common.Must2(mb[1].WriteString("efg"))
+	common.Must(writer.WriteMultiBuffer(mb))
+	if l1 != 7 || l2 != 7 {
+		t.Fatal("expect 7 bytes to pass both limiters, but got ", l1, " and ", l2)
+	}
+
+	writer.Interrupt()
+	b := buf.New()
+	common.Must2(b.WriteString("h"))
+	if err := writer.WriteMultiBuffer(buf.MultiBuffer{b}); err == nil {
+		t.Error("expect error after interrupted")
+	}
+}
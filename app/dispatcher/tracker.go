@@ -0,0 +1,82 @@
+package dispatcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/features/stats"
+	"github.com/frogwall/f2ray-core/v5/transport"
+)
+
+// trackedWriter counts bytes of a direction of a tracked connection, and calls
+// done, if any, when the direction is closed or interrupted.
+type trackedWriter struct {
+	counter stats.Counter
+	writer  buf.Writer
+	done    func()
+}
+
+func (w *trackedWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	w.counter.Add(int64(mb.Len()))
+	return w.writer.WriteMultiBuffer(mb)
+}
+
+func (w *trackedWriter) Close() error {
+	err := common.Close(w.writer)
+	if w.done != nil {
+		w.done()
+	}
+	return err
+}
+
+func (w *trackedWriter) Interrupt() {
+	common.Interrupt(w.writer)
+	if w.done != nil {
+		w.done()
+	}
+}
+
+// trackConnection tracks the connection of the links, if enabled by the system
+// policy. The connection is untracked when its downlink is done, or when ctx is
+// done, whichever comes first.
+func (d *DefaultDispatcher) trackConnection(ctx context.Context, destination net.Destination, inboundLink, outboundLink *transport.Link) stats.TrackedConnection {
+	tracker, ok := d.stats.(stats.ConnectionTracker)
+	if !ok || !d.policy.ForSystem().Stats.ConnectionTracking {
+		return nil
+	}
+
+	info := stats.ConnectionInfo{
+		Target: destination.String(),
+		Start:  time.Now(),
+	}
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		if inbound.Source.IsValid() {
+			info.Source = inbound.Source.String()
+		}
+		info.InboundTag = inbound.Tag
+		if inbound.User != nil {
+			info.User = inbound.User.Email
+		}
+	}
+
+	uplink := &trackedWriter{writer: inboundLink.Writer}
+	downlink := &trackedWriter{writer: outboundLink.Writer}
+	conn := tracker.TrackConnection(info, func() {
+		uplink.Interrupt()
+		downlink.Interrupt()
+	})
+	stop := context.AfterFunc(ctx, conn.Untrack)
+	downlink.done = func() {
+		stop()
+		conn.Untrack()
+	}
+	uplink.counter = conn.UplinkCounter()
+	downlink.counter = conn.DownlinkCounter()
+	inboundLink.Writer = uplink
+	outboundLink.Writer = downlink
+	return conn
+}
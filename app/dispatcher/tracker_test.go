@@ -0,0 +1,152 @@
+package dispatcher_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/frogwall/f2ray-core/v5/app/dispatcher"
+	"github.com/frogwall/f2ray-core/v5/app/policy"
+	"github.com/frogwall/f2ray-core/v5/app/stats"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/features/outbound"
+	"github.com/frogwall/f2ray-core/v5/transport"
+)
+
+// echoHandler is an outbound.Handler that sends back what it receives.
+type echoHandler struct{}
+
+func (echoHandler) Start() error { return nil }
+
+func (echoHandler) Close() error { return nil }
+
+func (echoHandler) Tag() string { return "echo" }
+
+func (echoHandler) Dispatch(ctx context.Context, link *transport.Link) {
+	if err := buf.Copy(link.Reader, link.Writer); err != nil {
+		common.Interrupt(link.Writer)
+		return
+	}
+	common.Close(link.Writer)
+}
+
+// silentHandler is an outbound.Handler that ends the response right away, and
+// keeps the request open.
+type silentHandler struct {
+	echoHandler
+}
+
+func (silentHandler) Dispatch(ctx context.Context, link *transport.Link) {
+	common.Close(link.Writer)
+}
+
+type testOutboundManager struct {
+	outbound.Manager
+	handler outbound.Handler
+}
+
+func (m testOutboundManager) GetDefaultHandler() outbound.Handler {
+	return m.handler
+}
+
+func newTrackingDispatcher(t *testing.T, handler outbound.Handler) (*DefaultDispatcher, *stats.Manager) {
+	pm, err := policy.New(context.Background(), &policy.Config{
+		System: &policy.SystemPolicy{
+			Stats: &policy.SystemPolicy_Stats{ConnectionTracking: true},
+		},
+	})
+	common.Must(err)
+	sm, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+
+	d := new(DefaultDispatcher)
+	common.Must(d.Init(&Config{}, testOutboundManager{handler: handler}, nil, pm, sm))
+	return d, sm
+}
+
+func waitConnections(t *testing.T, sm *stats.Manager, n int) {
+	for i := 0; i < 100 && len(sm.Connections()) != n; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if connections := sm.Connections(); len(connections) != n {
+		t.Fatal("expect ", n, " connections, but got ", len(connections))
+	}
+}
+
+func TestTrackConnection(t *testing.T) {
+	d, sm := newTrackingDispatcher(t, echoHandler{})
+
+	ctx := session.ContextWithInbound(context.Background(), &session.Inbound{
+		Source: net.TCPDestination(net.LocalHostIP, 50000),
+		Tag:    "in",
+	})
+	link, err := d.Dispatch(ctx, net.TCPDestination(net.DomainAddress("example.com"), 443))
+	common.Must(err)
+
+	common.Must(link.Writer.WriteMultiBuffer(buf.MergeBytes(nil, []byte("abcd"))))
+	mb, err := link.Reader.ReadMultiBuffer()
+	common.Must(err)
+	if mb.String() != "abcd" {
+		t.Error("unexpected response: ", mb.String())
+	}
+	buf.ReleaseMulti(mb)
+
+	waitConnections(t, sm, 1)
+	info := sm.Connections()[0]
+	if info.Source != "tcp:127.0.0.1:50000" || info.Target != "tcp:example.com:443" || info.InboundTag != "in" || info.OutboundTag != "echo" {
+		t.Error("unexpected connection: ", info)
+	}
+	if info.Uplink != 4 || info.Downlink != 4 {
+		t.Error("unexpected traffic: ", info.Uplink, " ", info.Downlink)
+	}
+
+	common.Must(common.Close(link.Writer))
+	waitConnections(t, sm, 0)
+}
+
+func TestCloseTrackedConnection(t *testing.T) {
+	d, sm := newTrackingDispatcher(t, echoHandler{})
+
+	link, err := d.Dispatch(context.Background(), net.TCPDestination(net.LocalHostIP, 80))
+	common.Must(err)
+	waitConnections(t, sm, 1)
+
+	common.Must(sm.CloseConnection(sm.Connections()[0].ID))
+	if _, err := link.Reader.ReadMultiBuffer(); err == nil {
+		t.Error("connection is not closed")
+	}
+	waitConnections(t, sm, 0)
+
+	if err := sm.CloseConnection(1); err == nil {
+		t.Error("closed connection is closed again")
+	}
+}
+
+func TestUntrackCanceledConnection(t *testing.T) {
+	d, sm := newTrackingDispatcher(t, echoHandler{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	link, err := d.Dispatch(ctx, net.TCPDestination(net.LocalHostIP, 80))
+	common.Must(err)
+	defer common.Interrupt(link.Writer)
+	waitConnections(t, sm, 1)
+
+	cancel()
+	waitConnections(t, sm, 0)
+}
+
+func TestUntrackFinishedDownlink(t *testing.T) {
+	d, sm := newTrackingDispatcher(t, silentHandler{})
+
+	link, err := d.Dispatch(context.Background(), net.TCPDestination(net.LocalHostIP, 80))
+	common.Must(err)
+	defer common.Interrupt(link.Writer)
+
+	if _, err := link.Reader.ReadMultiBuffer(); err == nil {
+		t.Error("downlink is not finished")
+	}
+	waitConnections(t, sm, 0)
+}
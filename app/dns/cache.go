@@ -0,0 +1,513 @@
+//go:build !confonly
+// +build !confonly
+
+package dns
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/persistentstorage/protostorage"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/environment"
+	"github.com/frogwall/f2ray-core/v5/common/environment/envctx"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/signal/pubsub"
+	"github.com/frogwall/f2ray-core/v5/common/task"
+	dns_feature "github.com/frogwall/f2ray-core/v5/features/dns"
+)
+
+const (
+	defaultStaleTTL = time.Hour * 24
+
+	// prefetchHits is how many times a record is hit before it is prefetched.
+	prefetchHits = 3
+
+	refreshTimeout = time.Second * 8
+
+	cacheSaveInterval = time.Minute * 10
+	cacheStorageKey   = "records"
+)
+
+// cachePolicy is how records are cached by name servers.
+type cachePolicy struct {
+	serveStale bool
+	staleTTL   time.Duration
+	prefetch   bool
+	minTTL     time.Duration
+	maxTTL     time.Duration
+}
+
+func newCachePolicy(config *CacheConfig) *cachePolicy {
+	p := &cachePolicy{
+		serveStale: config.GetServeStale(),
+		staleTTL:   time.Duration(config.GetStaleTtl()) * time.Second,
+		prefetch:   config.GetPrefetch(),
+		minTTL:     time.Duration(config.GetMinTtl()) * time.Second,
+		maxTTL:     time.Duration(config.GetMaxTtl()) * time.Second,
+	}
+	if p.staleTTL == 0 {
+		p.staleTTL = defaultStaleTTL
+	}
+	return p
+}
+
+// newItem clamps the TTL of a record that is received at now, and makes it
+// a cache item.
+func (p *cachePolicy) newItem(rec *IPRecord, now time.Time) *cacheItem {
+	ttl := rec.Expire.Sub(now)
+	if p.minTTL > 0 && ttl < p.minTTL {
+		ttl = p.minTTL
+	}
+	if p.maxTTL > 0 && ttl > p.maxTTL {
+		ttl = p.maxTTL
+	}
+	rec.Expire = now.Add(ttl)
+	return &cacheItem{IPRecord: rec, ttl: ttl}
+}
+
+// retention is how long a record is kept after it expires.
+func (p *cachePolicy) retention() time.Duration {
+	if p.serveStale {
+		return p.staleTTL
+	}
+	return 0
+}
+
+// cacheItem is a cached IPRecord.
+type cacheItem struct {
+	*IPRecord
+	ttl  time.Duration
+	hits uint32
+}
+
+// cachedServer is a Server that caches its records in a recordCache.
+type cachedServer interface {
+	Server
+	getCache() *recordCache
+}
+
+// recordCache holds the records of a name server, and notifies the queries
+// waiting for them.
+type recordCache struct {
+	sync.RWMutex
+	name       string
+	ips        map[string]record
+	pub        *pubsub.Service
+	cleanup    *task.Periodic
+	policy     *cachePolicy
+	refreshing map[string]bool
+}
+
+func newRecordCache(name string) *recordCache {
+	c := &recordCache{
+		name:       name,
+		ips:        make(map[string]record),
+		pub:        pubsub.NewService(),
+		policy:     newCachePolicy(nil),
+		refreshing: make(map[string]bool),
+	}
+	c.cleanup = &task.Periodic{
+		Interval: time.Minute,
+		Execute:  c.Cleanup,
+	}
+	return c
+}
+
+func (c *recordCache) setPolicy(policy *cachePolicy) {
+	c.Lock()
+	defer c.Unlock()
+	c.policy = policy
+}
+
+// Cleanup clears expired items from cache
+func (c *recordCache) Cleanup() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if len(c.ips) == 0 {
+		return newError(c.name, " nothing to do. stopping...")
+	}
+
+	deadline := time.Now().Add(-c.policy.retention())
+	for domain, record := range c.ips {
+		if record.A != nil && record.A.Expire.Before(deadline) {
+			record.A = nil
+		}
+		if record.AAAA != nil && record.AAAA.Expire.Before(deadline) {
+			record.AAAA = nil
+		}
+
+		if record.A == nil && record.AAAA == nil {
+			newError(c.name, " cleanup ", domain).AtDebug().WriteToLog()
+			delete(c.ips, domain)
+		} else {
+			c.ips[domain] = record
+		}
+	}
+
+	if len(c.ips) == 0 {
+		c.ips = make(map[string]record)
+	}
+
+	return nil
+}
+
+func (c *recordCache) updateIP(req *dnsRequest, ipRec *IPRecord) {
+	elapsed := time.Since(req.start)
+
+	c.Lock()
+	item := c.policy.newItem(ipRec, time.Now())
+	rec := c.ips[req.domain]
+	updated := false
+
+	switch req.reqType {
+	case dnsmessage.TypeA:
+		if isNewer(rec.A, ipRec) {
+			rec.A = item
+			updated = true
+		}
+	case dnsmessage.TypeAAAA:
+		addr := make([]net.Address, 0)
+		for _, ip := range ipRec.IP {
+			if len(ip.IP()) == net.IPv6len {
+				addr = append(addr, ip)
+			}
+		}
+		ipRec.IP = addr
+		if isNewer(rec.AAAA, ipRec) {
+			rec.AAAA = item
+			updated = true
+		}
+	}
+	newError(c.name, " got answer: ", req.domain, " ", req.reqType, " -> ", ipRec.IP, " ", elapsed).AtInfo().WriteToLog()
+
+	if updated {
+		c.ips[req.domain] = rec
+	}
+	switch req.reqType {
+	case dnsmessage.TypeA:
+		c.pub.Publish(req.domain+"4", nil)
+	case dnsmessage.TypeAAAA:
+		c.pub.Publish(req.domain+"6", nil)
+	}
+	c.Unlock()
+	common.Must(c.cleanup.Start())
+}
+
+// getIPs returns the IPs of a record, and whether the record should be
+// refreshed in background. Expired records are returned only if allowStale
+// is set and the policy serves stale records.
+func (c *recordCache) getIPs(rec *cacheItem, now time.Time, allowStale bool) ([]net.Address, bool, error) {
+	if rec == nil {
+		return nil, false, errRecordNotFound
+	}
+	refresh := false
+	switch p := c.policy; {
+	case rec.Expire.After(now):
+		if p.prefetch && atomic.AddUint32(&rec.hits, 1) >= prefetchHits && rec.Expire.Sub(now) < rec.ttl/10 {
+			refresh = true
+		}
+	case allowStale && p.serveStale && rec.Expire.Add(p.staleTTL).After(now):
+		refresh = true
+	default:
+		return nil, false, errRecordNotFound
+	}
+	if rec.RCode != dnsmessage.RCodeSuccess {
+		return nil, refresh, dns_feature.RCodeError(rec.RCode)
+	}
+	return rec.IP, refresh, nil
+}
+
+func (c *recordCache) findIPsForDomain(domain string, option dns_feature.IPOption, allowStale bool) ([]net.IP, bool, error) {
+	c.RLock()
+	defer c.RUnlock()
+
+	record, found := c.ips[domain]
+	if !found {
+		return nil, false, errRecordNotFound
+	}
+
+	now := time.Now()
+	var ips []net.Address
+	var lastErr error
+	var refresh bool
+	if option.IPv4Enable {
+		a, r, err := c.getIPs(record.A, now, allowStale)
+		if err != nil {
+			lastErr = err
+		}
+		ips = append(ips, a...)
+		refresh = refresh || r
+	}
+
+	if option.IPv6Enable {
+		aaaa, r, err := c.getIPs(record.AAAA, now, allowStale)
+		if err != nil {
+			lastErr = err
+		}
+		ips = append(ips, aaaa...)
+		refresh = refresh || r
+	}
+
+	if len(ips) > 0 {
+		netIPs, err := toNetIP(ips)
+		return netIPs, refresh, err
+	}
+
+	if lastErr != nil {
+		return nil, refresh, lastErr
+	}
+
+	return nil, refresh, dns_feature.ErrEmptyResponse
+}
+
+// subscribe returns a channel that is closed when the records of the domain
+// are all updated, or ctx is done.
+func (c *recordCache) subscribe(ctx context.Context, domain string, option dns_feature.IPOption) (<-chan interface{}, func()) {
+	// ipv4 and ipv6 belong to different subscription groups
+	var sub4, sub6 *pubsub.Subscriber
+	if option.IPv4Enable {
+		sub4 = c.pub.Subscribe(domain + "4")
+	}
+	if option.IPv6Enable {
+		sub6 = c.pub.Subscribe(domain + "6")
+	}
+	done := make(chan interface{})
+	go func() {
+		if sub4 != nil {
+			select {
+			case <-sub4.Wait():
+			case <-ctx.Done():
+			}
+		}
+		if sub6 != nil {
+			select {
+			case <-sub6.Wait():
+			case <-ctx.Done():
+			}
+		}
+		close(done)
+	}()
+	return done, func() {
+		if sub4 != nil {
+			sub4.Close()
+		}
+		if sub6 != nil {
+			sub6.Close()
+		}
+	}
+}
+
+// queryIP returns the IPs of the domain from the cache, or sends a query with
+// sendQuery and waits for the records.
+func (c *recordCache) queryIP(ctx context.Context, domain string, option dns_feature.IPOption, disableCache bool, sendQuery func(context.Context, string)) ([]net.IP, error) {
+	fqdn := Fqdn(domain)
+
+	if disableCache {
+		newError("DNS cache is disabled. Querying IP for ", domain, " at ", c.name).AtDebug().WriteToLog()
+	} else {
+		ips, refresh, err := c.findIPsForDomain(fqdn, option, true)
+		if err != errRecordNotFound {
+			if refresh {
+				c.refresh(ctx, fqdn, option, sendQuery)
+			}
+			newError(c.name, " cache HIT ", domain, " -> ", ips).Base(err).AtDebug().WriteToLog()
+			return ips, err
+		}
+	}
+
+	done, closeSub := c.subscribe(ctx, fqdn, option)
+	defer closeSub()
+	sendQuery(ctx, fqdn)
+
+	for {
+		ips, _, err := c.findIPsForDomain(fqdn, option, false)
+		if err != errRecordNotFound {
+			return ips, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-done:
+		}
+	}
+}
+
+// refresh queries the records of the domain again in background, if they are
+// not being refreshed.
+func (c *recordCache) refresh(ctx context.Context, fqdn string, option dns_feature.IPOption, sendQuery func(context.Context, string)) {
+	key := fqdn
+	if option.IPv4Enable {
+		key += "4"
+	}
+	if option.IPv6Enable {
+		key += "6"
+	}
+	c.Lock()
+	if c.refreshing[key] {
+		c.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.Unlock()
+
+	newError(c.name, " refreshing ", fqdn, " in background").AtDebug().WriteToLog()
+	go func() {
+		ctx, cancel := context.WithTimeout(core.ToBackgroundDetachedContext(ctx), refreshTimeout)
+		defer cancel()
+		done, closeSub := c.subscribe(ctx, fqdn, option)
+		defer closeSub()
+		sendQuery(ctx, fqdn)
+		<-done
+
+		c.Lock()
+		delete(c.refreshing, key)
+		c.Unlock()
+	}()
+}
+
+// export returns the records in the cache.
+func (c *recordCache) export() []*CacheRecord {
+	c.RLock()
+	defer c.RUnlock()
+
+	var records []*CacheRecord
+	for domain, record := range c.ips {
+		for reqType, rec := range map[dnsmessage.Type]*cacheItem{dnsmessage.TypeA: record.A, dnsmessage.TypeAAAA: record.AAAA} {
+			if rec == nil {
+				continue
+			}
+			cacheRecord := &CacheRecord{
+				Server: c.name,
+				Domain: domain,
+				Type:   uint32(reqType),
+				Rcode:  uint32(rec.RCode),
+				Expire: rec.Expire.Unix(),
+				Ttl:    uint32(rec.ttl / time.Second),
+			}
+			for _, ip := range rec.IP {
+				cacheRecord.Ip = append(cacheRecord.Ip, ip.IP())
+			}
+			records = append(records, cacheRecord)
+		}
+	}
+	return records
+}
+
+// restore adds records of this name server to the cache. Records that are
+// older than the ones in the cache or no longer retained are skipped.
+func (c *recordCache) restore(records []*CacheRecord) {
+	c.Lock()
+	deadline := time.Now().Add(-c.policy.retention())
+	restored := 0
+	for _, cacheRecord := range records {
+		if cacheRecord.Server != c.name {
+			continue
+		}
+		rec := &IPRecord{
+			RCode:  dnsmessage.RCode(cacheRecord.Rcode),
+			Expire: time.Unix(cacheRecord.Expire, 0),
+		}
+		if rec.Expire.Before(deadline) {
+			continue
+		}
+		for _, ip := range cacheRecord.Ip {
+			rec.IP = append(rec.IP, net.IPAddress(ip))
+		}
+		item := &cacheItem{IPRecord: rec, ttl: time.Duration(cacheRecord.Ttl) * time.Second}
+
+		r := c.ips[cacheRecord.Domain]
+		switch dnsmessage.Type(cacheRecord.Type) {
+		case dnsmessage.TypeA:
+			if !isNewer(r.A, rec) {
+				continue
+			}
+			r.A = item
+		case dnsmessage.TypeAAAA:
+			if !isNewer(r.AAAA, rec) {
+				continue
+			}
+			r.AAAA = item
+		default:
+			continue
+		}
+		c.ips[cacheRecord.Domain] = r
+		restored++
+	}
+	c.Unlock()
+
+	if restored > 0 {
+		newError(c.name, " restored ", restored, " cached records").AtDebug().WriteToLog()
+		if err := c.cleanup.Start(); err != nil {
+			newError(c.name, " failed to start cleanup").Base(err).AtDebug().WriteToLog()
+		}
+	}
+}
+
+func exportCache(clients []*Client) []*CacheRecord {
+	var records []*CacheRecord
+	exported := make(map[*recordCache]bool)
+	for _, client := range clients {
+		if server, ok := client.server.(cachedServer); ok && !exported[server.getCache()] {
+			exported[server.getCache()] = true
+			records = append(records, server.getCache().export()...)
+		}
+	}
+	return records
+}
+
+func restoreCache(clients []*Client, records []*CacheRecord) {
+	if len(records) == 0 {
+		return
+	}
+	for _, client := range clients {
+		if server, ok := client.server.(cachedServer); ok {
+			server.getCache().restore(records)
+		}
+	}
+}
+
+func (s *DNS) cacheStorage() (protostorage.ProtoPersistentStorage, error) {
+	appEnvironment, ok := envctx.EnvironmentFromContext(s.ctx).(environment.AppEnvironment)
+	if !ok {
+		return nil, newError("persistent storage is not available")
+	}
+	storage, err := appEnvironment.PersistentStorage().NarrowScope(s.ctx, []byte("dns_cache"))
+	if err != nil {
+		return nil, newError("failed to get persistent storage for dns_cache").Base(err)
+	}
+	return storage.(protostorage.ProtoPersistentStorage), nil
+}
+
+// loadCache restores the cache of name servers from the persistent storage.
+func (s *DNS) loadCache(storage protostorage.ProtoPersistentStorage) {
+	snapshot := new(CacheSnapshot)
+	if err := storage.GetProto(s.ctx, cacheStorageKey, snapshot); err != nil {
+		newError("failed to load DNS cache").Base(err).AtInfo().WriteToLog()
+		return
+	}
+	restoreCache(s.state().clients, snapshot.Record)
+	newError("loaded ", len(snapshot.Record), " DNS cache records").AtInfo().WriteToLog()
+}
+
+// saveCache saves the cache of name servers to the persistent storage, if
+// the cache is persistent.
+func (s *DNS) saveCache() error {
+	state := s.state()
+	if !state.persistCache {
+		return nil
+	}
+	storage, err := s.cacheStorage()
+	if err != nil {
+		return err
+	}
+	snapshot := &CacheSnapshot{Record: exportCache(state.clients)}
+	return storage.PutProto(s.ctx, cacheStorageKey, snapshot)
+}
@@ -0,0 +1,161 @@
+package dns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/environment"
+	"github.com/frogwall/f2ray-core/v5/common/environment/deferredpersistentstorage"
+	"github.com/frogwall/f2ray-core/v5/common/environment/envctx"
+	"github.com/frogwall/f2ray-core/v5/common/environment/filesystemimpl"
+	"github.com/frogwall/f2ray-core/v5/common/environment/systemnetworkimpl"
+	"github.com/frogwall/f2ray-core/v5/common/environment/transientstorageimpl"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	dns_feature "github.com/frogwall/f2ray-core/v5/features/dns"
+)
+
+var ipv4Only = dns_feature.IPOption{IPv4Enable: true}
+
+func answerA(c *recordCache, domain string, ip string, ttl time.Duration) {
+	c.updateIP(&dnsRequest{reqType: dnsmessage.TypeA, domain: domain, start: time.Now()}, &IPRecord{
+		IP:     []net.Address{net.ParseAddress(ip)},
+		Expire: time.Now().Add(ttl),
+	})
+}
+
+func TestRecordCacheServeStale(t *testing.T) {
+	c := newRecordCache("test")
+	c.setPolicy(newCachePolicy(&CacheConfig{ServeStale: true}))
+	answerA(c, "example.com.", "1.1.1.1", -time.Minute)
+
+	queried := make(chan string, 1)
+	ips, err := c.queryIP(context.Background(), "example.com", ipv4Only, false, func(ctx context.Context, fqdn string) {
+		queried <- fqdn
+		answerA(c, fqdn, "2.2.2.2", time.Minute)
+	})
+	common.Must(err)
+	if r := cmp.Diff(ips, []net.IP{{1, 1, 1, 1}}); r != "" {
+		t.Error("expect stale record: ", r)
+	}
+
+	select {
+	case fqdn := <-queried:
+		if fqdn != "example.com." {
+			t.Error("unexpected refresh of ", fqdn)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("stale record is not refreshed")
+	}
+	time.Sleep(time.Millisecond * 100)
+
+	ips, _, err = c.findIPsForDomain("example.com.", ipv4Only, false)
+	common.Must(err)
+	if r := cmp.Diff(ips, []net.IP{{2, 2, 2, 2}}); r != "" {
+		t.Error("expect refreshed record: ", r)
+	}
+}
+
+func TestRecordCacheWithoutServeStale(t *testing.T) {
+	c := newRecordCache("test")
+	answerA(c, "example.com.", "1.1.1.1", -time.Minute)
+
+	ips, err := c.queryIP(context.Background(), "example.com", ipv4Only, false, func(ctx context.Context, fqdn string) {
+		go answerA(c, fqdn, "2.2.2.2", time.Minute)
+	})
+	common.Must(err)
+	if r := cmp.Diff(ips, []net.IP{{2, 2, 2, 2}}); r != "" {
+		t.Error("expect new record: ", r)
+	}
+}
+
+func TestRecordCachePrefetch(t *testing.T) {
+	c := newRecordCache("test")
+	c.setPolicy(newCachePolicy(&CacheConfig{Prefetch: true}))
+	answerA(c, "example.com.", "1.1.1.1", time.Second*10)
+
+	// Move the record close to its expiry.
+	c.ips["example.com."].A.Expire = time.Now().Add(time.Millisecond * 500)
+
+	queried := make(chan string, prefetchHits)
+	sendQuery := func(ctx context.Context, fqdn string) {
+		queried <- fqdn
+	}
+	for i := 1; i <= prefetchHits; i++ {
+		common.Must2(c.queryIP(context.Background(), "example.com", ipv4Only, false, sendQuery))
+		if i < prefetchHits && len(queried) > 0 {
+			t.Fatal("record is prefetched after ", i, " hits")
+		}
+	}
+	select {
+	case <-queried:
+	case <-time.After(time.Second * 5):
+		t.Fatal("popular record is not prefetched")
+	}
+}
+
+func TestRecordCacheClampTTL(t *testing.T) {
+	c := newRecordCache("test")
+	c.setPolicy(newCachePolicy(&CacheConfig{MinTtl: 60, MaxTtl: 600}))
+
+	answerA(c, "short.com.", "1.1.1.1", time.Second)
+	answerA(c, "long.com.", "2.2.2.2", time.Hour)
+
+	if ttl := time.Until(c.ips["short.com."].A.Expire); ttl < time.Second*59 || ttl > time.Minute {
+		t.Error("TTL is not clamped to min TTL: ", ttl)
+	}
+	if ttl := time.Until(c.ips["long.com."].A.Expire); ttl < time.Second*599 || ttl > time.Minute*10 {
+		t.Error("TTL is not clamped to max TTL: ", ttl)
+	}
+}
+
+func TestRecordCacheExportRestore(t *testing.T) {
+	c := newRecordCache("test")
+	c.setPolicy(newCachePolicy(&CacheConfig{ServeStale: true}))
+	answerA(c, "example.com.", "1.1.1.1", time.Minute)
+	answerA(c, "expired.com.", "2.2.2.2", -time.Minute)
+	records := c.export()
+	if len(records) != 2 {
+		t.Fatal("expect 2 records, but got ", len(records))
+	}
+
+	other := newRecordCache("other")
+	other.restore(records)
+	if len(other.ips) != 0 {
+		t.Error("records of other name servers are restored")
+	}
+
+	restored := newRecordCache("test")
+	restored.restore(records)
+	ips, _, err := restored.findIPsForDomain("example.com.", ipv4Only, false)
+	common.Must(err)
+	if r := cmp.Diff(ips, []net.IP{{1, 1, 1, 1}}); r != "" {
+		t.Error(r)
+	}
+	if _, found := restored.ips["expired.com."]; found {
+		t.Error("expired record is restored")
+	}
+}
+
+func TestPersistentCacheWithoutStorage(t *testing.T) {
+	ctx := context.Background()
+	defaultNetworkImpl := systemnetworkimpl.NewSystemNetworkDefault()
+	deferredPersistentStorageImpl := deferredpersistentstorage.NewDeferredPersistentStorage(ctx)
+	rootEnv := environment.NewRootEnvImpl(ctx,
+		transientstorageimpl.NewScopedTransientStorageImpl(), defaultNetworkImpl.Dialer(), defaultNetworkImpl.Listener(),
+		filesystemimpl.NewDefaultFileSystemDefaultImpl(), deferredPersistentStorageImpl)
+	deferredPersistentStorageImpl.ProvideInner(ctx, nil)
+	ctx = envctx.ContextWithEnvironment(ctx, rootEnv.AppEnvironment("dns"))
+
+	s, err := New(ctx, &Config{Cache: &CacheConfig{Persistent: true}})
+	common.Must(err)
+	common.Must(s.Start())
+	if s.state().persistCache || s.cacheSaver != nil {
+		t.Error("expect the cache not to be persisted")
+	}
+	common.Must(s.Close())
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/common/platform"
 	"github.com/frogwall/f2ray-core/v5/common/session"
 	"github.com/frogwall/f2ray-core/v5/common/strmatcher"
+	"github.com/frogwall/f2ray-core/v5/common/task"
 	"github.com/frogwall/f2ray-core/v5/features"
 	"github.com/frogwall/f2ray-core/v5/features/dns"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
@@ -31,6 +32,7 @@ import (
 // DNS is a DNS rely server.
 type DNS struct {
 	sync.Mutex
+	access        sync.RWMutex
 	hosts         *StaticHosts
 	clients       []*Client
 	ctx           context.Context
@@ -38,6 +40,9 @@ type DNS struct {
 	fakeDNSEngine *FakeDNSEngine
 	domainMatcher strmatcher.IndexMatcher
 	matcherInfos  []DomainMatcherInfo
+	ruleSets      []*router.RuleSetProvider
+	persistCache  bool
+	cacheSaver    *task.Periodic
 }
 
 // DomainMatcherInfo contains information attached to index returned by Server.domainMatcher
@@ -82,9 +87,10 @@ func New(ctx context.Context, config *Config) (*DNS, error) {
 	}
 
 	s := &DNS{
-		hosts:   hosts,
-		clients: clients,
-		ctx:     ctx,
+		hosts:        hosts,
+		clients:      clients,
+		ctx:          ctx,
+		persistCache: config.GetCache().GetPersistent(),
 	}
 
 	// Establish members related to global DNS state
@@ -95,6 +101,9 @@ func New(ctx context.Context, config *Config) (*DNS, error) {
 	if err := establishDomainRules(s, config, nsClientMap); err != nil {
 		return nil, err
 	}
+	if err := establishRuleSets(s, config, nsClientMap); err != nil {
+		return nil, err
+	}
 	if err := establishExpectedIPs(s, config, nsClientMap); err != nil {
 		return nil, err
 	}
@@ -167,6 +176,32 @@ func establishDomainRules(s *DNS, config *Config, nsClientMap map[int]int) error
 	return nil
 }
 
+func establishRuleSets(s *DNS, config *Config, nsClientMap map[int]int) error {
+	ruleSets := make(map[string]*router.RuleSetProvider, len(config.RuleSet))
+	for _, rs := range config.RuleSet {
+		if _, found := ruleSets[rs.Tag]; found {
+			return newError("duplicated rule-set ", rs.Tag)
+		}
+		provider, err := router.NewRuleSetProvider(s.ctx, rs)
+		if err != nil {
+			return newError("failed to build rule-set").Base(err)
+		}
+		ruleSets[rs.Tag] = provider
+		s.ruleSets = append(s.ruleSets, provider)
+	}
+	for nsIdx, ns := range config.NameServer {
+		clientIdx := nsClientMap[nsIdx]
+		for _, tag := range ns.RuleSet {
+			provider, found := ruleSets[tag]
+			if !found {
+				return newError("rule-set ", tag, " not found")
+			}
+			s.clients[clientIdx].ruleSets = append(s.clients[clientIdx].ruleSets, provider)
+		}
+	}
+	return nil
+}
+
 func establishExpectedIPs(s *DNS, config *Config, nsClientMap map[int]int) error {
 	geoipContainer := router.GeoIPMatcherContainer{}
 	for nsIdx, ns := range config.NameServer {
@@ -185,7 +220,7 @@ func establishExpectedIPs(s *DNS, config *Config, nsClientMap map[int]int) error
 }
 
 func establishFakeDNS(s *DNS, config *Config, nsClientMap map[int]int) error {
-	fakeHolders := &fakedns.HolderMulti{}
+	fakeHolders := fakedns.NewEmptyFakeDNSHolderMulti(s.ctx)
 	fakeDefault := (*fakedns.HolderMulti)(nil)
 	if config.FakeDns != nil {
 		defaultEngine, err := fakeHolders.AddPoolMulti(config.FakeDns)
@@ -231,18 +266,155 @@ func (*DNS) Type() interface{} {
 
 // Start implements common.Runnable.
 func (s *DNS) Start() error {
+	for _, provider := range s.ruleSets {
+		if err := provider.Start(); err != nil {
+			return newError("failed to start rule-set ", provider.Tag()).Base(err)
+		}
+	}
+	if s.persistCache {
+		return s.startCacheSaver(true)
+	}
 	return nil
 }
 
+// startCacheSaver saves the cache of name servers periodically, and restores
+// it first if load is set. The cache falls back to not being persisted if the
+// persistent storage is not available.
+func (s *DNS) startCacheSaver(load bool) error {
+	storage, err := s.cacheStorage()
+	if err != nil {
+		newError("DNS cache is not persisted").Base(err).AtWarning().WriteToLog()
+		s.access.Lock()
+		s.persistCache = false
+		s.access.Unlock()
+		return nil
+	}
+	if load {
+		s.loadCache(storage)
+	}
+	s.cacheSaver = &task.Periodic{
+		Interval: cacheSaveInterval,
+		Execute: func() error {
+			if err := s.saveCache(); err != nil {
+				newError("failed to save DNS cache").Base(err).AtWarning().WriteToLog()
+			}
+			return nil
+		},
+	}
+	return s.cacheSaver.Start()
+}
+
 // Close implements common.Closable.
 func (s *DNS) Close() error {
+	if s.cacheSaver != nil {
+		s.cacheSaver.Close()
+	}
+	state := s.state()
+	for _, provider := range state.ruleSets {
+		provider.Close()
+	}
+	for _, client := range state.clients {
+		client.Close()
+	}
+	if err := s.saveCache(); err != nil {
+		return newError("failed to save DNS cache").Base(err)
+	}
 	return nil
 }
 
+// PrepareReload implements features.Reloadable.
+func (s *DNS) PrepareReload(config interface{}) (func(), error) {
+	if s.fakeDNSEngine != nil || hasFakeDNS(config) {
+		return nil, newError("DNS with FakeDNS cannot be reloaded")
+	}
+	obj, err := common.CreateObject(s.ctx, config)
+	if err != nil {
+		return nil, newError("failed to build DNS").Base(err)
+	}
+	next, ok := obj.(*DNS)
+	if !ok {
+		return nil, newError("not a DNS config")
+	}
+	return func() {
+		// Records are moved to the new name servers, so that they are not
+		// queried again after the reload.
+		restoreCache(next.clients, exportCache(s.state().clients))
+		for _, provider := range next.ruleSets {
+			if err := provider.Start(); err != nil {
+				newError("failed to start rule-set ", provider.Tag()).Base(err).AtWarning().WriteToLog()
+			}
+		}
+
+		s.access.Lock()
+		previousClients := s.clients
+		previousRuleSets := s.ruleSets
+		s.hosts = next.hosts
+		s.clients = next.clients
+		s.clientTags = next.clientTags
+		s.domainMatcher = next.domainMatcher
+		s.matcherInfos = next.matcherInfos
+		s.ruleSets = next.ruleSets
+		s.persistCache = next.persistCache
+		s.access.Unlock()
+		for _, provider := range previousRuleSets {
+			provider.Close()
+		}
+		for _, client := range previousClients {
+			if err := client.Close(); err != nil {
+				newError("failed to close name server ", client.Name()).Base(err).AtDebug().WriteToLog()
+			}
+		}
+		if next.persistCache && s.cacheSaver == nil {
+			if err := s.startCacheSaver(false); err != nil {
+				newError("failed to start saving DNS cache").Base(err).AtWarning().WriteToLog()
+			}
+		}
+	}, nil
+}
+
+func hasFakeDNS(config interface{}) bool {
+	switch config := config.(type) {
+	case *Config:
+		if config.FakeDns != nil {
+			return true
+		}
+		for _, ns := range config.NameServer {
+			if ns.FakeDns != nil {
+				return true
+			}
+		}
+	case *SimplifiedConfig:
+		return config.FakeDns != nil
+	}
+	return false
+}
+
+// state returns a view of the DNS that is not changed by reloads.
+func (s *DNS) state() *DNS {
+	s.access.RLock()
+	defer s.access.RUnlock()
+	return &DNS{
+		hosts:         s.hosts,
+		clients:       s.clients,
+		ctx:           s.ctx,
+		clientTags:    s.clientTags,
+		fakeDNSEngine: s.fakeDNSEngine,
+		domainMatcher: s.domainMatcher,
+		matcherInfos:  s.matcherInfos,
+		ruleSets:      s.ruleSets,
+		persistCache:  s.persistCache,
+	}
+}
+
 // IsOwnLink implements proxy.dns.ownLinkVerifier
 func (s *DNS) IsOwnLink(ctx context.Context) bool {
 	inbound := session.InboundFromContext(ctx)
-	return inbound != nil && s.clientTags[inbound.Tag]
+	if inbound == nil {
+		return false
+	}
+	s.access.RLock()
+	defer s.access.RUnlock()
+	return s.clientTags[inbound.Tag]
 }
 
 // AsFakeDNSClient implements dns.ClientWithFakeDNS.
@@ -278,6 +450,8 @@ func (s *DNS) lookupIPInternal(domain string, option dns.IPOption) ([]net.IP, er
 	// Normalize the FQDN form query
 	domain = strings.TrimSuffix(domain, ".")
 
+	s = s.state()
+
 	// Static host lookup
 	switch addrs := s.hosts.Lookup(domain, option); {
 	case addrs == nil: // Domain not recorded in static host
@@ -339,6 +513,25 @@ func (s *DNS) sortClients(domain string, option dns.IPOption) []*Client {
 		clientIdxs = append(clientIdxs, int(info.clientIdx))
 	}
 
+	// Rule-set matching
+	for idx, client := range s.clients {
+		switch {
+		case clientUsed[idx]:
+			continue
+		case !option.FakeEnable && isFakeDNS(client.server):
+			continue
+		}
+		for _, set := range client.ruleSets {
+			if set.MatchDomain(domain) {
+				domainRules = append(domainRules, fmt.Sprintf("rule-set:%s(DNS idx:%d)", set.Tag(), idx))
+				clientUsed[idx] = true
+				clients = append(clients, client)
+				clientIdxs = append(clientIdxs, idx)
+				break
+			}
+		}
+	}
+
 	// Default round-robin query
 	hasDomainMatch := len(clients) > 0
 	for idx, client := range s.clients {
@@ -468,6 +661,7 @@ func init() {
 				FallbackStrategy: v.FallbackStrategy,
 				SkipFallback:     v.SkipFallback,
 				Geoip:            v.Geoip,
+				RuleSet:          v.RuleSet,
 			}
 			for _, prioritizedDomain := range v.PrioritizedDomain {
 				nameserver.PrioritizedDomain = append(nameserver.PrioritizedDomain, &NameServer_PriorityDomain{
@@ -501,6 +695,8 @@ func init() {
 			QueryStrategy:    simplifiedConfig.QueryStrategy,
 			CacheStrategy:    simplifiedConfig.CacheStrategy,
 			FallbackStrategy: simplifiedConfig.FallbackStrategy,
+			Cache:            simplifiedConfig.Cache,
+			RuleSet:          simplifiedConfig.RuleSet,
 			// Deprecated flags
 			DisableCache:           simplifiedConfig.DisableCache,
 			DisableFallback:        simplifiedConfig.DisableFallback,
@@ -22,8 +22,8 @@ func Fqdn(domain string) string {
 }
 
 type record struct {
-	A    *IPRecord
-	AAAA *IPRecord
+	A    *cacheItem
+	AAAA *cacheItem
 }
 
 // IPRecord is a cacheable item for a resolved domain
@@ -34,17 +34,7 @@ type IPRecord struct {
 	RCode  dnsmessage.RCode
 }
 
-func (r *IPRecord) getIPs() ([]net.Address, error) {
-	if r == nil || r.Expire.Before(time.Now()) {
-		return nil, errRecordNotFound
-	}
-	if r.RCode != dnsmessage.RCodeSuccess {
-		return nil, dns_feature.RCodeError(r.RCode)
-	}
-	return r.IP, nil
-}
-
-func isNewer(baseRec *IPRecord, newRec *IPRecord) bool {
+func isNewer(baseRec *cacheItem, newRec *IPRecord) bool {
 	if newRec == nil {
 		return false
 	}
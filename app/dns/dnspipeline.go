@@ -0,0 +1,217 @@
+//go:build !confonly
+// +build !confonly
+
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+)
+
+// pipelineIdleTimeout is how long a pipelined connection is kept open without
+// any pending query.
+const pipelineIdleTimeout = time.Second * 30
+
+var (
+	errPipelineIdle   = newError("pipelined connection is idle")
+	errPipelineClosed = newError("pipeline closed")
+)
+
+// dnsPipeline sends DNS messages over a reused stream connection, with the
+// 2-byte length prefix of DNS over TCP. Queries are pipelined (RFC7766 6.2.1.1),
+// and responses, which may come out of order, are matched to queries by their IDs.
+type dnsPipeline struct {
+	sync.Mutex
+	dial        func(context.Context) (net.Conn, error)
+	idleTimeout time.Duration
+	conn        *pipelineConn
+}
+
+func newDNSPipeline(dial func(context.Context) (net.Conn, error)) *dnsPipeline {
+	return &dnsPipeline{
+		dial:        dial,
+		idleTimeout: pipelineIdleTimeout,
+	}
+}
+
+// Exchange sends a query and waits for its response. A reused connection
+// may have been closed by the server, so the query is sent again on a new
+// connection if the reused one fails.
+func (p *dnsPipeline) Exchange(ctx context.Context, query []byte) ([]byte, error) {
+	if len(query) < 2 {
+		return nil, newError("invalid query")
+	}
+	id := binary.BigEndian.Uint16(query)
+	for retried := false; ; retried = true {
+		conn, reused, err := p.getConn(ctx)
+		if err != nil {
+			return nil, newError("failed to dial nameserver").Base(err)
+		}
+		resp, err := conn.exchange(ctx, id, query)
+		if err == nil {
+			return resp, nil
+		}
+		if !reused || retried || ctx.Err() != nil {
+			return nil, err
+		}
+		newError("retrying query on a new connection").Base(err).AtDebug().WriteToLog()
+	}
+}
+
+// Close closes the current connection. Pending queries on it fail.
+func (p *dnsPipeline) Close() error {
+	p.Lock()
+	conn := p.conn
+	p.conn = nil
+	p.Unlock()
+	if conn != nil {
+		conn.close(errPipelineClosed)
+	}
+	return nil
+}
+
+func (p *dnsPipeline) getConn(ctx context.Context) (*pipelineConn, bool, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.conn != nil && !p.conn.done.Done() {
+		return p.conn, true, nil
+	}
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	p.conn = newPipelineConn(conn, p.idleTimeout)
+	return p.conn, false, nil
+}
+
+// pipelineConn is a connection of a dnsPipeline.
+type pipelineConn struct {
+	sync.Mutex
+	conn        net.Conn
+	writeAccess sync.Mutex
+	pending     map[uint16]chan []byte
+	idle        *time.Timer
+	idleTimeout time.Duration
+	done        *done.Instance
+	err         error
+}
+
+func newPipelineConn(conn net.Conn, idleTimeout time.Duration) *pipelineConn {
+	c := &pipelineConn{
+		conn:        conn,
+		pending:     make(map[uint16]chan []byte),
+		idleTimeout: idleTimeout,
+		done:        done.New(),
+	}
+	c.idle = time.AfterFunc(idleTimeout, c.closeIfIdle)
+	go c.readResponses()
+	return c
+}
+
+func (c *pipelineConn) exchange(ctx context.Context, id uint16, query []byte) ([]byte, error) {
+	ch := make(chan []byte, 1)
+	c.Lock()
+	if c.done.Done() {
+		c.Unlock()
+		return nil, newError("connection is closed").Base(c.err)
+	}
+	if _, found := c.pending[id]; found {
+		c.Unlock()
+		return nil, newError("query ID ", id, " is in use")
+	}
+	c.pending[id] = ch
+	c.idle.Stop()
+	c.Unlock()
+
+	frame := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(frame, uint16(len(query)))
+	copy(frame[2:], query)
+
+	c.writeAccess.Lock()
+	_, err := c.conn.Write(frame)
+	c.writeAccess.Unlock()
+	if err != nil {
+		c.close(err)
+		return nil, newError("failed to send query").Base(err)
+	}
+
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-c.done.Wait():
+		return nil, newError("connection is closed").Base(c.err)
+	case <-ctx.Done():
+		c.Lock()
+		delete(c.pending, id)
+		c.resetIdle()
+		c.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *pipelineConn) readResponses() {
+	var header [2]byte
+	for {
+		if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+			c.close(err)
+			return
+		}
+		resp := make([]byte, binary.BigEndian.Uint16(header[:]))
+		if _, err := io.ReadFull(c.conn, resp); err != nil {
+			c.close(err)
+			return
+		}
+		if len(resp) < 2 {
+			continue
+		}
+
+		id := binary.BigEndian.Uint16(resp)
+		c.Lock()
+		ch, found := c.pending[id]
+		delete(c.pending, id)
+		c.resetIdle()
+		c.Unlock()
+		if found {
+			ch <- resp
+		} else {
+			newError("dropped response of unknown query ID ", id).AtDebug().WriteToLog()
+		}
+	}
+}
+
+// resetIdle starts the idle timer if there is no pending query. It must be
+// called with the lock held.
+func (c *pipelineConn) resetIdle() {
+	if len(c.pending) == 0 && !c.done.Done() {
+		c.idle.Reset(c.idleTimeout)
+	}
+}
+
+func (c *pipelineConn) closeIfIdle() {
+	c.Lock()
+	idle := len(c.pending) == 0
+	c.Unlock()
+	if idle {
+		c.close(errPipelineIdle)
+	}
+}
+
+func (c *pipelineConn) close(err error) {
+	c.Lock()
+	if c.done.Done() {
+		c.Unlock()
+		return
+	}
+	c.err = err
+	c.done.Close()
+	c.idle.Stop()
+	c.Unlock()
+	c.conn.Close()
+}
@@ -0,0 +1,134 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	gonet "net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+func readFrame(t *testing.T, conn net.Conn) []byte {
+	var header [2]byte
+	common.Must2(io.ReadFull(conn, header[:]))
+	msg := make([]byte, binary.BigEndian.Uint16(header[:]))
+	common.Must2(io.ReadFull(conn, msg))
+	return msg
+}
+
+func writeFrame(conn net.Conn, msg []byte) {
+	frame := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(frame, uint16(len(msg)))
+	copy(frame[2:], msg)
+	common.Must2(conn.Write(frame))
+}
+
+func TestDNSPipeline(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	// The server answers each pair of queries in reverse order, by echoing them,
+	// then closes the connection.
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			first := readFrame(t, conn)
+			second := readFrame(t, conn)
+			writeFrame(conn, second)
+			writeFrame(conn, first)
+			conn.Close()
+		}
+	}()
+
+	var dials int32
+	pipeline := newDNSPipeline(func(ctx context.Context) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		return net.Dial("tcp", listener.Addr().String())
+	})
+
+	exchange := func(queries ...[]byte) {
+		results := make(chan error, len(queries))
+		for _, query := range queries {
+			go func(query []byte) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+				defer cancel()
+				resp, err := pipeline.Exchange(ctx, query)
+				if err == nil && string(resp) != string(query) {
+					err = newError("unexpected response ", resp, " to ", query)
+				}
+				results <- err
+			}(query)
+		}
+		for range queries {
+			common.Must(<-results)
+		}
+	}
+
+	exchange([]byte{0, 1, 'a'}, []byte{0, 2, 'b'})
+	if n := atomic.LoadInt32(&dials); n != 1 {
+		t.Fatal("expect 1 dial, but got ", n)
+	}
+
+	// The connection is closed by the server, and a new one is dialed.
+	exchange([]byte{0, 3, 'c'}, []byte{0, 4, 'd'})
+	if n := atomic.LoadInt32(&dials); n != 2 {
+		t.Fatal("expect 2 dials, but got ", n)
+	}
+}
+
+func TestDNSPipelineIdle(t *testing.T) {
+	client, server := gonet.Pipe()
+	defer server.Close()
+
+	conn := newPipelineConn(client, time.Millisecond*100)
+	go func() {
+		writeFrame(server, readFrame(t, server))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	common.Must2(conn.exchange(ctx, 1, []byte{0, 1}))
+
+	select {
+	case <-conn.done.Wait():
+	case <-time.After(time.Second * 5):
+		t.Fatal("idle connection is not closed")
+	}
+	if conn.err != errPipelineIdle {
+		t.Error("unexpected error: ", conn.err)
+	}
+}
+
+func TestDNSPipelineClose(t *testing.T) {
+	client, server := gonet.Pipe()
+	defer server.Close()
+
+	pipeline := newDNSPipeline(func(ctx context.Context) (net.Conn, error) {
+		return client, nil
+	})
+	go func() {
+		writeFrame(server, readFrame(t, server))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	common.Must2(pipeline.Exchange(ctx, []byte{0, 1}))
+
+	conn := pipeline.conn
+	common.Must(pipeline.Close())
+	if !conn.done.Done() {
+		t.Fatal("connection is not closed")
+	}
+	if conn.err != errPipelineClosed {
+		t.Error("unexpected error: ", conn.err)
+	}
+}
@@ -5,17 +5,29 @@ package fakedns
 
 import (
 	"context"
+	"hash/fnv"
 	"math"
 	"math/big"
 	gonet "net"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/frogwall/f2ray-core/v5/app/persistentstorage/protostorage"
 	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/cache"
+	"github.com/frogwall/f2ray-core/v5/common/environment"
+	"github.com/frogwall/f2ray-core/v5/common/environment/envctx"
 	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/task"
 	"github.com/frogwall/f2ray-core/v5/features/dns"
 )
 
+// saveInterval is how often the mappings of a persistent pool are saved.
+const saveInterval = time.Minute * 10
+
+var storageKeyReplacer = strings.NewReplacer("/", "_", ":", "-")
+
 type Holder struct {
 	domainToIP cache.Lru
 	nextIP     *big.Int
@@ -24,6 +36,10 @@ type Holder struct {
 	ipRange *gonet.IPNet
 
 	config *FakeDnsPool
+
+	ctx     context.Context
+	storage protostorage.ProtoPersistentStorage
+	saver   *task.Periodic
 }
 
 func (fkdns *Holder) IsIPInIPPool(ip net.Address) bool {
@@ -47,12 +63,25 @@ func (*Holder) Type() interface{} {
 
 func (fkdns *Holder) Start() error {
 	if fkdns.config != nil && fkdns.config.IpPool != "" && fkdns.config.LruSize != 0 {
-		return fkdns.initializeFromConfig()
+		if err := fkdns.initializeFromConfig(); err != nil {
+			return err
+		}
+		if fkdns.config.Persistent {
+			return fkdns.startPersistence()
+		}
+		return nil
 	}
 	return newError("invalid fakeDNS setting")
 }
 
 func (fkdns *Holder) Close() error {
+	if fkdns.saver != nil {
+		fkdns.saver.Close()
+		fkdns.saver = nil
+		if err := fkdns.save(); err != nil {
+			newError("failed to save fake dns mappings of ", fkdns.ipRange).Base(err).AtWarning().WriteToLog()
+		}
+	}
 	fkdns.domainToIP = nil
 	fkdns.nextIP = nil
 	fkdns.ipRange = nil
@@ -75,7 +104,7 @@ func NewFakeDNSHolder() (*Holder, error) {
 }
 
 func NewFakeDNSHolderConfigOnly(conf *FakeDnsPool) (*Holder, error) {
-	return &Holder{nil, nil, nil, nil, conf}, nil
+	return &Holder{config: conf}, nil
 }
 
 func (fkdns *Holder) initializeFromConfig() error {
@@ -117,6 +146,11 @@ func (fkdns *Holder) GetFakeIPForDomain(domain string) []net.Address {
 		return []net.Address{v.(net.Address)}
 	}
 	var ip net.Address
+	if fkdns.config != nil && fkdns.config.Persistent {
+		ip = fkdns.allocateFromHash(domain)
+		fkdns.domainToIP.Put(domain, ip)
+		return []net.Address{ip}
+	}
 	for {
 		ip = net.IPAddress(fkdns.nextIP.Bytes())
 
@@ -134,6 +168,118 @@ func (fkdns *Holder) GetFakeIPForDomain(domain string) []net.Address {
 	return []net.Address{ip}
 }
 
+// allocateFromHash returns the IP at the hash of domain in the pool, or the
+// first unused IP after it, so that a domain gets the same IP across restarts
+// as long as its IP is not taken by another domain.
+func (fkdns *Holder) allocateFromHash(domain string) net.Address {
+	ones, bits := fkdns.ipRange.Mask.Size()
+	size := big.NewInt(0).Lsh(big.NewInt(1), uint(bits-ones))
+	base := big.NewInt(0).SetBytes(fkdns.ipRange.IP)
+
+	hash := fnv.New64a()
+	hash.Write([]byte(domain))
+	offset := big.NewInt(0).SetUint64(hash.Sum64())
+	offset.Mod(offset, size)
+
+	ipBytes := make([]byte, len(fkdns.ipRange.IP))
+	for {
+		ip := net.IPAddress(big.NewInt(0).Add(base, offset).FillBytes(ipBytes))
+		if _, ok := fkdns.domainToIP.GetKeyFromValue(ip); !ok {
+			return ip
+		}
+		offset.Add(offset, big.NewInt(1))
+		if offset.Cmp(size) >= 0 {
+			offset.SetInt64(0)
+		}
+	}
+}
+
+// startPersistence restores the mappings of the pool, and saves them
+// periodically. The pool falls back to not being persisted if the persistent
+// storage is not available.
+func (fkdns *Holder) startPersistence() error {
+	if fkdns.storage == nil {
+		storage, err := fkdns.persistentStorage()
+		if err != nil {
+			newError("fake dns pool ", fkdns.ipRange, " is not persisted").Base(err).AtWarning().WriteToLog()
+			return nil
+		}
+		fkdns.storage = storage
+	}
+	fkdns.load()
+	fkdns.saver = &task.Periodic{
+		Interval: saveInterval,
+		Execute: func() error {
+			if err := fkdns.save(); err != nil {
+				newError("failed to save fake dns mappings of ", fkdns.ipRange).Base(err).AtWarning().WriteToLog()
+			}
+			return nil
+		},
+	}
+	return fkdns.saver.Start()
+}
+
+func (fkdns *Holder) persistentStorage() (protostorage.ProtoPersistentStorage, error) {
+	if fkdns.ctx == nil {
+		return nil, newError("app environment is not available")
+	}
+	appEnvironment, ok := envctx.EnvironmentFromContext(fkdns.ctx).(environment.AppEnvironment)
+	if !ok {
+		return nil, newError("persistent storage is not available")
+	}
+	storage, err := appEnvironment.PersistentStorage().NarrowScope(fkdns.ctx, []byte("fakedns"))
+	if err != nil {
+		return nil, newError("failed to get persistent storage for fakedns").Base(err)
+	}
+	return storage.(protostorage.ProtoPersistentStorage), nil
+}
+
+func (fkdns *Holder) storageKey() string {
+	return storageKeyReplacer.Replace(fkdns.ipRange.String())
+}
+
+// load restores the mappings of the pool from the persistent storage.
+func (fkdns *Holder) load() {
+	snapshot := new(FakeDnsSnapshot)
+	if err := fkdns.storage.GetProto(fkdns.context(), fkdns.storageKey(), snapshot); err != nil {
+		newError("failed to load fake dns mappings of ", fkdns.ipRange).Base(err).AtInfo().WriteToLog()
+		return
+	}
+	fkdns.mu.Lock()
+	defer fkdns.mu.Unlock()
+	for _, mapping := range snapshot.Mapping {
+		ip := net.IPAddress(mapping.Ip)
+		if mapping.Domain == "" || !ip.Family().IsIP() || !fkdns.ipRange.Contains(ip.IP()) {
+			continue
+		}
+		if _, ok := fkdns.domainToIP.GetKeyFromValue(ip); ok {
+			continue
+		}
+		fkdns.domainToIP.Put(mapping.Domain, ip)
+	}
+	newError("loaded ", len(snapshot.Mapping), " fake dns mappings of ", fkdns.ipRange).AtInfo().WriteToLog()
+}
+
+// save saves the mappings of the pool to the persistent storage.
+func (fkdns *Holder) save() error {
+	snapshot := new(FakeDnsSnapshot)
+	fkdns.domainToIP.Range(func(key, value interface{}) bool {
+		snapshot.Mapping = append(snapshot.Mapping, &FakeDnsMapping{
+			Domain: key.(string),
+			Ip:     value.(net.Address).IP(),
+		})
+		return true
+	})
+	return fkdns.storage.PutProto(fkdns.context(), fkdns.storageKey(), snapshot)
+}
+
+func (fkdns *Holder) context() context.Context {
+	if fkdns.ctx == nil {
+		return context.Background()
+	}
+	return fkdns.ctx
+}
+
 // GetDomainFromFakeDNS checks if an IP is a fake IP and have corresponding domain name
 func (fkdns *Holder) GetDomainFromFakeDNS(ip net.Address) string {
 	if !ip.Family().IsIP() || !fkdns.ipRange.Contains(ip.IP()) {
@@ -147,6 +293,14 @@ func (fkdns *Holder) GetDomainFromFakeDNS(ip net.Address) string {
 
 type HolderMulti struct {
 	holders []*Holder
+
+	ctx context.Context
+}
+
+// NewEmptyFakeDNSHolderMulti creates a HolderMulti without pools, whose pools
+// access the app environment of ctx.
+func NewEmptyFakeDNSHolderMulti(ctx context.Context) *HolderMulti {
+	return &HolderMulti{ctx: ctx}
 }
 
 func (h *HolderMulti) IsIPInIPPool(ip net.Address) bool {
@@ -218,6 +372,7 @@ func (h *HolderMulti) AddPool(poolConfig *FakeDnsPool) (*Holder, error) {
 	if err != nil {
 		return nil, err
 	}
+	holder.ctx = h.ctx
 	if running {
 		if err := holder.Start(); err != nil {
 			return nil, err
@@ -286,13 +441,13 @@ func init() {
 		if f, err = NewFakeDNSHolderConfigOnly(config.(*FakeDnsPool)); err != nil {
 			return nil, err
 		}
+		f.ctx = ctx
 		return f, nil
 	}))
 
 	common.Must(common.RegisterConfig((*FakeDnsPoolMulti)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
-		var f *HolderMulti
-		var err error
-		if f, err = NewFakeDNSHolderMulti(config.(*FakeDnsPoolMulti)); err != nil {
+		f := NewEmptyFakeDNSHolderMulti(ctx)
+		if err := f.createHolderGroups(config.(*FakeDnsPoolMulti)); err != nil {
 			return nil, err
 		}
 		return f, nil
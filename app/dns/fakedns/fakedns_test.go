@@ -1,14 +1,23 @@
 package fakedns
 
 import (
+	"context"
 	gonet "net"
+	"os"
 	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/environment"
+	"github.com/frogwall/f2ray-core/v5/common/environment/deferredpersistentstorage"
+	"github.com/frogwall/f2ray-core/v5/common/environment/envctx"
+	"github.com/frogwall/f2ray-core/v5/common/environment/filesystemimpl"
+	"github.com/frogwall/f2ray-core/v5/common/environment/systemnetworkimpl"
+	"github.com/frogwall/f2ray-core/v5/common/environment/transientstorageimpl"
 	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/uuid"
 )
@@ -313,3 +322,88 @@ func TestFakeDNSMultiAddPool(t *testing.T) {
 		runTest(false)
 	})
 }
+
+type memoryStorage map[string][]byte
+
+func (s memoryStorage) PutProto(_ context.Context, key string, pb proto.Message) error {
+	data, err := proto.Marshal(pb)
+	if err != nil {
+		return err
+	}
+	s[key] = data
+	return nil
+}
+
+func (s memoryStorage) GetProto(_ context.Context, key string, pb proto.Message) error {
+	data, ok := s[key]
+	if !ok {
+		return os.ErrNotExist
+	}
+	return proto.Unmarshal(data, pb)
+}
+
+func newPersistentHolder(storage memoryStorage, ipPool string) *Holder {
+	fkdns, err := NewFakeDNSHolderConfigOnly(&FakeDnsPool{
+		IpPool:     ipPool,
+		LruSize:    256,
+		Persistent: true,
+	})
+	common.Must(err)
+	fkdns.storage = storage
+	common.Must(fkdns.Start())
+	return fkdns
+}
+
+func TestFakeDnsHolderPersistent(t *testing.T) {
+	for _, ipPool := range []string{"198.18.0.0/15", "fddd:c5b4:ff5f:f4f0::/64"} {
+		storage := make(memoryStorage)
+		fkdns := newPersistentHolder(storage, ipPool)
+		addr := fkdns.GetFakeIPForDomain("fakednstest.v2fly.org")
+		addr2 := fkdns.GetFakeIPForDomain("fakednstest2.v2fly.org")
+		assert.NotEqual(t, addr[0], addr2[0])
+		common.Must(fkdns.Close())
+
+		restored := newPersistentHolder(storage, ipPool)
+		assert.Equal(t, "fakednstest.v2fly.org", restored.GetDomainFromFakeDNS(addr[0]))
+		assert.Equal(t, "fakednstest2.v2fly.org", restored.GetDomainFromFakeDNS(addr2[0]))
+
+		// Without the saved mappings, domains are still allocated the same IPs.
+		deterministic := newPersistentHolder(make(memoryStorage), ipPool)
+		assert.Equal(t, addr2, deterministic.GetFakeIPForDomain("fakednstest2.v2fly.org"))
+		assert.Equal(t, addr, deterministic.GetFakeIPForDomain("fakednstest.v2fly.org"))
+	}
+}
+
+func TestFakeDnsHolderPersistentCollision(t *testing.T) {
+	fkdns := newPersistentHolder(make(memoryStorage), "198.18.0.0/15")
+	addr := fkdns.GetFakeIPForDomain("fakednstest.v2fly.org")
+
+	other := newPersistentHolder(make(memoryStorage), "198.18.0.0/15")
+	other.domainToIP.Put("taken.v2fly.org", addr[0])
+	addr2 := other.GetFakeIPForDomain("fakednstest.v2fly.org")
+	assert.NotEqual(t, addr, addr2)
+	assert.Equal(t, "taken.v2fly.org", other.GetDomainFromFakeDNS(addr[0]))
+	assert.Equal(t, "fakednstest.v2fly.org", other.GetDomainFromFakeDNS(addr2[0]))
+}
+
+func TestFakeDnsHolderPersistentWithoutStorage(t *testing.T) {
+	ctx := context.Background()
+	defaultNetworkImpl := systemnetworkimpl.NewSystemNetworkDefault()
+	deferredPersistentStorageImpl := deferredpersistentstorage.NewDeferredPersistentStorage(ctx)
+	rootEnv := environment.NewRootEnvImpl(ctx,
+		transientstorageimpl.NewScopedTransientStorageImpl(), defaultNetworkImpl.Dialer(), defaultNetworkImpl.Listener(),
+		filesystemimpl.NewDefaultFileSystemDefaultImpl(), deferredPersistentStorageImpl)
+	deferredPersistentStorageImpl.ProvideInner(ctx, nil)
+
+	fkdns, err := NewFakeDNSHolderConfigOnly(&FakeDnsPool{
+		IpPool:     "198.18.0.0/15",
+		LruSize:    256,
+		Persistent: true,
+	})
+	common.Must(err)
+	fkdns.ctx = envctx.ContextWithEnvironment(ctx, rootEnv.AppEnvironment("fakedns"))
+	common.Must(fkdns.Start())
+	assert.Nil(t, fkdns.saver)
+	assert.NotEmpty(t, fkdns.GetFakeIPForDomain("fakednstest.v2fly.org"))
+	common.Must(fkdns.Close())
+}
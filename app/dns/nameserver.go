@@ -9,6 +9,7 @@ import (
 	core "github.com/frogwall/f2ray-core/v5"
 	"github.com/frogwall/f2ray-core/v5/app/dns/fakedns"
 	"github.com/frogwall/f2ray-core/v5/app/router"
+	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/errors"
 	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/session"
@@ -36,6 +37,7 @@ type Client struct {
 	fallbackStrategy FallbackStrategy
 
 	domains   []string
+	ruleSets  []*router.RuleSetProvider
 	expectIPs []*router.GeoIPMatcher
 	fakeDNS   Server
 }
@@ -64,10 +66,22 @@ func NewServer(ctx context.Context, dest net.Destination, onCreated func(Server)
 			return core.RequireFeatures(ctx, func(dispatcher routing.Dispatcher) error { return onCreatedWithError(NewDoHNameServer(u, dispatcher)) })
 		case strings.EqualFold(u.Scheme, "https+local"): // DOH Local mode
 			return onCreated(NewDoHLocalNameServer(u))
+		case strings.EqualFold(u.Scheme, "h3"): // DOH over HTTP/3 Remote mode
+			return core.RequireFeatures(ctx, func(dispatcher routing.Dispatcher) error { return onCreatedWithError(NewDoH3NameServer(u, dispatcher)) })
+		case strings.EqualFold(u.Scheme, "h3+local"): // DOH over HTTP/3 Local mode
+			return onCreated(NewDoH3LocalNameServer(u))
 		case strings.EqualFold(u.Scheme, "tcp"): // DNS-over-TCP Remote mode
 			return core.RequireFeatures(ctx, func(dispatcher routing.Dispatcher) error { return onCreatedWithError(NewTCPNameServer(u, dispatcher)) })
 		case strings.EqualFold(u.Scheme, "tcp+local"): // DNS-over-TCP Local mode
 			return onCreatedWithError(NewTCPLocalNameServer(u))
+		case strings.EqualFold(u.Scheme, "tls"): // DNS-over-TLS Remote mode
+			return core.RequireFeatures(ctx, func(dispatcher routing.Dispatcher) error { return onCreatedWithError(NewTLSNameServer(u, dispatcher)) })
+		case strings.EqualFold(u.Scheme, "tls+local"): // DNS-over-TLS Local mode
+			return onCreatedWithError(NewTLSLocalNameServer(u))
+		case strings.EqualFold(u.Scheme, "quic"): // DNS-over-QUIC Remote mode
+			return core.RequireFeatures(ctx, func(dispatcher routing.Dispatcher) error {
+				return onCreatedWithError(NewQUICRemoteNameServer(u, dispatcher))
+			})
 		case strings.EqualFold(u.Scheme, "quic+local"): // DNS-over-QUIC Local mode
 			return onCreatedWithError(NewQUICNameServer(u))
 		}
@@ -93,6 +107,9 @@ func NewClient(ctx context.Context, ns *NameServer, dns *Config) (*Client, error
 	if err != nil {
 		return nil, err
 	}
+	if server, ok := client.server.(cachedServer); ok {
+		server.getCache().setPolicy(newCachePolicy(dns.Cache))
+	}
 
 	// Initialize fields with default values
 	if len(ns.Tag) == 0 {
@@ -178,6 +195,11 @@ func (c *Client) Name() string {
 	return c.server.Name()
 }
 
+// Close closes the connections of the name server, if any.
+func (c *Client) Close() error {
+	return common.Close(c.server)
+}
+
 // QueryIP send DNS query to the name server with the client's IP and IP options.
 func (c *Client) QueryIP(ctx context.Context, domain string, option dns.IPOption) ([]net.IP, error) {
 	queryOption := option.With(c.queryStrategy)
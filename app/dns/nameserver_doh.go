@@ -6,6 +6,7 @@ package dns
 import (
 	"bytes"
 	"context"
+	gotls "crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,14 +14,12 @@ import (
 	"sync"
 	"time"
 
-	"golang.org/x/net/dns/dnsmessage"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 
-	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/protocol/dns"
 	"github.com/frogwall/f2ray-core/v5/common/session"
-	"github.com/frogwall/f2ray-core/v5/common/signal/pubsub"
-	"github.com/frogwall/f2ray-core/v5/common/task"
 	dns_feature "github.com/frogwall/f2ray-core/v5/features/dns"
 	"github.com/frogwall/f2ray-core/v5/features/routing"
 	"github.com/frogwall/f2ray-core/v5/transport/internet"
@@ -31,9 +30,7 @@ import (
 // thus most of the DOH implementation is copied from udpns.go
 type DoHNameServer struct {
 	sync.RWMutex
-	ips        map[string]record
-	pub        *pubsub.Service
-	cleanup    *task.Periodic
+	cache      *recordCache
 	httpClient *http.Client
 	dohURL     string
 	name       string
@@ -108,17 +105,55 @@ func NewDoHLocalNameServer(url *url.URL) *DoHNameServer {
 	return s
 }
 
+// NewDoH3NameServer creates DOH server object over HTTP/3 for remote resolving.
+// Unlike the dispatched TCP connections of DOH, the QUIC connection is kept
+// and reused by later queries.
+func NewDoH3NameServer(url *url.URL, dispatcher routing.Dispatcher) (*DoHNameServer, error) {
+	url.Scheme = "https"
+	s := baseDOHNameServer(url, "DOH3")
+	tr := &http3.Transport{
+		QUICConfig: &quic.Config{
+			HandshakeIdleTimeout: handshakeIdleTimeout,
+		},
+		Dial: func(ctx context.Context, addr string, tlsCfg *gotls.Config, cfg *quic.Config) (*quic.Conn, error) {
+			dest, err := net.ParseDestination("udp:" + addr)
+			if err != nil {
+				return nil, err
+			}
+			return dialDispatchedQUIC(ctx, dispatcher, dest, tlsCfg, cfg)
+		},
+	}
+	s.httpClient = &http.Client{
+		Transport: tr,
+		Timeout:   60 * time.Second,
+	}
+	newError("DNS: created Remote DOH3 client for ", url.String()).AtInfo().WriteToLog()
+	return s, nil
+}
+
+// NewDoH3LocalNameServer creates DOH client object over HTTP/3 for local resolving
+func NewDoH3LocalNameServer(url *url.URL) *DoHNameServer {
+	url.Scheme = "https"
+	s := baseDOHNameServer(url, "DOH3L")
+	tr := &http3.Transport{
+		QUICConfig: &quic.Config{
+			HandshakeIdleTimeout: handshakeIdleTimeout,
+		},
+	}
+	s.httpClient = &http.Client{
+		Timeout:   time.Second * 180,
+		Transport: tr,
+	}
+	newError("DNS: created Local DOH3 client for ", url.String()).AtInfo().WriteToLog()
+	return s
+}
+
 func baseDOHNameServer(url *url.URL, prefix string) *DoHNameServer {
 	s := &DoHNameServer{
-		ips:    make(map[string]record),
-		pub:    pubsub.NewService(),
 		name:   prefix + "//" + url.Host,
 		dohURL: url.String(),
 	}
-	s.cleanup = &task.Periodic{
-		Interval: time.Minute,
-		Execute:  s.Cleanup,
-	}
+	s.cache = newRecordCache(s.name)
 	return s
 }
 
@@ -127,80 +162,6 @@ func (s *DoHNameServer) Name() string {
 	return s.name
 }
 
-// Cleanup clears expired items from cache
-func (s *DoHNameServer) Cleanup() error {
-	now := time.Now()
-	s.Lock()
-	defer s.Unlock()
-
-	if len(s.ips) == 0 {
-		return newError("nothing to do. stopping...")
-	}
-
-	for domain, record := range s.ips {
-		if record.A != nil && record.A.Expire.Before(now) {
-			record.A = nil
-		}
-		if record.AAAA != nil && record.AAAA.Expire.Before(now) {
-			record.AAAA = nil
-		}
-
-		if record.A == nil && record.AAAA == nil {
-			newError(s.name, " cleanup ", domain).AtDebug().WriteToLog()
-			delete(s.ips, domain)
-		} else {
-			s.ips[domain] = record
-		}
-	}
-
-	if len(s.ips) == 0 {
-		s.ips = make(map[string]record)
-	}
-
-	return nil
-}
-
-func (s *DoHNameServer) updateIP(req *dnsRequest, ipRec *IPRecord) {
-	elapsed := time.Since(req.start)
-
-	s.Lock()
-	rec := s.ips[req.domain]
-	updated := false
-
-	switch req.reqType {
-	case dnsmessage.TypeA:
-		if isNewer(rec.A, ipRec) {
-			rec.A = ipRec
-			updated = true
-		}
-	case dnsmessage.TypeAAAA:
-		addr := make([]net.Address, 0)
-		for _, ip := range ipRec.IP {
-			if len(ip.IP()) == net.IPv6len {
-				addr = append(addr, ip)
-			}
-		}
-		ipRec.IP = addr
-		if isNewer(rec.AAAA, ipRec) {
-			rec.AAAA = ipRec
-			updated = true
-		}
-	}
-	newError(s.name, " got answer: ", req.domain, " ", req.reqType, " -> ", ipRec.IP, " ", elapsed).AtInfo().WriteToLog()
-
-	if updated {
-		s.ips[req.domain] = rec
-	}
-	switch req.reqType {
-	case dnsmessage.TypeA:
-		s.pub.Publish(req.domain+"4", nil)
-	case dnsmessage.TypeAAAA:
-		s.pub.Publish(req.domain+"6", nil)
-	}
-	s.Unlock()
-	common.Must(s.cleanup.Start())
-}
-
 func (s *DoHNameServer) newReqID() uint16 {
 	return 0
 }
@@ -255,7 +216,7 @@ func (s *DoHNameServer) sendQuery(ctx context.Context, domain string, clientIP n
 				newError("failed to handle DOH response").Base(err).AtError().WriteToLog()
 				return
 			}
-			s.updateIP(r, rec)
+			s.cache.updateIP(r, rec)
 		}(req)
 	}
 }
@@ -284,96 +245,14 @@ func (s *DoHNameServer) dohHTTPSContext(ctx context.Context, b []byte) ([]byte,
 	return io.ReadAll(resp.Body)
 }
 
-func (s *DoHNameServer) findIPsForDomain(domain string, option dns_feature.IPOption) ([]net.IP, error) {
-	s.RLock()
-	record, found := s.ips[domain]
-	s.RUnlock()
-
-	if !found {
-		return nil, errRecordNotFound
-	}
-
-	var ips []net.Address
-	var lastErr error
-	if option.IPv4Enable {
-		a, err := record.A.getIPs()
-		if err != nil {
-			lastErr = err
-		}
-		ips = append(ips, a...)
-	}
-
-	if option.IPv6Enable {
-		aaaa, err := record.AAAA.getIPs()
-		if err != nil {
-			lastErr = err
-		}
-		ips = append(ips, aaaa...)
-	}
-
-	if len(ips) > 0 {
-		return toNetIP(ips)
-	}
-
-	if lastErr != nil {
-		return nil, lastErr
-	}
-
-	return nil, dns_feature.ErrEmptyResponse
-}
-
 // QueryIP implements Server.
-func (s *DoHNameServer) QueryIP(ctx context.Context, domain string, clientIP net.IP, option dns_feature.IPOption, disableCache bool) ([]net.IP, error) { // nolint: dupl
-	fqdn := Fqdn(domain)
-
-	if disableCache {
-		newError("DNS cache is disabled. Querying IP for ", domain, " at ", s.name).AtDebug().WriteToLog()
-	} else {
-		ips, err := s.findIPsForDomain(fqdn, option)
-		if err != errRecordNotFound {
-			newError(s.name, " cache HIT ", domain, " -> ", ips).Base(err).AtDebug().WriteToLog()
-			return ips, err
-		}
-	}
+func (s *DoHNameServer) QueryIP(ctx context.Context, domain string, clientIP net.IP, option dns_feature.IPOption, disableCache bool) ([]net.IP, error) {
+	return s.cache.queryIP(ctx, domain, option, disableCache, func(ctx context.Context, fqdn string) {
+		s.sendQuery(ctx, fqdn, clientIP, option)
+	})
+}
 
-	// ipv4 and ipv6 belong to different subscription groups
-	var sub4, sub6 *pubsub.Subscriber
-	if option.IPv4Enable {
-		sub4 = s.pub.Subscribe(fqdn + "4")
-		defer sub4.Close()
-	}
-	if option.IPv6Enable {
-		sub6 = s.pub.Subscribe(fqdn + "6")
-		defer sub6.Close()
-	}
-	done := make(chan interface{})
-	go func() {
-		if sub4 != nil {
-			select {
-			case <-sub4.Wait():
-			case <-ctx.Done():
-			}
-		}
-		if sub6 != nil {
-			select {
-			case <-sub6.Wait():
-			case <-ctx.Done():
-			}
-		}
-		close(done)
-	}()
-	s.sendQuery(ctx, fqdn, clientIP, option)
-
-	for {
-		ips, err := s.findIPsForDomain(fqdn, option)
-		if err != errRecordNotFound {
-			return ips, err
-		}
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-done:
-		}
-	}
+// getCache implements cachedServer.
+func (s *DoHNameServer) getCache() *recordCache {
+	return s.cache
 }
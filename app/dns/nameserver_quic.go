@@ -3,22 +3,22 @@ package dns
 import (
 	"bytes"
 	"context"
+	gotls "crypto/tls"
 	"encoding/binary"
 	"net/url"
 	"sync"
 	"time"
 
 	"github.com/quic-go/quic-go"
-	"golang.org/x/net/dns/dnsmessage"
 
 	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/buf"
 	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/protocol/dns"
 	"github.com/frogwall/f2ray-core/v5/common/session"
-	"github.com/frogwall/f2ray-core/v5/common/signal/pubsub"
-	"github.com/frogwall/f2ray-core/v5/common/task"
 	dns_feature "github.com/frogwall/f2ray-core/v5/features/dns"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport"
 	"github.com/frogwall/f2ray-core/v5/transport/internet/tls"
 )
 
@@ -31,18 +31,33 @@ const handshakeIdleTimeout = time.Second * 8
 // QUICNameServer implemented DNS over QUIC
 type QUICNameServer struct {
 	sync.RWMutex
-	ips         map[string]record
-	pub         *pubsub.Service
-	cleanup     *task.Periodic
+	cache       *recordCache
 	name        string
 	destination net.Destination
 	connection  *quic.Conn
+	dispatcher  routing.Dispatcher
 }
 
 // NewQUICNameServer creates DNS-over-QUIC client object for local resolving
 func NewQUICNameServer(url *url.URL) (*QUICNameServer, error) {
 	newError("DNS: created Local DNS-over-QUIC client for ", url.String()).AtInfo().WriteToLog()
+	return baseQUICNameServer(url)
+}
+
+// NewQUICRemoteNameServer creates DNS-over-QUIC server object for remote
+// resolving. The QUIC packets are dispatched with the context of the query
+// that opens the connection.
+func NewQUICRemoteNameServer(url *url.URL, dispatcher routing.Dispatcher) (*QUICNameServer, error) {
+	newError("DNS: created Remote DNS-over-QUIC client for ", url.String()).AtInfo().WriteToLog()
+	s, err := baseQUICNameServer(url)
+	if err != nil {
+		return nil, err
+	}
+	s.dispatcher = dispatcher
+	return s, nil
+}
 
+func baseQUICNameServer(url *url.URL) (*QUICNameServer, error) {
 	var err error
 	port := net.Port(853)
 	if url.Port() != "" {
@@ -54,15 +69,10 @@ func NewQUICNameServer(url *url.URL) (*QUICNameServer, error) {
 	dest := net.UDPDestination(net.ParseAddress(url.Hostname()), port)
 
 	s := &QUICNameServer{
-		ips:         make(map[string]record),
-		pub:         pubsub.NewService(),
 		name:        url.String(),
 		destination: dest,
 	}
-	s.cleanup = &task.Periodic{
-		Interval: time.Minute,
-		Execute:  s.Cleanup,
-	}
+	s.cache = newRecordCache(s.name)
 
 	return s, nil
 }
@@ -72,80 +82,6 @@ func (s *QUICNameServer) Name() string {
 	return s.name
 }
 
-// Cleanup clears expired items from cache
-func (s *QUICNameServer) Cleanup() error {
-	now := time.Now()
-	s.Lock()
-	defer s.Unlock()
-
-	if len(s.ips) == 0 {
-		return newError("nothing to do. stopping...")
-	}
-
-	for domain, record := range s.ips {
-		if record.A != nil && record.A.Expire.Before(now) {
-			record.A = nil
-		}
-		if record.AAAA != nil && record.AAAA.Expire.Before(now) {
-			record.AAAA = nil
-		}
-
-		if record.A == nil && record.AAAA == nil {
-			newError(s.name, " cleanup ", domain).AtDebug().WriteToLog()
-			delete(s.ips, domain)
-		} else {
-			s.ips[domain] = record
-		}
-	}
-
-	if len(s.ips) == 0 {
-		s.ips = make(map[string]record)
-	}
-
-	return nil
-}
-
-func (s *QUICNameServer) updateIP(req *dnsRequest, ipRec *IPRecord) {
-	elapsed := time.Since(req.start)
-
-	s.Lock()
-	rec := s.ips[req.domain]
-	updated := false
-
-	switch req.reqType {
-	case dnsmessage.TypeA:
-		if isNewer(rec.A, ipRec) {
-			rec.A = ipRec
-			updated = true
-		}
-	case dnsmessage.TypeAAAA:
-		addr := make([]net.Address, 0)
-		for _, ip := range ipRec.IP {
-			if len(ip.IP()) == net.IPv6len {
-				addr = append(addr, ip)
-			}
-		}
-		ipRec.IP = addr
-		if isNewer(rec.AAAA, ipRec) {
-			rec.AAAA = ipRec
-			updated = true
-		}
-	}
-	newError(s.name, " got answer: ", req.domain, " ", req.reqType, " -> ", ipRec.IP, " ", elapsed).AtInfo().WriteToLog()
-
-	if updated {
-		s.ips[req.domain] = rec
-	}
-	switch req.reqType {
-	case dnsmessage.TypeA:
-		s.pub.Publish(req.domain+"4", nil)
-	case dnsmessage.TypeAAAA:
-		s.pub.Publish(req.domain+"6", nil)
-	}
-	s.Unlock()
-	common.Must(s.cleanup.Start())
-}
-
 func (s *QUICNameServer) newReqID() uint16 {
 	return 0
 }
@@ -232,103 +168,34 @@ func (s *QUICNameServer) sendQuery(ctx context.Context, domain string, clientIP
 				newError("failed to handle response").Base(err).AtError().WriteToLog()
 				return
 			}
-			s.updateIP(r, rec)
+			s.cache.updateIP(r, rec)
 		}(req)
 	}
 }
 
-func (s *QUICNameServer) findIPsForDomain(domain string, option dns_feature.IPOption) ([]net.IP, error) {
-	s.RLock()
-	record, found := s.ips[domain]
-	s.RUnlock()
-
-	if !found {
-		return nil, errRecordNotFound
-	}
-
-	var ips []net.Address
-	var lastErr error
-	if option.IPv4Enable {
-		a, err := record.A.getIPs()
-		if err != nil {
-			lastErr = err
-		}
-		ips = append(ips, a...)
-	}
-
-	if option.IPv6Enable {
-		aaaa, err := record.AAAA.getIPs()
-		if err != nil {
-			lastErr = err
-		}
-		ips = append(ips, aaaa...)
-	}
-
-	if len(ips) > 0 {
-		return toNetIP(ips)
-	}
-
-	if lastErr != nil {
-		return nil, lastErr
-	}
-
-	return nil, dns_feature.ErrEmptyResponse
-}
-
 // QueryIP is called from dns.Server->queryIPTimeout
 func (s *QUICNameServer) QueryIP(ctx context.Context, domain string, clientIP net.IP, option dns_feature.IPOption, disableCache bool) ([]net.IP, error) {
-	fqdn := Fqdn(domain)
-
-	if disableCache {
-		newError("DNS cache is disabled. Querying IP for ", domain, " at ", s.name).AtDebug().WriteToLog()
-	} else {
-		ips, err := s.findIPsForDomain(fqdn, option)
-		if err != errRecordNotFound {
-			newError(s.name, " cache HIT ", domain, " -> ", ips).Base(err).AtDebug().WriteToLog()
-			return ips, err
-		}
-	}
+	return s.cache.queryIP(ctx, domain, option, disableCache, func(ctx context.Context, fqdn string) {
+		s.sendQuery(ctx, fqdn, clientIP, option)
+	})
+}
 
-	// ipv4 and ipv6 belong to different subscription groups
-	var sub4, sub6 *pubsub.Subscriber
-	if option.IPv4Enable {
-		sub4 = s.pub.Subscribe(fqdn + "4")
-		defer sub4.Close()
-	}
-	if option.IPv6Enable {
-		sub6 = s.pub.Subscribe(fqdn + "6")
-		defer sub6.Close()
-	}
-	done := make(chan interface{})
-	go func() {
-		if sub4 != nil {
-			select {
-			case <-sub4.Wait():
-			case <-ctx.Done():
-			}
-		}
-		if sub6 != nil {
-			select {
-			case <-sub6.Wait():
-			case <-ctx.Done():
-			}
-		}
-		close(done)
-	}()
-	s.sendQuery(ctx, fqdn, clientIP, option)
+// getCache implements cachedServer.
+func (s *QUICNameServer) getCache() *recordCache {
+	return s.cache
+}
 
-	for {
-		ips, err := s.findIPsForDomain(fqdn, option)
-		if err != errRecordNotFound {
-			return ips, err
-		}
+// Close implements common.Closable.
+func (s *QUICNameServer) Close() error {
+	s.Lock()
+	defer s.Unlock()
 
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-done:
-		}
+	if s.connection == nil {
+		return nil
 	}
+	err := s.connection.CloseWithError(0, "")
+	s.connection = nil
+	return err
 }
 
 func isActive(s *quic.Conn) bool {
@@ -376,21 +243,15 @@ func (s *QUICNameServer) getConnection(ctx context.Context) (*quic.Conn, error)
 
 func (s *QUICNameServer) openConnection(ctx context.Context) (*quic.Conn, error) {
 	tlsConfig := tls.Config{
-		ServerName: func() string {
-			switch s.destination.Address.Family() {
-			case net.AddressFamilyIPv4, net.AddressFamilyIPv6:
-				return s.destination.Address.IP().String()
-			case net.AddressFamilyDomain:
-				return s.destination.Address.Domain()
-			default:
-				panic("unknown address family")
-			}
-		}(),
+		ServerName: serverName(s.destination),
 	}
 	quicConfig := &quic.Config{
 		HandshakeIdleTimeout: handshakeIdleTimeout,
 	}
 
+	if s.dispatcher != nil {
+		return dialDispatchedQUIC(ctx, s.dispatcher, s.destination, tlsConfig.GetTLSConfig(tls.WithNextProto(NextProtoDQ)), quicConfig)
+	}
 	conn, err := quic.DialAddr(ctx, s.destination.NetAddr(), tlsConfig.GetTLSConfig(tls.WithNextProto(NextProtoDQ)), quicConfig)
 	if err != nil {
 		return nil, err
@@ -408,3 +269,102 @@ func (s *QUICNameServer) openStream(ctx context.Context) (*quic.Stream, error) {
 	// open a new stream
 	return conn.OpenStreamSync(ctx)
 }
+
+// dialDispatchedQUIC opens a QUIC connection whose packets are sent through
+// the dispatcher.
+func dialDispatchedQUIC(ctx context.Context, dispatcher routing.Dispatcher, dest net.Destination, tlsConfig *gotls.Config, quicConfig *quic.Config) (*quic.Conn, error) {
+	// The connection outlives the query, so it must not be canceled with it.
+	linkCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	link, err := dispatcher.Dispatch(linkCtx, dest)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	packetConn := &dispatchedPacketConn{
+		link:   link,
+		addr:   &dispatchedAddr{dest: dest},
+		cancel: cancel,
+	}
+
+	conn, err := quic.Dial(ctx, packetConn, packetConn.addr, tlsConfig, quicConfig)
+	if err != nil {
+		packetConn.Close()
+		return nil, err
+	}
+	go func() {
+		<-conn.Context().Done()
+		packetConn.Close()
+	}()
+	return conn, nil
+}
+
+// dispatchedAddr is the address of a dispatched destination, which may be a domain.
+type dispatchedAddr struct {
+	dest net.Destination
+}
+
+func (a *dispatchedAddr) Network() string {
+	return a.dest.Network.SystemString()
+}
+
+func (a *dispatchedAddr) String() string {
+	return a.dest.NetAddr()
+}
+
+// dispatchedPacketConn is a net.PacketConn over a dispatched link to a single
+// destination. Each buffer on the link is a packet.
+type dispatchedPacketConn struct {
+	link   *transport.Link
+	addr   *dispatchedAddr
+	cancel context.CancelFunc
+	cache  buf.MultiBuffer
+}
+
+func (c *dispatchedPacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	for c.cache.IsEmpty() {
+		mb, err := c.link.Reader.ReadMultiBuffer()
+		if err != nil {
+			return 0, nil, err
+		}
+		c.cache = mb
+	}
+	var b *buf.Buffer
+	c.cache, b = buf.SplitFirst(c.cache)
+	n := copy(p, b.Bytes())
+	b.Release()
+	return n, c.addr, nil
+}
+
+func (c *dispatchedPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if len(p) > buf.Size {
+		return 0, newError("packet is too large: ", len(p))
+	}
+	b := buf.New()
+	common.Must2(b.Write(p))
+	if err := c.link.Writer.WriteMultiBuffer(buf.MultiBuffer{b}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *dispatchedPacketConn) Close() error {
+	c.cancel()
+	common.Interrupt(c.link.Reader)
+	return common.Close(c.link.Writer)
+}
+
+func (c *dispatchedPacketConn) LocalAddr() net.Addr {
+	return &net.UDPAddr{IP: net.AnyIP.IP()}
+}
+
+func (c *dispatchedPacketConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *dispatchedPacketConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *dispatchedPacketConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,80 @@
+//go:build !confonly
+// +build !confonly
+
+package dns
+
+import (
+	"context"
+	gotls "crypto/tls"
+	"net/url"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/tls"
+)
+
+// NewTLSNameServer creates DNS over TLS server object for remote resolving.
+// The connection is dispatched with the context of the query that opens it,
+// and reused by later queries until it is idle.
+func NewTLSNameServer(url *url.URL, dispatcher routing.Dispatcher) (*TCPNameServer, error) {
+	s, err := baseTCPNameServer(url, "DOT", net.Port(853))
+	if err != nil {
+		return nil, err
+	}
+
+	s.pipeline = newDNSPipeline(func(ctx context.Context) (net.Conn, error) {
+		// The connection outlives the query, so it must not be canceled with it.
+		link, err := dispatcher.Dispatch(context.WithoutCancel(ctx), s.destination)
+		if err != nil {
+			return nil, err
+		}
+
+		return clientTLS(ctx, net.NewConnection(
+			net.ConnectionInputMulti(link.Writer),
+			net.ConnectionOutputMulti(link.Reader),
+		), s.destination)
+	})
+	newError("DNS: created Remote DNS-over-TLS client for ", url.String()).AtInfo().WriteToLog()
+	return s, nil
+}
+
+// NewTLSLocalNameServer creates DNS over TLS client object for local resolving
+func NewTLSLocalNameServer(url *url.URL) (*TCPNameServer, error) {
+	s, err := baseTCPNameServer(url, "DOTL", net.Port(853))
+	if err != nil {
+		return nil, err
+	}
+
+	s.pipeline = newDNSPipeline(func(ctx context.Context) (net.Conn, error) {
+		conn, err := internet.DialSystem(ctx, s.destination, nil)
+		if err != nil {
+			return nil, err
+		}
+		return clientTLS(ctx, conn, s.destination)
+	})
+	newError("DNS: created Local DNS-over-TLS client for ", url.String()).AtInfo().WriteToLog()
+	return s, nil
+}
+
+func clientTLS(ctx context.Context, conn net.Conn, dest net.Destination) (net.Conn, error) {
+	config := (&tls.Config{ServerName: serverName(dest)}).GetTLSConfig()
+	tlsConn := gotls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, newError("failed to handshake with ", dest).Base(err)
+	}
+	return tlsConn, nil
+}
+
+// serverName returns the name to verify the certificate of a nameserver.
+func serverName(dest net.Destination) string {
+	switch dest.Address.Family() {
+	case net.AddressFamilyIPv4, net.AddressFamilyIPv6:
+		return dest.Address.IP().String()
+	case net.AddressFamilyDomain:
+		return dest.Address.Domain()
+	default:
+		panic("unknown address family")
+	}
+}
@@ -23,13 +23,7 @@ type Instance struct {
 
 // New creates a new log.Instance based on the given config.
 func New(ctx context.Context, config *Config) (*Instance, error) {
-	if config.Error == nil {
-		config.Error = &LogSpecification{Type: LogType_Console, Level: log.Severity_Warning}
-	}
-
-	if config.Access == nil {
-		config.Access = &LogSpecification{Type: LogType_None}
-	}
+	applyDefaults(config)
 
 	g := &Instance{
 		config: config,
@@ -47,9 +41,21 @@ func New(ctx context.Context, config *Config) (*Instance, error) {
 	return g, nil
 }
 
+func applyDefaults(config *Config) {
+	if config.Error == nil {
+		config.Error = &LogSpecification{Type: LogType_Console, Level: log.Severity_Warning}
+	}
+
+	if config.Access == nil {
+		config.Access = &LogSpecification{Type: LogType_None}
+	}
+}
+
 func (g *Instance) initAccessLogger() error {
 	handler, err := createHandler(g.config.Access.Type, HandlerCreatorOptions{
-		Path: g.config.Access.Path,
+		Path:     g.config.Access.Path,
+		Format:   g.config.Access.Format,
+		Rotation: g.config.Access.Rotation,
 	})
 	if err != nil {
 		return err
@@ -60,7 +66,9 @@ func (g *Instance) initAccessLogger() error {
 
 func (g *Instance) initErrorLogger() error {
 	handler, err := createHandler(g.config.Error.Type, HandlerCreatorOptions{
-		Path: g.config.Error.Path,
+		Path:     g.config.Error.Path,
+		Format:   g.config.Error.Format,
+		Rotation: g.config.Error.Rotation,
 	})
 	if err != nil {
 		return err
@@ -146,6 +154,29 @@ func (g *Instance) Handle(msg log.Message) {
 	}
 }
 
+// PrepareReload implements features.Reloadable.
+func (g *Instance) PrepareReload(config interface{}) (func(), error) {
+	next := &Instance{config: config.(*Config)}
+	applyDefaults(next.config)
+	if err := next.initAccessLogger(); err != nil {
+		return nil, newError("failed to initialize access logger").Base(err)
+	}
+	if err := next.initErrorLogger(); err != nil {
+		common.Close(next.accessLogger)
+		return nil, newError("failed to initialize error logger").Base(err)
+	}
+	return func() {
+		g.Lock()
+		defer g.Unlock()
+
+		common.Close(g.accessLogger)
+		common.Close(g.errorLogger)
+		g.config = next.config
+		g.accessLogger = next.accessLogger
+		g.errorLogger = next.errorLogger
+	}, nil
+}
+
 // Close implements common.Closable.Close().
 func (g *Instance) Close() error {
 	newError("Logger closing").AtDebug().WriteToLog()
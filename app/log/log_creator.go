@@ -1,12 +1,17 @@
 package log
 
 import (
+	golog "log"
+	"time"
+
 	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/log"
 )
 
 type HandlerCreatorOptions struct {
-	Path string
+	Path     string
+	Format   LogFormat
+	Rotation *LogRotation
 }
 
 type HandlerCreator func(LogType, HandlerCreatorOptions) (log.Handler, error)
@@ -30,16 +35,41 @@ func createHandler(logType LogType, options HandlerCreatorOptions) (log.Handler,
 	return creator(logType, options)
 }
 
+func (r *LogRotation) toOptions() log.RotationOptions {
+	if r == nil {
+		return log.RotationOptions{}
+	}
+	return log.RotationOptions{
+		MaxSize:    int64(r.MaxSize) * 1024 * 1024,
+		Interval:   time.Duration(r.Interval) * time.Second,
+		MaxBackups: int(r.MaxBackups),
+		MaxAge:     time.Duration(r.MaxAge) * 24 * time.Hour,
+	}
+}
+
 func init() {
 	common.Must(RegisterHandlerCreator(LogType_Console, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
+		if options.Format == LogFormat_JSON {
+			return log.NewJSONLogger(log.CreateStdoutLogWriterWithFlag(0)), nil
+		}
 		return log.NewLogger(log.CreateStdoutLogWriter()), nil
 	}))
 
 	common.Must(RegisterHandlerCreator(LogType_File, func(lt LogType, options HandlerCreatorOptions) (log.Handler, error) {
-		creator, err := log.CreateFileLogWriter(options.Path)
+		writerOptions := log.FileLogWriterOptions{
+			Flag:     golog.Ldate | golog.Ltime,
+			Rotation: options.Rotation.toOptions(),
+		}
+		if options.Format == LogFormat_JSON {
+			writerOptions.Flag = 0
+		}
+		creator, err := log.CreateFileLogWriterWithOptions(options.Path, writerOptions)
 		if err != nil {
 			return nil, err
 		}
+		if options.Format == LogFormat_JSON {
+			return log.NewJSONLogger(creator), nil
+		}
 		return log.NewLogger(creator), nil
 	}))
 
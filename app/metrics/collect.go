@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/observatory"
+	"github.com/frogwall/f2ray-core/v5/app/stats"
+	"github.com/frogwall/f2ray-core/v5/features/extension"
+	feature_stats "github.com/frogwall/f2ray-core/v5/features/stats"
+)
+
+// collectCounters turns the stat counters into labeled metrics. Counters are
+// named like "inbound>>>tag>>>traffic>>>uplink", those of unknown names are
+// exported as is with a name label.
+func collectCounters(set *metricSet, manager *stats.Manager) {
+	manager.VisitCounters(func(name string, c feature_stats.Counter) bool {
+		collectCounter(set, name, float64(c.Value()))
+		return true
+	})
+}
+
+func collectCounter(set *metricSet, name string, value float64) {
+	parts := strings.Split(name, ">>>")
+	if len(parts) == 4 {
+		kind, tag, metric, item := parts[0], parts[1], parts[2], parts[3]
+		switch {
+		case metric == "traffic" && (kind == "inbound" || kind == "outbound"):
+			set.family("v2ray_"+kind+"_traffic_bytes", typeCounter, "bytes", "Traffic of "+kind+" handlers.").
+				add(value, label{"tag", tag}, label{"direction", item})
+			return
+		case metric == "traffic" && kind == "user":
+			set.family("v2ray_user_traffic_bytes", typeCounter, "bytes", "Traffic of users.").
+				add(value, label{"user", tag}, label{"direction", item})
+			return
+		case metric == "connection" && item == "active" && (kind == "inbound" || kind == "outbound"):
+			set.family("v2ray_"+kind+"_active_connections", typeGauge, "", "Active connections of "+kind+" handlers.").
+				add(value, label{"tag", tag})
+			return
+		case metric == "pick" && kind == "balancer":
+			set.family("v2ray_balancer_picks", typeCounter, "", "Times an outbound is picked by a balancer.").
+				add(value, label{"balancer", tag}, label{"outbound", item})
+			return
+		}
+	}
+	set.family("v2ray_stats_counter", typeUnknown, "", "Stat counters of other names.").
+		add(value, label{"name", name})
+}
+
+// collectObservation exports the outbound status of an observatory.
+func collectObservation(set *metricSet, result *observatory.ObservationResult) {
+	alive := set.family("v2ray_observatory_alive", typeGauge, "", "Whether an outbound is alive.")
+	delay := set.family("v2ray_observatory_delay_seconds", typeGauge, "seconds", "Delay of an outbound.")
+	lastSeen := set.family("v2ray_observatory_last_seen_timestamp_seconds", typeGauge, "seconds", "Last time an outbound is seen alive.")
+	rtt := set.family("v2ray_observatory_health_ping_rtt_seconds", typeGauge, "seconds", "Round trip time of health pings.")
+	probes := set.family("v2ray_observatory_health_ping_probes", typeGauge, "", "Number of health pings in the measurement window.")
+
+	for _, status := range result.GetStatus() {
+		outbound := label{"outbound", status.OutboundTag}
+		if status.Alive {
+			alive.add(1, outbound)
+		} else {
+			alive.add(0, outbound)
+		}
+		delay.add(msToSeconds(status.Delay), outbound)
+		lastSeen.add(float64(status.LastSeenTime), outbound)
+
+		if ping := status.HealthPing; ping != nil {
+			rtt.add(msToSeconds(ping.Average), outbound, label{"stat", "average"})
+			rtt.add(msToSeconds(ping.Deviation), outbound, label{"stat", "deviation"})
+			rtt.add(msToSeconds(ping.Max), outbound, label{"stat", "max"})
+			rtt.add(msToSeconds(ping.Min), outbound, label{"stat", "min"})
+			probes.add(float64(ping.All), outbound, label{"result", "all"})
+			probes.add(float64(ping.Fail), outbound, label{"result", "fail"})
+		}
+	}
+}
+
+func msToSeconds(ms int64) float64 {
+	return float64(ms) / 1000
+}
+
+// collectRuntime exports stats of the Go runtime and the instance.
+func collectRuntime(set *metricSet, startTime time.Time) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	set.family("v2ray_build", typeInfo, "", "Build information.").
+		add(1, label{"version", core.Version()}, label{"go_version", runtime.Version()})
+	set.family("v2ray_uptime_seconds", typeGauge, "seconds", "Time since the metrics service is started.").
+		add(time.Since(startTime).Seconds())
+	set.family("go_goroutines", typeGauge, "", "Number of goroutines.").
+		add(float64(runtime.NumGoroutine()))
+	set.family("go_gc_cycles", typeCounter, "", "Number of completed GC cycles.").
+		add(float64(m.NumGC))
+	set.family("go_gc_pause_seconds", typeCounter, "seconds", "Cumulative time of GC stop-the-world pauses.").
+		add(float64(m.PauseTotalNs) / float64(time.Second))
+	set.family("go_memstats_alloc_bytes", typeCounter, "bytes", "Cumulative bytes allocated for heap objects.").
+		add(float64(m.TotalAlloc))
+	set.family("go_memstats_heap_alloc_bytes", typeGauge, "bytes", "Bytes of allocated heap objects.").
+		add(float64(m.HeapAlloc))
+	set.family("go_memstats_heap_inuse_bytes", typeGauge, "bytes", "Bytes in in-use heap spans.").
+		add(float64(m.HeapInuse))
+	set.family("go_memstats_heap_objects", typeGauge, "", "Number of allocated heap objects.").
+		add(float64(m.HeapObjects))
+	set.family("go_memstats_sys_bytes", typeGauge, "bytes", "Bytes of memory obtained from the OS.").
+		add(float64(m.Sys))
+}
+
+// collectObservatory exports the observation of the observatory of the
+// instance, if there is one.
+func collectObservatory(ctx context.Context, set *metricSet, instance *core.Instance) error {
+	feature := instance.GetFeature(extension.ObservatoryType())
+	if feature == nil {
+		return nil
+	}
+	message, err := feature.(extension.Observatory).GetObservation(ctx)
+	if err != nil {
+		return newError("cannot get observation").Base(err)
+	}
+	if result, ok := message.(*observatory.ObservationResult); ok {
+		collectObservation(set, result)
+	}
+	return nil
+}
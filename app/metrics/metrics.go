@@ -0,0 +1,135 @@
+package metrics
+
+//go:generate go run github.com/frogwall/f2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/stats"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	feature_stats "github.com/frogwall/f2ray-core/v5/features/stats"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+)
+
+const contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metricsService serves the stats of an instance in OpenMetrics text format.
+type metricsService struct {
+	listener net.Listener
+	config   *Config
+	access   sync.Mutex
+
+	instance  *core.Instance
+	stats     feature_stats.Manager
+	startTime time.Time
+
+	ctx context.Context
+}
+
+func (s *metricsService) Type() interface{} {
+	return (*struct{})(nil)
+}
+
+func (s *metricsService) Start() error {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	s.startTime = time.Now()
+
+	var listener net.Listener
+	var err error
+	address := net.ParseAddress(s.config.ListenAddr)
+
+	switch {
+	case address.Family().IsIP():
+		listener, err = internet.ListenSystem(s.ctx, &net.TCPAddr{IP: address.IP(), Port: int(s.config.ListenPort)}, nil)
+	case strings.EqualFold(address.Domain(), "localhost"):
+		listener, err = internet.ListenSystem(s.ctx, &net.TCPAddr{IP: net.IP{127, 0, 0, 1}, Port: int(s.config.ListenPort)}, nil)
+	default:
+		return newError("metrics service cannot listen on the address: ", address)
+	}
+	if err != nil {
+		return newError("metrics service cannot listen on the port ", s.config.ListenPort).Base(err)
+	}
+	s.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			newError("unable to serve metrics").Base(err).WriteToLog()
+		}
+	}()
+	return nil
+}
+
+func (s *metricsService) Close() error {
+	s.access.Lock()
+	defer s.access.Unlock()
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *metricsService) authorized(r *http.Request) bool {
+	if s.config.AuthToken == "" {
+		return true
+	}
+	text := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	return len(text) == 2 && text[0] == "Bearer" && text[1] == s.config.AuthToken
+}
+
+func (s *metricsService) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	set := s.collect(r.Context())
+	w.Header().Set("Content-Type", contentType)
+	if _, err := set.WriteTo(w); err != nil {
+		newError("failed to write metrics").Base(err).AtDebug().WriteToLog()
+	}
+}
+
+func (s *metricsService) collect(ctx context.Context) *metricSet {
+	set := newMetricSet()
+	if manager, ok := s.stats.(*stats.Manager); ok {
+		collectCounters(set, manager)
+	}
+	if s.instance != nil {
+		if err := collectObservatory(ctx, set, s.instance); err != nil {
+			newError("failed to collect observatory metrics").Base(err).AtWarning().WriteToLog()
+		}
+	}
+	collectRuntime(set, s.startTime)
+	return set
+}
+
+func newMetricsService(ctx context.Context, config *Config) (*metricsService, error) {
+	s := &metricsService{
+		config:   config,
+		instance: core.MustFromContext(ctx),
+		ctx:      ctx,
+	}
+	if err := core.RequireFeatures(ctx, func(sm feature_stats.Manager) {
+		s.stats = sm
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return newMetricsService(ctx, config.(*Config))
+	}))
+}
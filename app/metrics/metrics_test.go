@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/frogwall/f2ray-core/v5/app/observatory"
+	"github.com/frogwall/f2ray-core/v5/app/stats"
+	"github.com/frogwall/f2ray-core/v5/common"
+)
+
+func TestCollectCounters(t *testing.T) {
+	manager, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+	for name, value := range map[string]int64{
+		"inbound>>>socks>>>traffic>>>uplink":       100,
+		"outbound>>>direct>>>traffic>>>downlink":   200,
+		"user>>>love@v2fly.org>>>traffic>>>uplink": 300,
+		"outbound>>>proxy>>>connection>>>active":   2,
+		"balancer>>>b1>>>pick>>>proxy":             5,
+		"custom":                                   7,
+	} {
+		c, err := manager.RegisterCounter(name)
+		common.Must(err)
+		c.Set(value)
+	}
+
+	set := newMetricSet()
+	collectCounters(set, manager)
+	out := new(bytes.Buffer)
+	common.Must2(set.WriteTo(out))
+
+	expected := `# TYPE v2ray_balancer_picks counter
+# HELP v2ray_balancer_picks Times an outbound is picked by a balancer.
+v2ray_balancer_picks_total{balancer="b1",outbound="proxy"} 5
+# TYPE v2ray_inbound_traffic_bytes counter
+# UNIT v2ray_inbound_traffic_bytes bytes
+# HELP v2ray_inbound_traffic_bytes Traffic of inbound handlers.
+v2ray_inbound_traffic_bytes_total{tag="socks",direction="uplink"} 100
+# TYPE v2ray_outbound_active_connections gauge
+# HELP v2ray_outbound_active_connections Active connections of outbound handlers.
+v2ray_outbound_active_connections{tag="proxy"} 2
+# TYPE v2ray_outbound_traffic_bytes counter
+# UNIT v2ray_outbound_traffic_bytes bytes
+# HELP v2ray_outbound_traffic_bytes Traffic of outbound handlers.
+v2ray_outbound_traffic_bytes_total{tag="direct",direction="downlink"} 200
+# TYPE v2ray_stats_counter unknown
+# HELP v2ray_stats_counter Stat counters of other names.
+v2ray_stats_counter{name="custom"} 7
+# TYPE v2ray_user_traffic_bytes counter
+# UNIT v2ray_user_traffic_bytes bytes
+# HELP v2ray_user_traffic_bytes Traffic of users.
+v2ray_user_traffic_bytes_total{user="love@v2fly.org",direction="uplink"} 300
+# EOF
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestCollectObservation(t *testing.T) {
+	set := newMetricSet()
+	collectObservation(set, &observatory.ObservationResult{
+		Status: []*observatory.OutboundStatus{
+			{
+				OutboundTag:  "proxy",
+				Alive:        true,
+				Delay:        150,
+				LastSeenTime: 1700000000,
+				HealthPing:   &observatory.HealthPingMeasurementResult{All: 10, Fail: 1, Average: 120},
+			},
+			{OutboundTag: "backup"},
+		},
+	})
+	out := new(bytes.Buffer)
+	common.Must2(set.WriteTo(out))
+
+	for _, line := range []string{
+		`v2ray_observatory_alive{outbound="proxy"} 1`,
+		`v2ray_observatory_alive{outbound="backup"} 0`,
+		`v2ray_observatory_delay_seconds{outbound="proxy"} 0.15`,
+		`v2ray_observatory_last_seen_timestamp_seconds{outbound="proxy"} 1.7e+09`,
+		`v2ray_observatory_health_ping_rtt_seconds{outbound="proxy",stat="average"} 0.12`,
+		`v2ray_observatory_health_ping_probes{outbound="proxy",result="fail"} 1`,
+	} {
+		assert.Contains(t, out.String(), line+"\n")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `{name="a\"b\\c\nd"}`, formatLabels([]label{{"name", "a\"b\\c\nd"}}))
+}
+
+func TestHandleMetrics(t *testing.T) {
+	manager, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+	s := &metricsService{config: &Config{AuthToken: "token"}, stats: manager}
+
+	recorder := httptest.NewRecorder()
+	s.handleMetrics(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	recorder = httptest.NewRecorder()
+	s.handleMetrics(recorder, request)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, contentType, recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "# TYPE go_goroutines gauge\n")
+	assert.True(t, strings.HasSuffix(recorder.Body.String(), "# EOF\n"))
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricType is the type of a metric family in OpenMetrics.
+type metricType string
+
+const (
+	typeCounter metricType = "counter"
+	typeGauge   metricType = "gauge"
+	typeInfo    metricType = "info"
+	typeUnknown metricType = "unknown"
+)
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+type metricFamily struct {
+	name    string
+	help    string
+	typ     metricType
+	unit    string
+	samples []sample
+}
+
+// metricSet collects metric families and writes them in OpenMetrics text
+// format. Samples of a family may be added in any order.
+type metricSet struct {
+	families map[string]*metricFamily
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{families: make(map[string]*metricFamily)}
+}
+
+// family returns the family of the given name, creating it if it does not exist.
+func (s *metricSet) family(name string, typ metricType, unit string, help string) *metricFamily {
+	f, found := s.families[name]
+	if !found {
+		f = &metricFamily{name: name, typ: typ, unit: unit, help: help}
+		s.families[name] = f
+	}
+	return f
+}
+
+func (f *metricFamily) add(value float64, labels ...label) {
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+func (f *metricFamily) sampleName() string {
+	switch f.typ {
+	case typeCounter:
+		return f.name + "_total"
+	case typeInfo:
+		return f.name + "_info"
+	default:
+		return f.name
+	}
+}
+
+// WriteTo writes all the families sorted by name, followed by the EOF marker.
+func (s *metricSet) WriteTo(w io.Writer) (int64, error) {
+	names := make([]string, 0, len(s.families))
+	for name := range s.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := s.families[name]
+		b.WriteString("# TYPE " + f.name + " " + string(f.typ) + "\n")
+		if f.unit != "" {
+			b.WriteString("# UNIT " + f.name + " " + f.unit + "\n")
+		}
+		if f.help != "" {
+			b.WriteString("# HELP " + f.name + " " + escape(f.help, false) + "\n")
+		}
+		samples := make([]string, 0, len(f.samples))
+		for _, smp := range f.samples {
+			samples = append(samples, f.sampleName()+formatLabels(smp.labels)+" "+formatValue(smp.value)+"\n")
+		}
+		sort.Strings(samples)
+		for _, line := range samples {
+			b.WriteString(line)
+		}
+	}
+	b.WriteString("# EOF\n")
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for _, l := range labels {
+		parts = append(parts, l.name+"=\""+escape(l.value, true)+"\"")
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escape escapes a label value or a help text as required by OpenMetrics.
+func escape(s string, quote bool) string {
+	replacements := []string{`\`, `\\`, "\n", `\n`}
+	if quote {
+		replacements = append(replacements, `"`, `\"`)
+	}
+	return strings.NewReplacer(replacements...).Replace(s)
+}
@@ -0,0 +1,117 @@
+package command
+
+//go:generate go run github.com/frogwall/f2ray-core/v5/common/errors/errorgen
+
+import (
+	"context"
+
+	grpc "google.golang.org/grpc"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/policy"
+	"github.com/frogwall/f2ray-core/v5/common"
+	feature_policy "github.com/frogwall/f2ray-core/v5/features/policy"
+)
+
+// policyServer is an implementation of PolicyService.
+type policyServer struct {
+	policy feature_policy.Manager
+}
+
+func NewPolicyServer(manager feature_policy.Manager) PolicyServiceServer {
+	return &policyServer{
+		policy: manager,
+	}
+}
+
+func (s *policyServer) instance() (*policy.Instance, error) {
+	instance, ok := s.policy.(*policy.Instance)
+	if !ok {
+		return nil, newError("PolicyService only works with its own policy.Instance.")
+	}
+	return instance, nil
+}
+
+func (s *policyServer) SetUserBandwidth(ctx context.Context, request *SetUserBandwidthRequest) (*SetUserBandwidthResponse, error) {
+	if request.Email == "" {
+		return nil, newError("email must not be empty")
+	}
+	instance, err := s.instance()
+	if err != nil {
+		return nil, err
+	}
+	instance.SetUserBandwidth(request.Email, request.Bandwidth)
+	return &SetUserBandwidthResponse{}, nil
+}
+
+func (s *policyServer) RemoveUserBandwidth(ctx context.Context, request *RemoveUserBandwidthRequest) (*RemoveUserBandwidthResponse, error) {
+	instance, err := s.instance()
+	if err != nil {
+		return nil, err
+	}
+	instance.RemoveUserBandwidth(request.Email)
+	return &RemoveUserBandwidthResponse{}, nil
+}
+
+func (s *policyServer) SetInboundBandwidth(ctx context.Context, request *SetInboundBandwidthRequest) (*SetInboundBandwidthResponse, error) {
+	if request.Tag == "" {
+		return nil, newError("inbound tag must not be empty")
+	}
+	instance, err := s.instance()
+	if err != nil {
+		return nil, err
+	}
+	instance.SetInboundBandwidth(request.Tag, request.Bandwidth)
+	return &SetInboundBandwidthResponse{}, nil
+}
+
+func (s *policyServer) RemoveInboundBandwidth(ctx context.Context, request *RemoveInboundBandwidthRequest) (*RemoveInboundBandwidthResponse, error) {
+	instance, err := s.instance()
+	if err != nil {
+		return nil, err
+	}
+	instance.RemoveInboundBandwidth(request.Tag)
+	return &RemoveInboundBandwidthResponse{}, nil
+}
+
+func (s *policyServer) QueryBandwidth(ctx context.Context, request *QueryBandwidthRequest) (*QueryBandwidthResponse, error) {
+	instance, err := s.instance()
+	if err != nil {
+		return nil, err
+	}
+	response := &QueryBandwidthResponse{
+		User:    make(map[string]*policy.Policy_Bandwidth),
+		Inbound: make(map[string]*policy.Policy_Bandwidth),
+	}
+	instance.VisitBandwidth(func(email, inboundTag string, b *policy.Policy_Bandwidth) bool {
+		if email != "" {
+			response.User[email] = b
+		} else {
+			response.Inbound[inboundTag] = b
+		}
+		return true
+	})
+	return response, nil
+}
+
+func (s *policyServer) mustEmbedUnimplementedPolicyServiceServer() {}
+
+type service struct {
+	policyManager feature_policy.Manager
+}
+
+func (s *service) Register(server *grpc.Server) {
+	RegisterPolicyServiceServer(server, NewPolicyServer(s.policyManager))
+}
+
+func init() {
+	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, cfg interface{}) (interface{}, error) {
+		s := new(service)
+
+		core.RequireFeatures(ctx, func(pm feature_policy.Manager) {
+			s.policyManager = pm
+		})
+
+		return s, nil
+	}))
+}
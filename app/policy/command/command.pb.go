@@ -0,0 +1,594 @@
+package command
+
+import (
+	policy "github.com/frogwall/f2ray-core/v5/app/policy"
+	_ "github.com/frogwall/f2ray-core/v5/common/protoext"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SetUserBandwidthRequest struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Email         string                   `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Bandwidth     *policy.Policy_Bandwidth `protobuf:"bytes,2,opt,name=bandwidth,proto3" json:"bandwidth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserBandwidthRequest) Reset() {
+	*x = SetUserBandwidthRequest{}
+	mi := &file_app_policy_command_command_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserBandwidthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserBandwidthRequest) ProtoMessage() {}
+
+func (x *SetUserBandwidthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserBandwidthRequest.ProtoReflect.Descriptor instead.
+func (*SetUserBandwidthRequest) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SetUserBandwidthRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *SetUserBandwidthRequest) GetBandwidth() *policy.Policy_Bandwidth {
+	if x != nil {
+		return x.Bandwidth
+	}
+	return nil
+}
+
+type SetUserBandwidthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserBandwidthResponse) Reset() {
+	*x = SetUserBandwidthResponse{}
+	mi := &file_app_policy_command_command_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserBandwidthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserBandwidthResponse) ProtoMessage() {}
+
+func (x *SetUserBandwidthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserBandwidthResponse.ProtoReflect.Descriptor instead.
+func (*SetUserBandwidthResponse) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{1}
+}
+
+type RemoveUserBandwidthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUserBandwidthRequest) Reset() {
+	*x = RemoveUserBandwidthRequest{}
+	mi := &file_app_policy_command_command_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUserBandwidthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUserBandwidthRequest) ProtoMessage() {}
+
+func (x *RemoveUserBandwidthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUserBandwidthRequest.ProtoReflect.Descriptor instead.
+func (*RemoveUserBandwidthRequest) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RemoveUserBandwidthRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+type RemoveUserBandwidthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveUserBandwidthResponse) Reset() {
+	*x = RemoveUserBandwidthResponse{}
+	mi := &file_app_policy_command_command_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveUserBandwidthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveUserBandwidthResponse) ProtoMessage() {}
+
+func (x *RemoveUserBandwidthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveUserBandwidthResponse.ProtoReflect.Descriptor instead.
+func (*RemoveUserBandwidthResponse) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{3}
+}
+
+type SetInboundBandwidthRequest struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Tag           string                   `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Bandwidth     *policy.Policy_Bandwidth `protobuf:"bytes,2,opt,name=bandwidth,proto3" json:"bandwidth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetInboundBandwidthRequest) Reset() {
+	*x = SetInboundBandwidthRequest{}
+	mi := &file_app_policy_command_command_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetInboundBandwidthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetInboundBandwidthRequest) ProtoMessage() {}
+
+func (x *SetInboundBandwidthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetInboundBandwidthRequest.ProtoReflect.Descriptor instead.
+func (*SetInboundBandwidthRequest) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetInboundBandwidthRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *SetInboundBandwidthRequest) GetBandwidth() *policy.Policy_Bandwidth {
+	if x != nil {
+		return x.Bandwidth
+	}
+	return nil
+}
+
+type SetInboundBandwidthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetInboundBandwidthResponse) Reset() {
+	*x = SetInboundBandwidthResponse{}
+	mi := &file_app_policy_command_command_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetInboundBandwidthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetInboundBandwidthResponse) ProtoMessage() {}
+
+func (x *SetInboundBandwidthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetInboundBandwidthResponse.ProtoReflect.Descriptor instead.
+func (*SetInboundBandwidthResponse) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{5}
+}
+
+type RemoveInboundBandwidthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveInboundBandwidthRequest) Reset() {
+	*x = RemoveInboundBandwidthRequest{}
+	mi := &file_app_policy_command_command_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveInboundBandwidthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveInboundBandwidthRequest) ProtoMessage() {}
+
+func (x *RemoveInboundBandwidthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveInboundBandwidthRequest.ProtoReflect.Descriptor instead.
+func (*RemoveInboundBandwidthRequest) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RemoveInboundBandwidthRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type RemoveInboundBandwidthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveInboundBandwidthResponse) Reset() {
+	*x = RemoveInboundBandwidthResponse{}
+	mi := &file_app_policy_command_command_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveInboundBandwidthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveInboundBandwidthResponse) ProtoMessage() {}
+
+func (x *RemoveInboundBandwidthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveInboundBandwidthResponse.ProtoReflect.Descriptor instead.
+func (*RemoveInboundBandwidthResponse) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{7}
+}
+
+type QueryBandwidthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryBandwidthRequest) Reset() {
+	*x = QueryBandwidthRequest{}
+	mi := &file_app_policy_command_command_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryBandwidthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryBandwidthRequest) ProtoMessage() {}
+
+func (x *QueryBandwidthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryBandwidthRequest.ProtoReflect.Descriptor instead.
+func (*QueryBandwidthRequest) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{8}
+}
+
+type QueryBandwidthResponse struct {
+	state         protoimpl.MessageState              `protogen:"open.v1"`
+	User          map[string]*policy.Policy_Bandwidth `protobuf:"bytes,1,rep,name=user,proto3" json:"user,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Inbound       map[string]*policy.Policy_Bandwidth `protobuf:"bytes,2,rep,name=inbound,proto3" json:"inbound,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryBandwidthResponse) Reset() {
+	*x = QueryBandwidthResponse{}
+	mi := &file_app_policy_command_command_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryBandwidthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryBandwidthResponse) ProtoMessage() {}
+
+func (x *QueryBandwidthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryBandwidthResponse.ProtoReflect.Descriptor instead.
+func (*QueryBandwidthResponse) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QueryBandwidthResponse) GetUser() map[string]*policy.Policy_Bandwidth {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *QueryBandwidthResponse) GetInbound() map[string]*policy.Policy_Bandwidth {
+	if x != nil {
+		return x.Inbound
+	}
+	return nil
+}
+
+type Config struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_app_policy_command_command_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_app_policy_command_command_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_app_policy_command_command_proto_rawDescGZIP(), []int{10}
+}
+
+var File_app_policy_command_command_proto protoreflect.FileDescriptor
+
+const file_app_policy_command_command_proto_rawDesc = "" +
+	"\n" +
+	" app/policy/command/command.proto\x12\x1dv2ray.core.app.policy.command\x1a common/protoext/extensions.proto\x1a\x17app/policy/config.proto\"v\n" +
+	"\x17SetUserBandwidthRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12E\n" +
+	"\tbandwidth\x18\x02 \x01(\v2'.v2ray.core.app.policy.Policy.BandwidthR\tbandwidth\"\x1a\n" +
+	"\x18SetUserBandwidthResponse\"2\n" +
+	"\x1aRemoveUserBandwidthRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\"\x1d\n" +
+	"\x1bRemoveUserBandwidthResponse\"u\n" +
+	"\x1aSetInboundBandwidthRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12E\n" +
+	"\tbandwidth\x18\x02 \x01(\v2'.v2ray.core.app.policy.Policy.BandwidthR\tbandwidth\"\x1d\n" +
+	"\x1bSetInboundBandwidthResponse\"1\n" +
+	"\x1dRemoveInboundBandwidthRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\" \n" +
+	"\x1eRemoveInboundBandwidthResponse\"\x17\n" +
+	"\x15QueryBandwidthRequest\"\x92\x03\n" +
+	"\x16QueryBandwidthResponse\x12S\n" +
+	"\x04user\x18\x01 \x03(\v2?.v2ray.core.app.policy.command.QueryBandwidthResponse.UserEntryR\x04user\x12\\\n" +
+	"\ainbound\x18\x02 \x03(\v2B.v2ray.core.app.policy.command.QueryBandwidthResponse.InboundEntryR\ainbound\x1a`\n" +
+	"\tUserEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12=\n" +
+	"\x05value\x18\x02 \x01(\v2'.v2ray.core.app.policy.Policy.BandwidthR\x05value:\x028\x01\x1ac\n" +
+	"\fInboundEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12=\n" +
+	"\x05value\x18\x02 \x01(\v2'.v2ray.core.app.policy.Policy.BandwidthR\x05value:\x028\x01\"#\n" +
+	"\x06Config:\x19\x82\xb5\x18\x15\n" +
+	"\vgrpcservice\x12\x06policy2\xd4\x05\n" +
+	"\rPolicyService\x12\x85\x01\n" +
+	"\x10SetUserBandwidth\x126.v2ray.core.app.policy.command.SetUserBandwidthRequest\x1a7.v2ray.core.app.policy.command.SetUserBandwidthResponse\"\x00\x12\x8e\x01\n" +
+	"\x13RemoveUserBandwidth\x129.v2ray.core.app.policy.command.RemoveUserBandwidthRequest\x1a:.v2ray.core.app.policy.command.RemoveUserBandwidthResponse\"\x00\x12\x8e\x01\n" +
+	"\x13SetInboundBandwidth\x129.v2ray.core.app.policy.command.SetInboundBandwidthRequest\x1a:.v2ray.core.app.policy.command.SetInboundBandwidthResponse\"\x00\x12\x97\x01\n" +
+	"\x16RemoveInboundBandwidth\x12<.v2ray.core.app.policy.command.RemoveInboundBandwidthRequest\x1a=.v2ray.core.app.policy.command.RemoveInboundBandwidthResponse\"\x00\x12\x7f\n" +
+	"\x0eQueryBandwidth\x124.v2ray.core.app.policy.command.QueryBandwidthRequest\x1a5.v2ray.core.app.policy.command.QueryBandwidthResponse\"\x00B{\n" +
+	"!com.v2ray.core.app.policy.commandP\x01Z4github.com/frogwall/f2ray-core/v5/app/policy/command\xaa\x02\x1dV2Ray.Core.App.Policy.Commandb\x06proto3"
+
+var (
+	file_app_policy_command_command_proto_rawDescOnce sync.Once
+	file_app_policy_command_command_proto_rawDescData []byte
+)
+
+func file_app_policy_command_command_proto_rawDescGZIP() []byte {
+	file_app_policy_command_command_proto_rawDescOnce.Do(func() {
+		file_app_policy_command_command_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_app_policy_command_command_proto_rawDesc), len(file_app_policy_command_command_proto_rawDesc)))
+	})
+	return file_app_policy_command_command_proto_rawDescData
+}
+
+var file_app_policy_command_command_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_app_policy_command_command_proto_goTypes = []any{
+	(*SetUserBandwidthRequest)(nil),        // 0: v2ray.core.app.policy.command.SetUserBandwidthRequest
+	(*SetUserBandwidthResponse)(nil),       // 1: v2ray.core.app.policy.command.SetUserBandwidthResponse
+	(*RemoveUserBandwidthRequest)(nil),     // 2: v2ray.core.app.policy.command.RemoveUserBandwidthRequest
+	(*RemoveUserBandwidthResponse)(nil),    // 3: v2ray.core.app.policy.command.RemoveUserBandwidthResponse
+	(*SetInboundBandwidthRequest)(nil),     // 4: v2ray.core.app.policy.command.SetInboundBandwidthRequest
+	(*SetInboundBandwidthResponse)(nil),    // 5: v2ray.core.app.policy.command.SetInboundBandwidthResponse
+	(*RemoveInboundBandwidthRequest)(nil),  // 6: v2ray.core.app.policy.command.RemoveInboundBandwidthRequest
+	(*RemoveInboundBandwidthResponse)(nil), // 7: v2ray.core.app.policy.command.RemoveInboundBandwidthResponse
+	(*QueryBandwidthRequest)(nil),          // 8: v2ray.core.app.policy.command.QueryBandwidthRequest
+	(*QueryBandwidthResponse)(nil),         // 9: v2ray.core.app.policy.command.QueryBandwidthResponse
+	(*Config)(nil),                         // 10: v2ray.core.app.policy.command.Config
+	nil,                                    // 11: v2ray.core.app.policy.command.QueryBandwidthResponse.UserEntry
+	nil,                                    // 12: v2ray.core.app.policy.command.QueryBandwidthResponse.InboundEntry
+	(*policy.Policy_Bandwidth)(nil),        // 13: v2ray.core.app.policy.Policy.Bandwidth
+}
+var file_app_policy_command_command_proto_depIdxs = []int32{
+	13, // 0: v2ray.core.app.policy.command.SetUserBandwidthRequest.bandwidth:type_name -> v2ray.core.app.policy.Policy.Bandwidth
+	13, // 1: v2ray.core.app.policy.command.SetInboundBandwidthRequest.bandwidth:type_name -> v2ray.core.app.policy.Policy.Bandwidth
+	11, // 2: v2ray.core.app.policy.command.QueryBandwidthResponse.user:type_name -> v2ray.core.app.policy.command.QueryBandwidthResponse.UserEntry
+	12, // 3: v2ray.core.app.policy.command.QueryBandwidthResponse.inbound:type_name -> v2ray.core.app.policy.command.QueryBandwidthResponse.InboundEntry
+	13, // 4: v2ray.core.app.policy.command.QueryBandwidthResponse.UserEntry.value:type_name -> v2ray.core.app.policy.Policy.Bandwidth
+	13, // 5: v2ray.core.app.policy.command.QueryBandwidthResponse.InboundEntry.value:type_name -> v2ray.core.app.policy.Policy.Bandwidth
+	0,  // 6: v2ray.core.app.policy.command.PolicyService.SetUserBandwidth:input_type -> v2ray.core.app.policy.command.SetUserBandwidthRequest
+	2,  // 7: v2ray.core.app.policy.command.PolicyService.RemoveUserBandwidth:input_type -> v2ray.core.app.policy.command.RemoveUserBandwidthRequest
+	4,  // 8: v2ray.core.app.policy.command.PolicyService.SetInboundBandwidth:input_type -> v2ray.core.app.policy.command.SetInboundBandwidthRequest
+	6,  // 9: v2ray.core.app.policy.command.PolicyService.RemoveInboundBandwidth:input_type -> v2ray.core.app.policy.command.RemoveInboundBandwidthRequest
+	8,  // 10: v2ray.core.app.policy.command.PolicyService.QueryBandwidth:input_type -> v2ray.core.app.policy.command.QueryBandwidthRequest
+	1,  // 11: v2ray.core.app.policy.command.PolicyService.SetUserBandwidth:output_type -> v2ray.core.app.policy.command.SetUserBandwidthResponse
+	3,  // 12: v2ray.core.app.policy.command.PolicyService.RemoveUserBandwidth:output_type -> v2ray.core.app.policy.command.RemoveUserBandwidthResponse
+	5,  // 13: v2ray.core.app.policy.command.PolicyService.SetInboundBandwidth:output_type -> v2ray.core.app.policy.command.SetInboundBandwidthResponse
+	7,  // 14: v2ray.core.app.policy.command.PolicyService.RemoveInboundBandwidth:output_type -> v2ray.core.app.policy.command.RemoveInboundBandwidthResponse
+	9,  // 15: v2ray.core.app.policy.command.PolicyService.QueryBandwidth:output_type -> v2ray.core.app.policy.command.QueryBandwidthResponse
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_app_policy_command_command_proto_init() }
+func file_app_policy_command_command_proto_init() {
+	if File_app_policy_command_command_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_app_policy_command_command_proto_rawDesc), len(file_app_policy_command_command_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_app_policy_command_command_proto_goTypes,
+		DependencyIndexes: file_app_policy_command_command_proto_depIdxs,
+		MessageInfos:      file_app_policy_command_command_proto_msgTypes,
+	}.Build()
+	File_app_policy_command_command_proto = out.File
+	file_app_policy_command_command_proto_goTypes = nil
+	file_app_policy_command_command_proto_depIdxs = nil
+}
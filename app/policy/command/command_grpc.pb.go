@@ -0,0 +1,251 @@
+package command
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PolicyService_SetUserBandwidth_FullMethodName       = "/v2ray.core.app.policy.command.PolicyService/SetUserBandwidth"
+	PolicyService_RemoveUserBandwidth_FullMethodName    = "/v2ray.core.app.policy.command.PolicyService/RemoveUserBandwidth"
+	PolicyService_SetInboundBandwidth_FullMethodName    = "/v2ray.core.app.policy.command.PolicyService/SetInboundBandwidth"
+	PolicyService_RemoveInboundBandwidth_FullMethodName = "/v2ray.core.app.policy.command.PolicyService/RemoveInboundBandwidth"
+	PolicyService_QueryBandwidth_FullMethodName         = "/v2ray.core.app.policy.command.PolicyService/QueryBandwidth"
+)
+
+// PolicyServiceClient is the client API for PolicyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PolicyServiceClient interface {
+	SetUserBandwidth(ctx context.Context, in *SetUserBandwidthRequest, opts ...grpc.CallOption) (*SetUserBandwidthResponse, error)
+	RemoveUserBandwidth(ctx context.Context, in *RemoveUserBandwidthRequest, opts ...grpc.CallOption) (*RemoveUserBandwidthResponse, error)
+	SetInboundBandwidth(ctx context.Context, in *SetInboundBandwidthRequest, opts ...grpc.CallOption) (*SetInboundBandwidthResponse, error)
+	RemoveInboundBandwidth(ctx context.Context, in *RemoveInboundBandwidthRequest, opts ...grpc.CallOption) (*RemoveInboundBandwidthResponse, error)
+	QueryBandwidth(ctx context.Context, in *QueryBandwidthRequest, opts ...grpc.CallOption) (*QueryBandwidthResponse, error)
+}
+
+type policyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPolicyServiceClient(cc grpc.ClientConnInterface) PolicyServiceClient {
+	return &policyServiceClient{cc}
+}
+
+func (c *policyServiceClient) SetUserBandwidth(ctx context.Context, in *SetUserBandwidthRequest, opts ...grpc.CallOption) (*SetUserBandwidthResponse, error) {
+	out := new(SetUserBandwidthResponse)
+	err := c.cc.Invoke(ctx, PolicyService_SetUserBandwidth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyServiceClient) RemoveUserBandwidth(ctx context.Context, in *RemoveUserBandwidthRequest, opts ...grpc.CallOption) (*RemoveUserBandwidthResponse, error) {
+	out := new(RemoveUserBandwidthResponse)
+	err := c.cc.Invoke(ctx, PolicyService_RemoveUserBandwidth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyServiceClient) SetInboundBandwidth(ctx context.Context, in *SetInboundBandwidthRequest, opts ...grpc.CallOption) (*SetInboundBandwidthResponse, error) {
+	out := new(SetInboundBandwidthResponse)
+	err := c.cc.Invoke(ctx, PolicyService_SetInboundBandwidth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyServiceClient) RemoveInboundBandwidth(ctx context.Context, in *RemoveInboundBandwidthRequest, opts ...grpc.CallOption) (*RemoveInboundBandwidthResponse, error) {
+	out := new(RemoveInboundBandwidthResponse)
+	err := c.cc.Invoke(ctx, PolicyService_RemoveInboundBandwidth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *policyServiceClient) QueryBandwidth(ctx context.Context, in *QueryBandwidthRequest, opts ...grpc.CallOption) (*QueryBandwidthResponse, error) {
+	out := new(QueryBandwidthResponse)
+	err := c.cc.Invoke(ctx, PolicyService_QueryBandwidth_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PolicyServiceServer is the server API for PolicyService service.
+// All implementations must embed UnimplementedPolicyServiceServer
+// for forward compatibility
+type PolicyServiceServer interface {
+	SetUserBandwidth(context.Context, *SetUserBandwidthRequest) (*SetUserBandwidthResponse, error)
+	RemoveUserBandwidth(context.Context, *RemoveUserBandwidthRequest) (*RemoveUserBandwidthResponse, error)
+	SetInboundBandwidth(context.Context, *SetInboundBandwidthRequest) (*SetInboundBandwidthResponse, error)
+	RemoveInboundBandwidth(context.Context, *RemoveInboundBandwidthRequest) (*RemoveInboundBandwidthResponse, error)
+	QueryBandwidth(context.Context, *QueryBandwidthRequest) (*QueryBandwidthResponse, error)
+	mustEmbedUnimplementedPolicyServiceServer()
+}
+
+// UnimplementedPolicyServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPolicyServiceServer struct {
+}
+
+func (UnimplementedPolicyServiceServer) SetUserBandwidth(context.Context, *SetUserBandwidthRequest) (*SetUserBandwidthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserBandwidth not implemented")
+}
+func (UnimplementedPolicyServiceServer) RemoveUserBandwidth(context.Context, *RemoveUserBandwidthRequest) (*RemoveUserBandwidthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveUserBandwidth not implemented")
+}
+func (UnimplementedPolicyServiceServer) SetInboundBandwidth(context.Context, *SetInboundBandwidthRequest) (*SetInboundBandwidthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetInboundBandwidth not implemented")
+}
+func (UnimplementedPolicyServiceServer) RemoveInboundBandwidth(context.Context, *RemoveInboundBandwidthRequest) (*RemoveInboundBandwidthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveInboundBandwidth not implemented")
+}
+func (UnimplementedPolicyServiceServer) QueryBandwidth(context.Context, *QueryBandwidthRequest) (*QueryBandwidthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryBandwidth not implemented")
+}
+func (UnimplementedPolicyServiceServer) mustEmbedUnimplementedPolicyServiceServer() {}
+
+// UnsafePolicyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PolicyServiceServer will
+// result in compilation errors.
+type UnsafePolicyServiceServer interface {
+	mustEmbedUnimplementedPolicyServiceServer()
+}
+
+func RegisterPolicyServiceServer(s grpc.ServiceRegistrar, srv PolicyServiceServer) {
+	s.RegisterService(&PolicyService_ServiceDesc, srv)
+}
+
+func _PolicyService_SetUserBandwidth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserBandwidthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServiceServer).SetUserBandwidth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyService_SetUserBandwidth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServiceServer).SetUserBandwidth(ctx, req.(*SetUserBandwidthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyService_RemoveUserBandwidth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveUserBandwidthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServiceServer).RemoveUserBandwidth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyService_RemoveUserBandwidth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServiceServer).RemoveUserBandwidth(ctx, req.(*RemoveUserBandwidthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyService_SetInboundBandwidth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetInboundBandwidthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServiceServer).SetInboundBandwidth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyService_SetInboundBandwidth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServiceServer).SetInboundBandwidth(ctx, req.(*SetInboundBandwidthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyService_RemoveInboundBandwidth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveInboundBandwidthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServiceServer).RemoveInboundBandwidth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyService_RemoveInboundBandwidth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServiceServer).RemoveInboundBandwidth(ctx, req.(*RemoveInboundBandwidthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PolicyService_QueryBandwidth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryBandwidthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PolicyServiceServer).QueryBandwidth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PolicyService_QueryBandwidth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PolicyServiceServer).QueryBandwidth(ctx, req.(*QueryBandwidthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PolicyService_ServiceDesc is the grpc.ServiceDesc for PolicyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PolicyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v2ray.core.app.policy.command.PolicyService",
+	HandlerType: (*PolicyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetUserBandwidth",
+			Handler:    _PolicyService_SetUserBandwidth_Handler,
+		},
+		{
+			MethodName: "RemoveUserBandwidth",
+			Handler:    _PolicyService_RemoveUserBandwidth_Handler,
+		},
+		{
+			MethodName: "SetInboundBandwidth",
+			Handler:    _PolicyService_SetInboundBandwidth_Handler,
+		},
+		{
+			MethodName: "RemoveInboundBandwidth",
+			Handler:    _PolicyService_RemoveInboundBandwidth_Handler,
+		},
+		{
+			MethodName: "QueryBandwidth",
+			Handler:    _PolicyService_QueryBandwidth_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "app/policy/command/command.proto",
+}
@@ -0,0 +1,58 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/frogwall/f2ray-core/v5/app/policy"
+	. "github.com/frogwall/f2ray-core/v5/app/policy/command"
+	"github.com/frogwall/f2ray-core/v5/common"
+)
+
+func TestBandwidth(t *testing.T) {
+	m, err := policy.New(context.Background(), &policy.Config{
+		Inbound: map[string]*policy.Policy_Bandwidth{
+			"in": {Uplink: 1024},
+		},
+	})
+	common.Must(err)
+
+	s := NewPolicyServer(m)
+	ctx := context.Background()
+
+	_, err = s.SetUserBandwidth(ctx, &SetUserBandwidthRequest{
+		Email:     "love@v2fly.org",
+		Bandwidth: &policy.Policy_Bandwidth{Uplink: 2048, Downlink: 4096},
+	})
+	common.Must(err)
+	if _, err := s.SetUserBandwidth(ctx, &SetUserBandwidthRequest{}); err == nil {
+		t.Error("expect error for empty email")
+	}
+
+	resp, err := s.QueryBandwidth(ctx, &QueryBandwidthRequest{})
+	common.Must(err)
+	if r := cmp.Diff(&QueryBandwidthResponse{
+		User: map[string]*policy.Policy_Bandwidth{
+			"love@v2fly.org": {Uplink: 2048, Downlink: 4096},
+		},
+		Inbound: map[string]*policy.Policy_Bandwidth{
+			"in": {Uplink: 1024},
+		},
+	}, resp, protocmp.Transform()); r != "" {
+		t.Error(r)
+	}
+
+	_, err = s.RemoveUserBandwidth(ctx, &RemoveUserBandwidthRequest{Email: "love@v2fly.org"})
+	common.Must(err)
+	_, err = s.RemoveInboundBandwidth(ctx, &RemoveInboundBandwidthRequest{Tag: "in"})
+	common.Must(err)
+
+	resp, err = s.QueryBandwidth(ctx, &QueryBandwidthRequest{})
+	common.Must(err)
+	if len(resp.User) != 0 || len(resp.Inbound) != 0 {
+		t.Error("expect no bandwidth limits, but got ", resp)
+	}
+}
@@ -58,6 +58,12 @@ func (p *Policy) overrideWith(another *Policy) {
 			Connection: another.Buffer.Connection,
 		}
 	}
+	if another.Bandwidth != nil {
+		p.Bandwidth = &Policy_Bandwidth{
+			Uplink:   another.Bandwidth.Uplink,
+			Downlink: another.Bandwidth.Downlink,
+		}
+	}
 }
 
 // ToCorePolicy converts this Policy to policy.Session.
@@ -84,10 +90,13 @@ func (p *Policy) ToCorePolicy() policy.Session {
 func (p *SystemPolicy) ToCorePolicy() policy.System {
 	return policy.System{
 		Stats: policy.SystemStats{
-			InboundUplink:    p.Stats.InboundUplink,
-			InboundDownlink:  p.Stats.InboundDownlink,
-			OutboundUplink:   p.Stats.OutboundUplink,
-			OutboundDownlink: p.Stats.OutboundDownlink,
+			InboundUplink:      p.Stats.InboundUplink,
+			InboundDownlink:    p.Stats.InboundDownlink,
+			OutboundUplink:     p.Stats.OutboundUplink,
+			OutboundDownlink:   p.Stats.OutboundDownlink,
+			InboundConnection:  p.Stats.InboundConnection,
+			OutboundConnection: p.Stats.OutboundConnection,
+			ConnectionTracking: p.Stats.ConnectionTracking,
 		},
 		OverrideAccessLogDest: p.OverrideAccessLogDest,
 	}
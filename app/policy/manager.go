@@ -2,8 +2,10 @@ package policy
 
 import (
 	"context"
+	"sync"
 
 	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
 	"github.com/frogwall/f2ray-core/v5/features/policy"
 )
 
@@ -11,13 +13,29 @@ import (
 type Instance struct {
 	levels map[uint32]*Policy
 	system *SystemPolicy
+
+	access           sync.Mutex
+	userBandwidth    map[string]*Policy_Bandwidth
+	inboundBandwidth map[string]*Policy_Bandwidth
+	userLimiters     map[string]*userLimiter
+	inboundLimiters  map[string]*bandwidthLimiter
 }
 
 // New creates new Policy manager instance.
 func New(ctx context.Context, config *Config) (*Instance, error) {
 	m := &Instance{
-		levels: make(map[uint32]*Policy),
-		system: config.System,
+		levels:           make(map[uint32]*Policy),
+		system:           config.System,
+		userBandwidth:    make(map[string]*Policy_Bandwidth),
+		inboundBandwidth: make(map[string]*Policy_Bandwidth),
+		userLimiters:     make(map[string]*userLimiter),
+		inboundLimiters:  make(map[string]*bandwidthLimiter),
+	}
+	for email, b := range config.User {
+		m.userBandwidth[email] = b
+	}
+	for tag, b := range config.Inbound {
+		m.inboundBandwidth[tag] = b
 	}
 	if len(config.Level) > 0 {
 		for lv, p := range config.Level {
@@ -51,6 +69,140 @@ func (m *Instance) ForSystem() policy.System {
 	return m.system.ToCorePolicy()
 }
 
+// ForConnection implements policy.RateLimitManager. Connections of the same
+// inbound or user share a limiter, which is kept even if there are no limits,
+// so that limits set later apply to existing connections as well.
+func (m *Instance) ForConnection(inboundTag string, user *protocol.MemoryUser) policy.RateLimiters {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	var limiters policy.RateLimiters
+	if inboundTag != "" {
+		l, found := m.inboundLimiters[inboundTag]
+		if !found {
+			l = newBandwidthLimiter(m.inboundBandwidth[inboundTag])
+			m.inboundLimiters[inboundTag] = l
+		}
+		l.addTo(&limiters)
+	}
+	if user != nil {
+		if user.Email == "" {
+			// Without an email, the connection has its own limiter, whose limits
+			// never change.
+			if b := m.levelBandwidth(user.Level); isLimited(b) {
+				newBandwidthLimiter(b).addTo(&limiters)
+			}
+		} else {
+			l, found := m.userLimiters[user.Email]
+			if !found {
+				l = &userLimiter{
+					bandwidthLimiter: newBandwidthLimiter(m.bandwidthOfUser(user.Email, user.Level)),
+					level:            user.Level,
+				}
+				m.userLimiters[user.Email] = l
+			} else if l.released {
+				// The user is added again, maybe at another level.
+				l.level = user.Level
+				l.released = false
+				l.set(m.bandwidthOfUser(user.Email, user.Level))
+			}
+			l.addTo(&limiters)
+		}
+	}
+	return limiters
+}
+
+// ReleaseUser implements policy.RateLimitManager. The limiter of the user is
+// kept for its existing connections, and its level is updated when the user
+// connects again.
+func (m *Instance) ReleaseUser(email string) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if l, found := m.userLimiters[email]; found {
+		l.released = true
+	}
+}
+
+func (m *Instance) levelBandwidth(level uint32) *Policy_Bandwidth {
+	if p, ok := m.levels[level]; ok {
+		return p.Bandwidth
+	}
+	return nil
+}
+
+func (m *Instance) bandwidthOfUser(email string, level uint32) *Policy_Bandwidth {
+	if b, found := m.userBandwidth[email]; found {
+		return b
+	}
+	return m.levelBandwidth(level)
+}
+
+// SetUserBandwidth sets the bandwidth limits of a user, which override the
+// limits of the user level. It applies to existing connections as well.
+func (m *Instance) SetUserBandwidth(email string, b *Policy_Bandwidth) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	m.userBandwidth[email] = b
+	if l, found := m.userLimiters[email]; found {
+		l.set(b)
+	}
+}
+
+// RemoveUserBandwidth removes the bandwidth limits set for a user, so that
+// the limits of the user level apply.
+func (m *Instance) RemoveUserBandwidth(email string) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	delete(m.userBandwidth, email)
+	if l, found := m.userLimiters[email]; found {
+		l.set(m.levelBandwidth(l.level))
+	}
+}
+
+// SetInboundBandwidth sets the bandwidth limits of an inbound. It applies to
+// existing connections as well.
+func (m *Instance) SetInboundBandwidth(tag string, b *Policy_Bandwidth) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	m.inboundBandwidth[tag] = b
+	if l, found := m.inboundLimiters[tag]; found {
+		l.set(b)
+	}
+}
+
+// RemoveInboundBandwidth removes the bandwidth limits of an inbound.
+func (m *Instance) RemoveInboundBandwidth(tag string) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	delete(m.inboundBandwidth, tag)
+	if l, found := m.inboundLimiters[tag]; found {
+		l.set(nil)
+	}
+}
+
+// VisitBandwidth calls visitor on the bandwidth limits set for users and
+// inbounds, until visitor returns false.
+func (m *Instance) VisitBandwidth(visitor func(email, inboundTag string, b *Policy_Bandwidth) bool) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	for email, b := range m.userBandwidth {
+		if !visitor(email, "", b) {
+			return
+		}
+	}
+	for tag, b := range m.inboundBandwidth {
+		if !visitor("", tag, b) {
+			return
+		}
+	}
+}
+
 // Start implements common.Runnable.Start().
 func (m *Instance) Start() error {
 	return nil
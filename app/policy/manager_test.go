@@ -7,6 +7,7 @@ import (
 
 	. "github.com/frogwall/f2ray-core/v5/app/policy"
 	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
 	"github.com/frogwall/f2ray-core/v5/features/policy"
 )
 
@@ -43,3 +44,144 @@ func TestPolicy(t *testing.T) {
 		}
 	}
 }
+
+func TestRateLimit(t *testing.T) {
+	manager, err := New(context.Background(), &Config{
+		Level: map[uint32]*Policy{
+			1: {
+				Bandwidth: &Policy_Bandwidth{
+					Uplink: 64 * 1024,
+				},
+			},
+		},
+		Inbound: map[string]*Policy_Bandwidth{
+			"in": {Downlink: 64 * 1024},
+		},
+	})
+	common.Must(err)
+
+	user := &protocol.MemoryUser{Email: "love@v2fly.org", Level: 1}
+	limiters := manager.ForConnection("in", user)
+	if len(limiters.Uplink) != 2 || len(limiters.Downlink) != 2 {
+		t.Fatal("expect limiters of both the inbound and the user, but got ", limiters)
+	}
+	another := manager.ForConnection("", user)
+	if len(another.Uplink) != 1 || another.Uplink[0] != limiters.Uplink[1] {
+		t.Fatal("expect the limiter shared by connections of the user")
+	}
+
+	allowed := func(l policy.RateLimiter, n int) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		return l.WaitN(ctx, n) == nil
+	}
+
+	userUplink := limiters.Uplink[1]
+	if !allowed(userUplink, 64*1024) {
+		t.Error("expect the burst to be allowed")
+	}
+	if allowed(userUplink, 64*1024) {
+		t.Error("expect the user uplink to be limited")
+	}
+	if !allowed(limiters.Downlink[1], 1024*1024) {
+		t.Error("expect the user downlink to be unlimited")
+	}
+
+	manager.SetUserBandwidth("love@v2fly.org", &Policy_Bandwidth{Downlink: 64 * 1024})
+	if !allowed(userUplink, 1024*1024) {
+		t.Error("expect the user uplink to be unlimited")
+	}
+	manager.RemoveUserBandwidth("love@v2fly.org")
+	if allowed(userUplink, 1024*1024) {
+		t.Error("expect the user uplink to be limited by its level")
+	}
+
+	inboundDownlink := limiters.Downlink[0]
+	manager.RemoveInboundBandwidth("in")
+	if !allowed(inboundDownlink, 1024*1024) {
+		t.Error("expect the inbound downlink to be unlimited")
+	}
+
+	if limiters := manager.ForConnection("", &protocol.MemoryUser{}); len(limiters.Uplink) != 0 {
+		t.Error("expect no limiter for a user without limits, but got ", limiters)
+	}
+}
+
+func TestRateLimitExistingConnection(t *testing.T) {
+	manager, err := New(context.Background(), &Config{})
+	common.Must(err)
+
+	allowed := func(l policy.RateLimiter, n int) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		return l.WaitN(ctx, n) == nil
+	}
+
+	user := &protocol.MemoryUser{Email: "love@v2fly.org"}
+	limiters := manager.ForConnection("in", user)
+	if len(limiters.Uplink) != 2 || len(limiters.Downlink) != 2 {
+		t.Fatal("expect limiters of both the inbound and the user, but got ", limiters)
+	}
+	inboundDownlink, userUplink := limiters.Downlink[0], limiters.Uplink[1]
+	if !allowed(inboundDownlink, 1024*1024) || !allowed(userUplink, 1024*1024) {
+		t.Fatal("expect the connection to be unlimited")
+	}
+
+	manager.SetInboundBandwidth("in", &Policy_Bandwidth{Downlink: 64 * 1024})
+	if !allowed(inboundDownlink, 64*1024) {
+		t.Error("expect the burst to be allowed")
+	}
+	if allowed(inboundDownlink, 64*1024) {
+		t.Error("expect the inbound downlink of the connection to be limited")
+	}
+	manager.RemoveInboundBandwidth("in")
+	if !allowed(inboundDownlink, 1024*1024) {
+		t.Error("expect the inbound downlink to be unlimited")
+	}
+	manager.SetInboundBandwidth("in", &Policy_Bandwidth{Downlink: 64 * 1024})
+	if allowed(inboundDownlink, 1024*1024) {
+		t.Error("expect the inbound downlink to be limited again")
+	}
+
+	manager.SetUserBandwidth(user.Email, &Policy_Bandwidth{Uplink: 64 * 1024})
+	if allowed(userUplink, 1024*1024) {
+		t.Error("expect the user uplink of the connection to be limited")
+	}
+	manager.RemoveUserBandwidth(user.Email)
+	manager.ReleaseUser(user.Email)
+	if !allowed(userUplink, 1024*1024) {
+		t.Error("expect the user uplink to be unlimited")
+	}
+	manager.SetUserBandwidth(user.Email, &Policy_Bandwidth{Uplink: 64 * 1024})
+	if allowed(userUplink, 1024*1024) {
+		t.Error("expect the user uplink to be limited after the user is released")
+	}
+}
+
+func TestRateLimitReleasedUser(t *testing.T) {
+	manager, err := New(context.Background(), &Config{
+		Level: map[uint32]*Policy{
+			1: {
+				Bandwidth: &Policy_Bandwidth{
+					Uplink: 64 * 1024,
+				},
+			},
+		},
+	})
+	common.Must(err)
+
+	limiters := manager.ForConnection("", &protocol.MemoryUser{Email: "love@v2fly.org"})
+	manager.ReleaseUser("love@v2fly.org")
+
+	// The user is added back at another level, and shares the limiter with
+	// its existing connection.
+	another := manager.ForConnection("", &protocol.MemoryUser{Email: "love@v2fly.org", Level: 1})
+	if len(another.Uplink) != 1 || another.Uplink[0] != limiters.Uplink[0] {
+		t.Fatal("expect the limiter shared by connections of the user")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if limiters.Uplink[0].WaitN(ctx, 1024*1024) == nil {
+		t.Error("expect the user uplink to be limited by its new level")
+	}
+}
@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+)
+
+// minRateLimiterBurst is the minimum burst of a rate limiter, so that a
+// buffer of common size can pass a limiter in one go.
+const minRateLimiterBurst = 64 * 1024
+
+// rateLimiter is a token bucket of bytes, whose rate can be changed at runtime.
+type rateLimiter struct {
+	limiter *rate.Limiter
+}
+
+func newRateLimiter(bytesPerSecond uint64) *rateLimiter {
+	l := &rateLimiter{
+		limiter: rate.NewLimiter(rate.Inf, minRateLimiterBurst),
+	}
+	l.setRate(bytesPerSecond)
+	return l
+}
+
+// setRate changes the rate of the limiter, 0 for unlimited.
+func (l *rateLimiter) setRate(bytesPerSecond uint64) {
+	if bytesPerSecond == 0 {
+		l.limiter.SetLimit(rate.Inf)
+		return
+	}
+	burst := minRateLimiterBurst
+	if bytesPerSecond > uint64(burst) {
+		burst = int(bytesPerSecond)
+	}
+	l.limiter.SetBurst(burst)
+	l.limiter.SetLimit(rate.Limit(bytesPerSecond))
+}
+
+// WaitN implements policy.RateLimiter.
+func (l *rateLimiter) WaitN(ctx context.Context, n int) error {
+	for n > 0 {
+		if l.limiter.Limit() == rate.Inf {
+			return nil
+		}
+		chunk := min(n, l.limiter.Burst())
+		if err := l.limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// bandwidthLimiter limits the uplink and downlink traffic of a user or an
+// inbound.
+type bandwidthLimiter struct {
+	uplink   *rateLimiter
+	downlink *rateLimiter
+}
+
+func newBandwidthLimiter(b *Policy_Bandwidth) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		uplink:   newRateLimiter(b.GetUplink()),
+		downlink: newRateLimiter(b.GetDownlink()),
+	}
+}
+
+// isLimited returns whether b limits any direction of traffic.
+func isLimited(b *Policy_Bandwidth) bool {
+	return b.GetUplink() != 0 || b.GetDownlink() != 0
+}
+
+// set changes the limits of the limiter, nil for unlimited.
+func (l *bandwidthLimiter) set(b *Policy_Bandwidth) {
+	l.uplink.setRate(b.GetUplink())
+	l.downlink.setRate(b.GetDownlink())
+}
+
+// userLimiter is the limiter shared by all connections of a user.
+type userLimiter struct {
+	*bandwidthLimiter
+	level uint32
+	// released is whether the user has been removed from an inbound.
+	released bool
+}
+
+// addTo adds the limiters to those of a connection.
+func (l *bandwidthLimiter) addTo(limiters *policy.RateLimiters) {
+	limiters.Uplink = append(limiters.Uplink, l.uplink)
+	limiters.Downlink = append(limiters.Downlink, l.downlink)
+}
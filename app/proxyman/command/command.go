@@ -10,6 +10,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/common/serial"
 	"github.com/frogwall/f2ray-core/v5/features/inbound"
 	"github.com/frogwall/f2ray-core/v5/features/outbound"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
 	"github.com/frogwall/f2ray-core/v5/proxy"
 )
 
@@ -96,7 +97,15 @@ func (s *handlerServer) AlterInbound(ctx context.Context, request *AlterInboundR
 		return nil, newError("failed to get handler: ", request.Tag).Base(err)
 	}
 
-	return &AlterInboundResponse{}, operation.ApplyInbound(ctx, handler)
+	if err := operation.ApplyInbound(ctx, handler); err != nil {
+		return nil, err
+	}
+	if op, ok := operation.(*RemoveUserOperation); ok {
+		if rm, ok := s.s.GetFeature(policy.ManagerType()).(policy.RateLimitManager); ok {
+			rm.ReleaseUser(op.Email)
+		}
+	}
+	return &AlterInboundResponse{}, nil
 }
 
 func (s *handlerServer) AddOutbound(ctx context.Context, request *AddOutboundRequest) (*AddOutboundResponse, error) {
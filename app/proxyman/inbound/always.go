@@ -170,6 +170,18 @@ func (h *AlwaysOnInboundHandler) Close() error {
 	return nil
 }
 
+// CloseListeners implements inbound.ListenerCloser.
+func (h *AlwaysOnInboundHandler) CloseListeners() error {
+	var errs []error
+	for _, worker := range h.workers {
+		errs = append(errs, worker.CloseListener())
+	}
+	if err := errors.Combine(errs...); err != nil {
+		return newError("failed to close all listeners").Base(err)
+	}
+	return nil
+}
+
 func (h *AlwaysOnInboundHandler) GetRandomInboundProxy() (interface{}, net.Port, int) {
 	if len(h.workers) == 0 {
 		return nil, 0, 0
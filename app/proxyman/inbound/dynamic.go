@@ -8,6 +8,7 @@ import (
 	core "github.com/frogwall/f2ray-core/v5"
 	"github.com/frogwall/f2ray-core/v5/app/proxyman"
 	"github.com/frogwall/f2ray-core/v5/common/dice"
+	"github.com/frogwall/f2ray-core/v5/common/errors"
 	"github.com/frogwall/f2ray-core/v5/common/mux"
 	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/task"
@@ -183,7 +184,7 @@ func (h *DynamicInboundHandler) Start() error {
 }
 
 func (h *DynamicInboundHandler) Close() error {
-	return h.task.Close()
+	return errors.Combine(h.task.Close(), h.mux.Close())
 }
 
 func (h *DynamicInboundHandler) GetRandomInboundProxy() (interface{}, net.Port, int) {
@@ -88,6 +88,37 @@ func (m *Manager) RemoveHandler(ctx context.Context, tag string) error {
 	return common.ErrNoClue
 }
 
+// AttachHandler implements inbound.HandlerDetacher.
+func (m *Manager) AttachHandler(ctx context.Context, handler inbound.Handler) (inbound.Handler, error) {
+	tag := handler.Tag()
+	if tag == "" {
+		return nil, newError("cannot attach an untagged handler")
+	}
+
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	oldHandler := m.taggedHandlers[tag]
+	m.taggedHandlers[tag] = handler
+	return oldHandler, nil
+}
+
+// DetachHandler implements inbound.HandlerDetacher.
+func (m *Manager) DetachHandler(ctx context.Context, tag string) (inbound.Handler, error) {
+	if tag == "" {
+		return nil, common.ErrNoClue
+	}
+
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if handler, found := m.taggedHandlers[tag]; found {
+		delete(m.taggedHandlers, tag)
+		return handler, nil
+	}
+	return nil, common.ErrNoClue
+}
+
 // Start implements common.Runnable.
 func (m *Manager) Start() error {
 	m.access.Lock()
@@ -28,6 +28,8 @@ import (
 type worker interface {
 	Start() error
 	Close() error
+	// CloseListener stops accepting connections. Start listens again.
+	CloseListener() error
 	Port() net.Port
 	Proxy() proxy.Inbound
 }
@@ -44,7 +46,8 @@ type tcpWorker struct {
 	uplinkCounter   stats.Counter
 	downlinkCounter stats.Counter
 
-	hub internet.Listener
+	hub     internet.Listener
+	started bool
 
 	ctx context.Context
 }
@@ -130,13 +133,23 @@ func (w *tcpWorker) Start() error {
 		return newError("failed to listen TCP on ", w.port).AtWarning().Base(err)
 	}
 	w.hub = hub
+	w.started = true
 	return nil
 }
 
+func (w *tcpWorker) CloseListener() error {
+	if w.hub == nil {
+		return nil
+	}
+	hub := w.hub
+	w.hub = nil
+	return common.Close(hub)
+}
+
 func (w *tcpWorker) Close() error {
 	var errors []interface{}
-	if w.hub != nil {
-		if err := common.Close(w.hub); err != nil {
+	if w.started {
+		if err := w.CloseListener(); err != nil {
 			errors = append(errors, err)
 		}
 		if err := common.Close(w.proxy); err != nil {
@@ -266,11 +279,12 @@ func (w *udpWorker) getConnection(id connID) (*udpConn, bool) {
 	}
 
 	pReader, pWriter := pipe.New(pipe.DiscardOverflow(), pipe.WithSizeLimit(16*1024))
+	hub := w.hub
 	conn := &udpConn{
 		reader: pReader,
 		writer: pWriter,
 		output: func(b []byte) (int, error) {
-			return w.hub.WriteTo(b, id.src)
+			return hub.WriteTo(b, id.src)
 		},
 		remote: &net.UDPAddr{
 			IP:   id.src.Address.IP(),
@@ -403,22 +417,46 @@ func (w *udpWorker) Start() error {
 	return nil
 }
 
-func (w *udpWorker) Close() error {
+// CloseListener closes the UDP socket, which the existing sessions of the
+// worker are served on as well.
+func (w *udpWorker) CloseListener() error {
 	w.Lock()
 	defer w.Unlock()
 
+	return w.closeListener()
+}
+
+func (w *udpWorker) closeListener() error {
 	var errors []interface{}
 
 	if w.hub != nil {
 		if err := w.hub.Close(); err != nil {
 			errors = append(errors, err)
 		}
+		w.hub = nil
 	}
 
 	if w.checker != nil {
 		if err := w.checker.Close(); err != nil {
 			errors = append(errors, err)
 		}
+		w.checker = nil
+	}
+
+	if len(errors) > 0 {
+		return newError("failed to close all resources").Base(newError(serial.Concat(errors...)))
+	}
+	return nil
+}
+
+func (w *udpWorker) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	var errors []interface{}
+
+	if err := w.closeListener(); err != nil {
+		errors = append(errors, err)
 	}
 
 	if err := common.Close(w.proxy); err != nil {
@@ -449,7 +487,8 @@ type dsWorker struct {
 	uplinkCounter   stats.Counter
 	downlinkCounter stats.Counter
 
-	hub internet.Listener
+	hub     internet.Listener
+	started bool
 
 	ctx context.Context
 }
@@ -510,13 +549,23 @@ func (w *dsWorker) Start() error {
 		return newError("failed to listen Unix Domain Socket on ", w.address).AtWarning().Base(err)
 	}
 	w.hub = hub
+	w.started = true
 	return nil
 }
 
+func (w *dsWorker) CloseListener() error {
+	if w.hub == nil {
+		return nil
+	}
+	hub := w.hub
+	w.hub = nil
+	return common.Close(hub)
+}
+
 func (w *dsWorker) Close() error {
 	var errors []interface{}
-	if w.hub != nil {
-		if err := common.Close(w.hub); err != nil {
+	if w.started {
+		if err := w.CloseListener(); err != nil {
 			errors = append(errors, err)
 		}
 		if err := common.Close(w.proxy); err != nil {
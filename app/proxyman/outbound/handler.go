@@ -259,7 +259,11 @@ func (h *Handler) Dial(ctx context.Context, dest net.Destination) (internet.Conn
 	}
 
 	if isStream, err := packetaddr.GetDestinationSubsetOf(dest); err == nil && enablePacketAddrCapture {
-		packetConn, err := internet.ListenSystemPacket(ctx, &net.UDPAddr{IP: net.AnyIP.IP(), Port: 0}, h.streamSettings.SocketSettings)
+		var sockopt *internet.SocketConfig
+		if h.streamSettings != nil {
+			sockopt = h.streamSettings.SocketSettings
+		}
+		packetConn, err := internet.ListenSystemPacket(ctx, &net.UDPAddr{IP: net.AnyIP.IP(), Port: 0}, sockopt)
 		if err != nil {
 			return nil, newError("unable to listen socket").Base(err)
 		}
@@ -309,6 +313,19 @@ func (h *Handler) GetOutbound() proxy.Outbound {
 	return h.proxy
 }
 
+// Ping implements outbound.Pinger.
+func (h *Handler) Ping(ctx context.Context, dest net.Address, payload []byte) ([]byte, error) {
+	pinger, ok := h.proxy.(proxy.Pinger)
+	if !ok {
+		return nil, outbound.ErrPingNotSupported
+	}
+	var sockopt *internet.SocketConfig
+	if h.streamSettings != nil {
+		sockopt = h.streamSettings.SocketSettings
+	}
+	return pinger.Ping(ctx, dest, payload, sockopt)
+}
+
 // Start implements common.Runnable.
 func (h *Handler) Start() error {
 	return nil
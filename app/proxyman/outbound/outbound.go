@@ -98,6 +98,16 @@ func (m *Manager) GetHandler(tag string) outbound.Handler {
 
 // AddHandler implements outbound.Manager.
 func (m *Manager) AddHandler(ctx context.Context, handler outbound.Handler) error {
+	oldHandler, err := m.ReplaceHandler(ctx, handler)
+	if oldHandler != nil {
+		errors.New("will replace the existed outbound with the tag: " + handler.Tag()).AtWarning().WriteToLog()
+		_ = oldHandler.Close()
+	}
+	return err
+}
+
+// ReplaceHandler implements outbound.HandlerDetacher.
+func (m *Manager) ReplaceHandler(ctx context.Context, handler outbound.Handler) (outbound.Handler, error) {
 	m.access.Lock()
 	defer m.access.Unlock()
 	tag := handler.Tag()
@@ -107,43 +117,50 @@ func (m *Manager) AddHandler(ctx context.Context, handler outbound.Handler) erro
 		m.defaultHandler = handler
 	}
 
+	var oldHandler outbound.Handler
 	if len(tag) > 0 {
-		if oldHandler, found := m.taggedHandler[tag]; found {
-			errors.New("will replace the existed outbound with the tag: " + tag).AtWarning().WriteToLog()
-			_ = oldHandler.Close()
-		}
+		oldHandler = m.taggedHandler[tag]
 		m.taggedHandler[tag] = handler
 	} else {
 		m.untaggedHandlers = append(m.untaggedHandlers, handler)
 	}
 
 	if m.running {
-		return handler.Start()
+		return oldHandler, handler.Start()
 	}
 
-	return nil
+	return oldHandler, nil
 }
 
 // RemoveHandler implements outbound.Manager.
 func (m *Manager) RemoveHandler(ctx context.Context, tag string) error {
+	handler, err := m.DetachHandler(ctx, tag)
+	if err != nil {
+		return err
+	}
+	if err := handler.Close(); err != nil {
+		newError("failed to close handler ", tag).Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+	}
+	return nil
+}
+
+// DetachHandler implements outbound.HandlerDetacher.
+func (m *Manager) DetachHandler(ctx context.Context, tag string) (outbound.Handler, error) {
 	if tag == "" {
-		return common.ErrNoClue
+		return nil, common.ErrNoClue
 	}
 	m.access.Lock()
 	defer m.access.Unlock()
 
 	if handler, found := m.taggedHandler[tag]; found {
-		if err := handler.Close(); err != nil {
-			newError("failed to close handler ", tag).Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
-		}
 		delete(m.taggedHandler, tag)
 		if m.defaultHandler != nil && m.defaultHandler.Tag() == tag {
 			m.defaultHandler = nil
 		}
-		return nil
+		return handler, nil
 	}
 
-	return common.ErrNoClue
+	return nil, common.ErrNoClue
 }
 
 // Select implements outbound.HandlerSelector.
@@ -0,0 +1,135 @@
+package restfulapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"github.com/frogwall/f2ray-core/v5/app/stats"
+	feature_stats "github.com/frogwall/f2ray-core/v5/features/stats"
+)
+
+const defaultStreamConnectionsInterval = time.Second
+
+type Connection struct {
+	ID          uint64    `json:"id"`
+	Source      string    `json:"source"`
+	Target      string    `json:"target"`
+	Domain      string    `json:"domain,omitempty"`
+	Protocol    string    `json:"protocol,omitempty"`
+	InboundTag  string    `json:"inboundTag"`
+	OutboundTag string    `json:"outboundTag"`
+	User        string    `json:"user,omitempty"`
+	Start       time.Time `json:"start"`
+	Uplink      int64     `json:"uplink"`
+	Downlink    int64     `json:"downlink"`
+}
+
+type Connections struct {
+	Connections []*Connection `json:"connections"`
+}
+
+func newConnections(infos []*feature_stats.ConnectionInfo) *Connections {
+	connections := &Connections{Connections: make([]*Connection, 0, len(infos))}
+	for _, info := range infos {
+		connections.Connections = append(connections.Connections, &Connection{
+			ID:          info.ID,
+			Source:      info.Source,
+			Target:      info.Target,
+			Domain:      info.Domain,
+			Protocol:    info.Protocol,
+			InboundTag:  info.InboundTag,
+			OutboundTag: info.OutboundTag,
+			User:        info.User,
+			Start:       info.Start,
+			Uplink:      info.Uplink,
+			Downlink:    info.Downlink,
+		})
+	}
+	return connections
+}
+
+func (rs *restfulService) connectionTracker(w http.ResponseWriter, r *http.Request) (*stats.Manager, bool) {
+	manager, ok := rs.stats.(*stats.Manager)
+	if !ok {
+		render.Status(r, http.StatusNotImplemented)
+		render.JSON(w, r, render.M{})
+	}
+	return manager, ok
+}
+
+func (rs *restfulService) listConnections(w http.ResponseWriter, r *http.Request) {
+	manager, ok := rs.connectionTracker(w, r)
+	if !ok {
+		return
+	}
+	render.JSON(w, r, newConnections(manager.Connections()))
+}
+
+// streamConnections sends the list of connections periodically as server-sent events.
+func (rs *restfulService) streamConnections(w http.ResponseWriter, r *http.Request) {
+	interval := defaultStreamConnectionsInterval
+	if value := r.URL.Query().Get("interval"); value != "" {
+		milliseconds, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || milliseconds == 0 {
+			render.Status(r, http.StatusUnprocessableEntity)
+			render.JSON(w, r, render.M{})
+			return
+		}
+		interval = time.Duration(milliseconds) * time.Millisecond
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		render.Status(r, http.StatusNotImplemented)
+		render.JSON(w, r, render.M{})
+		return
+	}
+	manager, ok := rs.connectionTracker(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		data, err := json.Marshal(newConnections(manager.Connections()))
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-ticker.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (rs *restfulService) closeConnection(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.JSON(w, r, render.M{})
+		return
+	}
+	manager, ok := rs.connectionTracker(w, r)
+	if !ok {
+		return
+	}
+	if err := manager.CloseConnection(id); err != nil {
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, render.M{})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
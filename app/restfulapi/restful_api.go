@@ -75,6 +75,12 @@ func (rs *restfulService) start() error {
 	validate = validator.New()
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/{bound_type}/{tag}/stats", rs.tagStats)
+		r.Route("/connections", func(r chi.Router) {
+			r.Use(rs.TokenAuthMiddleware)
+			r.Get("/", rs.listConnections)
+			r.Get("/stream", rs.streamConnections)
+			r.Delete("/{id}", rs.closeConnection)
+		})
 	})
 	r.Get("/version", rs.version)
 
@@ -6,6 +6,8 @@ package router
 import (
 	"context"
 
+	"github.com/frogwall/f2ray-core/v5/common"
+
 	"github.com/frogwall/f2ray-core/v5/features/extension"
 	"github.com/frogwall/f2ray-core/v5/features/outbound"
 )
@@ -19,12 +21,15 @@ type BalancingPrincipleTarget interface {
 }
 
 type Balancer struct {
+	tag         string
 	selectors   []string
 	strategy    BalancingStrategy
 	ohm         outbound.Manager
 	fallbackTag string
 
 	override override
+
+	config *BalancingRule
 }
 
 // PickOutbound picks the tag of an outbound
@@ -60,6 +65,11 @@ func (b *Balancer) InjectContext(ctx context.Context) {
 	}
 }
 
+// Close closes the strategy of the Balancer, if it holds resources.
+func (b *Balancer) Close() error {
+	return common.Close(b.strategy)
+}
+
 // SelectOutbounds select outbounds with selectors of the Balancer
 func (b *Balancer) SelectOutbounds() ([]string, error) {
 	hs, ok := b.ohm.(outbound.HandlerSelector)
@@ -72,7 +82,7 @@ func (b *Balancer) SelectOutbounds() ([]string, error) {
 
 // GetPrincipleTarget implements routing.BalancerPrincipleTarget
 func (r *Router) GetPrincipleTarget(tag string) ([]string, error) {
-	if b, ok := r.balancers[tag]; ok {
+	if b, ok := r.getBalancer(tag); ok {
 		if s, ok := b.strategy.(BalancingPrincipleTarget); ok {
 			candidates, err := b.SelectOutbounds()
 			if err != nil {
@@ -87,7 +97,7 @@ func (r *Router) GetPrincipleTarget(tag string) ([]string, error) {
 
 // SetOverrideTarget implements routing.BalancerOverrider
 func (r *Router) SetOverrideTarget(tag, target string) error {
-	if b, ok := r.balancers[tag]; ok {
+	if b, ok := r.getBalancer(tag); ok {
 		b.override.Put(target)
 		return nil
 	}
@@ -96,7 +106,7 @@ func (r *Router) SetOverrideTarget(tag, target string) error {
 
 // GetOverrideTarget implements routing.BalancerOverrider
 func (r *Router) GetOverrideTarget(tag string) (string, error) {
-	if b, ok := r.balancers[tag]; ok {
+	if b, ok := r.getBalancer(tag); ok {
 		return b.override.Get(), nil
 	}
 	return "", newError("cannot find tag")
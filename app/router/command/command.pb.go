@@ -1,6 +1,7 @@
 package command
 
 import (
+	router "github.com/frogwall/f2ray-core/v5/app/router"
 	net "github.com/frogwall/f2ray-core/v5/common/net"
 	_ "github.com/frogwall/f2ray-core/v5/common/protoext"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -597,6 +598,689 @@ func (*OverrideBalancerTargetResponse) Descriptor() ([]byte, []int) {
 	return file_app_router_command_command_proto_rawDescGZIP(), []int{9}
 }
 
+type ListRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRulesRequest) Reset() {
+	*x = ListRulesRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesRequest) ProtoMessage() {}
+
+func (x *ListRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesRequest.ProtoReflect.Descriptor instead.
+func (*ListRulesRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{10}
+}
+
+// ListRulesResponse contains the routing rules in order, and the balancing
+// rules by tag.
+type ListRulesResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Rules         []*router.RoutingRule   `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	Balancers     []*router.BalancingRule `protobuf:"bytes,2,rep,name=balancers,proto3" json:"balancers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRulesResponse) Reset() {
+	*x = ListRulesResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRulesResponse) ProtoMessage() {}
+
+func (x *ListRulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRulesResponse.ProtoReflect.Descriptor instead.
+func (*ListRulesResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListRulesResponse) GetRules() []*router.RoutingRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ListRulesResponse) GetBalancers() []*router.BalancingRule {
+	if x != nil {
+		return x.Balancers
+	}
+	return nil
+}
+
+// AddRuleRequest appends a routing rule. The rule_tag of the rule, if set,
+// must not be used by other rules.
+type AddRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          *router.RoutingRule    `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddRuleRequest) Reset() {
+	*x = AddRuleRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRuleRequest) ProtoMessage() {}
+
+func (x *AddRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRuleRequest.ProtoReflect.Descriptor instead.
+func (*AddRuleRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AddRuleRequest) GetRule() *router.RoutingRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type AddRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddRuleResponse) Reset() {
+	*x = AddRuleResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRuleResponse) ProtoMessage() {}
+
+func (x *AddRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRuleResponse.ProtoReflect.Descriptor instead.
+func (*AddRuleResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{13}
+}
+
+// InsertRuleRequest inserts a routing rule at index. The rule is appended if
+// index is negative or beyond the number of rules.
+type InsertRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Rule          *router.RoutingRule    `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InsertRuleRequest) Reset() {
+	*x = InsertRuleRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InsertRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InsertRuleRequest) ProtoMessage() {}
+
+func (x *InsertRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InsertRuleRequest.ProtoReflect.Descriptor instead.
+func (*InsertRuleRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *InsertRuleRequest) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *InsertRuleRequest) GetRule() *router.RoutingRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type InsertRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InsertRuleResponse) Reset() {
+	*x = InsertRuleResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InsertRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InsertRuleResponse) ProtoMessage() {}
+
+func (x *InsertRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InsertRuleResponse.ProtoReflect.Descriptor instead.
+func (*InsertRuleResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{15}
+}
+
+// ReplaceRuleRequest replaces the routing rule with the rule_tag in place.
+type ReplaceRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Rule          *router.RoutingRule    `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplaceRuleRequest) Reset() {
+	*x = ReplaceRuleRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplaceRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplaceRuleRequest) ProtoMessage() {}
+
+func (x *ReplaceRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplaceRuleRequest.ProtoReflect.Descriptor instead.
+func (*ReplaceRuleRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReplaceRuleRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ReplaceRuleRequest) GetRule() *router.RoutingRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+type ReplaceRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplaceRuleResponse) Reset() {
+	*x = ReplaceRuleResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplaceRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplaceRuleResponse) ProtoMessage() {}
+
+func (x *ReplaceRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplaceRuleResponse.ProtoReflect.Descriptor instead.
+func (*ReplaceRuleResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{17}
+}
+
+type RemoveRuleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveRuleRequest) Reset() {
+	*x = RemoveRuleRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveRuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRuleRequest) ProtoMessage() {}
+
+func (x *RemoveRuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRuleRequest.ProtoReflect.Descriptor instead.
+func (*RemoveRuleRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RemoveRuleRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type RemoveRuleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveRuleResponse) Reset() {
+	*x = RemoveRuleResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveRuleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRuleResponse) ProtoMessage() {}
+
+func (x *RemoveRuleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRuleResponse.ProtoReflect.Descriptor instead.
+func (*RemoveRuleResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{19}
+}
+
+type AddBalancerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Balancer      *router.BalancingRule  `protobuf:"bytes,1,opt,name=balancer,proto3" json:"balancer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddBalancerRequest) Reset() {
+	*x = AddBalancerRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddBalancerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBalancerRequest) ProtoMessage() {}
+
+func (x *AddBalancerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBalancerRequest.ProtoReflect.Descriptor instead.
+func (*AddBalancerRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AddBalancerRequest) GetBalancer() *router.BalancingRule {
+	if x != nil {
+		return x.Balancer
+	}
+	return nil
+}
+
+type AddBalancerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddBalancerResponse) Reset() {
+	*x = AddBalancerResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddBalancerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBalancerResponse) ProtoMessage() {}
+
+func (x *AddBalancerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBalancerResponse.ProtoReflect.Descriptor instead.
+func (*AddBalancerResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{21}
+}
+
+// ReplaceBalancerRequest replaces the balancing rule with the tag. Routing
+// rules targeting the balancer are updated to the new one.
+type ReplaceBalancerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Balancer      *router.BalancingRule  `protobuf:"bytes,2,opt,name=balancer,proto3" json:"balancer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplaceBalancerRequest) Reset() {
+	*x = ReplaceBalancerRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplaceBalancerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplaceBalancerRequest) ProtoMessage() {}
+
+func (x *ReplaceBalancerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplaceBalancerRequest.ProtoReflect.Descriptor instead.
+func (*ReplaceBalancerRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReplaceBalancerRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *ReplaceBalancerRequest) GetBalancer() *router.BalancingRule {
+	if x != nil {
+		return x.Balancer
+	}
+	return nil
+}
+
+type ReplaceBalancerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplaceBalancerResponse) Reset() {
+	*x = ReplaceBalancerResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplaceBalancerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplaceBalancerResponse) ProtoMessage() {}
+
+func (x *ReplaceBalancerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplaceBalancerResponse.ProtoReflect.Descriptor instead.
+func (*ReplaceBalancerResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{23}
+}
+
+// RemoveBalancerRequest removes the balancing rule with the tag, if it is not
+// targeted by any routing rule.
+type RemoveBalancerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveBalancerRequest) Reset() {
+	*x = RemoveBalancerRequest{}
+	mi := &file_app_router_command_command_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveBalancerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveBalancerRequest) ProtoMessage() {}
+
+func (x *RemoveBalancerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveBalancerRequest.ProtoReflect.Descriptor instead.
+func (*RemoveBalancerRequest) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RemoveBalancerRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type RemoveBalancerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveBalancerResponse) Reset() {
+	*x = RemoveBalancerResponse{}
+	mi := &file_app_router_command_command_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveBalancerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveBalancerResponse) ProtoMessage() {}
+
+func (x *RemoveBalancerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_app_router_command_command_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveBalancerResponse.ProtoReflect.Descriptor instead.
+func (*RemoveBalancerResponse) Descriptor() ([]byte, []int) {
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{25}
+}
+
 type Config struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -605,7 +1289,7 @@ type Config struct {
 
 func (x *Config) Reset() {
 	*x = Config{}
-	mi := &file_app_router_command_command_proto_msgTypes[10]
+	mi := &file_app_router_command_command_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -617,7 +1301,7 @@ func (x *Config) String() string {
 func (*Config) ProtoMessage() {}
 
 func (x *Config) ProtoReflect() protoreflect.Message {
-	mi := &file_app_router_command_command_proto_msgTypes[10]
+	mi := &file_app_router_command_command_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -630,14 +1314,14 @@ func (x *Config) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Config.ProtoReflect.Descriptor instead.
 func (*Config) Descriptor() ([]byte, []int) {
-	return file_app_router_command_command_proto_rawDescGZIP(), []int{10}
+	return file_app_router_command_command_proto_rawDescGZIP(), []int{26}
 }
 
 var File_app_router_command_command_proto protoreflect.FileDescriptor
 
 const file_app_router_command_command_proto_rawDesc = "" +
 	"\n" +
-	" app/router/command/command.proto\x12\x1dv2ray.core.app.router.command\x1a common/protoext/extensions.proto\x1a\x18common/net/network.proto\"\xa8\x04\n" +
+	" app/router/command/command.proto\x12\x1dv2ray.core.app.router.command\x1a common/protoext/extensions.proto\x1a\x18common/net/network.proto\x1a\x17app/router/config.proto\"\xa8\x04\n" +
 	"\x0eRoutingContext\x12\x1e\n" +
 	"\n" +
 	"InboundTag\x18\x01 \x01(\tR\n" +
@@ -683,14 +1367,52 @@ const file_app_router_command_command_proto_rawDesc = "" +
 	"\x1dOverrideBalancerTargetRequest\x12 \n" +
 	"\vbalancerTag\x18\x01 \x01(\tR\vbalancerTag\x12\x16\n" +
 	"\x06target\x18\x02 \x01(\tR\x06target\" \n" +
-	"\x1eOverrideBalancerTargetResponse\"#\n" +
+	"\x1eOverrideBalancerTargetResponse\"\x12\n" +
+	"\x10ListRulesRequest\"\x91\x01\n" +
+	"\x11ListRulesResponse\x128\n" +
+	"\x05rules\x18\x01 \x03(\v2\".v2ray.core.app.router.RoutingRuleR\x05rules\x12B\n" +
+	"\tbalancers\x18\x02 \x03(\v2$.v2ray.core.app.router.BalancingRuleR\tbalancers\"H\n" +
+	"\x0eAddRuleRequest\x126\n" +
+	"\x04rule\x18\x01 \x01(\v2\".v2ray.core.app.router.RoutingRuleR\x04rule\"\x11\n" +
+	"\x0fAddRuleResponse\"a\n" +
+	"\x11InsertRuleRequest\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x126\n" +
+	"\x04rule\x18\x02 \x01(\v2\".v2ray.core.app.router.RoutingRuleR\x04rule\"\x14\n" +
+	"\x12InsertRuleResponse\"^\n" +
+	"\x12ReplaceRuleRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x126\n" +
+	"\x04rule\x18\x02 \x01(\v2\".v2ray.core.app.router.RoutingRuleR\x04rule\"\x15\n" +
+	"\x13ReplaceRuleResponse\"%\n" +
+	"\x11RemoveRuleRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\"\x14\n" +
+	"\x12RemoveRuleResponse\"V\n" +
+	"\x12AddBalancerRequest\x12@\n" +
+	"\bbalancer\x18\x01 \x01(\v2$.v2ray.core.app.router.BalancingRuleR\bbalancer\"\x15\n" +
+	"\x13AddBalancerResponse\"l\n" +
+	"\x16ReplaceBalancerRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12@\n" +
+	"\bbalancer\x18\x02 \x01(\v2$.v2ray.core.app.router.BalancingRuleR\bbalancer\"\x19\n" +
+	"\x17ReplaceBalancerResponse\")\n" +
+	"\x15RemoveBalancerRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\"\x18\n" +
+	"\x16RemoveBalancerResponse\"#\n" +
 	"\x06Config:\x19\x82\xb5\x18\x15\n" +
-	"\vgrpcservice\x12\x06router2\xa8\x04\n" +
+	"\vgrpcservice\x12\x06router2\xe6\v\n" +
 	"\x0eRoutingService\x12\x87\x01\n" +
 	"\x15SubscribeRoutingStats\x12;.v2ray.core.app.router.command.SubscribeRoutingStatsRequest\x1a-.v2ray.core.app.router.command.RoutingContext\"\x000\x01\x12m\n" +
 	"\tTestRoute\x12/.v2ray.core.app.router.command.TestRouteRequest\x1a-.v2ray.core.app.router.command.RoutingContext\"\x00\x12\x82\x01\n" +
 	"\x0fGetBalancerInfo\x125.v2ray.core.app.router.command.GetBalancerInfoRequest\x1a6.v2ray.core.app.router.command.GetBalancerInfoResponse\"\x00\x12\x97\x01\n" +
-	"\x16OverrideBalancerTarget\x12<.v2ray.core.app.router.command.OverrideBalancerTargetRequest\x1a=.v2ray.core.app.router.command.OverrideBalancerTargetResponse\"\x00B{\n" +
+	"\x16OverrideBalancerTarget\x12<.v2ray.core.app.router.command.OverrideBalancerTargetRequest\x1a=.v2ray.core.app.router.command.OverrideBalancerTargetResponse\"\x00\x12p\n" +
+	"\tListRules\x12/.v2ray.core.app.router.command.ListRulesRequest\x1a0.v2ray.core.app.router.command.ListRulesResponse\"\x00\x12j\n" +
+	"\aAddRule\x12-.v2ray.core.app.router.command.AddRuleRequest\x1a..v2ray.core.app.router.command.AddRuleResponse\"\x00\x12s\n" +
+	"\n" +
+	"InsertRule\x120.v2ray.core.app.router.command.InsertRuleRequest\x1a1.v2ray.core.app.router.command.InsertRuleResponse\"\x00\x12v\n" +
+	"\vReplaceRule\x121.v2ray.core.app.router.command.ReplaceRuleRequest\x1a2.v2ray.core.app.router.command.ReplaceRuleResponse\"\x00\x12s\n" +
+	"\n" +
+	"RemoveRule\x120.v2ray.core.app.router.command.RemoveRuleRequest\x1a1.v2ray.core.app.router.command.RemoveRuleResponse\"\x00\x12v\n" +
+	"\vAddBalancer\x121.v2ray.core.app.router.command.AddBalancerRequest\x1a2.v2ray.core.app.router.command.AddBalancerResponse\"\x00\x12\x82\x01\n" +
+	"\x0fReplaceBalancer\x125.v2ray.core.app.router.command.ReplaceBalancerRequest\x1a6.v2ray.core.app.router.command.ReplaceBalancerResponse\"\x00\x12\x7f\n" +
+	"\x0eRemoveBalancer\x124.v2ray.core.app.router.command.RemoveBalancerRequest\x1a5.v2ray.core.app.router.command.RemoveBalancerResponse\"\x00B{\n" +
 	"!com.v2ray.core.app.router.commandP\x01Z4github.com/frogwall/f2ray-core/v5/app/router/command\xaa\x02\x1dV2Ray.Core.App.Router.Commandb\x06proto3"
 
 var (
@@ -705,7 +1427,7 @@ func file_app_router_command_command_proto_rawDescGZIP() []byte {
 	return file_app_router_command_command_proto_rawDescData
 }
 
-var file_app_router_command_command_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_app_router_command_command_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_app_router_command_command_proto_goTypes = []any{
 	(*RoutingContext)(nil),                 // 0: v2ray.core.app.router.command.RoutingContext
 	(*SubscribeRoutingStatsRequest)(nil),   // 1: v2ray.core.app.router.command.SubscribeRoutingStatsRequest
@@ -717,30 +1439,71 @@ var file_app_router_command_command_proto_goTypes = []any{
 	(*GetBalancerInfoResponse)(nil),        // 7: v2ray.core.app.router.command.GetBalancerInfoResponse
 	(*OverrideBalancerTargetRequest)(nil),  // 8: v2ray.core.app.router.command.OverrideBalancerTargetRequest
 	(*OverrideBalancerTargetResponse)(nil), // 9: v2ray.core.app.router.command.OverrideBalancerTargetResponse
-	(*Config)(nil),                         // 10: v2ray.core.app.router.command.Config
-	nil,                                    // 11: v2ray.core.app.router.command.RoutingContext.AttributesEntry
-	(net.Network)(0),                       // 12: v2ray.core.common.net.Network
+	(*ListRulesRequest)(nil),               // 10: v2ray.core.app.router.command.ListRulesRequest
+	(*ListRulesResponse)(nil),              // 11: v2ray.core.app.router.command.ListRulesResponse
+	(*AddRuleRequest)(nil),                 // 12: v2ray.core.app.router.command.AddRuleRequest
+	(*AddRuleResponse)(nil),                // 13: v2ray.core.app.router.command.AddRuleResponse
+	(*InsertRuleRequest)(nil),              // 14: v2ray.core.app.router.command.InsertRuleRequest
+	(*InsertRuleResponse)(nil),             // 15: v2ray.core.app.router.command.InsertRuleResponse
+	(*ReplaceRuleRequest)(nil),             // 16: v2ray.core.app.router.command.ReplaceRuleRequest
+	(*ReplaceRuleResponse)(nil),            // 17: v2ray.core.app.router.command.ReplaceRuleResponse
+	(*RemoveRuleRequest)(nil),              // 18: v2ray.core.app.router.command.RemoveRuleRequest
+	(*RemoveRuleResponse)(nil),             // 19: v2ray.core.app.router.command.RemoveRuleResponse
+	(*AddBalancerRequest)(nil),             // 20: v2ray.core.app.router.command.AddBalancerRequest
+	(*AddBalancerResponse)(nil),            // 21: v2ray.core.app.router.command.AddBalancerResponse
+	(*ReplaceBalancerRequest)(nil),         // 22: v2ray.core.app.router.command.ReplaceBalancerRequest
+	(*ReplaceBalancerResponse)(nil),        // 23: v2ray.core.app.router.command.ReplaceBalancerResponse
+	(*RemoveBalancerRequest)(nil),          // 24: v2ray.core.app.router.command.RemoveBalancerRequest
+	(*RemoveBalancerResponse)(nil),         // 25: v2ray.core.app.router.command.RemoveBalancerResponse
+	(*Config)(nil),                         // 26: v2ray.core.app.router.command.Config
+	nil,                                    // 27: v2ray.core.app.router.command.RoutingContext.AttributesEntry
+	(net.Network)(0),                       // 28: v2ray.core.common.net.Network
+	(*router.RoutingRule)(nil),             // 29: v2ray.core.app.router.RoutingRule
+	(*router.BalancingRule)(nil),           // 30: v2ray.core.app.router.BalancingRule
 }
 var file_app_router_command_command_proto_depIdxs = []int32{
-	12, // 0: v2ray.core.app.router.command.RoutingContext.Network:type_name -> v2ray.core.common.net.Network
-	11, // 1: v2ray.core.app.router.command.RoutingContext.Attributes:type_name -> v2ray.core.app.router.command.RoutingContext.AttributesEntry
+	28, // 0: v2ray.core.app.router.command.RoutingContext.Network:type_name -> v2ray.core.common.net.Network
+	27, // 1: v2ray.core.app.router.command.RoutingContext.Attributes:type_name -> v2ray.core.app.router.command.RoutingContext.AttributesEntry
 	0,  // 2: v2ray.core.app.router.command.TestRouteRequest.RoutingContext:type_name -> v2ray.core.app.router.command.RoutingContext
 	4,  // 3: v2ray.core.app.router.command.BalancerMsg.override:type_name -> v2ray.core.app.router.command.OverrideInfo
 	3,  // 4: v2ray.core.app.router.command.BalancerMsg.principle_target:type_name -> v2ray.core.app.router.command.PrincipleTargetInfo
 	5,  // 5: v2ray.core.app.router.command.GetBalancerInfoResponse.balancer:type_name -> v2ray.core.app.router.command.BalancerMsg
-	1,  // 6: v2ray.core.app.router.command.RoutingService.SubscribeRoutingStats:input_type -> v2ray.core.app.router.command.SubscribeRoutingStatsRequest
-	2,  // 7: v2ray.core.app.router.command.RoutingService.TestRoute:input_type -> v2ray.core.app.router.command.TestRouteRequest
-	6,  // 8: v2ray.core.app.router.command.RoutingService.GetBalancerInfo:input_type -> v2ray.core.app.router.command.GetBalancerInfoRequest
-	8,  // 9: v2ray.core.app.router.command.RoutingService.OverrideBalancerTarget:input_type -> v2ray.core.app.router.command.OverrideBalancerTargetRequest
-	0,  // 10: v2ray.core.app.router.command.RoutingService.SubscribeRoutingStats:output_type -> v2ray.core.app.router.command.RoutingContext
-	0,  // 11: v2ray.core.app.router.command.RoutingService.TestRoute:output_type -> v2ray.core.app.router.command.RoutingContext
-	7,  // 12: v2ray.core.app.router.command.RoutingService.GetBalancerInfo:output_type -> v2ray.core.app.router.command.GetBalancerInfoResponse
-	9,  // 13: v2ray.core.app.router.command.RoutingService.OverrideBalancerTarget:output_type -> v2ray.core.app.router.command.OverrideBalancerTargetResponse
-	10, // [10:14] is the sub-list for method output_type
-	6,  // [6:10] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	29, // 6: v2ray.core.app.router.command.ListRulesResponse.rules:type_name -> v2ray.core.app.router.RoutingRule
+	30, // 7: v2ray.core.app.router.command.ListRulesResponse.balancers:type_name -> v2ray.core.app.router.BalancingRule
+	29, // 8: v2ray.core.app.router.command.AddRuleRequest.rule:type_name -> v2ray.core.app.router.RoutingRule
+	29, // 9: v2ray.core.app.router.command.InsertRuleRequest.rule:type_name -> v2ray.core.app.router.RoutingRule
+	29, // 10: v2ray.core.app.router.command.ReplaceRuleRequest.rule:type_name -> v2ray.core.app.router.RoutingRule
+	30, // 11: v2ray.core.app.router.command.AddBalancerRequest.balancer:type_name -> v2ray.core.app.router.BalancingRule
+	30, // 12: v2ray.core.app.router.command.ReplaceBalancerRequest.balancer:type_name -> v2ray.core.app.router.BalancingRule
+	1,  // 13: v2ray.core.app.router.command.RoutingService.SubscribeRoutingStats:input_type -> v2ray.core.app.router.command.SubscribeRoutingStatsRequest
+	2,  // 14: v2ray.core.app.router.command.RoutingService.TestRoute:input_type -> v2ray.core.app.router.command.TestRouteRequest
+	6,  // 15: v2ray.core.app.router.command.RoutingService.GetBalancerInfo:input_type -> v2ray.core.app.router.command.GetBalancerInfoRequest
+	8,  // 16: v2ray.core.app.router.command.RoutingService.OverrideBalancerTarget:input_type -> v2ray.core.app.router.command.OverrideBalancerTargetRequest
+	10, // 17: v2ray.core.app.router.command.RoutingService.ListRules:input_type -> v2ray.core.app.router.command.ListRulesRequest
+	12, // 18: v2ray.core.app.router.command.RoutingService.AddRule:input_type -> v2ray.core.app.router.command.AddRuleRequest
+	14, // 19: v2ray.core.app.router.command.RoutingService.InsertRule:input_type -> v2ray.core.app.router.command.InsertRuleRequest
+	16, // 20: v2ray.core.app.router.command.RoutingService.ReplaceRule:input_type -> v2ray.core.app.router.command.ReplaceRuleRequest
+	18, // 21: v2ray.core.app.router.command.RoutingService.RemoveRule:input_type -> v2ray.core.app.router.command.RemoveRuleRequest
+	20, // 22: v2ray.core.app.router.command.RoutingService.AddBalancer:input_type -> v2ray.core.app.router.command.AddBalancerRequest
+	22, // 23: v2ray.core.app.router.command.RoutingService.ReplaceBalancer:input_type -> v2ray.core.app.router.command.ReplaceBalancerRequest
+	24, // 24: v2ray.core.app.router.command.RoutingService.RemoveBalancer:input_type -> v2ray.core.app.router.command.RemoveBalancerRequest
+	0,  // 25: v2ray.core.app.router.command.RoutingService.SubscribeRoutingStats:output_type -> v2ray.core.app.router.command.RoutingContext
+	0,  // 26: v2ray.core.app.router.command.RoutingService.TestRoute:output_type -> v2ray.core.app.router.command.RoutingContext
+	7,  // 27: v2ray.core.app.router.command.RoutingService.GetBalancerInfo:output_type -> v2ray.core.app.router.command.GetBalancerInfoResponse
+	9,  // 28: v2ray.core.app.router.command.RoutingService.OverrideBalancerTarget:output_type -> v2ray.core.app.router.command.OverrideBalancerTargetResponse
+	11, // 29: v2ray.core.app.router.command.RoutingService.ListRules:output_type -> v2ray.core.app.router.command.ListRulesResponse
+	13, // 30: v2ray.core.app.router.command.RoutingService.AddRule:output_type -> v2ray.core.app.router.command.AddRuleResponse
+	15, // 31: v2ray.core.app.router.command.RoutingService.InsertRule:output_type -> v2ray.core.app.router.command.InsertRuleResponse
+	17, // 32: v2ray.core.app.router.command.RoutingService.ReplaceRule:output_type -> v2ray.core.app.router.command.ReplaceRuleResponse
+	19, // 33: v2ray.core.app.router.command.RoutingService.RemoveRule:output_type -> v2ray.core.app.router.command.RemoveRuleResponse
+	21, // 34: v2ray.core.app.router.command.RoutingService.AddBalancer:output_type -> v2ray.core.app.router.command.AddBalancerResponse
+	23, // 35: v2ray.core.app.router.command.RoutingService.ReplaceBalancer:output_type -> v2ray.core.app.router.command.ReplaceBalancerResponse
+	25, // 36: v2ray.core.app.router.command.RoutingService.RemoveBalancer:output_type -> v2ray.core.app.router.command.RemoveBalancerResponse
+	25, // [25:37] is the sub-list for method output_type
+	13, // [13:25] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_app_router_command_command_proto_init() }
@@ -754,7 +1517,7 @@ func file_app_router_command_command_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_app_router_command_command_proto_rawDesc), len(file_app_router_command_command_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   12,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/process"
 	"github.com/frogwall/f2ray-core/v5/features/routing"
 )
 
@@ -35,6 +36,12 @@ func (c routingContext) GetSkipDNSResolve() bool {
 	return false
 }
 
+// GetProcess is a mock implementation here to match the interface, as the
+// process is looked up from the local socket of a connection.
+func (c routingContext) GetProcess() *process.Info {
+	return nil
+}
+
 // AsRoutingContext converts a protobuf RoutingContext into an implementation of routing.Context.
 func AsRoutingContext(r *RoutingContext) routing.Context {
 	return routingContext{r}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"context"
+
+	"github.com/frogwall/f2ray-core/v5/app/router"
+)
+
+// ruleManager is the interface of routers of which rules can be managed at runtime, as router.Router.
+type ruleManager interface {
+	ListRules() ([]*router.RoutingRule, []*router.BalancingRule)
+	AddRule(rule *router.RoutingRule) error
+	InsertRule(index int, rule *router.RoutingRule) error
+	ReplaceRule(tag string, rule *router.RoutingRule) error
+	RemoveRule(tag string) error
+	AddBalancer(rule *router.BalancingRule) error
+	ReplaceBalancer(tag string, rule *router.BalancingRule) error
+	RemoveBalancer(tag string) error
+}
+
+func (s *routingServer) ruleManager() (ruleManager, error) {
+	if m, ok := s.router.(ruleManager); ok {
+		return m, nil
+	}
+	return nil, newError("unsupported router implementation")
+}
+
+func (s *routingServer) ListRules(ctx context.Context, request *ListRulesRequest) (*ListRulesResponse, error) {
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	rules, balancers := m.ListRules()
+	return &ListRulesResponse{Rules: rules, Balancers: balancers}, nil
+}
+
+func (s *routingServer) AddRule(ctx context.Context, request *AddRuleRequest) (*AddRuleResponse, error) {
+	if request.Rule == nil {
+		return nil, newError("empty rule")
+	}
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &AddRuleResponse{}, m.AddRule(request.Rule)
+}
+
+func (s *routingServer) InsertRule(ctx context.Context, request *InsertRuleRequest) (*InsertRuleResponse, error) {
+	if request.Rule == nil {
+		return nil, newError("empty rule")
+	}
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &InsertRuleResponse{}, m.InsertRule(int(request.Index), request.Rule)
+}
+
+func (s *routingServer) ReplaceRule(ctx context.Context, request *ReplaceRuleRequest) (*ReplaceRuleResponse, error) {
+	if request.Rule == nil {
+		return nil, newError("empty rule")
+	}
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &ReplaceRuleResponse{}, m.ReplaceRule(request.Tag, request.Rule)
+}
+
+func (s *routingServer) RemoveRule(ctx context.Context, request *RemoveRuleRequest) (*RemoveRuleResponse, error) {
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &RemoveRuleResponse{}, m.RemoveRule(request.Tag)
+}
+
+func (s *routingServer) AddBalancer(ctx context.Context, request *AddBalancerRequest) (*AddBalancerResponse, error) {
+	if request.Balancer == nil {
+		return nil, newError("empty balancer")
+	}
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &AddBalancerResponse{}, m.AddBalancer(request.Balancer)
+}
+
+func (s *routingServer) ReplaceBalancer(ctx context.Context, request *ReplaceBalancerRequest) (*ReplaceBalancerResponse, error) {
+	if request.Balancer == nil {
+		return nil, newError("empty balancer")
+	}
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &ReplaceBalancerResponse{}, m.ReplaceBalancer(request.Tag, request.Balancer)
+}
+
+func (s *routingServer) RemoveBalancer(ctx context.Context, request *RemoveBalancerRequest) (*RemoveBalancerResponse, error) {
+	m, err := s.ruleManager()
+	if err != nil {
+		return nil, err
+	}
+	return &RemoveBalancerResponse{}, m.RemoveBalancer(request.Tag)
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"strings"
+	"sync/atomic"
 
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
@@ -64,12 +65,22 @@ func domainToMatcher(domain *routercommon.Domain) (strmatcher.Matcher, error) {
 }
 
 type DomainMatcher struct {
-	matcher strmatcher.IndexMatcher
+	matcherType string
+	matcher     atomic.Value // strmatcher.IndexMatcher
 }
 
 func NewDomainMatcher(matcherType string, domains []*routercommon.Domain) (*DomainMatcher, error) {
+	m := &DomainMatcher{matcherType: matcherType}
+	if err := m.Update(domains); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Update replaces the domains of the matcher. Matches in progress are not affected.
+func (m *DomainMatcher) Update(domains []*routercommon.Domain) error {
 	var indexMatcher strmatcher.IndexMatcher
-	switch matcherType {
+	switch m.matcherType {
 	case "mph", "hybrid":
 		indexMatcher = strmatcher.NewMphIndexMatcher()
 	case "linear":
@@ -80,18 +91,19 @@ func NewDomainMatcher(matcherType string, domains []*routercommon.Domain) (*Doma
 	for _, domain := range domains {
 		matcher, err := domainToMatcher(domain)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		indexMatcher.Add(matcher)
 	}
 	if err := indexMatcher.Build(); err != nil {
-		return nil, err
+		return err
 	}
-	return &DomainMatcher{matcher: indexMatcher}, nil
+	m.matcher.Store(indexMatcher)
+	return nil
 }
 
 func (m *DomainMatcher) Match(domain string) bool {
-	return m.matcher.MatchAny(domain)
+	return m.matcher.Load().(strmatcher.IndexMatcher).MatchAny(domain)
 }
 
 // Apply implements Condition.
@@ -104,7 +116,7 @@ func (m *DomainMatcher) Apply(ctx routing.Context) bool {
 }
 
 type MultiGeoIPMatcher struct {
-	matchers []*GeoIPMatcher
+	matchers atomic.Pointer[[]*GeoIPMatcher]
 	onSource bool
 }
 
@@ -119,13 +131,31 @@ func NewMultiGeoIPMatcher(geoips []*routercommon.GeoIP, onSource bool) (*MultiGe
 	}
 
 	matcher := &MultiGeoIPMatcher{
-		matchers: matchers,
 		onSource: onSource,
 	}
+	matcher.matchers.Store(&matchers)
 
 	return matcher, nil
 }
 
+// Update replaces the IP ranges of the matcher. Unlike NewMultiGeoIPMatcher,
+// the GeoIPs are not shared by country code, as they may change on each update.
+func (m *MultiGeoIPMatcher) Update(geoips []*routercommon.GeoIP) error {
+	matchers := make([]*GeoIPMatcher, 0, len(geoips))
+	for _, geoip := range geoips {
+		matcher := &GeoIPMatcher{
+			countryCode:  geoip.CountryCode,
+			reverseMatch: geoip.InverseMatch,
+		}
+		if err := matcher.Init(geoip.Cidr); err != nil {
+			return err
+		}
+		matchers = append(matchers, matcher)
+	}
+	m.matchers.Store(&matchers)
+	return nil
+}
+
 // Apply implements Condition.
 func (m *MultiGeoIPMatcher) Apply(ctx routing.Context) bool {
 	var ips []net.IP
@@ -134,8 +164,13 @@ func (m *MultiGeoIPMatcher) Apply(ctx routing.Context) bool {
 	} else {
 		ips = ctx.GetTargetIPs()
 	}
+	return m.matchAny(ips)
+}
+
+func (m *MultiGeoIPMatcher) matchAny(ips []net.IP) bool {
+	matchers := *m.matchers.Load()
 	for _, ip := range ips {
-		for _, matcher := range m.matchers {
+		for _, matcher := range matchers {
 			if matcher.Match(ip) {
 				return true
 			}
@@ -275,7 +310,9 @@ func (m *ProtocolMatcher) Apply(ctx routing.Context) bool {
 }
 
 type AttributeMatcher struct {
-	program *starlark.Program
+	// compiled is the compiled expression, or nil if it is evaluated by program.
+	compiled attrBool
+	program  *starlark.Program
 }
 
 func NewAttributeMatcher(code string) (*AttributeMatcher, error) {
@@ -284,18 +321,28 @@ func NewAttributeMatcher(code string) (*AttributeMatcher, error) {
 		return nil, newError("attr rule").Base(err)
 	}
 	p, err := starlark.FileProgram(starFile, func(name string) bool {
-		return name == "attrs"
+		return name == "attrs" || name == matchBuiltin.Name()
 	})
 	if err != nil {
 		return nil, err
 	}
+	compiled, err := compileAttrBool(starFile.Stmts[0].(*syntax.AssignStmt).RHS)
+	if err != nil {
+		newError("attr rule is evaluated by starlark: ", code).Base(err).AtDebug().WriteToLog()
+	}
 	return &AttributeMatcher{
-		program: p,
+		compiled: compiled,
+		program:  p,
 	}, nil
 }
 
 // Match implements attributes matching.
 func (m *AttributeMatcher) Match(attrs map[string]string) bool {
+	if m.compiled != nil {
+		satisfied, ok := m.compiled(attrs)
+		return ok && satisfied
+	}
+
 	attrsDict := new(starlark.Dict)
 	for key, value := range attrs {
 		attrsDict.SetKey(starlark.String(key), starlark.String(value))
@@ -303,6 +350,7 @@ func (m *AttributeMatcher) Match(attrs map[string]string) bool {
 
 	predefined := make(starlark.StringDict)
 	predefined["attrs"] = attrsDict
+	predefined[matchBuiltin.Name()] = matchBuiltin
 
 	thread := &starlark.Thread{
 		Name: "matcher",
@@ -323,3 +371,76 @@ func (m *AttributeMatcher) Apply(ctx routing.Context) bool {
 	}
 	return m.Match(attributes)
 }
+
+type ProcessNameMatcher struct {
+	names []string
+}
+
+func NewProcessNameMatcher(names []string) *ProcessNameMatcher {
+	return &ProcessNameMatcher{
+		names: names,
+	}
+}
+
+// Apply implements Condition.
+func (m *ProcessNameMatcher) Apply(ctx routing.Context) bool {
+	info := ctx.GetProcess()
+	if info == nil || info.Path == "" {
+		return false
+	}
+	name := info.Name()
+	for _, n := range m.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+type ProcessPathMatcher struct {
+	paths []string
+}
+
+func NewProcessPathMatcher(paths []string) *ProcessPathMatcher {
+	return &ProcessPathMatcher{
+		paths: paths,
+	}
+}
+
+// Apply implements Condition.
+func (m *ProcessPathMatcher) Apply(ctx routing.Context) bool {
+	info := ctx.GetProcess()
+	if info == nil || info.Path == "" {
+		return false
+	}
+	for _, p := range m.paths {
+		if p == info.Path {
+			return true
+		}
+	}
+	return false
+}
+
+type UIDMatcher struct {
+	uids []uint32
+}
+
+func NewUIDMatcher(uids []uint32) *UIDMatcher {
+	return &UIDMatcher{
+		uids: uids,
+	}
+}
+
+// Apply implements Condition.
+func (m *UIDMatcher) Apply(ctx routing.Context) bool {
+	info := ctx.GetProcess()
+	if info == nil {
+		return false
+	}
+	for _, uid := range m.uids {
+		if uid == info.UID {
+			return true
+		}
+	}
+	return false
+}
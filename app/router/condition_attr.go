@@ -0,0 +1,371 @@
+package router
+
+import (
+	"regexp"
+	"strings"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Attribute rules are starlark expressions over attrs, the attributes of the
+// connection. Common expressions are compiled to Go closures once, so that
+// matching does not set up a starlark interpreter on every connection:
+//
+//	attrs[':method'] == 'GET' and attrs[':path'].startswith('/api')
+//	'user-agent' in attrs and match('curl/.*', attrs['user-agent'])
+//
+// Supported are string literals, attrs[key], attrs.get(key, default), the
+// string methods lower, upper, strip, startswith and endswith, the builtin
+// match(pattern, s) of regexps, the operators ==, !=, in, not in, and, or
+// and not, and the constants True and False. As in starlark, looking up an
+// absent attribute with attrs[key] fails the whole expression. Expressions
+// beyond these are evaluated by starlark.
+
+// attrString evaluates to a string, or false if the evaluation fails.
+type attrString func(attrs map[string]string) (string, bool)
+
+// attrBool evaluates to a bool, or false if the evaluation fails.
+type attrBool func(attrs map[string]string) (bool, bool)
+
+// matchBuiltin is match(pattern, s) in starlark, which reports whether s
+// contains any match of the regexp pattern.
+var matchBuiltin = starlark.NewBuiltin("match", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern, s string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &pattern, &s); err != nil {
+		return nil, err
+	}
+	matched, err := regexp.MatchString(pattern, s)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(matched), nil
+})
+
+func isAttrsIdent(expr syntax.Expr) bool {
+	ident, ok := expr.(*syntax.Ident)
+	return ok && ident.Name == "attrs"
+}
+
+func stringLiteral(expr syntax.Expr) (string, bool) {
+	literal, ok := expr.(*syntax.Literal)
+	if !ok || literal.Token != syntax.STRING {
+		return "", false
+	}
+	return literal.Value.(string), true
+}
+
+func compileAttrString(expr syntax.Expr) (attrString, error) {
+	switch expr := expr.(type) {
+	case *syntax.ParenExpr:
+		return compileAttrString(expr.X)
+	case *syntax.Literal:
+		value, ok := stringLiteral(expr)
+		if !ok {
+			return nil, newError("unsupported literal ", expr.Raw)
+		}
+		return func(map[string]string) (string, bool) {
+			return value, true
+		}, nil
+	case *syntax.IndexExpr:
+		if !isAttrsIdent(expr.X) {
+			return nil, newError("unsupported index expression")
+		}
+		key, err := compileAttrString(expr.Y)
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) (string, bool) {
+			k, ok := key(attrs)
+			if !ok {
+				return "", false
+			}
+			value, found := attrs[k]
+			return value, found
+		}, nil
+	case *syntax.CallExpr:
+		return compileAttrStringCall(expr)
+	default:
+		return nil, newError("unsupported string expression")
+	}
+}
+
+func compileAttrStringCall(expr *syntax.CallExpr) (attrString, error) {
+	dot, ok := expr.Fn.(*syntax.DotExpr)
+	if !ok {
+		return nil, newError("unsupported call")
+	}
+	if isAttrsIdent(dot.X) {
+		if dot.Name.Name != "get" || len(expr.Args) != 2 {
+			return nil, newError("unsupported call of attrs")
+		}
+		key, err := compileAttrString(expr.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		def, err := compileAttrString(expr.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) (string, bool) {
+			k, ok := key(attrs)
+			if !ok {
+				return "", false
+			}
+			if value, found := attrs[k]; found {
+				return value, true
+			}
+			return def(attrs)
+		}, nil
+	}
+
+	var transform func(string) string
+	switch dot.Name.Name {
+	case "lower":
+		transform = strings.ToLower
+	case "upper":
+		transform = strings.ToUpper
+	case "strip":
+		transform = strings.TrimSpace
+	default:
+		return nil, newError("unsupported string method ", dot.Name.Name)
+	}
+	if len(expr.Args) != 0 {
+		return nil, newError("unsupported arguments of ", dot.Name.Name)
+	}
+	s, err := compileAttrString(dot.X)
+	if err != nil {
+		return nil, err
+	}
+	return func(attrs map[string]string) (string, bool) {
+		value, ok := s(attrs)
+		if !ok {
+			return "", false
+		}
+		return transform(value), true
+	}, nil
+}
+
+func compileAttrBool(expr syntax.Expr) (attrBool, error) {
+	switch expr := expr.(type) {
+	case *syntax.ParenExpr:
+		return compileAttrBool(expr.X)
+	case *syntax.Ident:
+		var value bool
+		switch expr.Name {
+		case "True":
+			value = true
+		case "False":
+			value = false
+		default:
+			return nil, newError("unsupported identifier ", expr.Name)
+		}
+		return func(map[string]string) (bool, bool) {
+			return value, true
+		}, nil
+	case *syntax.UnaryExpr:
+		if expr.Op != syntax.NOT {
+			return nil, newError("unsupported operator ", expr.Op)
+		}
+		x, err := compileAttrBool(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) (bool, bool) {
+			value, ok := x(attrs)
+			return !value, ok
+		}, nil
+	case *syntax.BinaryExpr:
+		return compileAttrBinary(expr)
+	case *syntax.CallExpr:
+		if isAttrBoolCall(expr) {
+			return compileAttrBoolCall(expr)
+		}
+	}
+
+	// A string is true if it is not empty.
+	s, err := compileAttrString(expr)
+	if err != nil {
+		return nil, err
+	}
+	return func(attrs map[string]string) (bool, bool) {
+		value, ok := s(attrs)
+		return len(value) > 0, ok
+	}, nil
+}
+
+func compileAttrBinary(expr *syntax.BinaryExpr) (attrBool, error) {
+	switch expr.Op {
+	case syntax.AND, syntax.OR:
+		x, err := compileAttrBool(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := compileAttrBool(expr.Y)
+		if err != nil {
+			return nil, err
+		}
+		// The value of x decides the result if it equals shortCircuit.
+		shortCircuit := expr.Op == syntax.OR
+		return func(attrs map[string]string) (bool, bool) {
+			value, ok := x(attrs)
+			if !ok || value == shortCircuit {
+				return value, ok
+			}
+			return y(attrs)
+		}, nil
+	case syntax.EQL, syntax.NEQ:
+		x, err := compileAttrString(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := compileAttrString(expr.Y)
+		if err != nil {
+			return nil, err
+		}
+		negate := expr.Op == syntax.NEQ
+		return func(attrs map[string]string) (bool, bool) {
+			a, ok := x(attrs)
+			if !ok {
+				return false, false
+			}
+			b, ok := y(attrs)
+			return (a == b) != negate, ok
+		}, nil
+	case syntax.IN, syntax.NOT_IN:
+		x, err := compileAttrString(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		contains, err := compileAttrContainer(expr.Y)
+		if err != nil {
+			return nil, err
+		}
+		negate := expr.Op == syntax.NOT_IN
+		return func(attrs map[string]string) (bool, bool) {
+			value, ok := x(attrs)
+			if !ok {
+				return false, false
+			}
+			found, ok := contains(attrs, value)
+			return found != negate, ok
+		}, nil
+	default:
+		return nil, newError("unsupported operator ", expr.Op)
+	}
+}
+
+// compileAttrContainer compiles the right operand of in, which is attrs, a
+// list or tuple of strings, or a string.
+func compileAttrContainer(expr syntax.Expr) (func(attrs map[string]string, value string) (bool, bool), error) {
+	if isAttrsIdent(expr) {
+		return func(attrs map[string]string, key string) (bool, bool) {
+			_, found := attrs[key]
+			return found, true
+		}, nil
+	}
+
+	if paren, ok := expr.(*syntax.ParenExpr); ok {
+		return compileAttrContainer(paren.X)
+	}
+
+	var list []syntax.Expr
+	switch expr := expr.(type) {
+	case *syntax.ListExpr:
+		list = expr.List
+	case *syntax.TupleExpr:
+		list = expr.List
+	default:
+		s, err := compileAttrString(expr)
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string, value string) (bool, bool) {
+			container, ok := s(attrs)
+			return strings.Contains(container, value), ok
+		}, nil
+	}
+
+	elements := make(map[string]bool, len(list))
+	for _, e := range list {
+		value, ok := stringLiteral(e)
+		if !ok {
+			return nil, newError("unsupported element of list")
+		}
+		elements[value] = true
+	}
+	return func(attrs map[string]string, value string) (bool, bool) {
+		return elements[value], true
+	}, nil
+}
+
+// isAttrBoolCall reports whether expr is a call of match, startswith or endswith.
+func isAttrBoolCall(expr *syntax.CallExpr) bool {
+	switch fn := expr.Fn.(type) {
+	case *syntax.Ident:
+		return fn.Name == "match"
+	case *syntax.DotExpr:
+		return fn.Name.Name == "startswith" || fn.Name.Name == "endswith"
+	default:
+		return false
+	}
+}
+
+func compileAttrBoolCall(expr *syntax.CallExpr) (attrBool, error) {
+	switch fn := expr.Fn.(type) {
+	case *syntax.Ident:
+		if fn.Name != "match" || len(expr.Args) != 2 {
+			return nil, newError("unsupported function ", fn.Name)
+		}
+		pattern, ok := stringLiteral(expr.Args[0])
+		if !ok {
+			return nil, newError("pattern of match is not a string literal")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, newError("invalid pattern ", pattern).Base(err)
+		}
+		s, err := compileAttrString(expr.Args[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) (bool, bool) {
+			value, ok := s(attrs)
+			if !ok {
+				return false, false
+			}
+			return re.MatchString(value), true
+		}, nil
+	case *syntax.DotExpr:
+		var test func(s, affix string) bool
+		switch fn.Name.Name {
+		case "startswith":
+			test = strings.HasPrefix
+		case "endswith":
+			test = strings.HasSuffix
+		default:
+			return nil, newError("unsupported method ", fn.Name.Name)
+		}
+		if len(expr.Args) != 1 {
+			return nil, newError("unsupported arguments of ", fn.Name.Name)
+		}
+		s, err := compileAttrString(fn.X)
+		if err != nil {
+			return nil, err
+		}
+		affix, err := compileAttrString(expr.Args[0])
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) (bool, bool) {
+			value, ok := s(attrs)
+			if !ok {
+				return false, false
+			}
+			a, ok := affix(attrs)
+			return test(value, a), ok
+		}, nil
+	default:
+		return nil, newError("unsupported call")
+	}
+}
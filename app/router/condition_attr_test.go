@@ -0,0 +1,132 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	routing_session "github.com/frogwall/f2ray-core/v5/features/routing/session"
+)
+
+// starlarkOnly returns a copy of m, which is evaluated by starlark.
+func starlarkOnly(m *AttributeMatcher) *AttributeMatcher {
+	return &AttributeMatcher{program: m.program}
+}
+
+func TestAttributeMatcherCompiled(t *testing.T) {
+	attrsList := []map[string]string{
+		nil,
+		{":method": "GET", ":path": "/api/v1", "user-agent": "curl/8.0", "host": " Example.COM "},
+		{":method": "POST", ":path": "/upload", "accept": "text/html"},
+	}
+	cases := []struct {
+		code     string
+		compiled bool
+	}{
+		{"attrs[':method'] == 'GET'", true},
+		{"attrs[':method'] != 'GET'", true},
+		{"attrs[':path'].startswith('/api')", true},
+		{"attrs[':path'].endswith('load')", true},
+		{"attrs['host'].strip().lower() == 'example.com'", true},
+		{"attrs.get('user-agent', '').upper().startswith('CURL')", true},
+		{"'accept' in attrs", true},
+		{"'accept' not in attrs", true},
+		{"'api' in attrs[':path']", true},
+		{"attrs[':method'] in ['GET', 'HEAD']", true},
+		{"attrs[':method'] not in ('GET', 'HEAD')", true},
+		{"match('^curl/[0-9.]+$', attrs['user-agent'])", true},
+		{"match('^curl/', attrs.get('user-agent', ''))", true},
+		{"attrs[':method'] == 'POST' or attrs['user-agent'] == 'curl/8.0'", true},
+		{"attrs['user-agent'] == 'curl/8.0' or attrs[':method'] == 'POST'", true},
+		{"attrs[':method'] == 'GET' and not attrs[':path'].startswith('/upload')", true},
+		{"True or attrs['absent'] == ''", true},
+		{"False and attrs['absent'] == ''", true},
+		{"attrs.get('accept', '')", true},
+		{"attrs[':path'].startswith(('/api', '/upload'))", false},
+		{"len(attrs) > 2", false},
+	}
+
+	for _, tc := range cases {
+		m, err := NewAttributeMatcher(tc.code)
+		common.Must(err)
+		if (m.compiled != nil) != tc.compiled {
+			t.Error("compiled of ", tc.code, ": expected ", tc.compiled)
+		}
+		fallback := starlarkOnly(m)
+		for _, attrs := range attrsList {
+			if expected, actual := fallback.Match(attrs), m.Match(attrs); expected != actual {
+				t.Error(tc.code, " on ", attrs, ": expected ", expected, " but got ", actual)
+			}
+		}
+	}
+}
+
+func BenchmarkAttributeMatcher(b *testing.B) {
+	m, err := NewAttributeMatcher("attrs[':method'] == 'GET' and attrs[':path'].startswith('/api') and 'user-agent' in attrs")
+	common.Must(err)
+	attrs := map[string]string{":method": "GET", ":path": "/api/v1", "user-agent": "curl/8.0", "accept": "*/*"}
+
+	for name, matcher := range map[string]*AttributeMatcher{
+		"Compiled": m,
+		"Starlark": starlarkOnly(m),
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = matcher.Match(attrs)
+			}
+		})
+	}
+}
+
+// BenchmarkAttributeRules picks a route through many attribute rules, where
+// only the last one matches.
+func BenchmarkAttributeRules(b *testing.B) {
+	const ruleCount = 16
+	build := func(compiled bool) *Router {
+		config := new(Config)
+		for i := 0; i < ruleCount; i++ {
+			config.Rule = append(config.Rule, &RoutingRule{
+				TargetTag:  &RoutingRule_Tag{Tag: fmt.Sprint("out-", i)},
+				Attributes: fmt.Sprintf("attrs[':path'].startswith('/api/v%d') and attrs[':method'] == 'GET'", ruleCount-1-i),
+			})
+		}
+		r := new(Router)
+		common.Must(r.Init(context.Background(), config, nil, nil, nil))
+		if !compiled {
+			for _, rule := range r.rules {
+				for i, cond := range *rule.Condition.(*ConditionChan) {
+					if m, ok := cond.(*AttributeMatcher); ok {
+						(*rule.Condition.(*ConditionChan))[i] = starlarkOnly(m)
+					}
+				}
+			}
+		}
+		return r
+	}
+
+	content := &session.Content{Attributes: map[string]string{":method": "GET", ":path": "/api/v0/users", "host": "example.com"}}
+	ctx := session.ContextWithContent(context.Background(), content)
+	ctx = session.ContextWithOutbound(ctx, &session.Outbound{Target: net.TCPDestination(net.DomainAddress("example.com"), 80)})
+	routingCtx := routing_session.AsRoutingContext(ctx)
+
+	for _, compiled := range []bool{true, false} {
+		r := build(compiled)
+		name := "Starlark"
+		if compiled {
+			name = "Compiled"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				route, err := r.PickRoute(routingCtx)
+				if err != nil || route.GetOutboundTag() != fmt.Sprint("out-", ruleCount-1) {
+					b.Fatal("unexpected route: ", route, err)
+				}
+			}
+		})
+	}
+}
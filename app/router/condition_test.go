@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -371,6 +372,47 @@ func loadGeoSite(country string) ([]*routercommon.Domain, error) {
 	return nil, errors.New("country not found: " + country)
 }
 
+func TestProcessRule(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process lookup is not supported on ", runtime.GOOS)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	common.Must(err)
+	defer conn.Close()
+
+	executable, err := os.Executable()
+	common.Must(err)
+	uid := uint32(os.Getuid())
+
+	cases := []struct {
+		rule   *router.RoutingRule
+		output bool
+	}{
+		{rule: &router.RoutingRule{ProcessName: []string{filepath.Base(executable)}}, output: true},
+		{rule: &router.RoutingRule{ProcessName: []string{"curl"}}, output: false},
+		{rule: &router.RoutingRule{ProcessPath: []string{executable}}, output: true},
+		{rule: &router.RoutingRule{ProcessPath: []string{"/usr/bin/" + filepath.Base(executable)}}, output: false},
+		{rule: &router.RoutingRule{Uid: []uint32{uid}}, output: true},
+		{rule: &router.RoutingRule{Uid: []uint32{uid + 1}}, output: false},
+	}
+	for _, test := range cases {
+		cond, err := test.rule.BuildCondition()
+		common.Must(err)
+
+		ctx := withInbound(&session.Inbound{Source: net.DestinationFromAddr(conn.LocalAddr())})
+		if actual := cond.Apply(ctx); actual != test.output {
+			t.Error("test case failed: ", test.rule, " expected ", test.output, " but got ", actual)
+		}
+		if cond.Apply(withBackground()) {
+			t.Error("rule matches connection without source: ", test.rule)
+		}
+	}
+}
+
 func TestChinaSites(t *testing.T) {
 	domains, err := loadGeoSite("CN")
 	common.Must(err)
@@ -0,0 +1,205 @@
+package router
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ParseWeekday parses a day of the week, in its English name, e.g. "sat" or
+// "Saturday", or its number, 0 for Sunday.
+func ParseWeekday(s string) (uint32, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		if n > 6 {
+			return 0, newError("invalid weekday ", s)
+		}
+		return uint32(n), nil
+	}
+	if len(s) >= 3 {
+		if day, found := weekdayNames[s[:3]]; found && strings.HasPrefix(strings.ToLower(day.String()), s) {
+			return uint32(day), nil
+		}
+	}
+	return 0, newError("invalid weekday ", s)
+}
+
+// parseTimeOfDay parses "15:04" or "15:04:05" to seconds since midnight.
+// "24:00" is accepted as the end of the day.
+func parseTimeOfDay(s string) (uint32, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return 0, newError("invalid time of day ", s)
+	}
+	limits := []uint64{24, 59, 59}
+	var seconds uint64
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || n > limits[i] {
+			return 0, newError("invalid time of day ", s)
+		}
+		seconds = seconds*60 + n
+	}
+	if len(parts) == 2 {
+		seconds *= 60
+	}
+	if seconds > secondsPerDay {
+		return 0, newError("invalid time of day ", s)
+	}
+	return uint32(seconds), nil
+}
+
+// ParseTimeRange parses a window of the day, e.g. "22:00-06:00".
+func ParseTimeRange(s string) (*TimeCondition_Range, error) {
+	begin, end, found := strings.Cut(s, "-")
+	if !found {
+		return nil, newError("invalid time range ", s)
+	}
+	r := new(TimeCondition_Range)
+	var err error
+	if r.Begin, err = parseTimeOfDay(begin); err != nil {
+		return nil, err
+	}
+	if r.End, err = parseTimeOfDay(end); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Build builds the TimeCondition in text.
+func (c *SimplifiedTimeCondition) Build() (*TimeCondition, error) {
+	condition := &TimeCondition{Timezone: c.Timezone}
+	for _, s := range c.Range {
+		r, err := ParseTimeRange(s)
+		if err != nil {
+			return nil, err
+		}
+		condition.Range = append(condition.Range, r)
+	}
+	for _, s := range c.Weekday {
+		day, err := ParseWeekday(s)
+		if err != nil {
+			return nil, err
+		}
+		condition.Weekday = append(condition.Weekday, day)
+	}
+	return condition, nil
+}
+
+// loadLocation loads an IANA timezone, or a fixed zone of a UTC offset, e.g.
+// "+08:00", "-0530" or "UTC+8".
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	offset := strings.TrimPrefix(strings.TrimPrefix(name, "UTC"), "GMT")
+	if len(offset) > 1 && (offset[0] == '+' || offset[0] == '-') {
+		hours, minutes, found := strings.Cut(offset[1:], ":")
+		if !found && len(hours) > 2 {
+			hours, minutes = hours[:len(hours)-2], hours[len(hours)-2:]
+		}
+		h, err := strconv.ParseUint(hours, 10, 32)
+		if err != nil || h > 14 {
+			return nil, newError("invalid UTC offset ", name)
+		}
+		var m uint64
+		if minutes != "" {
+			if m, err = strconv.ParseUint(minutes, 10, 32); err != nil || m > 59 {
+				return nil, newError("invalid UTC offset ", name)
+			}
+		}
+		seconds := int(h*3600 + m*60)
+		if offset[0] == '-' {
+			seconds = -seconds
+		}
+		return time.FixedZone(name, seconds), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, newError("failed to load timezone ", name).Base(err)
+	}
+	return location, nil
+}
+
+// TimeMatcher matches the current time against windows of the day and days of the week.
+type TimeMatcher struct {
+	ranges   []*TimeCondition_Range
+	weekdays [7]bool
+	anyDay   bool
+	location *time.Location
+
+	// now returns the current time, which is replaced in tests.
+	now func() time.Time
+}
+
+func NewTimeMatcher(condition *TimeCondition) (*TimeMatcher, error) {
+	location, err := loadLocation(condition.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range condition.Range {
+		if r.Begin > secondsPerDay || r.End > secondsPerDay || r.Begin == r.End {
+			return nil, newError("invalid time range from ", r.Begin, " to ", r.End)
+		}
+	}
+	m := &TimeMatcher{
+		ranges:   condition.Range,
+		anyDay:   len(condition.Weekday) == 0,
+		location: location,
+		now:      time.Now,
+	}
+	for _, day := range condition.Weekday {
+		if day > 6 {
+			return nil, newError("invalid weekday ", day)
+		}
+		m.weekdays[day] = true
+	}
+	return m, nil
+}
+
+func (m *TimeMatcher) onDay(day time.Weekday) bool {
+	return m.anyDay || m.weekdays[day]
+}
+
+// Match reports whether t is in any window of the day, on any of the days.
+func (m *TimeMatcher) Match(t time.Time) bool {
+	t = t.In(m.location)
+	day := t.Weekday()
+	if len(m.ranges) == 0 {
+		return m.onDay(day)
+	}
+	hour, minute, second := t.Clock()
+	seconds := uint32(hour*3600 + minute*60 + second)
+	for _, r := range m.ranges {
+		if r.Begin < r.End {
+			if seconds >= r.Begin && seconds < r.End && m.onDay(day) {
+				return true
+			}
+			continue
+		}
+		// The window wraps over midnight. The part after midnight belongs to the previous day.
+		if seconds >= r.Begin && m.onDay(day) || seconds < r.End && m.onDay((day+6)%7) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply implements Condition.
+func (m *TimeMatcher) Apply(ctx routing.Context) bool {
+	return m.Match(m.now())
+}
@@ -0,0 +1,150 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	routing_session "github.com/frogwall/f2ray-core/v5/features/routing/session"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		begin uint32
+		end   uint32
+		err   bool
+	}{
+		{input: "22:00-06:00", begin: 22 * 3600, end: 6 * 3600},
+		{input: " 08:30:15 - 24:00 ", begin: 8*3600 + 30*60 + 15, end: secondsPerDay},
+		{input: "08:00", err: true},
+		{input: "08:60-09:00", err: true},
+		{input: "24:01-01:00", err: true},
+		{input: "8-9", err: true},
+	} {
+		r, err := ParseTimeRange(tc.input)
+		if tc.err {
+			if err == nil {
+				t.Error("nil error for ", tc.input)
+			}
+			continue
+		}
+		common.Must(err)
+		if r.Begin != tc.begin || r.End != tc.end {
+			t.Error("unexpected range of ", tc.input, ": ", r)
+		}
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	for input, expected := range map[string]uint32{
+		"sun":      0,
+		"Saturday": 6,
+		"WED":      3,
+		"1":        1,
+	} {
+		day, err := ParseWeekday(input)
+		common.Must(err)
+		if day != expected {
+			t.Error("expect ", expected, " for ", input, ", but actually ", day)
+		}
+	}
+	for _, input := range []string{"7", "sa", "saturn", ""} {
+		if _, err := ParseWeekday(input); err == nil {
+			t.Error("nil error for ", input)
+		}
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	ref := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for input, expected := range map[string]int{
+		"+08:00":   8 * 3600,
+		"-0530":    -(5*3600 + 30*60),
+		"UTC+8":    8 * 3600,
+		"GMT-3":    -3 * 3600,
+		"UTC":      0,
+		"+14":      14 * 3600,
+		"+05:45":   5*3600 + 45*60,
+		"UTC+0800": 8 * 3600,
+	} {
+		location, err := loadLocation(input)
+		common.Must(err)
+		if _, offset := ref.In(location).Zone(); offset != expected {
+			t.Error("expect offset ", expected, " of ", input, ", but actually ", offset)
+		}
+	}
+	for _, input := range []string{"+15:00", "+08:75", "Nowhere/City"} {
+		if _, err := loadLocation(input); err == nil {
+			t.Error("nil error for ", input)
+		}
+	}
+}
+
+func TestTimeMatcher(t *testing.T) {
+	night, err := (&SimplifiedTimeCondition{
+		Range:    []string{"22:00-06:00"},
+		Weekday:  []string{"fri", "sat"},
+		Timezone: "+08:00",
+	}).Build()
+	common.Must(err)
+	m, err := NewTimeMatcher(night)
+	common.Must(err)
+
+	zone := time.FixedZone("", 8*3600)
+	for _, tc := range []struct {
+		time     time.Time
+		expected bool
+	}{
+		// 2024-01-05 is a Friday.
+		{time.Date(2024, 1, 5, 22, 0, 0, 0, zone), true},
+		{time.Date(2024, 1, 5, 21, 59, 59, 0, zone), false},
+		{time.Date(2024, 1, 6, 5, 59, 59, 0, zone), true},
+		{time.Date(2024, 1, 6, 6, 0, 0, 0, zone), false},
+		{time.Date(2024, 1, 6, 23, 0, 0, 0, zone), true},
+		// Sunday morning belongs to the window of Saturday night.
+		{time.Date(2024, 1, 7, 1, 0, 0, 0, zone), true},
+		{time.Date(2024, 1, 7, 23, 0, 0, 0, zone), false},
+		// Friday morning belongs to the window of Thursday night.
+		{time.Date(2024, 1, 5, 1, 0, 0, 0, zone), false},
+		// The same instant in another timezone.
+		{time.Date(2024, 1, 5, 14, 30, 0, 0, time.UTC), true},
+	} {
+		if actual := m.Match(tc.time); actual != tc.expected {
+			t.Error("expect ", tc.expected, " at ", tc.time, ", but actually ", actual)
+		}
+	}
+
+	now := time.Date(2024, 1, 5, 23, 0, 0, 0, zone)
+	m.now = func() time.Time { return now }
+	ctx := routing_session.AsRoutingContext(context.Background())
+	if !m.Apply(ctx) {
+		t.Error("expect match at ", now)
+	}
+	now = now.Add(8 * time.Hour)
+	if m.Apply(ctx) {
+		t.Error("expect no match at ", now)
+	}
+}
+
+func TestTimeMatcherWeekdays(t *testing.T) {
+	m, err := NewTimeMatcher(&TimeCondition{Weekday: []uint32{0, 6}, Timezone: "UTC"})
+	common.Must(err)
+	if !m.Match(time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expect match on Saturday")
+	}
+	if m.Match(time.Date(2024, 1, 8, 12, 0, 0, 0, time.UTC)) {
+		t.Error("expect no match on Monday")
+	}
+
+	for _, condition := range []*TimeCondition{
+		{Range: []*TimeCondition_Range{{Begin: 3600, End: 3600}}},
+		{Range: []*TimeCondition_Range{{Begin: 0, End: secondsPerDay + 1}}},
+		{Weekday: []uint32{7}},
+	} {
+		if _, err := NewTimeMatcher(condition); err == nil {
+			t.Error("nil error for ", condition)
+		}
+	}
+}
@@ -21,6 +21,8 @@ type Rule struct {
 	Tag       string
 	Balancer  *Balancer
 	Condition Condition
+
+	config *RoutingRule
 }
 
 func (r *Rule) GetTag() (string, error) {
@@ -36,6 +38,11 @@ func (r *Rule) Apply(ctx routing.Context) bool {
 }
 
 func (rr *RoutingRule) BuildCondition() (Condition, error) {
+	return rr.buildCondition(nil)
+}
+
+// buildCondition builds the condition of the rule, looking up rule-sets by tag in ruleSets.
+func (rr *RoutingRule) buildCondition(ruleSets map[string]*RuleSetProvider) (Condition, error) {
 	conds := NewConditionChan()
 
 	if len(rr.Domain) > 0 {
@@ -122,6 +129,43 @@ func (rr *RoutingRule) BuildCondition() (Condition, error) {
 		conds.Add(cond)
 	}
 
+	if rr.Time != nil {
+		cond, err := NewTimeMatcher(rr.Time)
+		if err != nil {
+			return nil, newError("failed to build time condition").Base(err)
+		}
+		conds.Add(cond)
+	}
+
+	if len(rr.RuleSet) > 0 {
+		sets, err := lookupRuleSets(ruleSets, rr.RuleSet)
+		if err != nil {
+			return nil, err
+		}
+		conds.Add(NewRuleSetMatcher(sets, false))
+	}
+
+	if len(rr.SourceRuleSet) > 0 {
+		sets, err := lookupRuleSets(ruleSets, rr.SourceRuleSet)
+		if err != nil {
+			return nil, err
+		}
+		conds.Add(NewRuleSetMatcher(sets, true))
+	}
+
+	// Conditions of the local process are the last, as the lookup is costly.
+	if len(rr.ProcessName) > 0 {
+		conds.Add(NewProcessNameMatcher(rr.ProcessName))
+	}
+
+	if len(rr.ProcessPath) > 0 {
+		conds.Add(NewProcessPathMatcher(rr.ProcessPath))
+	}
+
+	if len(rr.Uid) > 0 {
+		conds.Add(NewUIDMatcher(rr.Uid))
+	}
+
 	if conds.Len() == 0 {
 		return nil, newError("this rule has no effective fields").AtWarning()
 	}
@@ -129,6 +173,18 @@ func (rr *RoutingRule) BuildCondition() (Condition, error) {
 	return conds, nil
 }
 
+func lookupRuleSets(ruleSets map[string]*RuleSetProvider, tags []string) ([]*RuleSetProvider, error) {
+	sets := make([]*RuleSetProvider, 0, len(tags))
+	for _, tag := range tags {
+		set, found := ruleSets[tag]
+		if !found {
+			return nil, newError("rule-set ", tag, " not found")
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
 // Build builds the balancing rule
 func (br *BalancingRule) Build(ohm outbound.Manager, dispatcher routing.Dispatcher) (*Balancer, error) {
 	switch br.Strategy {
@@ -4,6 +4,7 @@ package router
 
 import (
 	"context"
+	"sync"
 
 	core "github.com/frogwall/f2ray-core/v5"
 	"github.com/frogwall/f2ray-core/v5/common"
@@ -12,16 +13,27 @@ import (
 	"github.com/frogwall/f2ray-core/v5/features/outbound"
 	"github.com/frogwall/f2ray-core/v5/features/routing"
 	routing_dns "github.com/frogwall/f2ray-core/v5/features/routing/dns"
+	"github.com/frogwall/f2ray-core/v5/features/stats"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/geodata"
 )
 
 // Router is an implementation of routing.Router.
 type Router struct {
+	access         sync.RWMutex
+	ctx            context.Context
 	domainStrategy DomainStrategy
 	rules          []*Rule
 	balancers      map[string]*Balancer
+	ruleSets       map[string]*RuleSetProvider
 	dns            dns.Client
+	stats          stats.Manager
+	ohm            outbound.Manager
+	dispatcher     routing.Dispatcher
+
+	// update serializes updates of rules and balancers, which are built
+	// without holding access, and then swapped in.
+	update sync.Mutex
 }
 
 // Route is an implementation of routing.Route.
@@ -33,43 +45,118 @@ type Route struct {
 
 // Init initializes the Router.
 func (r *Router) Init(ctx context.Context, config *Config, d dns.Client, ohm outbound.Manager, dispatcher routing.Dispatcher) error {
+	r.ctx = ctx
 	r.domainStrategy = config.DomainStrategy
 	r.dns = d
+	r.ohm = ohm
+	r.dispatcher = dispatcher
 
 	r.balancers = make(map[string]*Balancer, len(config.BalancingRule))
 	for _, rule := range config.BalancingRule {
-		balancer, err := rule.Build(ohm, dispatcher)
+		balancer, err := r.buildBalancer(rule)
 		if err != nil {
 			return err
 		}
-		balancer.InjectContext(ctx)
 		r.balancers[rule.Tag] = balancer
 	}
 
+	r.ruleSets = make(map[string]*RuleSetProvider, len(config.RuleSet))
+	for _, rs := range config.RuleSet {
+		if _, found := r.ruleSets[rs.Tag]; found {
+			return newError("duplicated rule-set ", rs.Tag)
+		}
+		provider, err := NewRuleSetProvider(ctx, rs)
+		if err != nil {
+			return newError("failed to build rule-set").Base(err)
+		}
+		r.ruleSets[rs.Tag] = provider
+	}
+
 	r.rules = make([]*Rule, 0, len(config.Rule))
 	for _, rule := range config.Rule {
-		cond, err := rule.BuildCondition()
+		rr, err := buildRule(rule, r.balancers, r.ruleSets)
 		if err != nil {
 			return err
 		}
-		rr := &Rule{
-			Condition: cond,
-			Tag:       rule.GetTag(),
-		}
-		btag := rule.GetBalancingTag()
-		if len(btag) > 0 {
-			brule, found := r.balancers[btag]
-			if !found {
-				return newError("balancer ", btag, " not found")
-			}
-			rr.Balancer = brule
-		}
 		r.rules = append(r.rules, rr)
 	}
 
 	return nil
 }
 
+func (r *Router) buildBalancer(rule *BalancingRule) (*Balancer, error) {
+	balancer, err := rule.Build(r.ohm, r.dispatcher)
+	if err != nil {
+		return nil, err
+	}
+	balancer.InjectContext(r.ctx)
+	balancer.tag = rule.Tag
+	balancer.config = rule
+	return balancer, nil
+}
+
+func buildRule(rule *RoutingRule, balancers map[string]*Balancer, ruleSets map[string]*RuleSetProvider) (*Rule, error) {
+	cond, err := rule.buildCondition(ruleSets)
+	if err != nil {
+		return nil, err
+	}
+	rr := &Rule{
+		Condition: cond,
+		Tag:       rule.GetTag(),
+		config:    rule,
+	}
+	btag := rule.GetBalancingTag()
+	if len(btag) > 0 {
+		brule, found := balancers[btag]
+		if !found {
+			return nil, newError("balancer ", btag, " not found")
+		}
+		rr.Balancer = brule
+	}
+	return rr, nil
+}
+
+// PrepareReload implements features.Reloadable.
+func (r *Router) PrepareReload(config interface{}) (func(), error) {
+	obj, err := common.CreateObject(r.ctx, config)
+	if err != nil {
+		return nil, newError("failed to build router").Base(err)
+	}
+	next, ok := obj.(*Router)
+	if !ok {
+		return nil, newError("not a router config")
+	}
+	return func() {
+		r.update.Lock()
+		defer r.update.Unlock()
+		for _, provider := range next.ruleSets {
+			if err := provider.Start(); err != nil {
+				newError("failed to start rule-set ", provider.config.Tag).Base(err).AtWarning().WriteToLog()
+			}
+		}
+		r.access.Lock()
+		previous, previousBalancers := r.ruleSets, r.balancers
+		r.domainStrategy = next.domainStrategy
+		r.rules = next.rules
+		r.balancers = next.balancers
+		r.ruleSets = next.ruleSets
+		r.access.Unlock()
+		for _, provider := range previous {
+			provider.Close()
+		}
+		for _, balancer := range previousBalancers {
+			balancer.Close()
+		}
+	}, nil
+}
+
+func (r *Router) getBalancer(tag string) (*Balancer, bool) {
+	r.access.RLock()
+	defer r.access.RUnlock()
+	b, found := r.balancers[tag]
+	return b, found
+}
+
 // PickRoute implements routing.Router.
 func (r *Router) PickRoute(ctx routing.Context) (routing.Route, error) {
 	rule, ctx, err := r.pickRouteInternal(ctx)
@@ -80,33 +167,51 @@ func (r *Router) PickRoute(ctx routing.Context) (routing.Route, error) {
 	if err != nil {
 		return nil, err
 	}
+	if rule.Balancer != nil {
+		r.countBalancerPick(rule.Balancer.tag, tag)
+	}
 	return &Route{Context: ctx, outboundTag: tag}, nil
 }
 
+// countBalancerPick counts the times an outbound is picked by a balancer, if stats are enabled.
+func (r *Router) countBalancerPick(balancerTag, outboundTag string) {
+	if r.stats == nil || balancerTag == "" {
+		return
+	}
+	name := "balancer>>>" + balancerTag + ">>>pick>>>" + outboundTag
+	if c, _ := stats.GetOrRegisterCounter(r.stats, name); c != nil {
+		c.Add(1)
+	}
+}
+
 func (r *Router) pickRouteInternal(ctx routing.Context) (*Rule, routing.Context, error) {
 	// SkipDNSResolve is set from DNS module.
 	// the DOH remote server maybe a domain name,
 	// this prevents cycle resolving dead loop
 	skipDNSResolve := ctx.GetSkipDNSResolve()
 
-	if r.domainStrategy == DomainStrategy_IpOnDemand && !skipDNSResolve {
+	r.access.RLock()
+	domainStrategy, rules := r.domainStrategy, r.rules
+	r.access.RUnlock()
+
+	if domainStrategy == DomainStrategy_IpOnDemand && !skipDNSResolve {
 		ctx = routing_dns.ContextWithDNSClient(ctx, r.dns)
 	}
 
-	for _, rule := range r.rules {
+	for _, rule := range rules {
 		if rule.Apply(ctx) {
 			return rule, ctx, nil
 		}
 	}
 
-	if r.domainStrategy != DomainStrategy_IpIfNonMatch || len(ctx.GetTargetDomain()) == 0 || skipDNSResolve {
+	if domainStrategy != DomainStrategy_IpIfNonMatch || len(ctx.GetTargetDomain()) == 0 || skipDNSResolve {
 		return nil, ctx, common.ErrNoClue
 	}
 
 	ctx = routing_dns.ContextWithDNSClient(ctx, r.dns)
 
 	// Try applying rules again if we have IPs.
-	for _, rule := range r.rules {
+	for _, rule := range rules {
 		if rule.Apply(ctx) {
 			return rule, ctx, nil
 		}
@@ -117,11 +222,24 @@ func (r *Router) pickRouteInternal(ctx routing.Context) (*Rule, routing.Context,
 
 // Start implements common.Runnable.
 func (r *Router) Start() error {
+	for _, provider := range r.ruleSets {
+		if err := provider.Start(); err != nil {
+			return newError("failed to start rule-set ", provider.config.Tag).Base(err)
+		}
+	}
 	return nil
 }
 
 // Close implements common.Closable.
 func (r *Router) Close() error {
+	r.access.RLock()
+	defer r.access.RUnlock()
+	for _, provider := range r.ruleSets {
+		provider.Close()
+	}
+	for _, balancer := range r.balancers {
+		balancer.Close()
+	}
 	return nil
 }
 
@@ -148,6 +266,11 @@ func init() {
 		}); err != nil {
 			return nil, err
 		}
+		if err := core.RequireFeatures(ctx, func(sm stats.Manager) {
+			r.stats = sm
+		}); err != nil {
+			return nil, err
+		}
 		return r, nil
 	}))
 
@@ -245,6 +368,19 @@ func init() {
 			rule.UserEmail = v.UserEmail
 			rule.InboundTag = v.InboundTag
 			rule.DomainMatcher = v.DomainMatcher
+			rule.ProcessName = v.ProcessName
+			rule.ProcessPath = v.ProcessPath
+			rule.Uid = v.Uid
+			rule.RuleTag = v.RuleTag
+			rule.RuleSet = v.RuleSet
+			rule.SourceRuleSet = v.SourceRuleSet
+			if v.Time != nil {
+				var err error
+				rule.Time, err = v.Time.Build()
+				if err != nil {
+					return nil, newError("invalid time condition").Base(err)
+				}
+			}
 			switch s := v.TargetTag.(type) {
 			case *SimplifiedRoutingRule_Tag:
 				rule.TargetTag = &RoutingRule_Tag{s.Tag}
@@ -258,6 +394,7 @@ func init() {
 			DomainStrategy: simplifiedConfig.DomainStrategy,
 			Rule:           routingRules,
 			BalancingRule:  simplifiedConfig.BalancingRule,
+			RuleSet:        simplifiedConfig.RuleSet,
 		}
 		return common.CreateObject(ctx, fullConfig)
 	}))
@@ -247,3 +247,90 @@ func TestIPIfNonMatchIP(t *testing.T) {
 		t.Error("expect tag 'test', bug actually ", tag)
 	}
 }
+
+func TestRuleManagement(t *testing.T) {
+	config := &Config{
+		Rule: []*RoutingRule{
+			{
+				RuleTag:   "tcp",
+				TargetTag: &RoutingRule_Tag{Tag: "tcp-out"},
+				Networks:  []net.Network{net.Network_TCP},
+			},
+		},
+	}
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	mockDNS := mocks.NewDNSClient(mockCtl)
+	mockOhm := mocks.NewOutboundManager(mockCtl)
+	mockHs := mocks.NewOutboundHandlerSelector(mockCtl)
+	mockHs.EXPECT().Select(gomock.Eq([]string{"b-"})).AnyTimes().Return([]string{"b-out"})
+	mockHs.EXPECT().Select(gomock.Eq([]string{"c-"})).AnyTimes().Return([]string{"c-out"})
+
+	r := new(Router)
+	common.Must(r.Init(context.TODO(), config, mockDNS, &mockOutboundManager{
+		Manager:         mockOhm,
+		HandlerSelector: mockHs,
+	}, nil))
+
+	pick := func(domain string) string {
+		ctx := session.ContextWithOutbound(context.Background(), &session.Outbound{Target: net.TCPDestination(net.DomainAddress(domain), 80)})
+		route, err := r.PickRoute(routing_session.AsRoutingContext(ctx))
+		if err != nil {
+			return ""
+		}
+		return route.GetOutboundTag()
+	}
+
+	domainRule := &RoutingRule{
+		RuleTag:   "domain",
+		TargetTag: &RoutingRule_BalancingTag{BalancingTag: "balance"},
+		Domain:    []*routercommon.Domain{{Type: routercommon.Domain_RootDomain, Value: "v2fly.org"}},
+	}
+	if err := r.InsertRule(0, domainRule); err == nil {
+		t.Error("rule with unknown balancer is inserted")
+	}
+	common.Must(r.AddBalancer(&BalancingRule{Tag: "balance", OutboundSelector: []string{"b-"}}))
+	common.Must(r.InsertRule(0, domainRule))
+	if err := r.AddRule(domainRule); err == nil {
+		t.Error("rule with duplicated tag is added")
+	}
+	if tag := pick("v2fly.org"); tag != "b-out" {
+		t.Error("expect tag 'b-out', but actually ", tag)
+	}
+	if tag := pick("example.com"); tag != "tcp-out" {
+		t.Error("expect tag 'tcp-out', but actually ", tag)
+	}
+
+	common.Must(r.ReplaceBalancer("balance", &BalancingRule{OutboundSelector: []string{"c-"}}))
+	if tag := pick("v2fly.org"); tag != "c-out" {
+		t.Error("expect tag 'c-out', but actually ", tag)
+	}
+	if err := r.RemoveBalancer("balance"); err == nil {
+		t.Error("balancer in use is removed")
+	}
+
+	common.Must(r.ReplaceRule("tcp", &RoutingRule{
+		RuleTag:   "tcp",
+		TargetTag: &RoutingRule_Tag{Tag: "direct"},
+		Networks:  []net.Network{net.Network_TCP},
+	}))
+	if tag := pick("example.com"); tag != "direct" {
+		t.Error("expect tag 'direct', but actually ", tag)
+	}
+
+	common.Must(r.RemoveRule("domain"))
+	common.Must(r.RemoveBalancer("balance"))
+	if tag := pick("v2fly.org"); tag != "direct" {
+		t.Error("expect tag 'direct', but actually ", tag)
+	}
+	if err := r.RemoveRule("domain"); err == nil {
+		t.Error("removed rule is removed again")
+	}
+
+	rules, balancers := r.ListRules()
+	if len(rules) != 1 || rules[0].RuleTag != "tcp" || rules[0].GetTag() != "direct" || len(balancers) != 0 {
+		t.Error("unexpected rules: ", rules, balancers)
+	}
+}
@@ -0,0 +1,221 @@
+package router
+
+import (
+	"sort"
+)
+
+// Rules and balancers are updated by copy-on-write: an update builds a new
+// slice of rules or a new map of balancers, and swaps it in while holding
+// access, so that PickRoute always sees a complete table.
+
+// ListRules returns the configs of the routing rules and the balancing rules of
+// the Router, in order.
+func (r *Router) ListRules() ([]*RoutingRule, []*BalancingRule) {
+	r.access.RLock()
+	defer r.access.RUnlock()
+
+	rules := make([]*RoutingRule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule.config)
+	}
+	balancers := make([]*BalancingRule, 0, len(r.balancers))
+	for _, balancer := range r.balancers {
+		balancers = append(balancers, balancer.config)
+	}
+	sort.Slice(balancers, func(i, j int) bool {
+		return balancers[i].Tag < balancers[j].Tag
+	})
+	return rules, balancers
+}
+
+func (r *Router) snapshot() ([]*Rule, map[string]*Balancer) {
+	r.access.RLock()
+	defer r.access.RUnlock()
+	return r.rules, r.balancers
+}
+
+func (r *Router) swap(rules []*Rule, balancers map[string]*Balancer) {
+	r.access.Lock()
+	defer r.access.Unlock()
+	r.rules = rules
+	r.balancers = balancers
+}
+
+func findRule(rules []*Rule, tag string) int {
+	if len(tag) == 0 {
+		return -1
+	}
+	for i, rule := range rules {
+		if rule.config.GetRuleTag() == tag {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddRule appends a routing rule to the Router.
+func (r *Router) AddRule(config *RoutingRule) error {
+	return r.InsertRule(-1, config)
+}
+
+// InsertRule inserts a routing rule to the Router at index. The rule is
+// appended if index is negative or beyond the number of rules.
+func (r *Router) InsertRule(index int, config *RoutingRule) error {
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	rules, balancers := r.snapshot()
+	if findRule(rules, config.RuleTag) >= 0 {
+		return newError("rule ", config.RuleTag, " already exists")
+	}
+	rule, err := buildRule(config, balancers, r.ruleSets)
+	if err != nil {
+		return newError("failed to build rule").Base(err)
+	}
+	if index < 0 || index > len(rules) {
+		index = len(rules)
+	}
+
+	next := make([]*Rule, 0, len(rules)+1)
+	next = append(next, rules[:index]...)
+	next = append(next, rule)
+	next = append(next, rules[index:]...)
+	r.swap(next, balancers)
+	return nil
+}
+
+// ReplaceRule replaces the routing rule with the rule tag in place.
+func (r *Router) ReplaceRule(tag string, config *RoutingRule) error {
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	rules, balancers := r.snapshot()
+	index := findRule(rules, tag)
+	if index < 0 {
+		return newError("rule ", tag, " not found")
+	}
+	if i := findRule(rules, config.RuleTag); i >= 0 && i != index {
+		return newError("rule ", config.RuleTag, " already exists")
+	}
+	rule, err := buildRule(config, balancers, r.ruleSets)
+	if err != nil {
+		return newError("failed to build rule").Base(err)
+	}
+
+	next := append([]*Rule(nil), rules...)
+	next[index] = rule
+	r.swap(next, balancers)
+	return nil
+}
+
+// RemoveRule removes the routing rule with the rule tag.
+func (r *Router) RemoveRule(tag string) error {
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	rules, balancers := r.snapshot()
+	index := findRule(rules, tag)
+	if index < 0 {
+		return newError("rule ", tag, " not found")
+	}
+
+	next := make([]*Rule, 0, len(rules)-1)
+	next = append(next, rules[:index]...)
+	next = append(next, rules[index+1:]...)
+	r.swap(next, balancers)
+	return nil
+}
+
+// AddBalancer adds a balancing rule to the Router.
+func (r *Router) AddBalancer(config *BalancingRule) error {
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	if config.Tag == "" {
+		return newError("empty balancer tag")
+	}
+	rules, balancers := r.snapshot()
+	if _, found := balancers[config.Tag]; found {
+		return newError("balancer ", config.Tag, " already exists")
+	}
+	balancer, err := r.buildBalancer(config)
+	if err != nil {
+		return newError("failed to build balancer").Base(err)
+	}
+
+	next := make(map[string]*Balancer, len(balancers)+1)
+	for tag, b := range balancers {
+		next[tag] = b
+	}
+	next[config.Tag] = balancer
+	r.swap(rules, next)
+	return nil
+}
+
+// ReplaceBalancer replaces the balancing rule with the tag. Routing rules
+// targeting the balancer are updated to the new one. The tag of the new
+// balancing rule must be the same, or empty.
+func (r *Router) ReplaceBalancer(tag string, config *BalancingRule) error {
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	rules, balancers := r.snapshot()
+	previous, found := balancers[tag]
+	if !found {
+		return newError("balancer ", tag, " not found")
+	}
+	if config.Tag == "" {
+		config.Tag = tag
+	} else if config.Tag != tag {
+		return newError("tag of balancer ", tag, " cannot be changed to ", config.Tag)
+	}
+	balancer, err := r.buildBalancer(config)
+	if err != nil {
+		return newError("failed to build balancer").Base(err)
+	}
+
+	nextBalancers := make(map[string]*Balancer, len(balancers))
+	for t, b := range balancers {
+		nextBalancers[t] = b
+	}
+	nextBalancers[tag] = balancer
+
+	nextRules := make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Balancer != nil && rule.Balancer.tag == tag {
+			copied := *rule
+			copied.Balancer = balancer
+			rule = &copied
+		}
+		nextRules = append(nextRules, rule)
+	}
+	r.swap(nextRules, nextBalancers)
+	return previous.Close()
+}
+
+// RemoveBalancer removes the balancing rule with the tag. A balancer cannot
+// be removed while it is the target of any routing rule.
+func (r *Router) RemoveBalancer(tag string) error {
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	rules, balancers := r.snapshot()
+	previous, found := balancers[tag]
+	if !found {
+		return newError("balancer ", tag, " not found")
+	}
+	for _, rule := range rules {
+		if rule.Balancer != nil && rule.Balancer.tag == tag {
+			return newError("balancer ", tag, " is in use by rules")
+		}
+	}
+
+	next := make(map[string]*Balancer, len(balancers)-1)
+	for t, b := range balancers {
+		if t != tag {
+			next[t] = b
+		}
+	}
+	r.swap(rules, next)
+	return previous.Close()
+}
@@ -0,0 +1,307 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	gonet "net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/app/persistentstorage/protostorage"
+	"github.com/frogwall/f2ray-core/v5/app/router/routercommon"
+	"github.com/frogwall/f2ray-core/v5/app/router/ruleset"
+	"github.com/frogwall/f2ray-core/v5/common/environment"
+	"github.com/frogwall/f2ray-core/v5/common/environment/envctx"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+)
+
+// ruleSetFetchTimeout is the timeout of fetching a rule-set from url.
+const ruleSetFetchTimeout = time.Minute
+
+// RuleSetProvider loads a rule-set, and refreshes it periodically. Its
+// matchers are updated in place, so rules referring to the rule-set follow
+// the refresh without being rebuilt.
+type RuleSetProvider struct {
+	ctx     context.Context
+	config  *RuleSet
+	domains *DomainMatcher
+	ips     *MultiGeoIPMatcher
+	hasIP   atomic.Bool
+	done    *done.Instance
+}
+
+// NewRuleSetProvider creates a RuleSetProvider, which matches nothing until it is started.
+func NewRuleSetProvider(ctx context.Context, config *RuleSet) (*RuleSetProvider, error) {
+	if config.Tag == "" {
+		return nil, newError("empty rule-set tag")
+	}
+	if (config.Path == "") == (config.Url == "") {
+		return nil, newError("either path or url of rule-set ", config.Tag, " must be set")
+	}
+	domains, err := NewDomainMatcher(config.DomainMatcher, nil)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := NewMultiGeoIPMatcher(nil, false)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleSetProvider{
+		ctx:     ctx,
+		config:  config,
+		domains: domains,
+		ips:     ips,
+		done:    done.New(),
+	}, nil
+}
+
+// Start implements common.Runnable. A local rule-set is loaded before Start
+// returns. A remote one is loaded from the cache, and fetched in background
+// if the cache is absent or expired.
+func (p *RuleSetProvider) Start() error {
+	interval := time.Duration(p.config.UpdateInterval) * time.Second
+	delay := time.Duration(0)
+	if p.config.Path != "" {
+		if err := p.refresh(); err != nil {
+			return err
+		}
+		if interval == 0 {
+			return nil
+		}
+		delay = interval
+	} else if updateTime, ok := p.loadCache(); ok && interval > 0 {
+		if age := time.Since(updateTime); age < interval {
+			delay = interval - age
+		}
+	}
+	go p.run(delay, interval)
+	return nil
+}
+
+// Close implements common.Closable.
+func (p *RuleSetProvider) Close() error {
+	return p.done.Close()
+}
+
+// Tag returns the tag of the rule-set.
+func (p *RuleSetProvider) Tag() string {
+	return p.config.Tag
+}
+
+// MatchDomain returns whether domain is in the rule-set.
+func (p *RuleSetProvider) MatchDomain(domain string) bool {
+	return p.domains.Match(domain)
+}
+
+func (p *RuleSetProvider) run(delay, interval time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-p.done.Wait():
+			return
+		case <-timer.C:
+		}
+		if err := p.refresh(); err != nil {
+			newError("failed to refresh rule-set ", p.config.Tag).Base(err).AtWarning().WriteToLog()
+		}
+		if interval == 0 {
+			return
+		}
+		timer.Reset(interval)
+	}
+}
+
+// refresh loads the rule-set from its source, and updates the matchers.
+func (p *RuleSetProvider) refresh() error {
+	var content []byte
+	var err error
+	if p.config.Path != "" {
+		content, err = os.ReadFile(p.config.Path)
+	} else {
+		content, err = p.fetch()
+	}
+	if err != nil {
+		return newError("failed to load rule-set ", p.config.Tag).Base(err)
+	}
+	if err := p.update(content); err != nil {
+		return err
+	}
+	if p.config.Url != "" {
+		p.saveCache(content)
+	}
+	newError("rule-set ", p.config.Tag, " updated").AtInfo().WriteToLog()
+	return nil
+}
+
+func (p *RuleSetProvider) fetch() ([]byte, error) {
+	instanceNetwork, ok := envctx.EnvironmentFromContext(p.ctx).(environment.InstanceNetworkCapabilitySet)
+	if !ok {
+		return nil, newError("outbound dialer is not available")
+	}
+	outboundDialer := instanceNetwork.OutboundDialer()
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network string, addr string) (gonet.Conn, error) {
+				dest, err := net.ParseDestination(network + ":" + addr)
+				if err != nil {
+					return nil, newError("unable to parse destination")
+				}
+				return outboundDialer(p.ctx, dest, p.config.OutboundTag)
+			},
+		},
+		Timeout: ruleSetFetchTimeout,
+	}
+	resp, err := client.Get(p.config.Url)
+	if err != nil {
+		return nil, newError("unable to send request").Base(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newError("unexpected http status ", resp.StatusCode, "=", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// update parses content, and updates the matchers with it.
+func (p *RuleSetProvider) update(content []byte) error {
+	var rs *ruleset.RuleSet
+	var err error
+	switch p.config.Format {
+	case RuleSet_Text:
+		rs, err = ruleset.ParseText(content)
+	case RuleSet_GeoSite:
+		rs, err = ruleset.ParseGeoSite(content, p.config.Code)
+	case RuleSet_GeoIP:
+		rs, err = ruleset.ParseGeoIP(content, p.config.Code)
+	case RuleSet_SRS:
+		rs, err = ruleset.ParseSRS(content)
+	default:
+		err = newError("unknown format ", p.config.Format)
+	}
+	if err != nil {
+		return newError("failed to parse rule-set ", p.config.Tag).Base(err)
+	}
+	return p.Update(rs)
+}
+
+// Update replaces the domains and IP ranges of the rule-set.
+func (p *RuleSetProvider) Update(rs *ruleset.RuleSet) error {
+	if err := p.domains.Update(rs.Domains); err != nil {
+		return newError("failed to build domains of rule-set ", p.config.Tag).Base(err)
+	}
+	var geoips []*routercommon.GeoIP
+	if len(rs.CIDRs) > 0 {
+		geoips = append(geoips, &routercommon.GeoIP{Cidr: rs.CIDRs})
+	}
+	if err := p.ips.Update(geoips); err != nil {
+		return newError("failed to build IP ranges of rule-set ", p.config.Tag).Base(err)
+	}
+	p.hasIP.Store(len(geoips) > 0)
+	return nil
+}
+
+func (p *RuleSetProvider) cacheStorage() (protostorage.ProtoPersistentStorage, error) {
+	appEnvironment, ok := envctx.EnvironmentFromContext(p.ctx).(environment.AppEnvironment)
+	if !ok {
+		return nil, newError("persistent storage is not available")
+	}
+	storage, err := appEnvironment.PersistentStorage().NarrowScope(p.ctx, []byte("rule_set"))
+	if err != nil {
+		return nil, newError("failed to get persistent storage for rule_set").Base(err)
+	}
+	return storage.(protostorage.ProtoPersistentStorage), nil
+}
+
+// cacheKey identifies the cache by the source of the rule-set rather than its
+// tag, as tags are only unique within the router or DNS owning the rule-set.
+func (p *RuleSetProvider) cacheKey() string {
+	hash := sha256.Sum256([]byte(p.config.Url + "\n" + p.config.Format.String() + "\n" + p.config.Code))
+	return hex.EncodeToString(hash[:])
+}
+
+// loadCache updates the matchers with the cached content, and returns the
+// time it was fetched.
+func (p *RuleSetProvider) loadCache() (time.Time, bool) {
+	storage, err := p.cacheStorage()
+	if err != nil {
+		newError("failed to load cache of rule-set ", p.config.Tag).Base(err).AtInfo().WriteToLog()
+		return time.Time{}, false
+	}
+	cache := new(RuleSetCache)
+	if err := storage.GetProto(p.ctx, p.cacheKey(), cache); err != nil {
+		newError("failed to load cache of rule-set ", p.config.Tag).Base(err).AtInfo().WriteToLog()
+		return time.Time{}, false
+	}
+	if err := p.update(cache.Content); err != nil {
+		newError("failed to load cache of rule-set ", p.config.Tag).Base(err).AtWarning().WriteToLog()
+		return time.Time{}, false
+	}
+	return time.Unix(cache.UpdateTime, 0), true
+}
+
+func (p *RuleSetProvider) saveCache(content []byte) {
+	storage, err := p.cacheStorage()
+	if err == nil {
+		err = storage.PutProto(p.ctx, p.cacheKey(), &RuleSetCache{
+			Content:    content,
+			UpdateTime: time.Now().Unix(),
+		})
+	}
+	if err != nil {
+		newError("failed to save cache of rule-set ", p.config.Tag).Base(err).AtWarning().WriteToLog()
+	}
+}
+
+// RuleSetMatcher matches the target domain and IPs, or the source IPs, against rule-sets.
+type RuleSetMatcher struct {
+	sets     []*RuleSetProvider
+	onSource bool
+}
+
+func NewRuleSetMatcher(sets []*RuleSetProvider, onSource bool) *RuleSetMatcher {
+	return &RuleSetMatcher{
+		sets:     sets,
+		onSource: onSource,
+	}
+}
+
+// Apply implements Condition.
+func (m *RuleSetMatcher) Apply(ctx routing.Context) bool {
+	if !m.onSource {
+		if domain := ctx.GetTargetDomain(); len(domain) > 0 {
+			for _, set := range m.sets {
+				if set.MatchDomain(domain) {
+					return true
+				}
+			}
+		}
+	}
+	// IPs are only looked up when needed, as target IPs may be resolved on demand.
+	var ips []net.IP
+	for _, set := range m.sets {
+		if !set.hasIP.Load() {
+			continue
+		}
+		if ips == nil {
+			if m.onSource {
+				ips = ctx.GetSourceIPs()
+			} else {
+				ips = ctx.GetTargetIPs()
+			}
+			if len(ips) == 0 {
+				return false
+			}
+		}
+		if set.ips.matchAny(ips) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+// Package ruleset parses lists of domains and IP ranges for routing, in the
+// formats of plain text, V2Ray GeoSite and GeoIP files, and sing-box binary
+// rule-sets.
+package ruleset
+
+//go:generate go run github.com/frogwall/f2ray-core/v5/common/errors/errorgen
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/frogwall/f2ray-core/v5/app/router/routercommon"
+)
+
+// RuleSet is a list of domains and IP ranges.
+type RuleSet struct {
+	Domains []*routercommon.Domain
+	CIDRs   []*routercommon.CIDR
+}
+
+// ParseGeoSite parses the list with code in a GeoSite file.
+func ParseGeoSite(b []byte, code string) (*RuleSet, error) {
+	var list routercommon.GeoSiteList
+	if err := proto.Unmarshal(b, &list); err != nil {
+		return nil, newError("invalid GeoSite file").Base(err)
+	}
+	for _, site := range list.Entry {
+		if strings.EqualFold(site.CountryCode, code) {
+			return &RuleSet{Domains: site.Domain}, nil
+		}
+	}
+	return nil, newError("list ", code, " not found in GeoSite file")
+}
+
+// ParseGeoIP parses the list with code in a GeoIP file.
+func ParseGeoIP(b []byte, code string) (*RuleSet, error) {
+	var list routercommon.GeoIPList
+	if err := proto.Unmarshal(b, &list); err != nil {
+		return nil, newError("invalid GeoIP file").Base(err)
+	}
+	for _, geoip := range list.Entry {
+		if strings.EqualFold(geoip.CountryCode, code) {
+			return &RuleSet{CIDRs: geoip.Cidr}, nil
+		}
+	}
+	return nil, newError("list ", code, " not found in GeoIP file")
+}
@@ -0,0 +1,146 @@
+package ruleset_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sagernet/sing/common/domain"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"github.com/frogwall/f2ray-core/v5/app/router/routercommon"
+	. "github.com/frogwall/f2ray-core/v5/app/router/ruleset"
+	"github.com/frogwall/f2ray-core/v5/common"
+)
+
+func TestParseText(t *testing.T) {
+	rs, err := ParseText([]byte(`
+# comment
+example.com
+full:www.v2fly.org # trailing comment
+keyword:google
+regexp:^a[0-9]+\.com$
++.Example.org
+
+10.0.0.0/8
+1.1.1.1
+2001:db8::/32
+`))
+	common.Must(err)
+	if diff := cmp.Diff(&RuleSet{
+		Domains: []*routercommon.Domain{
+			{Type: routercommon.Domain_RootDomain, Value: "example.com"},
+			{Type: routercommon.Domain_Full, Value: "www.v2fly.org"},
+			{Type: routercommon.Domain_Plain, Value: "google"},
+			{Type: routercommon.Domain_Regex, Value: `^a[0-9]+\.com$`},
+			{Type: routercommon.Domain_RootDomain, Value: "example.org"},
+		},
+		CIDRs: []*routercommon.CIDR{
+			{Ip: []byte{10, 0, 0, 0}, Prefix: 8},
+			{Ip: []byte{1, 1, 1, 1}, Prefix: 32},
+			{Ip: []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, Prefix: 32},
+		},
+	}, rs, protocmp.Transform()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestParseGeoSite(t *testing.T) {
+	b, err := proto.Marshal(&routercommon.GeoSiteList{Entry: []*routercommon.GeoSite{
+		{CountryCode: "CN", Domain: []*routercommon.Domain{{Type: routercommon.Domain_RootDomain, Value: "cn"}}},
+	}})
+	common.Must(err)
+	rs, err := ParseGeoSite(b, "cn")
+	common.Must(err)
+	if len(rs.Domains) != 1 || rs.Domains[0].Value != "cn" {
+		t.Error("unexpected domains: ", rs.Domains)
+	}
+	if _, err := ParseGeoSite(b, "us"); err == nil {
+		t.Error("nil error for unknown list")
+	}
+}
+
+func writeUvarint(w *bufio.Writer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+// writeIPRange writes an IP set of a range, as sing-box does.
+func writeIPRange(w *bufio.Writer, from, to []byte) {
+	w.WriteByte(1)
+	binary.Write(w, binary.BigEndian, uint64(1))
+	writeUvarint(w, uint64(len(from)))
+	w.Write(from)
+	writeUvarint(w, uint64(len(to)))
+	w.Write(to)
+}
+
+func TestParseSRS(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	w := bufio.NewWriter(zw)
+	writeUvarint(w, 3)
+
+	// A rule of domains, a keyword and an IP range.
+	w.WriteByte(0)
+	w.WriteByte(2)
+	common.Must(domain.NewMatcher([]string{"www.v2fly.org"}, []string{"example.com", ".example.org"}, false).Write(w))
+	w.WriteByte(3)
+	writeUvarint(w, 1)
+	writeUvarint(w, 6)
+	w.WriteString("google")
+	w.WriteByte(6)
+	writeIPRange(w, []byte{10, 0, 0, 0}, []byte{10, 0, 1, 255})
+	w.WriteByte(0xFF)
+	w.WriteByte(0)
+
+	// A rule with ports, which is ignored.
+	w.WriteByte(0)
+	w.WriteByte(2)
+	common.Must(domain.NewMatcher([]string{"ignored.com"}, nil, false).Write(w))
+	w.WriteByte(9)
+	writeUvarint(w, 1)
+	binary.Write(w, binary.BigEndian, uint16(443))
+	w.WriteByte(0xFF)
+	w.WriteByte(0)
+
+	// A logical rule, which is ignored.
+	w.WriteByte(1)
+	w.WriteByte(0)
+	writeUvarint(w, 1)
+	w.WriteByte(0)
+	w.WriteByte(4)
+	writeUvarint(w, 1)
+	writeUvarint(w, 2)
+	w.WriteString(".*")
+	w.WriteByte(0xFF)
+	w.WriteByte(0)
+	w.WriteByte(0)
+
+	common.Must(w.Flush())
+	common.Must(zw.Close())
+
+	rs, err := ParseSRS(append([]byte{'S', 'R', 'S', 2}, compressed.Bytes()...))
+	common.Must(err)
+	if diff := cmp.Diff(&RuleSet{
+		Domains: []*routercommon.Domain{
+			{Type: routercommon.Domain_Full, Value: "www.v2fly.org"},
+			{Type: routercommon.Domain_Regex, Value: `\.example\.org$`},
+			{Type: routercommon.Domain_RootDomain, Value: "example.com"},
+			{Type: routercommon.Domain_Plain, Value: "google"},
+		},
+		CIDRs: []*routercommon.CIDR{
+			{Ip: []byte{10, 0, 0, 0}, Prefix: 23},
+		},
+	}, rs, protocmp.Transform()); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err := ParseSRS([]byte("SRS\x09")); err == nil {
+		t.Error("nil error for unsupported version")
+	}
+}
@@ -0,0 +1,306 @@
+package ruleset
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"net/netip"
+	"regexp"
+
+	"github.com/sagernet/sing/common/domain"
+	"go4.org/netipx"
+
+	"github.com/frogwall/f2ray-core/v5/app/router/routercommon"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+// srsMagic is the magic of the binary rule-set of sing-box.
+var srsMagic = []byte("SRS")
+
+const (
+	// srsMaxVersion is the latest version of the binary rule-set supported.
+	srsMaxVersion = 3
+	// srsMaxItemSize is the limit of the size of a string or an address.
+	srsMaxItemSize = 64 * 1024
+)
+
+// Types of rules and rule items of the binary rule-set.
+const (
+	srsRuleDefault uint8 = 0
+	srsRuleLogical uint8 = 1
+
+	srsItemQueryType          uint8 = 0
+	srsItemNetwork            uint8 = 1
+	srsItemDomain             uint8 = 2
+	srsItemDomainKeyword      uint8 = 3
+	srsItemDomainRegex        uint8 = 4
+	srsItemSourceIPCIDR       uint8 = 5
+	srsItemIPCIDR             uint8 = 6
+	srsItemSourcePort         uint8 = 7
+	srsItemSourcePortRange    uint8 = 8
+	srsItemPort               uint8 = 9
+	srsItemPortRange          uint8 = 10
+	srsItemProcessName        uint8 = 11
+	srsItemProcessPath        uint8 = 12
+	srsItemPackageName        uint8 = 13
+	srsItemWIFISSID           uint8 = 14
+	srsItemWIFIBSSID          uint8 = 15
+	srsItemAdGuardDomain      uint8 = 16
+	srsItemProcessPathRegex   uint8 = 17
+	srsItemNetworkType        uint8 = 18
+	srsItemNetworkIsExpensive uint8 = 19
+	srsItemNetworkIsConstrain uint8 = 20
+	srsItemFinal              uint8 = 0xFF
+)
+
+// ParseSRS parses a binary rule-set of sing-box. Only domains, domain
+// keywords, domain regexps and destination IP ranges of default rules are
+// taken. Logical rules, inverted rules and other items are ignored, as they
+// cannot be expressed as lists.
+func ParseSRS(b []byte) (*RuleSet, error) {
+	if !bytes.HasPrefix(b, srsMagic) || len(b) < len(srsMagic)+1 {
+		return nil, newError("invalid binary rule-set")
+	}
+	if version := b[len(srsMagic)]; version > srsMaxVersion {
+		return nil, newError("unsupported version of binary rule-set: ", version)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(b[len(srsMagic)+1:]))
+	if err != nil {
+		return nil, newError("invalid binary rule-set").Base(err)
+	}
+	defer zr.Close()
+	reader := bufio.NewReader(zr)
+
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, newError("invalid binary rule-set").Base(err)
+	}
+	rs := new(RuleSet)
+	for i := uint64(0); i < count; i++ {
+		if err := readSRSRule(reader, rs, true); err != nil {
+			return nil, newError("invalid rule ", i, " in binary rule-set").Base(err)
+		}
+	}
+	return rs, nil
+}
+
+// readSRSRule reads a rule, and adds its domains and IP ranges to rs if take
+// is true and the rule can be expressed as lists.
+func readSRSRule(reader *bufio.Reader, rs *RuleSet, take bool) error {
+	ruleType, err := reader.ReadByte()
+	if err != nil {
+		return err
+	}
+	switch ruleType {
+	case srsRuleDefault:
+		return readSRSDefaultRule(reader, rs, take)
+	case srsRuleLogical:
+		if _, err := reader.ReadByte(); err != nil { // mode
+			return err
+		}
+		count, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < count; i++ {
+			if err := readSRSRule(reader, rs, false); err != nil {
+				return err
+			}
+		}
+		_, err = reader.ReadByte() // invert
+		return err
+	default:
+		return newError("unknown rule type ", ruleType)
+	}
+}
+
+func readSRSDefaultRule(reader *bufio.Reader, rs *RuleSet, take bool) error {
+	var domains []*routercommon.Domain
+	var cidrs []*routercommon.CIDR
+	for {
+		itemType, err := reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch itemType {
+		case srsItemDomain:
+			matcher, err := domain.ReadMatcher(reader)
+			if err != nil {
+				return err
+			}
+			domains = append(domains, dumpSRSDomains(matcher)...)
+		case srsItemDomainKeyword:
+			keywords, err := readSRSStrings(reader)
+			if err != nil {
+				return err
+			}
+			for _, keyword := range keywords {
+				domains = append(domains, &routercommon.Domain{Type: routercommon.Domain_Plain, Value: keyword})
+			}
+		case srsItemDomainRegex:
+			regexps, err := readSRSStrings(reader)
+			if err != nil {
+				return err
+			}
+			for _, r := range regexps {
+				domains = append(domains, &routercommon.Domain{Type: routercommon.Domain_Regex, Value: r})
+			}
+		case srsItemIPCIDR:
+			ranges, err := readSRSIPSet(reader)
+			if err != nil {
+				return err
+			}
+			cidrs = append(cidrs, ranges...)
+		case srsItemSourceIPCIDR:
+			if _, err := readSRSIPSet(reader); err != nil {
+				return err
+			}
+			take = false
+		case srsItemQueryType, srsItemSourcePort, srsItemPort:
+			if err := skipSRSUint16s(reader); err != nil {
+				return err
+			}
+			take = false
+		case srsItemNetwork, srsItemSourcePortRange, srsItemPortRange, srsItemProcessName, srsItemProcessPath,
+			srsItemPackageName, srsItemWIFISSID, srsItemWIFIBSSID, srsItemProcessPathRegex:
+			if _, err := readSRSStrings(reader); err != nil {
+				return err
+			}
+			take = false
+		case srsItemNetworkType:
+			count, err := binary.ReadUvarint(reader)
+			if err != nil {
+				return err
+			}
+			if _, err := reader.Discard(int(count)); err != nil {
+				return err
+			}
+			take = false
+		case srsItemNetworkIsExpensive, srsItemNetworkIsConstrain:
+			take = false
+		case srsItemAdGuardDomain:
+			return newError("AdGuard domain rules are not supported")
+		case srsItemFinal:
+			invert, err := reader.ReadByte()
+			if err != nil {
+				return err
+			}
+			if take && invert == 0 {
+				rs.Domains = append(rs.Domains, domains...)
+				rs.CIDRs = append(rs.CIDRs, cidrs...)
+			}
+			return nil
+		default:
+			return newError("unknown rule item type ", itemType)
+		}
+	}
+}
+
+// dumpSRSDomains converts the domains of a domain matcher of sing-box. Domain
+// suffixes starting with "." match subdomains only, so they are converted to
+// regexps.
+func dumpSRSDomains(matcher *domain.Matcher) []*routercommon.Domain {
+	fulls, suffixes := matcher.Dump()
+	domains := make([]*routercommon.Domain, 0, len(fulls)+len(suffixes))
+	for _, d := range fulls {
+		domains = append(domains, &routercommon.Domain{Type: routercommon.Domain_Full, Value: d})
+	}
+	for _, s := range suffixes {
+		if len(s) > 0 && s[0] == '.' {
+			domains = append(domains, &routercommon.Domain{Type: routercommon.Domain_Regex, Value: regexp.QuoteMeta(s) + "$"})
+		} else {
+			domains = append(domains, &routercommon.Domain{Type: routercommon.Domain_RootDomain, Value: s})
+		}
+	}
+	return domains
+}
+
+func readSRSBytes(reader *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > srsMaxItemSize {
+		return nil, newError("item too large")
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(reader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func readSRSStrings(reader *bufio.Reader) ([]string, error) {
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for i := uint64(0); i < count; i++ {
+		b, err := readSRSBytes(reader)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, string(b))
+	}
+	return values, nil
+}
+
+func skipSRSUint16s(reader *bufio.Reader) error {
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < count; i++ {
+		if _, err := reader.Discard(2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSRSIPSet reads an IP set, of ranges of IP addresses, as CIDRs.
+func readSRSIPSet(reader *bufio.Reader) ([]*routercommon.CIDR, error) {
+	version, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != 1 {
+		return nil, newError("unsupported version of IP set: ", version)
+	}
+	var count uint64
+	if err := binary.Read(reader, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	var cidrs []*routercommon.CIDR
+	for i := uint64(0); i < count; i++ {
+		from, err := readSRSBytes(reader)
+		if err != nil {
+			return nil, err
+		}
+		to, err := readSRSBytes(reader)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) != len(to) || len(from) != net.IPv4len && len(from) != net.IPv6len {
+			return nil, newError("invalid IP range")
+		}
+		cidrs = append(cidrs, rangeToCIDRs(from, to)...)
+	}
+	return cidrs, nil
+}
+
+func rangeToCIDRs(from, to []byte) []*routercommon.CIDR {
+	fromAddr, _ := netip.AddrFromSlice(from)
+	toAddr, _ := netip.AddrFromSlice(to)
+	var cidrs []*routercommon.CIDR
+	for _, prefix := range netipx.IPRangeFrom(fromAddr, toAddr).Prefixes() {
+		cidrs = append(cidrs, &routercommon.CIDR{
+			Ip:     prefix.Addr().AsSlice(),
+			Prefix: uint32(prefix.Bits()),
+		})
+	}
+	return cidrs
+}
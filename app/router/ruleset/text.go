@@ -0,0 +1,83 @@
+package ruleset
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/frogwall/f2ray-core/v5/app/router/routercommon"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+var textDomainPrefixes = []struct {
+	prefix string
+	typ    routercommon.Domain_Type
+}{
+	{"domain:", routercommon.Domain_RootDomain},
+	{"full:", routercommon.Domain_Full},
+	{"keyword:", routercommon.Domain_Plain},
+	{"regexp:", routercommon.Domain_Regex},
+	{"+.", routercommon.Domain_RootDomain},
+}
+
+// ParseText parses a list in plain text, of an entry per line. Lines starting
+// with "#" are comments. An entry is an IP address or a CIDR, or a domain. A
+// domain matches its subdomains as well, unless it has the prefix "full:".
+// Prefixes "domain:", "keyword:", "regexp:" and "+." are supported as well.
+func ParseText(b []byte) (*RuleSet, error) {
+	rs := new(RuleSet)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(entry, "#"); i >= 0 {
+			entry = strings.TrimSpace(entry[:i])
+		}
+		if len(entry) == 0 {
+			continue
+		}
+
+		if cidr, ok := parseCIDR(entry); ok {
+			rs.CIDRs = append(rs.CIDRs, cidr)
+			continue
+		}
+
+		domain := &routercommon.Domain{Type: routercommon.Domain_RootDomain, Value: entry}
+		for _, p := range textDomainPrefixes {
+			if strings.HasPrefix(entry, p.prefix) {
+				domain.Type = p.typ
+				domain.Value = entry[len(p.prefix):]
+				break
+			}
+		}
+		if domain.Type != routercommon.Domain_Regex {
+			domain.Value = strings.ToLower(domain.Value)
+		}
+		if len(domain.Value) == 0 {
+			return nil, newError("invalid entry at line ", line, ": ", entry)
+		}
+		rs.Domains = append(rs.Domains, domain)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, newError("failed to read list").Base(err)
+	}
+	return rs, nil
+}
+
+func parseCIDR(s string) (*routercommon.CIDR, bool) {
+	if ip := net.ParseIP(s); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &routercommon.CIDR{Ip: ip4, Prefix: 32}, true
+		}
+		return &routercommon.CIDR{Ip: ip, Prefix: 128}, true
+	}
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, false
+	}
+	ones, _ := ipNet.Mask.Size()
+	ip := ipNet.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &routercommon.CIDR{Ip: ip, Prefix: uint32(ones)}, true
+}
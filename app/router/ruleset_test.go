@@ -0,0 +1,130 @@
+package router
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/environment"
+	"github.com/frogwall/f2ray-core/v5/common/environment/deferredpersistentstorage"
+	"github.com/frogwall/f2ray-core/v5/common/environment/envctx"
+	"github.com/frogwall/f2ray-core/v5/common/environment/filesystemimpl"
+	"github.com/frogwall/f2ray-core/v5/common/environment/systemnetworkimpl"
+	"github.com/frogwall/f2ray-core/v5/common/environment/transientstorageimpl"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	routing_session "github.com/frogwall/f2ray-core/v5/features/routing/session"
+)
+
+func TestRuleSetRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	common.Must(os.WriteFile(path, []byte("v2fly.org\n10.0.0.0/8\n"), 0o600))
+
+	r := new(Router)
+	common.Must(r.Init(context.Background(), &Config{
+		RuleSet: []*RuleSet{{Tag: "list", Path: path}},
+		Rule: []*RoutingRule{
+			{
+				TargetTag: &RoutingRule_Tag{Tag: "list-out"},
+				RuleSet:   []string{"list"},
+			},
+			{
+				TargetTag:     &RoutingRule_Tag{Tag: "source-out"},
+				SourceRuleSet: []string{"list"},
+			},
+		},
+	}, nil, nil, nil))
+	common.Must(r.Start())
+	defer r.Close()
+
+	pick := func(source, target net.Address) string {
+		ctx := session.ContextWithInbound(context.Background(), &session.Inbound{Source: net.TCPDestination(source, 1234)})
+		ctx = session.ContextWithOutbound(ctx, &session.Outbound{Target: net.TCPDestination(target, 80)})
+		route, err := r.PickRoute(routing_session.AsRoutingContext(ctx))
+		if err != nil {
+			return ""
+		}
+		return route.GetOutboundTag()
+	}
+	local := net.ParseAddress("192.168.0.1")
+
+	for _, tc := range []struct {
+		source net.Address
+		target net.Address
+		tag    string
+	}{
+		{local, net.DomainAddress("www.v2fly.org"), "list-out"},
+		{local, net.ParseAddress("10.1.2.3"), "list-out"},
+		{local, net.DomainAddress("example.com"), ""},
+		{net.ParseAddress("10.0.0.1"), net.DomainAddress("example.com"), "source-out"},
+	} {
+		if tag := pick(tc.source, tc.target); tag != tc.tag {
+			t.Error("expect tag '", tc.tag, "' for ", tc.target, ", but actually ", tag)
+		}
+	}
+
+	common.Must(os.WriteFile(path, []byte("example.com\n"), 0o600))
+	common.Must(r.ruleSets["list"].refresh())
+	for _, tc := range []struct {
+		target net.Address
+		tag    string
+	}{
+		{net.DomainAddress("www.v2fly.org"), ""},
+		{net.ParseAddress("10.1.2.3"), ""},
+		{net.DomainAddress("example.com"), "list-out"},
+	} {
+		if tag := pick(local, tc.target); tag != tc.tag {
+			t.Error("expect tag '", tc.tag, "' for ", tc.target, ", but actually ", tag)
+		}
+	}
+}
+
+func TestRuleSetNotFound(t *testing.T) {
+	err := new(Router).Init(context.Background(), &Config{
+		Rule: []*RoutingRule{
+			{
+				TargetTag: &RoutingRule_Tag{Tag: "list-out"},
+				RuleSet:   []string{"list"},
+			},
+		},
+	}, nil, nil, nil)
+	if err == nil {
+		t.Error("nil error for unknown rule-set")
+	}
+}
+
+func TestRuleSetWithoutPersistentStorage(t *testing.T) {
+	ctx := context.Background()
+	defaultNetworkImpl := systemnetworkimpl.NewSystemNetworkDefault()
+	deferredPersistentStorageImpl := deferredpersistentstorage.NewDeferredPersistentStorage(ctx)
+	rootEnv := environment.NewRootEnvImpl(ctx,
+		transientstorageimpl.NewScopedTransientStorageImpl(), defaultNetworkImpl.Dialer(), defaultNetworkImpl.Listener(),
+		filesystemimpl.NewDefaultFileSystemDefaultImpl(), deferredPersistentStorageImpl)
+	deferredPersistentStorageImpl.ProvideInner(ctx, nil)
+	ctx = envctx.ContextWithEnvironment(ctx, rootEnv.AppEnvironment("router"))
+
+	provider, err := NewRuleSetProvider(ctx, &RuleSet{Tag: "list", Url: "http://127.0.0.1:1/list.txt"})
+	common.Must(err)
+	common.Must(provider.Start())
+	common.Must(provider.Close())
+}
+
+func TestRuleSetCacheKey(t *testing.T) {
+	key := func(config *RuleSet) string {
+		provider, err := NewRuleSetProvider(context.Background(), config)
+		common.Must(err)
+		return provider.cacheKey()
+	}
+	geosite := key(&RuleSet{Tag: "cn", Url: "https://example.com/geosite.dat", Format: RuleSet_GeoSite, Code: "cn"})
+	if geosite == key(&RuleSet{Tag: "cn", Url: "https://example.com/cn.srs", Format: RuleSet_SRS}) {
+		t.Error("rule-sets of the same tag share the cache")
+	}
+	if geosite == key(&RuleSet{Tag: "cn", Url: "https://example.com/geosite.dat", Format: RuleSet_GeoSite, Code: "geolocation-!cn"}) {
+		t.Error("rule-sets of different codes share the cache")
+	}
+	if geosite != key(&RuleSet{Tag: "dns-cn", Url: "https://example.com/geosite.dat", Format: RuleSet_GeoSite, Code: "cn"}) {
+		t.Error("rule-sets of the same source do not share the cache")
+	}
+}
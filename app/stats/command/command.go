@@ -121,6 +121,7 @@ type service struct {
 
 func (s *service) Register(server *grpc.Server) {
 	RegisterStatsServiceServer(server, NewStatsServer(s.statsManager))
+	RegisterConnectionServiceServer(server, NewConnectionServer(s.statsManager))
 }
 
 func init() {
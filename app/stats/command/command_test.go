@@ -3,6 +3,7 @@ package command_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -10,6 +11,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/app/stats"
 	. "github.com/frogwall/f2ray-core/v5/app/stats/command"
 	"github.com/frogwall/f2ray-core/v5/common"
+	feature_stats "github.com/frogwall/f2ray-core/v5/features/stats"
 )
 
 func TestGetStats(t *testing.T) {
@@ -90,3 +92,54 @@ func TestQueryStats(t *testing.T) {
 		t.Error(r)
 	}
 }
+
+func TestConnectionServer(t *testing.T) {
+	m, err := stats.NewManager(context.Background(), &stats.Config{})
+	common.Must(err)
+
+	closed := false
+	conn := m.TrackConnection(feature_stats.ConnectionInfo{
+		Source:     "tcp:127.0.0.1:50000",
+		Target:     "tcp:example.com:443",
+		InboundTag: "in",
+		Start:      time.Unix(1700000000, 0),
+	}, func() {
+		closed = true
+	})
+	conn.Update(func(info *feature_stats.ConnectionInfo) {
+		info.OutboundTag = "out"
+	})
+	conn.UplinkCounter().Add(10)
+	conn.DownlinkCounter().Add(20)
+
+	s := NewConnectionServer(m)
+	resp, err := s.ListConnections(context.Background(), &ListConnectionsRequest{})
+	common.Must(err)
+	if r := cmp.Diff(resp.Connections, []*Connection{{
+		Id:          1,
+		Source:      "tcp:127.0.0.1:50000",
+		Target:      "tcp:example.com:443",
+		InboundTag:  "in",
+		OutboundTag: "out",
+		StartTime:   1700000000000,
+		Uplink:      10,
+		Downlink:    20,
+	}}, cmpopts.IgnoreUnexported(Connection{})); r != "" {
+		t.Error(r)
+	}
+
+	if _, err := s.CloseConnection(context.Background(), &CloseConnectionRequest{Id: 2}); err == nil {
+		t.Error("nil error for unknown connection")
+	}
+	common.Must2(s.CloseConnection(context.Background(), &CloseConnectionRequest{Id: 1}))
+	if !closed {
+		t.Error("connection is not closed")
+	}
+
+	conn.Untrack()
+	resp, err = s.ListConnections(context.Background(), &ListConnectionsRequest{})
+	common.Must(err)
+	if len(resp.Connections) != 0 {
+		t.Error("untracked connection is listed")
+	}
+}
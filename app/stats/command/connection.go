@@ -0,0 +1,94 @@
+package command
+
+import (
+	"context"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/app/stats"
+	feature_stats "github.com/frogwall/f2ray-core/v5/features/stats"
+)
+
+const defaultStreamConnectionsInterval = time.Second
+
+// connectionServer is an implementation of ConnectionService.
+type connectionServer struct {
+	stats feature_stats.Manager
+}
+
+func NewConnectionServer(manager feature_stats.Manager) ConnectionServiceServer {
+	return &connectionServer{
+		stats: manager,
+	}
+}
+
+func (s *connectionServer) manager() (*stats.Manager, error) {
+	manager, ok := s.stats.(*stats.Manager)
+	if !ok {
+		return nil, newError("connections are only tracked by its own stats.Manager.")
+	}
+	return manager, nil
+}
+
+func (s *connectionServer) listConnections(manager *stats.Manager) *ListConnectionsResponse {
+	response := &ListConnectionsResponse{}
+	for _, info := range manager.Connections() {
+		response.Connections = append(response.Connections, &Connection{
+			Id:          info.ID,
+			Source:      info.Source,
+			Target:      info.Target,
+			Domain:      info.Domain,
+			Protocol:    info.Protocol,
+			InboundTag:  info.InboundTag,
+			OutboundTag: info.OutboundTag,
+			User:        info.User,
+			StartTime:   info.Start.UnixMilli(),
+			Uplink:      info.Uplink,
+			Downlink:    info.Downlink,
+		})
+	}
+	return response
+}
+
+func (s *connectionServer) ListConnections(ctx context.Context, request *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	manager, err := s.manager()
+	if err != nil {
+		return nil, err
+	}
+	return s.listConnections(manager), nil
+}
+
+func (s *connectionServer) StreamConnections(request *StreamConnectionsRequest, stream ConnectionService_StreamConnectionsServer) error {
+	manager, err := s.manager()
+	if err != nil {
+		return err
+	}
+	interval := defaultStreamConnectionsInterval
+	if request.Interval > 0 {
+		interval = time.Duration(request.Interval) * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := stream.Send(s.listConnections(manager)); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *connectionServer) CloseConnection(ctx context.Context, request *CloseConnectionRequest) (*CloseConnectionResponse, error) {
+	manager, err := s.manager()
+	if err != nil {
+		return nil, err
+	}
+	if err := manager.CloseConnection(request.Id); err != nil {
+		return nil, err
+	}
+	return &CloseConnectionResponse{}, nil
+}
+
+func (s *connectionServer) mustEmbedUnimplementedConnectionServiceServer() {}
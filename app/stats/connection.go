@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/frogwall/f2ray-core/v5/features/stats"
+)
+
+// trackedConnection is an implementation of stats.TrackedConnection.
+type trackedConnection struct {
+	manager  *Manager
+	closer   func()
+	uplink   Counter
+	downlink Counter
+
+	access sync.RWMutex
+	info   stats.ConnectionInfo
+}
+
+// Update implements stats.TrackedConnection.
+func (c *trackedConnection) Update(f func(info *stats.ConnectionInfo)) {
+	c.access.Lock()
+	defer c.access.Unlock()
+	f(&c.info)
+}
+
+// UplinkCounter implements stats.TrackedConnection.
+func (c *trackedConnection) UplinkCounter() stats.Counter {
+	return &c.uplink
+}
+
+// DownlinkCounter implements stats.TrackedConnection.
+func (c *trackedConnection) DownlinkCounter() stats.Counter {
+	return &c.downlink
+}
+
+// Untrack implements stats.TrackedConnection.
+func (c *trackedConnection) Untrack() {
+	c.manager.connectionAccess.Lock()
+	defer c.manager.connectionAccess.Unlock()
+	delete(c.manager.connections, c.info.ID)
+}
+
+func (c *trackedConnection) snapshot() *stats.ConnectionInfo {
+	c.access.RLock()
+	info := c.info
+	c.access.RUnlock()
+	info.Uplink = c.uplink.Value()
+	info.Downlink = c.downlink.Value()
+	return &info
+}
+
+// TrackConnection implements stats.ConnectionTracker.
+func (m *Manager) TrackConnection(info stats.ConnectionInfo, closer func()) stats.TrackedConnection {
+	m.connectionAccess.Lock()
+	defer m.connectionAccess.Unlock()
+
+	m.lastConnectionID++
+	info.ID = m.lastConnectionID
+	info.Uplink = 0
+	info.Downlink = 0
+	c := &trackedConnection{
+		manager: m,
+		closer:  closer,
+		info:    info,
+	}
+	m.connections[info.ID] = c
+	return c
+}
+
+// Connections returns the information of all tracked connections, ordered by ID.
+func (m *Manager) Connections() []*stats.ConnectionInfo {
+	m.connectionAccess.RLock()
+	connections := make([]*trackedConnection, 0, len(m.connections))
+	for _, c := range m.connections {
+		connections = append(connections, c)
+	}
+	m.connectionAccess.RUnlock()
+
+	infos := make([]*stats.ConnectionInfo, 0, len(connections))
+	for _, c := range connections {
+		infos = append(infos, c.snapshot())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// CloseConnection closes the tracked connection of the ID.
+func (m *Manager) CloseConnection(id uint64) error {
+	m.connectionAccess.RLock()
+	c, found := m.connections[id]
+	m.connectionAccess.RUnlock()
+	if !found {
+		return newError("connection ", id, " not found")
+	}
+	newError("close connection ", id).AtDebug().WriteToLog()
+	c.closer()
+	return nil
+}
@@ -17,6 +17,10 @@ type Manager struct {
 	counters map[string]*Counter
 	channels map[string]*Channel
 	running  bool
+
+	connectionAccess sync.RWMutex
+	connections      map[uint64]*trackedConnection
+	lastConnectionID uint64
 }
 
 // NewManager creates an instance of Statistics Manager.
@@ -24,6 +28,8 @@ func NewManager(ctx context.Context, config *Config) (*Manager, error) {
 	m := &Manager{
 		counters: make(map[string]*Counter),
 		channels: make(map[string]*Channel),
+
+		connections: make(map[uint64]*trackedConnection),
 	}
 
 	return m, nil
@@ -0,0 +1,165 @@
+//go:build linux
+// +build linux
+
+package tun
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/frogwall/f2ray-core/v5/common/errors"
+)
+
+const (
+	defaultRouteTable   = 2022
+	defaultRulePriority = 9000
+)
+
+// autoRoute is the system routes and policy rules installed for a TUN device.
+type autoRoute struct {
+	conn   *netlinkConn
+	index  int
+	table  uint32
+	routes []*net.IPNet
+	rules  []*policyRule
+}
+
+func setupAutoRoute(config *Config) (*autoRoute, error) {
+	iface, err := net.InterfaceByName(config.Name)
+	if err != nil {
+		return nil, newError("failed to find interface ", config.Name).Base(err)
+	}
+	conn, err := openNetlink()
+	if err != nil {
+		return nil, err
+	}
+	r := &autoRoute{
+		conn:  conn,
+		index: iface.Index,
+		table: config.RouteTable,
+	}
+	if r.table == 0 {
+		r.table = defaultRouteTable
+	}
+	if err := r.setup(config); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *autoRoute) setup(config *Config) error {
+	if err := r.conn.request(unix.RTM_NEWLINK, 0, linkUpMessage(r.index)); err != nil {
+		return newError("failed to bring up ", config.Name).Base(err)
+	}
+
+	families := make(map[uint8]bool)
+	for _, cidr := range config.Routes {
+		dst := &net.IPNet{IP: cidr.Ip, Mask: net.CIDRMask(int(cidr.Prefix), len(cidr.Ip)*8)}
+		if err := r.conn.request(unix.RTM_NEWROUTE, unix.NLM_F_CREATE|unix.NLM_F_REPLACE, routeMessage(dst, r.index, r.table)); err != nil {
+			return newError("failed to add route ", dst, " to ", config.Name).Base(err)
+		}
+		r.routes = append(r.routes, dst)
+		if dst.IP.To4() != nil {
+			families[unix.AF_INET] = true
+		} else {
+			families[unix.AF_INET6] = true
+		}
+	}
+	if len(r.routes) == 0 {
+		return newError("no route to install for ", config.Name)
+	}
+
+	var mark uint32
+	if config.SocketSettings != nil {
+		mark = config.SocketSettings.Mark
+	}
+	if mark == 0 {
+		newError("auto route without a mark in socket settings, traffic of outbounds may loop back into ", config.Name).AtWarning().WriteToLog()
+	}
+	priority := config.RulePriority
+	if priority == 0 {
+		priority = defaultRulePriority
+	}
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		if families[family] {
+			r.rules = append(r.rules, autoRouteRules(family, priority, r.table, mark, config.StrictRoute)...)
+		}
+	}
+	for _, rule := range r.rules {
+		// Remove the rule left by an instance that did not exit cleanly.
+		for {
+			if err := r.conn.request(unix.RTM_DELRULE, 0, rule.message()); err != nil {
+				break
+			}
+		}
+		if err := r.conn.request(unix.RTM_NEWRULE, unix.NLM_F_CREATE|unix.NLM_F_EXCL, rule.message()); err != nil {
+			return newError("failed to add policy rule of priority ", rule.priority).Base(err)
+		}
+	}
+	return nil
+}
+
+// autoRouteRules returns the policy rules of a family:
+//   - Traffic with the mark is routed by the main table.
+//   - Unless strict, traffic is routed by the main table if it matches a route
+//     other than the default route, so that local networks stay reachable.
+//   - Traffic is routed by the table of the TUN device.
+//   - If strict, traffic not routed by the table of the TUN device is unreachable.
+func autoRouteRules(family uint8, priority uint32, table uint32, mark uint32, strict bool) []*policyRule {
+	var rules []*policyRule
+	if mark != 0 {
+		rules = append(rules, &policyRule{
+			family:               family,
+			priority:             priority,
+			action:               unix.FR_ACT_TO_TBL,
+			table:                unix.RT_TABLE_MAIN,
+			mark:                 mark,
+			suppressPrefixLength: -1,
+		})
+	}
+	if !strict {
+		rules = append(rules, &policyRule{
+			family:               family,
+			priority:             priority + 1,
+			action:               unix.FR_ACT_TO_TBL,
+			table:                unix.RT_TABLE_MAIN,
+			suppressPrefixLength: 0,
+		})
+	}
+	rules = append(rules, &policyRule{
+		family:               family,
+		priority:             priority + 2,
+		action:               unix.FR_ACT_TO_TBL,
+		table:                table,
+		suppressPrefixLength: -1,
+	})
+	if strict {
+		rules = append(rules, &policyRule{
+			family:               family,
+			priority:             priority + 3,
+			action:               unix.FR_ACT_UNREACHABLE,
+			suppressPrefixLength: -1,
+		})
+	}
+	return rules
+}
+
+// Close removes the routes and policy rules.
+func (r *autoRoute) Close() error {
+	var errs []error
+	for _, rule := range r.rules {
+		if err := r.conn.request(unix.RTM_DELRULE, 0, rule.message()); err != nil && err != unix.ENOENT {
+			errs = append(errs, newError("failed to delete policy rule of priority ", rule.priority).Base(err))
+		}
+	}
+	for _, dst := range r.routes {
+		// The route is gone if the TUN device is closed.
+		if err := r.conn.request(unix.RTM_DELROUTE, 0, routeMessage(dst, r.index, r.table)); err != nil && err != unix.ENOENT && err != unix.ESRCH && err != unix.ENODEV {
+			errs = append(errs, newError("failed to delete route ", dst).Base(err))
+		}
+	}
+	errs = append(errs, r.conn.Close())
+	return errors.Combine(errs...)
+}
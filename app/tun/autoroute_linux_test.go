@@ -0,0 +1,153 @@
+package tun
+
+import (
+	"encoding/binary"
+	"runtime"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+	"gvisor.dev/gvisor/pkg/tcpip/link/tun"
+
+	"github.com/frogwall/f2ray-core/v5/app/router/routercommon"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+)
+
+// inNetNS runs f in a new network namespace with a TUN device.
+func inNetNS(t *testing.T, name string, f func()) {
+	errCh := make(chan error, 1)
+	go func() {
+		// The thread is left locked to discard it with the namespace.
+		runtime.LockOSThread()
+		if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+			errCh <- newError("failed to create network namespace").Base(err)
+			return
+		}
+		fd, err := tun.Open(name)
+		if err != nil {
+			errCh <- newError("failed to open tun device").Base(err)
+			return
+		}
+		defer unix.Close(fd)
+		f()
+		errCh <- nil
+	}()
+	if err := <-errCh; err != nil {
+		t.Skip(err)
+	}
+}
+
+// dumpRules returns the actions of policy rules by priorities.
+func dumpRules(t *testing.T, family int) map[uint32]uint8 {
+	data, err := syscall.NetlinkRIB(unix.RTM_GETRULE, family)
+	common.Must(err)
+	msgs, err := syscall.ParseNetlinkMessage(data)
+	common.Must(err)
+	rules := make(map[uint32]uint8)
+	for _, msg := range msgs {
+		if msg.Header.Type != unix.RTM_NEWRULE {
+			continue
+		}
+		// The attributes follow the 12-byte struct fib_rule_hdr.
+		for b := msg.Data[12:]; len(b) >= unix.SizeofRtAttr; {
+			length := int(binary.NativeEndian.Uint16(b))
+			if binary.NativeEndian.Uint16(b[2:]) == unix.FRA_PRIORITY {
+				rules[binary.NativeEndian.Uint32(b[unix.SizeofRtAttr:])] = msg.Data[7]
+			}
+			length = (length + unix.NLA_ALIGNTO - 1) & ^(unix.NLA_ALIGNTO - 1)
+			if length == 0 || length > len(b) {
+				break
+			}
+			b = b[length:]
+		}
+	}
+	return rules
+}
+
+// countRoutes returns the number of routes in a routing table.
+func countRoutes(t *testing.T, family int, table uint32) int {
+	data, err := syscall.NetlinkRIB(unix.RTM_GETROUTE, family)
+	common.Must(err)
+	msgs, err := syscall.ParseNetlinkMessage(data)
+	common.Must(err)
+	count := 0
+	for _, msg := range msgs {
+		if msg.Header.Type != unix.RTM_NEWROUTE {
+			continue
+		}
+		attrs, err := syscall.ParseNetlinkRouteAttr(&msg)
+		common.Must(err)
+		for _, attr := range attrs {
+			if attr.Attr.Type == unix.RTA_TABLE && binary.NativeEndian.Uint32(attr.Value) == table {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func TestAutoRoute(t *testing.T) {
+	inNetNS(t, "tun-test", func() {
+		config := &Config{
+			Name: "tun-test",
+			Routes: []*routercommon.CIDR{
+				{Ip: []byte{0, 0, 0, 0}, Prefix: 0},
+				{Ip: make([]byte, 16), Prefix: 0},
+			},
+			SocketSettings: &internet.SocketConfig{Mark: 255},
+		}
+		route, err := setupAutoRoute(config)
+		common.Must(err)
+
+		for _, family := range []int{unix.AF_INET, unix.AF_INET6} {
+			if n := countRoutes(t, family, defaultRouteTable); n != 1 {
+				t.Error("expect 1 route in family ", family, ", but got ", n)
+			}
+			rules := dumpRules(t, family)
+			for _, priority := range []uint32{9000, 9001, 9002} {
+				if rules[priority] != unix.FR_ACT_TO_TBL {
+					t.Error("missing rule of priority ", priority, " in family ", family)
+				}
+			}
+		}
+
+		common.Must(route.Close())
+		for _, family := range []int{unix.AF_INET, unix.AF_INET6} {
+			rules := dumpRules(t, family)
+			for _, priority := range []uint32{9000, 9001, 9002} {
+				if _, found := rules[priority]; found {
+					t.Error("rule of priority ", priority, " is not deleted")
+				}
+			}
+		}
+	})
+}
+
+func TestAutoRouteStrict(t *testing.T) {
+	inNetNS(t, "tun-test", func() {
+		config := &Config{
+			Name:         "tun-test",
+			Routes:       []*routercommon.CIDR{{Ip: []byte{198, 18, 0, 0}, Prefix: 15}},
+			StrictRoute:  true,
+			RulePriority: 100,
+		}
+		route, err := setupAutoRoute(config)
+		common.Must(err)
+		defer route.Close()
+
+		rules := dumpRules(t, unix.AF_INET)
+		if _, found := rules[101]; found {
+			t.Error("local networks are not routed into the TUN device")
+		}
+		if rules[102] != unix.FR_ACT_TO_TBL {
+			t.Error("missing rule of the TUN device")
+		}
+		if rules[103] != unix.FR_ACT_UNREACHABLE {
+			t.Error("missing unreachable rule")
+		}
+		if _, found := dumpRules(t, unix.AF_INET6)[102]; found {
+			t.Error("unexpected IPv6 rule")
+		}
+	})
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package tun
+
+type autoRoute struct{}
+
+func setupAutoRoute(config *Config) (*autoRoute, error) {
+	return nil, newError("auto route is not supported on this platform")
+}
+
+func (r *autoRoute) Close() error {
+	return nil
+}
@@ -33,8 +33,20 @@ type Config struct {
 	EnableSpoofing        bool                      `protobuf:"varint,9,opt,name=enable_spoofing,json=enableSpoofing,proto3" json:"enable_spoofing,omitempty"`
 	SocketSettings        *internet.SocketConfig    `protobuf:"bytes,10,opt,name=socket_settings,json=socketSettings,proto3" json:"socket_settings,omitempty"`
 	SniffingSettings      *proxyman.SniffingConfig  `protobuf:"bytes,11,opt,name=sniffing_settings,json=sniffingSettings,proto3" json:"sniffing_settings,omitempty"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+	// Install system routes of `routes` to the TUN device, and policy rules to
+	// use them. Traffic with the mark of `socket_settings` is excluded. Linux only.
+	AutoRoute bool `protobuf:"varint,12,opt,name=auto_route,json=autoRoute,proto3" json:"auto_route,omitempty"`
+	// With auto_route, also route traffic to local networks into the TUN device,
+	// and make traffic not routed to the TUN device unreachable instead of leaking.
+	StrictRoute bool `protobuf:"varint,13,opt,name=strict_route,json=strictRoute,proto3" json:"strict_route,omitempty"`
+	// Routing table of the routes installed by auto_route. Default 2022.
+	RouteTable uint32 `protobuf:"varint,14,opt,name=route_table,json=routeTable,proto3" json:"route_table,omitempty"`
+	// Priority of the first policy rule installed by auto_route. Default 9000.
+	RulePriority uint32 `protobuf:"varint,15,opt,name=rule_priority,json=rulePriority,proto3" json:"rule_priority,omitempty"`
+	// Answer A and AAAA queries to port 53 with the built-in DNS.
+	DnsHijack     bool `protobuf:"varint,16,opt,name=dns_hijack,json=dnsHijack,proto3" json:"dns_hijack,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Config) Reset() {
@@ -144,11 +156,46 @@ func (x *Config) GetSniffingSettings() *proxyman.SniffingConfig {
 	return nil
 }
 
+func (x *Config) GetAutoRoute() bool {
+	if x != nil {
+		return x.AutoRoute
+	}
+	return false
+}
+
+func (x *Config) GetStrictRoute() bool {
+	if x != nil {
+		return x.StrictRoute
+	}
+	return false
+}
+
+func (x *Config) GetRouteTable() uint32 {
+	if x != nil {
+		return x.RouteTable
+	}
+	return 0
+}
+
+func (x *Config) GetRulePriority() uint32 {
+	if x != nil {
+		return x.RulePriority
+	}
+	return 0
+}
+
+func (x *Config) GetDnsHijack() bool {
+	if x != nil {
+		return x.DnsHijack
+	}
+	return false
+}
+
 var File_app_tun_config_proto protoreflect.FileDescriptor
 
 const file_app_tun_config_proto_rawDesc = "" +
 	"\n" +
-	"\x14app/tun/config.proto\x12\x12v2ray.core.app.tun\x1a\x19app/proxyman/config.proto\x1a$app/router/routercommon/common.proto\x1a common/protoext/extensions.proto\x1a\"common/net/packetaddr/config.proto\x1a\x1ftransport/internet/config.proto\"\xd2\x04\n" +
+	"\x14app/tun/config.proto\x12\x12v2ray.core.app.tun\x1a\x19app/proxyman/config.proto\x1a$app/router/routercommon/common.proto\x1a common/protoext/extensions.proto\x1a\"common/net/packetaddr/config.proto\x1a\x1ftransport/internet/config.proto\"\xf9\x05\n" +
 	"\x06Config\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
 	"\x03mtu\x18\x02 \x01(\rR\x03mtu\x12\x1d\n" +
@@ -162,7 +209,15 @@ const file_app_tun_config_proto_rawDesc = "" +
 	"\x0fenable_spoofing\x18\t \x01(\bR\x0eenableSpoofing\x12T\n" +
 	"\x0fsocket_settings\x18\n" +
 	" \x01(\v2+.v2ray.core.transport.internet.SocketConfigR\x0esocketSettings\x12T\n" +
-	"\x11sniffing_settings\x18\v \x01(\v2'.v2ray.core.app.proxyman.SniffingConfigR\x10sniffingSettings:\x12\x82\xb5\x18\x0e\n" +
+	"\x11sniffing_settings\x18\v \x01(\v2'.v2ray.core.app.proxyman.SniffingConfigR\x10sniffingSettings\x12\x1d\n" +
+	"\n" +
+	"auto_route\x18\f \x01(\bR\tautoRoute\x12!\n" +
+	"\fstrict_route\x18\r \x01(\bR\vstrictRoute\x12\x1f\n" +
+	"\vroute_table\x18\x0e \x01(\rR\n" +
+	"routeTable\x12#\n" +
+	"\rrule_priority\x18\x0f \x01(\rR\frulePriority\x12\x1d\n" +
+	"\n" +
+	"dns_hijack\x18\x10 \x01(\bR\tdnsHijack:\x12\x82\xb5\x18\x0e\n" +
 	"\aservice\x12\x03tunBZ\n" +
 	"\x16com.v2ray.core.app.tunP\x01Z)github.com/frogwall/f2ray-core/v5/app/tun\xaa\x02\x12V2Ray.Core.App.Tunb\x06proto3"
 
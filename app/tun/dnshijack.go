@@ -0,0 +1,152 @@
+package tun
+
+import (
+	"io"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	dns_proto "github.com/frogwall/f2ray-core/v5/common/protocol/dns"
+	"github.com/frogwall/f2ray-core/v5/common/strmatcher"
+	"github.com/frogwall/f2ray-core/v5/features/dns"
+)
+
+// dnsHijackTTL is the TTL of records in hijacked DNS responses.
+const dnsHijackTTL = 600
+
+// dnsHijacker answers DNS queries to port 53 with the built-in DNS.
+type dnsHijacker struct {
+	ipv4Lookup dns.IPv4Lookup
+	ipv6Lookup dns.IPv6Lookup
+}
+
+func newDNSHijacker(client dns.Client) (*dnsHijacker, error) {
+	if clientWithFakeDNS, ok := client.(dns.ClientWithFakeDNS); ok {
+		client = clientWithFakeDNS.AsFakeDNSClient()
+	}
+	ipv4Lookup, ok := client.(dns.IPv4Lookup)
+	if !ok {
+		return nil, newError("dns.Client doesn't implement IPv4Lookup")
+	}
+	ipv6Lookup, ok := client.(dns.IPv6Lookup)
+	if !ok {
+		return nil, newError("dns.Client doesn't implement IPv6Lookup")
+	}
+	return &dnsHijacker{ipv4Lookup: ipv4Lookup, ipv6Lookup: ipv6Lookup}, nil
+}
+
+// isHijacked returns whether traffic to dest is DNS traffic to hijack.
+func (h *dnsHijacker) isHijacked(dest net.Destination) bool {
+	return h != nil && dest.Port == 53
+}
+
+// parseIPQuery returns the query in b if it is a query of A or AAAA records.
+func parseIPQuery(b []byte) (*dnsmessage.Message, bool) {
+	query := new(dnsmessage.Message)
+	if err := query.Unpack(b); err != nil || query.Response || len(query.Questions) != 1 {
+		return nil, false
+	}
+	switch query.Questions[0].Type {
+	case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+		return query, true
+	default:
+		return nil, false
+	}
+}
+
+// resolve returns the response to a query of A or AAAA records.
+func (h *dnsHijacker) resolve(query *dnsmessage.Message) (*buf.Buffer, error) {
+	question := query.Questions[0]
+	response := &dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 query.ID,
+			Response:           true,
+			RecursionDesired:   query.RecursionDesired,
+			RecursionAvailable: true,
+		},
+		Questions: query.Questions,
+	}
+
+	domain, err := strmatcher.ToDomain(question.Name.String())
+	if err != nil {
+		response.RCode = dnsmessage.RCodeFormatError
+		return dns_proto.PackMessage(response)
+	}
+
+	var ips []net.IP
+	switch question.Type {
+	case dnsmessage.TypeA:
+		ips, err = h.ipv4Lookup.LookupIPv4(domain)
+	case dnsmessage.TypeAAAA:
+		ips, err = h.ipv6Lookup.LookupIPv6(domain)
+	}
+	if rcode := dns.RCodeFromError(err); rcode != 0 {
+		response.RCode = dnsmessage.RCode(rcode)
+	} else if err != nil && err != dns.ErrEmptyResponse {
+		newError("failed to lookup ", domain, " for hijacked DNS query").Base(err).WriteToLog()
+		response.RCode = dnsmessage.RCodeServerFailure
+	}
+
+	header := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: dnsHijackTTL}
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil && question.Type == dnsmessage.TypeA {
+			var r dnsmessage.AResource
+			copy(r.A[:], ip4)
+			response.Answers = append(response.Answers, dnsmessage.Resource{Header: header, Body: &r})
+		} else if len(ip) == net.IPv6len && question.Type == dnsmessage.TypeAAAA {
+			var r dnsmessage.AAAAResource
+			copy(r.AAAA[:], ip)
+			response.Answers = append(response.Answers, dnsmessage.Resource{Header: header, Body: &r})
+		}
+	}
+	return dns_proto.PackMessage(response)
+}
+
+// errorResponse returns the response with rcode to a DNS message.
+func errorResponse(b []byte, rcode dnsmessage.RCode) (*buf.Buffer, error) {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(b)
+	if err != nil {
+		return nil, err
+	}
+	return dns_proto.PackMessage(&dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 header.ID,
+			Response:           true,
+			RecursionDesired:   header.RecursionDesired,
+			RecursionAvailable: true,
+			RCode:              rcode,
+		},
+	})
+}
+
+// handleTCP answers DNS queries over TCP. Only queries of A and AAAA records
+// are resolved, as there is no upstream server for other queries.
+func (h *dnsHijacker) handleTCP(conn net.Conn) error {
+	reader := dns_proto.NewTCPReader(buf.NewReader(conn))
+	defer reader.Close()
+	writer := &dns_proto.TCPWriter{Writer: buf.NewWriter(conn)}
+	for {
+		b, err := reader.ReadMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return newError("failed to read hijacked DNS query").Base(err)
+		}
+		var response *buf.Buffer
+		if query, ok := parseIPQuery(b.Bytes()); ok {
+			response, err = h.resolve(query)
+		} else {
+			response, err = errorResponse(b.Bytes(), dnsmessage.RCodeNotImplemented)
+		}
+		b.Release()
+		if err != nil {
+			return newError("failed to answer hijacked DNS query").Base(err)
+		}
+		if err := writer.WriteMessage(response); err != nil {
+			return newError("failed to write hijacked DNS response").Base(err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package tun
+
+import (
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/features/dns"
+)
+
+type staticDNSClient struct{}
+
+func (staticDNSClient) Type() interface{} { return dns.ClientType() }
+func (staticDNSClient) Start() error      { return nil }
+func (staticDNSClient) Close() error      { return nil }
+
+func (staticDNSClient) LookupIP(domain string) ([]net.IP, error) {
+	return []net.IP{{1, 2, 3, 4}}, nil
+}
+
+func (staticDNSClient) LookupIPv4(domain string) ([]net.IP, error) {
+	if domain != "v2fly.org." {
+		return nil, dns.ErrEmptyResponse
+	}
+	return []net.IP{{1, 2, 3, 4}}, nil
+}
+
+func (staticDNSClient) LookupIPv6(domain string) ([]net.IP, error) {
+	return nil, dns.ErrEmptyResponse
+}
+
+func TestDNSHijack(t *testing.T) {
+	hijacker, err := newDNSHijacker(staticDNSClient{})
+	common.Must(err)
+	if !hijacker.isHijacked(net.UDPDestination(net.LocalHostIP, 53)) {
+		t.Error("port 53 is not hijacked")
+	}
+
+	query := func(name string, qType dnsmessage.Type) *dnsmessage.Message {
+		b, err := (&dnsmessage.Message{
+			Header:    dnsmessage.Header{ID: 7, RecursionDesired: true},
+			Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qType, Class: dnsmessage.ClassINET}},
+		}).Pack()
+		common.Must(err)
+		msg, ok := parseIPQuery(b)
+		if !ok {
+			return nil
+		}
+		response, err := hijacker.resolve(msg)
+		common.Must(err)
+		defer response.Release()
+		answer := new(dnsmessage.Message)
+		common.Must(answer.Unpack(response.Bytes()))
+		return answer
+	}
+
+	answer := query("v2fly.org.", dnsmessage.TypeA)
+	if answer.ID != 7 || len(answer.Answers) != 1 || answer.Answers[0].Body.(*dnsmessage.AResource).A != [4]byte{1, 2, 3, 4} {
+		t.Error("unexpected answer: ", answer)
+	}
+	if answer := query("v2fly.org.", dnsmessage.TypeAAAA); answer.RCode != dnsmessage.RCodeSuccess || len(answer.Answers) != 0 {
+		t.Error("unexpected answer: ", answer)
+	}
+	if answer := query("v2fly.org.", dnsmessage.TypeMX); answer != nil {
+		t.Error("MX query is hijacked")
+	}
+}
@@ -0,0 +1,237 @@
+package tun
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/checksum"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+
+	"github.com/frogwall/f2ray-core/v5/app/tun/device"
+	tun_net "github.com/frogwall/f2ray-core/v5/app/tun/net"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/features/outbound"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	routing_session "github.com/frogwall/f2ray-core/v5/features/routing/session"
+)
+
+const (
+	// icmpEchoTimeout is the time to wait for the reply of a routed ICMP echo request.
+	icmpEchoTimeout = time.Second * 5
+
+	icmpEchoHopLimit = 64
+)
+
+// icmpEchoRequest is an ICMP echo request of IPv4 or IPv6 read from the TUN device.
+type icmpEchoRequest struct {
+	ipv6     bool
+	src      tcpip.Address
+	dst      tcpip.Address
+	ident    uint16
+	sequence uint16
+	data     []byte
+}
+
+// parseICMPEchoRequest returns the ICMP echo request in an IP packet. Requests
+// to multicast or broadcast addresses and fragmented requests are ignored.
+func parseICMPEchoRequest(protocol tcpip.NetworkProtocolNumber, b []byte) (*icmpEchoRequest, bool) {
+	switch protocol {
+	case header.IPv4ProtocolNumber:
+		ip := header.IPv4(b)
+		if !ip.IsValid(len(b)) || ip.TransportProtocol() != header.ICMPv4ProtocolNumber || ip.More() || ip.FragmentOffset() != 0 {
+			return nil, false
+		}
+		dst := ip.DestinationAddress()
+		if header.IsV4MulticastAddress(dst) || dst == header.IPv4Broadcast {
+			return nil, false
+		}
+		icmp := header.ICMPv4(ip.Payload())
+		if len(icmp) < header.ICMPv4MinimumSize || icmp.Type() != header.ICMPv4Echo || icmp.Code() != 0 {
+			return nil, false
+		}
+		return &icmpEchoRequest{
+			src:      ip.SourceAddress(),
+			dst:      dst,
+			ident:    icmp.Ident(),
+			sequence: icmp.Sequence(),
+			data:     append([]byte(nil), icmp.Payload()...),
+		}, true
+	case header.IPv6ProtocolNumber:
+		ip := header.IPv6(b)
+		if !ip.IsValid(len(b)) || ip.TransportProtocol() != header.ICMPv6ProtocolNumber {
+			return nil, false
+		}
+		dst := ip.DestinationAddress()
+		if header.IsV6MulticastAddress(dst) {
+			return nil, false
+		}
+		icmp := header.ICMPv6(ip.Payload())
+		if len(icmp) < header.ICMPv6EchoMinimumSize || icmp.Type() != header.ICMPv6EchoRequest || icmp.Code() != 0 {
+			return nil, false
+		}
+		return &icmpEchoRequest{
+			ipv6:     true,
+			src:      ip.SourceAddress(),
+			dst:      dst,
+			ident:    icmp.Ident(),
+			sequence: icmp.Sequence(),
+			data:     append([]byte(nil), icmp.Payload()...),
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// isICMPPacket peeks whether an IP packet is of ICMP, so that other packets
+// are not copied.
+func isICMPPacket(protocol tcpip.NetworkProtocolNumber, pkt stack.PacketBufferPtr) bool {
+	switch protocol {
+	case header.IPv4ProtocolNumber:
+		b, ok := pkt.Data().PullUp(header.IPv4MinimumSize)
+		return ok && header.IPv4(b).TransportProtocol() == header.ICMPv4ProtocolNumber
+	case header.IPv6ProtocolNumber:
+		b, ok := pkt.Data().PullUp(header.IPv6MinimumSize)
+		return ok && header.IPv6(b).TransportProtocol() == header.ICMPv6ProtocolNumber
+	default:
+		return false
+	}
+}
+
+// reply returns the IP packet of the echo reply to the request, with data.
+func (r *icmpEchoRequest) reply(data []byte) []byte {
+	if r.ipv6 {
+		b := make([]byte, header.IPv6MinimumSize+header.ICMPv6EchoMinimumSize+len(data))
+		ip := header.IPv6(b)
+		ip.Encode(&header.IPv6Fields{
+			PayloadLength:     uint16(len(b) - header.IPv6MinimumSize),
+			TransportProtocol: header.ICMPv6ProtocolNumber,
+			HopLimit:          icmpEchoHopLimit,
+			SrcAddr:           r.dst,
+			DstAddr:           r.src,
+		})
+		icmp := header.ICMPv6(ip.Payload())
+		icmp.SetType(header.ICMPv6EchoReply)
+		icmp.SetIdent(r.ident)
+		icmp.SetSequence(r.sequence)
+		copy(icmp.Payload(), data)
+		icmp.SetChecksum(header.ICMPv6Checksum(header.ICMPv6ChecksumParams{
+			Header:      icmp[:header.ICMPv6EchoMinimumSize],
+			Src:         r.dst,
+			Dst:         r.src,
+			PayloadCsum: checksum.Checksum(data, 0),
+			PayloadLen:  len(data),
+		}))
+		return b
+	}
+
+	b := make([]byte, header.IPv4MinimumSize+header.ICMPv4MinimumSize+len(data))
+	ip := header.IPv4(b)
+	ip.Encode(&header.IPv4Fields{
+		TotalLength: uint16(len(b)),
+		TTL:         icmpEchoHopLimit,
+		Protocol:    uint8(header.ICMPv4ProtocolNumber),
+		SrcAddr:     r.dst,
+		DstAddr:     r.src,
+	})
+	ip.SetChecksum(^ip.CalculateChecksum())
+	icmp := header.ICMPv4(ip.Payload())
+	icmp.SetType(header.ICMPv4EchoReply)
+	icmp.SetIdent(r.ident)
+	icmp.SetSequence(r.sequence)
+	copy(icmp.Payload(), data)
+	icmp.SetChecksum(header.ICMPv4Checksum(icmp[:header.ICMPv4MinimumSize], checksum.Checksum(data, 0)))
+	return b
+}
+
+// ICMPHandler answers ICMP echo requests from the TUN device. Requests are
+// routed as traffic of network "icmp". If the outbound can forward them, for
+// example freedom, the reply comes from the real destination; if the outbound
+// cannot, the request is answered locally; otherwise, for example blackhole,
+// the request is dropped.
+type ICMPHandler struct {
+	ctx    context.Context
+	config *Config
+	router routing.Router
+	ohm    outbound.Manager
+	writer io.Writer
+}
+
+func (h *ICMPHandler) Handle(request *icmpEchoRequest) {
+	ctx := session.ContextWithInbound(h.ctx, &session.Inbound{
+		Tag:    h.config.Tag,
+		Source: net.Destination{Network: net.Network_ICMP, Address: tun_net.AddressFromTCPIPAddr(request.src)},
+	})
+	dest := tun_net.AddressFromTCPIPAddr(request.dst)
+	ctx = session.ContextWithOutbound(ctx, &session.Outbound{Target: net.ICMPDestination(dest)})
+
+	var handler outbound.Handler
+	if h.router != nil {
+		if route, err := h.router.PickRoute(routing_session.AsRoutingContext(ctx)); err == nil {
+			handler = h.ohm.GetHandler(route.GetOutboundTag())
+		}
+	}
+	if handler == nil {
+		handler = h.ohm.GetDefaultHandler()
+	}
+
+	data := request.data
+	if pinger, ok := handler.(outbound.Pinger); ok {
+		ctx, cancel := context.WithTimeout(ctx, icmpEchoTimeout)
+		defer cancel()
+		reply, err := pinger.Ping(ctx, dest, request.data)
+		switch {
+		case err == nil:
+			data = reply
+		case err == outbound.ErrPingNotSupported:
+		default:
+			newError("failed to forward ICMP echo request to ", dest).Base(err).WriteToLog(session.ExportIDToError(ctx))
+			return
+		}
+	} else if handler == nil {
+		return
+	}
+
+	if _, err := h.writer.Write(request.reply(data)); err != nil {
+		newError("failed to write ICMP echo reply").Base(err).WriteToLog(session.ExportIDToError(ctx))
+	}
+}
+
+// NewDeviceWithICMPHandler returns a device passing ICMP echo requests to an
+// ICMPHandler, as the network stack would answer them itself.
+func NewDeviceWithICMPHandler(overlay device.Device, handler *ICMPHandler) device.Device {
+	handler.writer = device.NewLinkWriterToWriter(overlay)
+	return &icmpDevice{
+		Device:  overlay,
+		handler: handler,
+	}
+}
+
+type icmpDevice struct {
+	device.Device
+	handler *ICMPHandler
+
+	secondaryDispatcher stack.NetworkDispatcher
+}
+
+func (d *icmpDevice) DeliverNetworkPacket(protocol tcpip.NetworkProtocolNumber, pkt stack.PacketBufferPtr) {
+	if isICMPPacket(protocol, pkt) {
+		if request, ok := parseICMPEchoRequest(protocol, pkt.Data().AsRange().ToSlice()); ok {
+			go d.handler.Handle(request)
+			return
+		}
+	}
+	d.secondaryDispatcher.DeliverNetworkPacket(protocol, pkt)
+}
+
+func (d *icmpDevice) DeliverLinkPacket(protocol tcpip.NetworkProtocolNumber, pkt stack.PacketBufferPtr) {
+	d.secondaryDispatcher.DeliverLinkPacket(protocol, pkt)
+}
+
+func (d *icmpDevice) Attach(dispatcher stack.NetworkDispatcher) {
+	d.secondaryDispatcher = dispatcher
+	d.Device.Attach(d)
+}
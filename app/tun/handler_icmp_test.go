@@ -0,0 +1,162 @@
+package tun
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/header"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/features/outbound"
+)
+
+type staticOutboundManager struct {
+	outbound.Manager
+	handler outbound.Handler
+}
+
+func (m *staticOutboundManager) GetHandler(tag string) outbound.Handler {
+	return m.handler
+}
+
+func (m *staticOutboundManager) GetDefaultHandler() outbound.Handler {
+	return m.handler
+}
+
+type pingHandler struct {
+	outbound.Handler
+	reply []byte
+	err   error
+}
+
+func (h *pingHandler) Ping(ctx context.Context, dest net.Address, payload []byte) ([]byte, error) {
+	return h.reply, h.err
+}
+
+type packetRecorder struct {
+	packets [][]byte
+}
+
+func (r *packetRecorder) Write(b []byte) (int, error) {
+	r.packets = append(r.packets, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+// echoRequestPacket returns an IP packet of an ICMP echo request, by swapping
+// the addresses and type of the reply to a request from dst to src.
+func echoRequestPacket(src, dst tcpip.Address, data []byte) (tcpip.NetworkProtocolNumber, []byte) {
+	r := &icmpEchoRequest{src: dst, dst: src, ident: 1234, sequence: 7}
+	if src.Len() == net.IPv6len {
+		r.ipv6 = true
+		b := r.reply(data)
+		ip := header.IPv6(b)
+		icmp := header.ICMPv6(ip.Payload())
+		icmp.SetType(header.ICMPv6EchoRequest)
+		icmp.SetChecksum(0)
+		icmp.SetChecksum(header.ICMPv6Checksum(header.ICMPv6ChecksumParams{Header: icmp, Src: src, Dst: dst}))
+		return header.IPv6ProtocolNumber, b
+	}
+	b := r.reply(data)
+	icmp := header.ICMPv4(header.IPv4(b).Payload())
+	icmp.SetType(header.ICMPv4Echo)
+	icmp.SetChecksum(0)
+	icmp.SetChecksum(header.ICMPv4Checksum(icmp, 0))
+	return header.IPv4ProtocolNumber, b
+}
+
+func TestICMPEchoReply(t *testing.T) {
+	for _, addrs := range [][2]tcpip.Address{
+		{tcpip.AddrFromSlice(net.IP{10, 0, 0, 1}), tcpip.AddrFromSlice(net.IP{1, 1, 1, 1})},
+		{tcpip.AddrFromSlice(net.ParseIP("fd00::1")), tcpip.AddrFromSlice(net.ParseIP("2001:db8::1"))},
+	} {
+		src, dst := addrs[0], addrs[1]
+		protocol, packet := echoRequestPacket(src, dst, []byte("ping"))
+		request, ok := parseICMPEchoRequest(protocol, packet)
+		if !ok {
+			t.Fatal("failed to parse echo request from ", src)
+		}
+		if request.src != src || request.dst != dst || request.ident != 1234 || request.sequence != 7 || string(request.data) != "ping" {
+			t.Fatal("unexpected echo request: ", request)
+		}
+
+		reply := request.reply([]byte("pong"))
+		if protocol == header.IPv4ProtocolNumber {
+			ip := header.IPv4(reply)
+			if !ip.IsValid(len(reply)) || !ip.IsChecksumValid() || ip.SourceAddress() != dst || ip.DestinationAddress() != src {
+				t.Fatal("invalid IPv4 header of echo reply")
+			}
+			icmp := header.ICMPv4(ip.Payload())
+			if icmp.Type() != header.ICMPv4EchoReply || icmp.Ident() != 1234 || icmp.Sequence() != 7 || string(icmp.Payload()) != "pong" {
+				t.Error("unexpected echo reply: ", icmp)
+			}
+			if header.ICMPv4Checksum(icmp, 0) != icmp.Checksum() {
+				t.Error("invalid checksum of echo reply")
+			}
+		} else {
+			ip := header.IPv6(reply)
+			if !ip.IsValid(len(reply)) || ip.SourceAddress() != dst || ip.DestinationAddress() != src {
+				t.Fatal("invalid IPv6 header of echo reply")
+			}
+			icmp := header.ICMPv6(ip.Payload())
+			if icmp.Type() != header.ICMPv6EchoReply || icmp.Ident() != 1234 || icmp.Sequence() != 7 || string(icmp.Payload()) != "pong" {
+				t.Error("unexpected echo reply: ", icmp)
+			}
+			if header.ICMPv6Checksum(header.ICMPv6ChecksumParams{Header: icmp, Src: dst, Dst: src}) != icmp.Checksum() {
+				t.Error("invalid checksum of echo reply")
+			}
+		}
+	}
+}
+
+func TestICMPEchoRequestIgnored(t *testing.T) {
+	src := tcpip.AddrFromSlice(net.IP{10, 0, 0, 1})
+	protocol, packet := echoRequestPacket(src, tcpip.AddrFromSlice(net.IP{224, 0, 0, 1}), nil)
+	if _, ok := parseICMPEchoRequest(protocol, packet); ok {
+		t.Error("echo request to multicast address is parsed")
+	}
+	protocol, packet = echoRequestPacket(src, tcpip.AddrFromSlice(net.IP{1, 1, 1, 1}), nil)
+	header.IPv4(packet).SetFlagsFragmentOffset(header.IPv4FlagMoreFragments, 0)
+	if _, ok := parseICMPEchoRequest(protocol, packet); ok {
+		t.Error("fragmented echo request is parsed")
+	}
+}
+
+func TestICMPHandler(t *testing.T) {
+	src := tcpip.AddrFromSlice(net.IP{10, 0, 0, 1})
+	dst := tcpip.AddrFromSlice(net.IP{1, 1, 1, 1})
+	protocol, packet := echoRequestPacket(src, dst, []byte("ping"))
+	request, _ := parseICMPEchoRequest(protocol, packet)
+
+	for _, tc := range []struct {
+		handler outbound.Handler
+		reply   []byte
+	}{
+		{handler: &pingHandler{reply: []byte("real")}, reply: []byte("real")},
+		{handler: &pingHandler{err: outbound.ErrPingNotSupported}, reply: []byte("ping")},
+		{handler: &pingHandler{err: newError("blocked")}},
+		{handler: nil},
+	} {
+		recorder := new(packetRecorder)
+		handler := &ICMPHandler{
+			ctx:    context.Background(),
+			config: &Config{Tag: "tun"},
+			ohm:    &staticOutboundManager{handler: tc.handler},
+			writer: recorder,
+		}
+		handler.Handle(request)
+		if tc.reply == nil {
+			if len(recorder.packets) != 0 {
+				t.Error("echo request is not dropped")
+			}
+			continue
+		}
+		if len(recorder.packets) != 1 {
+			t.Fatal("expect 1 echo reply, but got ", len(recorder.packets))
+		}
+		if data := header.ICMPv4(header.IPv4(recorder.packets[0]).Payload()).Payload(); !bytes.Equal(data, tc.reply) {
+			t.Error("unexpected data of echo reply: ", string(data))
+		}
+	}
+}
@@ -43,9 +43,10 @@ type TCPHandler struct {
 	dispatcher    routing.Dispatcher
 	policyManager policy.Manager
 	config        *Config
+	dnsHijacker   *dnsHijacker
 }
 
-func SetTCPHandler(ctx context.Context, dispatcher routing.Dispatcher, policyManager policy.Manager, config *Config) StackOption {
+func SetTCPHandler(ctx context.Context, dispatcher routing.Dispatcher, policyManager policy.Manager, config *Config, hijacker *dnsHijacker) StackOption {
 	return func(s *stack.Stack) error {
 		tcpForwarder := tcp.NewForwarder(s, rcvWnd, maxInFlight, func(r *tcp.ForwarderRequest) {
 			wg := new(waiter.Queue)
@@ -72,6 +73,7 @@ func SetTCPHandler(ctx context.Context, dispatcher routing.Dispatcher, policyMan
 				dispatcher:    dispatcher,
 				policyManager: policyManager,
 				config:        config,
+				dnsHijacker:   hijacker,
 			}
 
 			go handler.Handle(conn)
@@ -91,6 +93,9 @@ func (h *TCPHandler) Handle(conn tun_net.TCPConn) error {
 
 	dest := net.TCPDestination(tun_net.AddressFromTCPIPAddr(id.LocalAddress), net.Port(id.LocalPort))
 	src := net.TCPDestination(tun_net.AddressFromTCPIPAddr(id.RemoteAddress), net.Port(id.RemotePort))
+	if h.dnsHijacker.isHijacked(dest) {
+		return h.dnsHijacker.handleTCP(conn)
+	}
 	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
 		From:   src,
 		To:     dest,
@@ -3,6 +3,8 @@ package tun
 import (
 	"context"
 
+	"golang.org/x/net/dns/dnsmessage"
+
 	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
 	"gvisor.dev/gvisor/pkg/tcpip/stack"
 	gvisor_udp "gvisor.dev/gvisor/pkg/tcpip/transport/udp"
@@ -23,6 +25,7 @@ type UDPHandler struct {
 	dispatcher    routing.Dispatcher
 	policyManager policy.Manager
 	config        *Config
+	dnsHijacker   *dnsHijacker
 }
 
 type udpConn struct {
@@ -34,7 +37,7 @@ func (c *udpConn) ID() *stack.TransportEndpointID {
 	return &c.id
 }
 
-func SetUDPHandler(ctx context.Context, dispatcher routing.Dispatcher, policyManager policy.Manager, config *Config) StackOption {
+func SetUDPHandler(ctx context.Context, dispatcher routing.Dispatcher, policyManager policy.Manager, config *Config, hijacker *dnsHijacker) StackOption {
 	return func(s *stack.Stack) error {
 		udpForwarder := gvisor_udp.NewForwarder(s, func(r *gvisor_udp.ForwarderRequest) {
 			wg := new(waiter.Queue)
@@ -54,6 +57,7 @@ func SetUDPHandler(ctx context.Context, dispatcher routing.Dispatcher, policyMan
 				dispatcher:    dispatcher,
 				policyManager: policyManager,
 				config:        config,
+				dnsHijacker:   hijacker,
 			}
 			go handler.Handle(conn)
 		})
@@ -98,9 +102,30 @@ func (h *UDPHandler) Handle(conn tun_net.UDPConn) error {
 			if err != nil {
 				return newError("failed to read UDP packet").Base(err)
 			}
+			if h.dnsHijacker.isHijacked(dest) {
+				if query, ok := parseIPQuery(buffer[:n]); ok {
+					go h.answerDNS(conn, src, query)
+					continue
+				}
+			}
 			currentPacketCtx := ctx
 
 			udpServer.Dispatch(currentPacketCtx, dest, buf.FromBytes(buffer[:n]))
 		}
 	}
 }
+
+func (h *UDPHandler) answerDNS(conn tun_net.UDPConn, src net.Destination, query *dnsmessage.Message) {
+	response, err := h.dnsHijacker.resolve(query)
+	if err != nil {
+		newError("failed to answer hijacked DNS query").Base(err).WriteToLog()
+		return
+	}
+	defer response.Release()
+	if _, err := conn.WriteTo(response.Bytes(), &net.UDPAddr{
+		IP:   src.Address.IP(),
+		Port: int(src.Port),
+	}); err != nil {
+		newError("failed to write hijacked DNS response").Base(err).WriteToLog()
+	}
+}
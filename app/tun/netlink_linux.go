@@ -0,0 +1,159 @@
+//go:build linux
+// +build linux
+
+package tun
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// netlinkConn sends route netlink requests to the kernel.
+type netlinkConn struct {
+	fd  int
+	seq uint32
+}
+
+func openNetlink() (*netlinkConn, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, newError("failed to open netlink socket").Base(err)
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		unix.Close(fd)
+		return nil, newError("failed to bind netlink socket").Base(err)
+	}
+	return &netlinkConn{fd: fd}, nil
+}
+
+func (c *netlinkConn) Close() error {
+	return unix.Close(c.fd)
+}
+
+// request sends a request and waits for its acknowledgement.
+func (c *netlinkConn) request(msgType uint16, flags uint16, msg *netlinkMessage) error {
+	c.seq++
+	data := make([]byte, unix.SizeofNlMsghdr, unix.SizeofNlMsghdr+len(msg.data))
+	data = append(data, msg.data...)
+	binary.NativeEndian.PutUint32(data[0:], uint32(len(data)))
+	binary.NativeEndian.PutUint16(data[4:], msgType)
+	binary.NativeEndian.PutUint16(data[6:], flags|unix.NLM_F_REQUEST|unix.NLM_F_ACK)
+	binary.NativeEndian.PutUint32(data[8:], c.seq)
+	if err := unix.Sendto(c.fd, data, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return err
+	}
+
+	buffer := make([]byte, unix.Getpagesize())
+	for {
+		n, _, err := unix.Recvfrom(c.fd, buffer, 0)
+		if err != nil {
+			return err
+		}
+		replies, err := syscall.ParseNetlinkMessage(buffer[:n])
+		if err != nil {
+			return err
+		}
+		for _, reply := range replies {
+			if reply.Header.Seq != c.seq || reply.Header.Type != unix.NLMSG_ERROR {
+				continue
+			}
+			if len(reply.Data) < 4 {
+				return newError("invalid netlink acknowledgement")
+			}
+			if errno := int32(binary.NativeEndian.Uint32(reply.Data)); errno != 0 {
+				return unix.Errno(-errno)
+			}
+			return nil
+		}
+	}
+}
+
+// netlinkMessage is the body of a netlink message, with its attributes.
+type netlinkMessage struct {
+	data []byte
+}
+
+func newNetlinkMessage(header []byte) *netlinkMessage {
+	return &netlinkMessage{data: header}
+}
+
+func (m *netlinkMessage) addAttr(attrType uint16, value []byte) {
+	length := unix.SizeofRtAttr + len(value)
+	attr := make([]byte, (length+unix.NLA_ALIGNTO-1) & ^(unix.NLA_ALIGNTO-1))
+	binary.NativeEndian.PutUint16(attr[0:], uint16(length))
+	binary.NativeEndian.PutUint16(attr[2:], attrType)
+	copy(attr[unix.SizeofRtAttr:], value)
+	m.data = append(m.data, attr...)
+}
+
+func (m *netlinkMessage) addUint32(attrType uint16, value uint32) {
+	var b [4]byte
+	binary.NativeEndian.PutUint32(b[:], value)
+	m.addAttr(attrType, b[:])
+}
+
+// linkUpMessage returns the RTM_NEWLINK message to bring up an interface.
+func linkUpMessage(index int) *netlinkMessage {
+	header := make([]byte, unix.SizeofIfInfomsg)
+	binary.NativeEndian.PutUint32(header[4:], uint32(index))
+	binary.NativeEndian.PutUint32(header[8:], unix.IFF_UP)
+	binary.NativeEndian.PutUint32(header[12:], unix.IFF_UP)
+	return newNetlinkMessage(header)
+}
+
+// routeMessage returns the RTM_NEWROUTE or RTM_DELROUTE message of a route to
+// the interface in a routing table.
+func routeMessage(dst *net.IPNet, index int, table uint32) *netlinkMessage {
+	family := uint8(unix.AF_INET6)
+	ip := dst.IP.To16()
+	if ip4 := dst.IP.To4(); ip4 != nil {
+		family = unix.AF_INET
+		ip = ip4
+	}
+	ones, _ := dst.Mask.Size()
+	msg := newNetlinkMessage([]byte{
+		family, uint8(ones), 0, 0,
+		unix.RT_TABLE_UNSPEC, unix.RTPROT_BOOT, unix.RT_SCOPE_LINK, unix.RTN_UNICAST,
+		0, 0, 0, 0,
+	})
+	msg.addAttr(unix.RTA_DST, ip)
+	msg.addUint32(unix.RTA_OIF, uint32(index))
+	msg.addUint32(unix.RTA_TABLE, table)
+	return msg
+}
+
+// policyRule is a routing policy rule, as of `ip rule`.
+type policyRule struct {
+	family   uint8
+	priority uint32
+	action   uint8
+	table    uint32
+	mark     uint32
+	// suppressPrefixLength is the FRA_SUPPRESS_PREFIXLEN of the rule, if it is not negative.
+	suppressPrefixLength int
+}
+
+// message returns the RTM_NEWRULE or RTM_DELRULE message of the rule. The
+// layout of the header is struct fib_rule_hdr.
+func (r *policyRule) message() *netlinkMessage {
+	msg := newNetlinkMessage([]byte{
+		r.family, 0, 0, 0,
+		unix.RT_TABLE_UNSPEC, 0, 0, r.action,
+		0, 0, 0, 0,
+	})
+	msg.addUint32(unix.FRA_PRIORITY, r.priority)
+	if r.table != 0 {
+		msg.addUint32(unix.FRA_TABLE, r.table)
+	}
+	if r.mark != 0 {
+		msg.addUint32(unix.FRA_FWMARK, r.mark)
+		msg.addUint32(unix.FRA_FWMASK, 0xffffffff)
+	}
+	if r.suppressPrefixLength >= 0 {
+		msg.addUint32(unix.FRA_SUPPRESS_PREFIXLEN, uint32(r.suppressPrefixLength))
+	}
+	return msg
+}
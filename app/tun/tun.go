@@ -14,6 +14,8 @@ import (
 	"github.com/frogwall/f2ray-core/v5/app/tun/tunsorter"
 	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/net/packetaddr"
+	"github.com/frogwall/f2ray-core/v5/features/dns"
+	"github.com/frogwall/f2ray-core/v5/features/outbound"
 	"github.com/frogwall/f2ray-core/v5/features/policy"
 	"github.com/frogwall/f2ray-core/v5/features/routing"
 )
@@ -24,9 +26,13 @@ type TUN struct {
 	ctx           context.Context
 	dispatcher    routing.Dispatcher
 	policyManager policy.Manager
+	router        routing.Router
+	ohm           outbound.Manager
 	config        *Config
+	dnsHijacker   *dnsHijacker
 
-	stack *stack.Stack
+	stack     *stack.Stack
+	autoRoute *autoRoute
 }
 
 func (t *TUN) Type() interface{} {
@@ -43,6 +49,13 @@ func (t *TUN) Start() error {
 		return newError("failed to create device").Base(err).AtError()
 	}
 
+	tunDevice = NewDeviceWithICMPHandler(tunDevice, &ICMPHandler{
+		ctx:    t.ctx,
+		config: t.config,
+		router: t.router,
+		ohm:    t.ohm,
+	})
+
 	if t.config.PacketEncoding != packetaddr.PacketAddrType_None {
 		writer := device.NewLinkWriterToWriter(tunDevice)
 		sorter := tunsorter.NewTunSorter(writer, t.dispatcher, t.config.PacketEncoding, t.ctx)
@@ -56,10 +69,27 @@ func (t *TUN) Start() error {
 	}
 	t.stack = stack
 
+	if t.config.AutoRoute {
+		autoRoute, err := setupAutoRoute(t.config)
+		if err != nil {
+			t.stack.Close()
+			t.stack.Wait()
+			t.stack = nil
+			return newError("failed to set up auto route").Base(err).AtError()
+		}
+		t.autoRoute = autoRoute
+	}
+
 	return nil
 }
 
 func (t *TUN) Close() error {
+	if t.autoRoute != nil {
+		if err := t.autoRoute.Close(); err != nil {
+			newError("failed to clean up auto route").Base(err).AtWarning().WriteToLog()
+		}
+		t.autoRoute = nil
+	}
 	if t.stack != nil {
 		t.stack.Close()
 		t.stack.Wait()
@@ -67,11 +97,21 @@ func (t *TUN) Close() error {
 	return nil
 }
 
-func (t *TUN) Init(ctx context.Context, config *Config, dispatcher routing.Dispatcher, policyManager policy.Manager) error {
+func (t *TUN) Init(ctx context.Context, config *Config, dispatcher routing.Dispatcher, policyManager policy.Manager, dnsClient dns.Client, router routing.Router, ohm outbound.Manager) error {
 	t.ctx = ctx
 	t.config = config
 	t.dispatcher = dispatcher
 	t.policyManager = policyManager
+	t.router = router
+	t.ohm = ohm
+
+	if config.DnsHijack {
+		hijacker, err := newDNSHijacker(dnsClient)
+		if err != nil {
+			return newError("failed to enable DNS hijack").Base(err)
+		}
+		t.dnsHijacker = hijacker
+	}
 
 	return nil
 }
@@ -79,8 +119,8 @@ func (t *TUN) Init(ctx context.Context, config *Config, dispatcher routing.Dispa
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
 		tun := new(TUN)
-		err := core.RequireFeatures(ctx, func(d routing.Dispatcher, p policy.Manager) error {
-			return tun.Init(ctx, config.(*Config), d, p)
+		err := core.RequireFeatures(ctx, func(d routing.Dispatcher, p policy.Manager, c dns.Client, r routing.Router, om outbound.Manager) error {
+			return tun.Init(ctx, config.(*Config), d, p, c, r, om)
 		})
 		return tun, err
 	}))
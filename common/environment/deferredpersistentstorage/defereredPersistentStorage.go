@@ -62,6 +62,9 @@ func (d *deferredPersistentStorage) Clear(ctx context.Context) {
 
 func (d *deferredPersistentStorage) NarrowScope(ctx context.Context, key []byte) (storage.ScopedPersistentStorage, error) {
 	if d.ready.Err() != nil {
+		if d.inner == nil {
+			return nil, errNotExist
+		}
 		return d.inner.NarrowScope(ctx, key)
 	}
 	ready, done := context.WithCancel(ctx)
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+)
+
+// jsonTimeLayout is the layout of the time of JSON logs, RFC 3339 in milliseconds.
+const jsonTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+type jsonGeneralMessage struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+type jsonAccessBytes struct {
+	Uplink   int64 `json:"uplink"`
+	Downlink int64 `json:"downlink"`
+}
+
+type jsonAccessMessage struct {
+	Time     string          `json:"time"`
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Status   string          `json:"status"`
+	Reason   string          `json:"reason,omitempty"`
+	Email    string          `json:"email,omitempty"`
+	Detour   string          `json:"detour,omitempty"`
+	Protocol string          `json:"protocol,omitempty"`
+	Method   string          `json:"method,omitempty"`
+	Bytes    jsonAccessBytes `json:"bytes"`
+	// Duration is in milliseconds.
+	Duration int64 `json:"duration"`
+}
+
+// FormatJSON returns msg as a JSON object, with the current time. Fields of
+// GeneralMessages are time, level and message; fields of AccessMessages are
+// time, from, to, status, reason, email, detour, protocol, method, bytes of
+// uplink and downlink, and duration in milliseconds.
+func FormatJSON(msg Message) string {
+	now := time.Now().Format(jsonTimeLayout)
+
+	var v interface{}
+	switch msg := msg.(type) {
+	case *AccessMessage:
+		v = &jsonAccessMessage{
+			Time:     now,
+			From:     serial.ToString(msg.From),
+			To:       serial.ToString(msg.To),
+			Status:   string(msg.Status),
+			Reason:   serial.ToString(msg.Reason),
+			Email:    msg.Email,
+			Detour:   msg.Detour,
+			Protocol: msg.Protocol,
+			Method:   msg.Method,
+			Bytes:    jsonAccessBytes{Uplink: msg.Upload, Downlink: msg.Download},
+			Duration: msg.Duration.Milliseconds(),
+		}
+	case *GeneralMessage:
+		v = &jsonGeneralMessage{
+			Time:    now,
+			Level:   strings.ToLower(msg.Severity.String()),
+			Message: serial.ToString(msg.Content),
+		}
+	default:
+		v = &jsonGeneralMessage{
+			Time:    now,
+			Message: msg.String(),
+		}
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return msg.String()
+	}
+	return string(b)
+}
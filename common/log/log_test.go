@@ -1,7 +1,9 @@
 package log_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -31,3 +33,50 @@ func TestLogRecord(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestFormatJSON(t *testing.T) {
+	var access map[string]interface{}
+	if err := json.Unmarshal([]byte(log.FormatJSON(&log.AccessMessage{
+		From:     net.TCPDestination(net.ParseAddress("127.0.0.1"), 50000),
+		To:       "tcp:example.com:443",
+		Status:   log.AccessAccepted,
+		Email:    "love@v2fly.org",
+		Detour:   "direct",
+		Protocol: "http",
+		Method:   "CONNECT",
+		Upload:   100,
+		Download: 200,
+		Duration: 1500 * time.Millisecond,
+	})), &access); err != nil {
+		t.Fatal(err)
+	}
+	delete(access, "time")
+	if diff := cmp.Diff(map[string]interface{}{
+		"from":     "tcp:127.0.0.1:50000",
+		"to":       "tcp:example.com:443",
+		"status":   "accepted",
+		"email":    "love@v2fly.org",
+		"detour":   "direct",
+		"protocol": "http",
+		"method":   "CONNECT",
+		"bytes":    map[string]interface{}{"uplink": 100.0, "downlink": 200.0},
+		"duration": 1500.0,
+	}, access); diff != "" {
+		t.Error(diff)
+	}
+
+	var general map[string]interface{}
+	if err := json.Unmarshal([]byte(log.FormatJSON(&log.GeneralMessage{
+		Severity: log.Severity_Warning,
+		Content:  "test",
+	})), &general); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := time.Parse(time.RFC3339, general["time"].(string)); err != nil {
+		t.Error(err)
+	}
+	delete(general, "time")
+	if diff := cmp.Diff(map[string]interface{}{"level": "warning", "message": "test"}, general); diff != "" {
+		t.Error(diff)
+	}
+}
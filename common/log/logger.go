@@ -22,6 +22,7 @@ type WriterCreator func() Writer
 
 type generalLogger struct {
 	creator WriterCreator
+	format  func(Message) string
 	buffer  chan Message
 	access  *semaphore.Instance
 	done    *done.Instance
@@ -31,6 +32,19 @@ type generalLogger struct {
 func NewLogger(logWriterCreator WriterCreator) Handler {
 	return &generalLogger{
 		creator: logWriterCreator,
+		format:  Message.String,
+		buffer:  make(chan Message, 16),
+		access:  semaphore.New(1),
+		done:    done.New(),
+	}
+}
+
+// NewJSONLogger returns a log handler that writes messages as lines of JSON
+// objects. As the objects include the time, the LogWriters should not prefix it.
+func NewJSONLogger(logWriterCreator WriterCreator) Handler {
+	return &generalLogger{
+		creator: logWriterCreator,
+		format:  FormatJSON,
 		buffer:  make(chan Message, 16),
 		access:  semaphore.New(1),
 		done:    done.New(),
@@ -55,7 +69,7 @@ func (l *generalLogger) run() {
 		case <-l.done.Wait():
 			return
 		case msg := <-l.buffer:
-			logger.Write(msg.String() + platform.LineSeparator())
+			logger.Write(l.format(msg) + platform.LineSeparator())
 			dataWritten = true
 		case <-ticker.C:
 			if !dataWritten {
@@ -97,7 +111,7 @@ func (w *consoleLogWriter) Close() error {
 }
 
 type fileLogWriter struct {
-	file   *os.File
+	file   io.Closer
 	logger *log.Logger
 }
 
@@ -112,9 +126,15 @@ func (w *fileLogWriter) Close() error {
 
 // CreateStdoutLogWriter returns a LogWriterCreator that creates LogWriter for stdout.
 func CreateStdoutLogWriter() WriterCreator {
+	return CreateStdoutLogWriterWithFlag(log.Ldate | log.Ltime)
+}
+
+// CreateStdoutLogWriterWithFlag returns a LogWriterCreator that creates
+// LogWriter for stdout, with the flag of log.Logger for the prefix of logs.
+func CreateStdoutLogWriterWithFlag(flag int) WriterCreator {
 	return func() Writer {
 		return &consoleLogWriter{
-			logger: log.New(os.Stdout, "", log.Ldate|log.Ltime),
+			logger: log.New(os.Stdout, "", flag),
 		}
 	}
 }
@@ -128,13 +148,38 @@ func CreateStderrLogWriter() WriterCreator {
 	}
 }
 
+// FileLogWriterOptions are the options of LogWriters for files.
+type FileLogWriterOptions struct {
+	// Flag is the flag of log.Logger for the prefix of logs.
+	Flag int
+	// Rotation is the rotation of the file. The file is never rotated if it is zero.
+	Rotation RotationOptions
+}
+
 // CreateFileLogWriter returns a LogWriterCreator that creates LogWriter for the given file.
 func CreateFileLogWriter(path string) (WriterCreator, error) {
+	return CreateFileLogWriterWithOptions(path, FileLogWriterOptions{Flag: log.Ldate | log.Ltime})
+}
+
+// CreateFileLogWriterWithOptions returns a LogWriterCreator that creates
+// LogWriter for the given file, with options.
+func CreateFileLogWriterWithOptions(path string, options FileLogWriterOptions) (WriterCreator, error) {
 	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
 	if err != nil {
 		return nil, err
 	}
 	file.Close()
+	if options.Rotation.enabled() {
+		// The rotating file is shared by LogWriters, which are recreated once the
+		// logger is idle, so that the size and period of the file are kept.
+		rotating := newRotatingFile(path, options.Rotation)
+		return func() Writer {
+			return &fileLogWriter{
+				file:   rotating,
+				logger: log.New(rotating, "", options.Flag),
+			}
+		}, nil
+	}
 	return func() Writer {
 		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
 		if err != nil {
@@ -142,7 +187,7 @@ func CreateFileLogWriter(path string) (WriterCreator, error) {
 		}
 		return &fileLogWriter{
 			file:   file,
-			logger: log.New(file, "", log.Ldate|log.Ltime),
+			logger: log.New(file, "", options.Flag),
 		}
 	}, nil
 }
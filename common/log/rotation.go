@@ -0,0 +1,178 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotationTimeLayout is the layout of the time suffix of rotated files.
+const rotationTimeLayout = "20060102-150405.000"
+
+// RotationOptions are the options of rotating a log file. Rotated files are
+// renamed with the time of rotation as the suffix, e.g. "access.log.20060102-150405.000".
+type RotationOptions struct {
+	// MaxSize is the size in bytes, beyond which the file is rotated. Zero for no limit.
+	MaxSize int64
+	// Interval is the period of the file, at the end of which the file is
+	// rotated. Periods are aligned to UTC, e.g. days start at 00:00 UTC. Zero
+	// for no limit.
+	Interval time.Duration
+	// MaxBackups is the number of rotated files to retain. Zero to retain all.
+	MaxBackups int
+	// MaxAge is the age of rotated files to retain. Zero to retain all.
+	MaxAge time.Duration
+}
+
+func (o RotationOptions) enabled() bool {
+	return o.MaxSize > 0 || o.Interval > 0
+}
+
+// rotatingFile is an io.WriteCloser that writes to a file and rotates it. The
+// file is reopened on next Write once it is closed.
+type rotatingFile struct {
+	sync.Mutex
+	path    string
+	options RotationOptions
+
+	file   *os.File
+	size   int64
+	period time.Time
+
+	now func() time.Time
+}
+
+func newRotatingFile(path string, options RotationOptions) *rotatingFile {
+	return &rotatingFile{
+		path:    path,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	// An existing file belongs to the period when it was last written.
+	created := f.now()
+	if f.size > 0 {
+		created = info.ModTime()
+	}
+	f.period = f.periodOf(created)
+	return nil
+}
+
+func (f *rotatingFile) periodOf(t time.Time) time.Time {
+	if f.options.Interval <= 0 {
+		return time.Time{}
+	}
+	return t.Truncate(f.options.Interval)
+}
+
+func (f *rotatingFile) shouldRotate(n int, now time.Time) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.options.MaxSize > 0 && f.size+int64(n) > f.options.MaxSize {
+		return true
+	}
+	return f.options.Interval > 0 && !f.periodOf(now).Equal(f.period)
+}
+
+func (f *rotatingFile) rotate(now time.Time) error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	if err := os.Rename(f.path, f.path+"."+now.Format(rotationTimeLayout)); err != nil {
+		return err
+	}
+	if f.options.MaxBackups > 0 || f.options.MaxAge > 0 {
+		go f.removeBackups(now)
+	}
+	return f.open()
+}
+
+// removeBackups removes rotated files beyond MaxBackups or older than MaxAge.
+func (f *rotatingFile) removeBackups(now time.Time) {
+	dir, base := filepath.Split(f.path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		path string
+		time time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		suffix := strings.TrimPrefix(entry.Name(), base+".")
+		if entry.IsDir() || len(suffix) == len(entry.Name()) {
+			continue
+		}
+		t, err := time.ParseInLocation(rotationTimeLayout, suffix, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, entry.Name()), time: t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].time.After(backups[j].time)
+	})
+
+	for i, b := range backups {
+		if f.options.MaxBackups > 0 && i >= f.options.MaxBackups ||
+			f.options.MaxAge > 0 && now.Sub(b.time) > f.options.MaxAge {
+			os.Remove(b.path)
+		}
+	}
+}
+
+// Write implements io.Writer.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if now := f.now(); f.shouldRotate(len(p), now) {
+		if err := f.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close implements io.Closer.
+func (f *rotatingFile) Close() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
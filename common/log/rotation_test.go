@@ -0,0 +1,94 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func write(t *testing.T, f *rotatingFile, s string) {
+	t.Helper()
+	_, err := f.Write([]byte(s))
+	must(t, err)
+}
+
+func readDir(t *testing.T, dir string) (current string, backups []string) {
+	entries, err := os.ReadDir(dir)
+	must(t, err)
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		must(t, err)
+		if entry.Name() == "access.log" {
+			current = string(b)
+		} else {
+			backups = append(backups, string(b))
+		}
+	}
+	return
+}
+
+func TestRotatingFileBySize(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	f := newRotatingFile(filepath.Join(dir, "access.log"), RotationOptions{MaxSize: 10, MaxBackups: 2})
+	f.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for _, s := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggg\n"} {
+		write(t, f, s)
+	}
+	must(t, f.Close())
+
+	// Backups are removed asynchronously.
+	var current string
+	var backups []string
+	for i := 0; i < 100; i++ {
+		if current, backups = readDir(t, dir); len(backups) == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if current != "gggg\n" {
+		t.Error("unexpected current file: ", current)
+	}
+	if strings.Join(backups, "|") != "cccc\ndddd\n|eeee\nffff\n" {
+		t.Error("unexpected backups: ", backups)
+	}
+}
+
+func TestRotatingFileByInterval(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+	path := filepath.Join(dir, "access.log")
+	f := newRotatingFile(path, RotationOptions{Interval: 24 * time.Hour})
+	f.now = func() time.Time { return now }
+
+	write(t, f, "day 1\n")
+	must(t, f.Close())
+	// The reopened file belongs to the period when it was last written.
+	must(t, os.Chtimes(path, now, now))
+	now = now.Add(30 * time.Minute)
+	write(t, f, "day 1\n")
+	now = now.Add(time.Hour)
+	write(t, f, "day 2\n")
+	must(t, f.Close())
+
+	current, backups := readDir(t, dir)
+	if current != "day 2\n" {
+		t.Error("unexpected current file: ", current)
+	}
+	if len(backups) != 1 || backups[0] != "day 1\nday 1\n" {
+		t.Error("unexpected backups: ", backups)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/common/buf"
 	"github.com/frogwall/f2ray-core/v5/common/errors"
 	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/net/packetaddr"
 	"github.com/frogwall/f2ray-core/v5/common/protocol"
 	"github.com/frogwall/f2ray-core/v5/common/session"
 	"github.com/frogwall/f2ray-core/v5/common/signal/done"
@@ -263,6 +264,16 @@ func fetchInput(ctx context.Context, s *Session, output buf.Writer) {
 	defer writer.Close()
 
 	newError("dispatching request to ", dest).WriteToLog(session.ExportIDToError(ctx))
+	if s.packetConn != nil {
+		writer.globalID = globalIDFromContext(ctx)
+		if err := fetchPackets(s.packetConn, writer); err != nil && errors.Cause(err) != io.EOF {
+			newError("failed to fetch all packets").Base(err).WriteToLog(session.ExportIDToError(ctx))
+			writer.hasError = true
+			common.Interrupt(s.input)
+		}
+		return
+	}
+
 	if err := writeFirstPayload(s.input, writer); err != nil {
 		newError("failed to write first payload").Base(err).WriteToLog(session.ExportIDToError(ctx))
 		writer.hasError = true
@@ -310,6 +321,12 @@ func (m *ClientWorker) Dispatch(ctx context.Context, link *transport.Link) bool
 	}
 	s.input = link.Reader
 	s.output = link.Writer
+	// Sessions to the packetaddr destination are carried in XUDP, for full-cone UDP.
+	// Other UDP sessions stay plain Mux sessions to their destinations, as their
+	// packets carry no address.
+	if conn, err := packetaddr.ToPacketAddrConn(link, session.OutboundFromContext(ctx).Target); err == nil {
+		s.packetConn = conn
+	}
 	go fetchInput(ctx, s, m.link.Writer)
 	return true
 }
@@ -343,7 +360,11 @@ func (m *ClientWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 	}
 
 	rr := s.NewReader(reader)
-	err := buf.Copy(rr, s.output)
+	output := s.output
+	if s.packetConn != nil {
+		output = s.newPacketWriter(meta)
+	}
+	err := buf.Copy(rr, output)
 	if err != nil && buf.IsWriteError(err) {
 		newError("failed to write to downstream. closing session ", s.ID).Base(err).WriteToLog()
 
@@ -51,6 +51,10 @@ Frame format
 2 bytes - port
 n bytes - address
 
+8 bytes - GlobalID, of new sessions of XUDP only
+
+Frames of SessionStatusKeep of XUDP carry the network and the address of the
+packet, as frames of SessionStatusNew do.
 */
 
 type FrameMetadata struct {
@@ -58,6 +62,9 @@ type FrameMetadata struct {
 	SessionID     uint16
 	Option        bitmask.Byte
 	SessionStatus SessionStatus
+	// GlobalID identifies the UDP socket of the client of a XUDP session. It is
+	// zero for sessions other than XUDP.
+	GlobalID [8]byte
 }
 
 func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
@@ -70,7 +77,7 @@ func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
 	common.Must(b.WriteByte(byte(f.SessionStatus)))
 	common.Must(b.WriteByte(byte(f.Option)))
 
-	if f.SessionStatus == SessionStatusNew {
+	if f.SessionStatus == SessionStatusNew || f.SessionStatus == SessionStatusKeep && f.Target.Network == net.Network_UDP {
 		switch f.Target.Network {
 		case net.Network_TCP:
 			common.Must(b.WriteByte(byte(TargetNetworkTCP)))
@@ -81,6 +88,10 @@ func (f FrameMetadata) WriteTo(b *buf.Buffer) error {
 		if err := addrParser.WriteAddressPort(b, f.Target.Address, f.Target.Port); err != nil {
 			return err
 		}
+
+		if f.SessionStatus == SessionStatusNew && f.GlobalID != [8]byte{} {
+			common.Must2(b.Write(f.GlobalID[:]))
+		}
 	}
 
 	len1 := b.Len()
@@ -117,9 +128,10 @@ func (f *FrameMetadata) UnmarshalFromBuffer(b *buf.Buffer) error {
 	f.SessionID = binary.BigEndian.Uint16(b.BytesTo(2))
 	f.SessionStatus = SessionStatus(b.Byte(2))
 	f.Option = bitmask.Byte(b.Byte(3))
-	f.Target.Network = net.Network_Unknown
+	f.Target = net.Destination{}
+	f.GlobalID = [8]byte{}
 
-	if f.SessionStatus == SessionStatusNew {
+	if f.SessionStatus == SessionStatusNew || f.SessionStatus == SessionStatusKeep && b.Len() > 4 && TargetNetwork(b.Byte(4)) == TargetNetworkUDP {
 		if b.Len() < 8 {
 			return newError("insufficient buffer: ", b.Len())
 		}
@@ -139,6 +151,10 @@ func (f *FrameMetadata) UnmarshalFromBuffer(b *buf.Buffer) error {
 		default:
 			return newError("unknown network type: ", network)
 		}
+
+		if f.SessionStatus == SessionStatusNew && network == TargetNetworkUDP && b.Len() >= 8 {
+			copy(f.GlobalID[:], b.BytesTo(8))
+		}
 	}
 
 	return nil
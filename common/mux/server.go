@@ -19,11 +19,14 @@ import (
 
 type Server struct {
 	dispatcher routing.Dispatcher
+	xudp       *xudpManager
 }
 
 // NewServer creates a new mux.Server.
 func NewServer(ctx context.Context) *Server {
-	s := &Server{}
+	s := &Server{
+		xudp: newXUDPManager(),
+	}
 	core.RequireFeatures(ctx, func(d routing.Dispatcher) {
 		s.dispatcher = d
 	})
@@ -45,10 +48,10 @@ func (s *Server) Dispatch(ctx context.Context, dest net.Destination) (*transport
 	uplinkReader, uplinkWriter := pipe.New(opts...)
 	downlinkReader, downlinkWriter := pipe.New(opts...)
 
-	_, err := NewServerWorker(ctx, s.dispatcher, &transport.Link{
+	_, err := newServerWorker(ctx, s.dispatcher, &transport.Link{
 		Reader: uplinkReader,
 		Writer: downlinkWriter,
-	})
+	}, s.xudp)
 	if err != nil {
 		return nil, err
 	}
@@ -63,20 +66,28 @@ func (s *Server) Start() error {
 
 // Close implements common.Closable.
 func (s *Server) Close() error {
-	return nil
+	return s.xudp.Close()
 }
 
 type ServerWorker struct {
 	dispatcher     routing.Dispatcher
 	link           *transport.Link
 	sessionManager *SessionManager
+	xudp           *xudpManager
 }
 
+// NewServerWorker creates a ServerWorker, whose XUDP sessions are not shared
+// with other workers.
 func NewServerWorker(ctx context.Context, d routing.Dispatcher, link *transport.Link) (*ServerWorker, error) {
+	return newServerWorker(ctx, d, link, newXUDPManager())
+}
+
+func newServerWorker(ctx context.Context, d routing.Dispatcher, link *transport.Link, xudp *xudpManager) (*ServerWorker, error) {
 	worker := &ServerWorker{
 		dispatcher:     d,
 		link:           link,
 		sessionManager: NewSessionManager(),
+		xudp:           xudp,
 	}
 	go worker.run(ctx)
 	return worker, nil
@@ -122,6 +133,9 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 		}
 		ctx = log.ContextWithAccessMessage(ctx, msg)
 	}
+	if meta.Target.Network == net.Network_UDP && meta.GlobalID != [8]byte{} {
+		return w.handleStatusNewXUDP(ctx, meta, reader)
+	}
 	link, err := w.dispatcher.Dispatch(ctx, meta.Target)
 	if err != nil {
 		if meta.Option.Has(OptionData) {
@@ -138,6 +152,7 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 	}
 	if meta.Target.Network == net.Network_UDP {
 		s.transferType = protocol.TransferTypePacket
+		s.target = meta.Target
 	}
 	w.sessionManager.Add(s)
 	go handle(ctx, s, w.link.Writer)
@@ -154,6 +169,46 @@ func (w *ServerWorker) handleStatusNew(ctx context.Context, meta *FrameMetadata,
 	return nil
 }
 
+// handleStatusNewXUDP attaches a new session to the XUDP session of its
+// GlobalID, which is dispatched to the packetaddr destination if absent.
+func (w *ServerWorker) handleStatusNewXUDP(ctx context.Context, meta *FrameMetadata, reader *buf.BufferedReader) error {
+	if !meta.Target.Address.Family().IsIP() {
+		// Full-cone sessions to domains are not supported, see packetaddr.
+		closingWriter := NewResponseWriter(meta.SessionID, w.link.Writer, protocol.TransferTypePacket)
+		closingWriter.hasError = true
+		closingWriter.Close()
+		if meta.Option.Has(OptionData) {
+			buf.Copy(NewStreamReader(reader), buf.Discard)
+		}
+		newError("XUDP session to domain ", meta.Target, " rejected").AtInfo().WriteToLog(session.ExportIDToError(ctx))
+		return nil
+	}
+	s := &Session{
+		parent:       w.sessionManager,
+		ID:           meta.SessionID,
+		transferType: protocol.TransferTypePacket,
+		target:       meta.Target,
+	}
+	writer := NewResponseWriter(s.ID, w.link.Writer, s.transferType)
+	if err := w.xudp.attach(ctx, w.dispatcher, meta.GlobalID, s, writer); err != nil {
+		if meta.Option.Has(OptionData) {
+			buf.Copy(NewStreamReader(reader), buf.Discard)
+		}
+		return newError("failed to dispatch XUDP request.").Base(err)
+	}
+	w.sessionManager.Add(s)
+	if !meta.Option.Has(OptionData) {
+		return nil
+	}
+
+	rr := s.NewReader(reader)
+	if err := buf.Copy(rr, s.newPacketWriter(meta)); err != nil {
+		buf.Copy(rr, buf.Discard)
+		return s.Close()
+	}
+	return nil
+}
+
 func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.BufferedReader) error {
 	if !meta.Option.Has(OptionData) {
 		return nil
@@ -169,7 +224,15 @@ func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 	}
 
 	rr := s.NewReader(reader)
-	err := buf.Copy(rr, s.output)
+	output := s.output
+	if s.packetConn != nil {
+		output = s.newPacketWriter(meta)
+	} else if meta.Target.Network == net.Network_UDP && meta.Target != s.target {
+		// Packets of a session of fixed destination are not sent elsewhere.
+		newError("dropping packet of session ", s.ID, " to ", meta.Target).AtInfo().WriteToLog()
+		output = buf.Discard
+	}
+	err := buf.Copy(rr, output)
 
 	if err != nil && buf.IsWriteError(err) {
 		newError("failed to write to downstream writer. closing session ", s.ID).Base(err).WriteToLog()
@@ -189,7 +252,8 @@ func (w *ServerWorker) handleStatusKeep(meta *FrameMetadata, reader *buf.Buffere
 
 func (w *ServerWorker) handleStatusEnd(meta *FrameMetadata, reader *buf.BufferedReader) error {
 	if s, found := w.sessionManager.Get(meta.SessionID); found {
-		if meta.Option.Has(OptionError) {
+		// A XUDP session is kept for the next Mux session of its GlobalID.
+		if meta.Option.Has(OptionError) && s.xudp == nil {
 			common.Interrupt(s.input)
 			common.Interrupt(s.output)
 		}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/protocol"
 )
 
@@ -126,6 +127,10 @@ func (m *SessionManager) Close() error {
 	m.closed = true
 
 	for _, s := range m.sessions {
+		if s.xudp != nil {
+			s.xudp.detach(s)
+			continue
+		}
 		common.Close(s.input)
 		common.Close(s.output)
 	}
@@ -141,12 +146,23 @@ type Session struct {
 	parent       *SessionManager
 	ID           uint16
 	transferType protocol.TransferType
+
+	// packetConn exchanges the packets of a XUDP session, with their addresses.
+	packetConn net.PacketConn
+	// target is the address of packets in frames without one.
+	target net.Destination
+	// xudp is set on the server, where the XUDP session outlives this session.
+	xudp *xudpSession
 }
 
 // Close closes all resources associated with this session.
 func (s *Session) Close() error {
-	common.Close(s.output)
-	common.Close(s.input)
+	if s.xudp != nil {
+		s.xudp.detach(s)
+	} else {
+		common.Close(s.output)
+		common.Close(s.input)
+	}
 	s.parent.Remove(s.ID)
 	return nil
 }
@@ -16,6 +16,7 @@ type Writer struct {
 	followup     bool
 	hasError     bool
 	transferType protocol.TransferType
+	globalID     [8]byte
 }
 
 func NewWriter(id uint16, dest net.Destination, writer buf.Writer, transferType protocol.TransferType) *Writer {
@@ -40,7 +41,6 @@ func NewResponseWriter(id uint16, writer buf.Writer, transferType protocol.Trans
 func (w *Writer) getNextFrameMeta() FrameMetadata {
 	meta := FrameMetadata{
 		SessionID: w.id,
-		Target:    w.dest,
 	}
 
 	if w.followup {
@@ -48,6 +48,7 @@ func (w *Writer) getNextFrameMeta() FrameMetadata {
 	} else {
 		w.followup = true
 		meta.SessionStatus = SessionStatusNew
+		meta.Target = w.dest
 	}
 
 	return meta
@@ -88,6 +89,18 @@ func (w *Writer) writeData(mb buf.MultiBuffer) error {
 	return writeMetaWithFrame(w.writer, meta, mb)
 }
 
+// writePacket writes a packet of XUDP in a frame, with the address of the packet.
+func (w *Writer) writePacket(b *buf.Buffer, dest net.Destination) error {
+	meta := w.getNextFrameMeta()
+	meta.Target = dest
+	if meta.SessionStatus == SessionStatusNew {
+		meta.GlobalID = w.globalID
+	}
+	meta.Option.Set(OptionData)
+
+	return writeMetaWithFrame(w.writer, meta, buf.MultiBuffer{b})
+}
+
 // WriteMultiBuffer implements buf.Writer.
 func (w *Writer) WriteMultiBuffer(mb buf.MultiBuffer) error {
 	defer buf.ReleaseMulti(mb)
@@ -0,0 +1,312 @@
+package mux
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"lukechampine.com/blake3"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/net/packetaddr"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/task"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+)
+
+// XUDP carries full-cone UDP in Mux sessions, compatible with Xray. Every
+// packet is carried in a frame of its own, with its address. A new session
+// carries a GlobalID, which identifies the UDP socket of the client. The
+// server keeps the session of a GlobalID for xudpExpiration after its Mux
+// session ends, and a new Mux session of the same user and GlobalID takes it
+// over, so that the mapping of the NAT survives the reconnection of Mux.
+//
+// Proxies exchange packets of XUDP with their addresses in the format of
+// packetaddr: the client carries sessions dispatched to the packetaddr
+// destination in XUDP, and the server dispatches XUDP sessions to it. As in
+// packetaddr, packets to domains are dropped, and new sessions to domains are
+// rejected.
+//
+// Only links dispatched to the packetaddr destination, such as those of socks
+// inbounds with the packet encoding, are carried in XUDP. Other UDP links
+// carry no addresses in their packets, so each of them is carried in a plain
+// Mux session to its own destination.
+
+// xudpExpiration is how long a XUDP session is kept after its Mux session ends.
+const xudpExpiration = time.Minute
+
+var xudpBaseKey = func() []byte {
+	key := make([]byte, 32)
+	common.Must2(rand.Read(key))
+	return key
+}()
+
+// globalIDFromContext derives the GlobalID of a XUDP session from its inbound
+// source, so that sessions of the same socket of the client share it.
+func globalIDFromContext(ctx context.Context) (globalID [8]byte) {
+	if inbound := session.InboundFromContext(ctx); inbound != nil && inbound.Source.IsValid() {
+		h := blake3.New(8, xudpBaseKey)
+		h.Write([]byte(inbound.Source.String()))
+		copy(globalID[:], h.Sum(nil))
+		return
+	}
+	common.Must2(rand.Read(globalID[:]))
+	return
+}
+
+// fetchPackets writes the packets read from conn in frames, with their addresses.
+func fetchPackets(conn net.PacketConn, writer *Writer) error {
+	for {
+		b := buf.New()
+		n, addr, err := conn.ReadFrom(b.Extend(buf.Size))
+		if err != nil {
+			b.Release()
+			return err
+		}
+		b.Resize(0, int32(n))
+		if err := writer.writePacket(b, net.DestinationFromAddr(addr)); err != nil {
+			return err
+		}
+	}
+}
+
+// packetWriter writes packets to the PacketConn of a XUDP session.
+type packetWriter struct {
+	conn net.PacketConn
+	dest net.Destination
+}
+
+// newPacketWriter creates a buf.Writer of the packets in a frame, to the
+// address in the frame, or the target of the session if the frame has none.
+func (s *Session) newPacketWriter(meta *FrameMetadata) buf.Writer {
+	dest := s.target
+	if meta.Target.Network == net.Network_UDP {
+		dest = meta.Target
+	}
+	return &packetWriter{
+		conn: s.packetConn,
+		dest: dest,
+	}
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (w *packetWriter) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+
+	if w.dest.Address == nil || w.dest.Address.Family().IsDomain() {
+		newError("dropping packet to ", w.dest).AtDebug().WriteToLog()
+		return nil
+	}
+	addr := &net.UDPAddr{IP: w.dest.Address.IP(), Port: int(w.dest.Port.Value())}
+	for _, b := range mb {
+		if _, err := w.conn.WriteTo(b.Bytes(), addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// xudpKey identifies a XUDP session on the server. GlobalIDs are chosen by
+// clients, so sessions of different users are kept apart.
+type xudpKey struct {
+	user     *protocol.MemoryUser
+	globalID [8]byte
+}
+
+// xudpSession is the full-cone UDP session of a GlobalID on the server.
+type xudpSession struct {
+	key     xudpKey
+	conn    net.PacketConn
+	manager *xudpManager
+
+	access sync.Mutex
+	owner  *Session
+	writer *Writer
+	expire time.Time
+}
+
+// attach makes s the Mux session of x, which receives packets from now on.
+// It returns the previous Mux session, if any, which is to be ended.
+func (x *xudpSession) attach(s *Session, writer *Writer) (*Session, *Writer) {
+	x.access.Lock()
+	defer x.access.Unlock()
+
+	previous, previousWriter := x.owner, x.writer
+	x.owner = s
+	x.writer = writer
+	return previous, previousWriter
+}
+
+// detach detaches s from x, and x expires unless another Mux session takes it over.
+func (x *xudpSession) detach(s *Session) {
+	x.access.Lock()
+	defer x.access.Unlock()
+
+	if x.owner != s {
+		return
+	}
+	x.owner = nil
+	x.writer = nil
+	x.expire = time.Now().Add(xudpExpiration)
+}
+
+func (x *xudpSession) expired(now time.Time) bool {
+	x.access.Lock()
+	defer x.access.Unlock()
+
+	return x.owner == nil && now.After(x.expire)
+}
+
+// run writes the packets of x to its current Mux session, until conn is closed.
+func (x *xudpSession) run() {
+	for {
+		b := buf.New()
+		n, addr, err := x.conn.ReadFrom(b.Extend(buf.Size))
+		if err != nil {
+			b.Release()
+			break
+		}
+		b.Resize(0, int32(n))
+
+		x.access.Lock()
+		writer := x.writer
+		x.access.Unlock()
+		if writer == nil {
+			b.Release()
+			continue
+		}
+		if err := writer.writePacket(b, net.DestinationFromAddr(addr)); err != nil {
+			newError("failed to write packet of XUDP session").Base(err).AtDebug().WriteToLog()
+		}
+	}
+
+	x.manager.remove(x)
+	endSession(x.attach(nil, nil))
+}
+
+// endSession ends a Mux session detached from its XUDP session.
+func endSession(s *Session, writer *Writer) {
+	if s != nil {
+		writer.Close()
+		s.parent.Remove(s.ID)
+	}
+}
+
+// xudpManager holds the XUDP sessions of a Server, which are closed with it.
+type xudpManager struct {
+	access      sync.Mutex
+	sessions    map[xudpKey]*xudpSession
+	cleanupTask *task.Periodic
+	closed      bool
+}
+
+func newXUDPManager() *xudpManager {
+	m := &xudpManager{
+		sessions: make(map[xudpKey]*xudpSession),
+	}
+	m.cleanupTask = &task.Periodic{
+		Interval: time.Second * 30,
+		Execute:  m.cleanupFunc,
+	}
+	return m
+}
+
+// attach attaches s to the XUDP session of globalID of the inbound user, which
+// is dispatched if absent, and ends the Mux session it was attached to.
+func (m *xudpManager) attach(ctx context.Context, dispatcher routing.Dispatcher, globalID [8]byte, s *Session, writer *Writer) error {
+	key := xudpKey{globalID: globalID}
+	if inbound := session.InboundFromContext(ctx); inbound != nil {
+		key.user = inbound.User
+	}
+	previous, previousWriter, start, err := m.attachInternal(ctx, dispatcher, key, s, writer)
+	if err != nil {
+		return err
+	}
+	if start {
+		common.Must(m.cleanupTask.Start())
+	}
+	if previous != nil {
+		newError("XUDP session taken over by session ", s.ID).AtDebug().WriteToLog(session.ExportIDToError(ctx))
+	}
+	endSession(previous, previousWriter)
+	return nil
+}
+
+func (m *xudpManager) attachInternal(ctx context.Context, dispatcher routing.Dispatcher, key xudpKey, s *Session, writer *Writer) (*Session, *Writer, bool, error) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if m.closed {
+		return nil, nil, false, newError("mux server closed")
+	}
+
+	if x, found := m.sessions[key]; found {
+		s.xudp = x
+		s.packetConn = x.conn
+		previous, previousWriter := x.attach(s, writer)
+		return previous, previousWriter, false, nil
+	}
+
+	// The session outlives the Mux connection it is dispatched from.
+	conn, err := packetaddr.CreatePacketAddrConn(context.WithoutCancel(ctx), dispatcher, false)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	x := &xudpSession{
+		key:     key,
+		conn:    conn,
+		manager: m,
+		owner:   s,
+		writer:  writer,
+	}
+	s.xudp = x
+	s.packetConn = conn
+	m.sessions[key] = x
+	go x.run()
+
+	return nil, nil, len(m.sessions) == 1, nil
+}
+
+func (m *xudpManager) remove(x *xudpSession) {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if m.sessions[x.key] == x {
+		delete(m.sessions, x.key)
+	}
+}
+
+func (m *xudpManager) cleanupFunc() error {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	if len(m.sessions) == 0 {
+		return newError("no XUDP session")
+	}
+
+	now := time.Now()
+	for key, x := range m.sessions {
+		if x.expired(now) {
+			delete(m.sessions, key)
+			x.conn.Close()
+		}
+	}
+	return nil
+}
+
+// Close closes all XUDP sessions.
+func (m *xudpManager) Close() error {
+	m.access.Lock()
+	defer m.access.Unlock()
+
+	m.closed = true
+	for key, x := range m.sessions {
+		delete(m.sessions, key)
+		x.conn.Close()
+	}
+	return m.cleanupTask.Close()
+}
@@ -0,0 +1,215 @@
+package mux
+
+import (
+	"context"
+	gonet "net"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/net/packetaddr"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport"
+	"github.com/frogwall/f2ray-core/v5/transport/pipe"
+)
+
+func TestXUDPFrame(t *testing.T) {
+	frames := []FrameMetadata{
+		{
+			SessionID:     1,
+			SessionStatus: SessionStatusNew,
+			Target:        net.UDPDestination(net.IPAddress([]byte{1, 2, 3, 4}), 53),
+			Option:        OptionData,
+			GlobalID:      [8]byte{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			SessionID:     1,
+			SessionStatus: SessionStatusKeep,
+			Target:        net.UDPDestination(net.IPAddress([]byte{5, 6, 7, 8}), 443),
+			Option:        OptionData,
+		},
+		{
+			SessionID:     1,
+			SessionStatus: SessionStatusKeep,
+			Option:        OptionData,
+		},
+	}
+
+	for _, frame := range frames {
+		b := buf.New()
+		common.Must(frame.WriteTo(b))
+
+		var meta FrameMetadata
+		common.Must(meta.Unmarshal(b))
+		if r := cmp.Diff(meta, frame); r != "" {
+			t.Error("metadata: ", r)
+		}
+		b.Release()
+	}
+}
+
+type xudpTestDispatcher struct {
+	sync.Mutex
+	dests []net.Destination
+	links chan *transport.Link
+}
+
+func (d *xudpTestDispatcher) Dispatch(ctx context.Context, dest net.Destination) (*transport.Link, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	d.dests = append(d.dests, dest)
+	d.links <- &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}
+	return &transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, nil
+}
+
+func (d *xudpTestDispatcher) Type() interface{} {
+	return routing.DispatcherType()
+}
+
+func (d *xudpTestDispatcher) Start() error {
+	return nil
+}
+
+func (d *xudpTestDispatcher) Close() error {
+	return nil
+}
+
+func writePacket(t *testing.T, writer buf.Writer, payload string, addr gonet.Addr) {
+	b, err := packetaddr.AttachAddressToPacket(buf.FromBytes([]byte(payload)), addr)
+	common.Must(err)
+	common.Must(writer.WriteMultiBuffer(buf.MultiBuffer{b}))
+}
+
+func readPacket(t *testing.T, reader buf.Reader, payload string, addr gonet.Addr) {
+	mb, err := reader.ReadMultiBuffer()
+	common.Must(err)
+	b, packetAddr, err := packetaddr.ExtractAddressFromPacket(mb[0])
+	common.Must(err)
+	if packetAddr.String() != addr.String() {
+		t.Error("address: ", packetAddr, ", expected ", addr)
+	}
+	if s := b.String(); s != payload {
+		t.Error("payload: ", s, ", expected ", payload)
+	}
+}
+
+// newXUDPConnection connects a ClientWorker to a ServerWorker of the user,
+// and dispatches a session to the packetaddr destination through them.
+func newXUDPConnection(t *testing.T, dispatcher routing.Dispatcher, manager *xudpManager, user *protocol.MemoryUser, source net.Destination) *transport.Link {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	serverCtx := session.ContextWithInbound(context.Background(), &session.Inbound{User: user})
+	_, err := newServerWorker(serverCtx, dispatcher, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}, manager)
+	common.Must(err)
+	client, err := NewClientWorker(transport.Link{Reader: downlinkReader, Writer: uplinkWriter}, ClientStrategy{})
+	common.Must(err)
+
+	ctx := session.ContextWithInbound(context.Background(), &session.Inbound{Source: source})
+	ctx = session.ContextWithOutbound(ctx, &session.Outbound{
+		Target: net.UDPDestination(net.DomainAddress("sp.packet-addr.v2fly.arpa"), 0),
+	})
+	appUplinkReader, appUplinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	appDownlinkReader, appDownlinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	if !client.Dispatch(ctx, &transport.Link{Reader: appUplinkReader, Writer: appDownlinkWriter}) {
+		t.Fatal("failed to dispatch")
+	}
+	return &transport.Link{Reader: appDownlinkReader, Writer: appUplinkWriter}
+}
+
+func TestXUDP(t *testing.T) {
+	dispatcher := &xudpTestDispatcher{links: make(chan *transport.Link, 2)}
+	manager := newXUDPManager()
+	defer manager.Close()
+	user := &protocol.MemoryUser{Email: "a@v2fly.org"}
+	source := net.UDPDestination(net.LocalHostIP, 10086)
+	dns := &gonet.UDPAddr{IP: gonet.IP{1, 2, 3, 4}, Port: 53}
+	peer := &gonet.UDPAddr{IP: gonet.IP{5, 6, 7, 8}, Port: 443}
+
+	app := newXUDPConnection(t, dispatcher, manager, user, source)
+	writePacket(t, app.Writer, "ping", dns)
+	outbound := <-dispatcher.links
+	readPacket(t, outbound.Reader, "ping", dns)
+
+	// Packets from any address reach the client, as of full-cone NAT.
+	writePacket(t, outbound.Writer, "pong", dns)
+	readPacket(t, app.Reader, "pong", dns)
+	writePacket(t, outbound.Writer, "hello", peer)
+	readPacket(t, app.Reader, "hello", peer)
+
+	// A new Mux connection of the same user and source takes over the session.
+	app2 := newXUDPConnection(t, dispatcher, manager, user, source)
+	writePacket(t, app2.Writer, "ping2", peer)
+	readPacket(t, outbound.Reader, "ping2", peer)
+	writePacket(t, outbound.Writer, "pong2", peer)
+	readPacket(t, app2.Reader, "pong2", peer)
+
+	// Another user of the same GlobalID gets a session of its own.
+	other := newXUDPConnection(t, dispatcher, manager, &protocol.MemoryUser{Email: "b@v2fly.org"}, source)
+	writePacket(t, other.Writer, "ping3", peer)
+	otherOutbound := <-dispatcher.links
+	readPacket(t, otherOutbound.Reader, "ping3", peer)
+
+	dispatcher.Lock()
+	defer dispatcher.Unlock()
+	if len(dispatcher.dests) != 2 {
+		t.Fatal("dispatched ", len(dispatcher.dests), " times, expected twice")
+	}
+	if dest := dispatcher.dests[0]; dest.Network != net.Network_UDP || dest.Address.String() != "sp.packet-addr.v2fly.arpa" {
+		t.Error("dispatched to ", dest)
+	}
+}
+
+// TestXUDPWireFormat checks frames of XUDP against the framing of Xray.
+func TestXUDPWireFormat(t *testing.T) {
+	output := buf.New()
+	defer output.Release()
+	writer := NewWriter(1, net.Destination{}, buf.NewWriter(output), protocol.TransferTypePacket)
+	writer.globalID = [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	common.Must(writer.writePacket(buf.FromBytes([]byte("ping")), net.UDPDestination(net.IPAddress([]byte{1, 2, 3, 4}), 53)))
+	common.Must(writer.writePacket(buf.FromBytes([]byte("pong")), net.UDPDestination(net.IPAddress([]byte{5, 6, 7, 8}), 443)))
+
+	expected := []byte{
+		// New: id, status, option, network, port, address, GlobalID
+		0, 20, 0, 1, 1, 1, 2, 0, 53, 1, 1, 2, 3, 4, 1, 2, 3, 4, 5, 6, 7, 8,
+		0, 4, 'p', 'i', 'n', 'g',
+		// Keep: id, status, option, network, port, address
+		0, 12, 0, 1, 2, 1, 2, 1, 187, 1, 5, 6, 7, 8,
+		0, 4, 'p', 'o', 'n', 'g',
+	}
+	if r := cmp.Diff(output.Bytes(), expected); r != "" {
+		t.Error("frames: ", r)
+	}
+}
+
+func TestXUDPDomainRejected(t *testing.T) {
+	uplinkReader, uplinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	downlinkReader, downlinkWriter := pipe.New(pipe.WithoutSizeLimit())
+	dispatcher := &xudpTestDispatcher{links: make(chan *transport.Link, 1)}
+	manager := newXUDPManager()
+	defer manager.Close()
+	_, err := newServerWorker(context.Background(), dispatcher, &transport.Link{Reader: uplinkReader, Writer: downlinkWriter}, manager)
+	common.Must(err)
+
+	writer := NewWriter(1, net.Destination{}, uplinkWriter, protocol.TransferTypePacket)
+	writer.globalID = [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	common.Must(writer.writePacket(buf.FromBytes([]byte("ping")), net.UDPDestination(net.DomainAddress("www.v2fly.org"), 443)))
+
+	var meta FrameMetadata
+	common.Must(meta.Unmarshal(&buf.BufferedReader{Reader: downlinkReader}))
+	if meta.SessionID != 1 || meta.SessionStatus != SessionStatusEnd || !meta.Option.Has(OptionError) {
+		t.Error("unexpected frame: ", meta)
+	}
+	if len(dispatcher.links) != 0 {
+		t.Error("session to domain dispatched")
+	}
+}
@@ -88,12 +88,20 @@ func UnixDestination(address Address) Destination {
 	}
 }
 
+// ICMPDestination creates an ICMP destination with given address
+func ICMPDestination(address Address) Destination {
+	return Destination{
+		Network: Network_ICMP,
+		Address: address,
+	}
+}
+
 // NetAddr returns the network address in this Destination in string form.
 func (d Destination) NetAddr() string {
 	addr := ""
 	if d.Network == Network_TCP || d.Network == Network_UDP {
 		addr = d.Address.String() + ":" + d.Port.String()
-	} else if d.Network == Network_UNIX {
+	} else if d.Network == Network_UNIX || d.Network == Network_ICMP {
 		addr = d.Address.String()
 	}
 	return addr
@@ -109,6 +117,8 @@ func (d Destination) String() string {
 		prefix = "udp:"
 	case Network_UNIX:
 		prefix = "unix:"
+	case Network_ICMP:
+		prefix = "icmp:"
 	}
 	return prefix + d.NetAddr()
 }
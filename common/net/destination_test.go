@@ -33,6 +33,12 @@ func TestDestinationProperty(t *testing.T) {
 			String:    "unix:/tmp/test.sock",
 			NetString: "/tmp/test.sock",
 		},
+		{
+			Input:     ICMPDestination(IPAddress([]byte{1, 2, 3, 4})),
+			Network:   Network_ICMP,
+			String:    "icmp:1.2.3.4",
+			NetString: "1.2.3.4",
+		},
 	}
 
 	for _, testCase := range testCases {
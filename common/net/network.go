@@ -15,6 +15,8 @@ func (n Network) SystemString() string {
 		return "udp"
 	case Network_UNIX:
 		return "unix"
+	case Network_ICMP:
+		return "icmp"
 	default:
 		return "unknown"
 	}
@@ -65,6 +67,8 @@ func ParseNetwork(net string) Network {
 		return Network_UDP
 	case "unix":
 		return Network_UNIX
+	case "icmp":
+		return Network_ICMP
 	default:
 		return Network_Unknown
 	}
@@ -24,6 +24,7 @@ const (
 	Network_TCP    Network = 2
 	Network_UDP    Network = 3
 	Network_UNIX   Network = 4
+	Network_ICMP   Network = 5
 )
 
 // Enum value maps for Network.
@@ -34,6 +35,7 @@ var (
 		2: "TCP",
 		3: "UDP",
 		4: "UNIX",
+		5: "ICMP",
 	}
 	Network_value = map[string]int32{
 		"Unknown": 0,
@@ -41,6 +43,7 @@ var (
 		"TCP":     2,
 		"UDP":     3,
 		"UNIX":    4,
+		"ICMP":    5,
 	}
 )
 
@@ -122,13 +125,14 @@ const file_common_net_network_proto_rawDesc = "" +
 	"\n" +
 	"\x18common/net/network.proto\x12\x15v2ray.core.common.net\"G\n" +
 	"\vNetworkList\x128\n" +
-	"\anetwork\x18\x01 \x03(\x0e2\x1e.v2ray.core.common.net.NetworkR\anetwork*B\n" +
+	"\anetwork\x18\x01 \x03(\x0e2\x1e.v2ray.core.common.net.NetworkR\anetwork*L\n" +
 	"\aNetwork\x12\v\n" +
 	"\aUnknown\x10\x00\x12\x0e\n" +
 	"\x06RawTCP\x10\x01\x1a\x02\b\x01\x12\a\n" +
 	"\x03TCP\x10\x02\x12\a\n" +
 	"\x03UDP\x10\x03\x12\b\n" +
-	"\x04UNIX\x10\x04Bc\n" +
+	"\x04UNIX\x10\x04\x12\b\n" +
+	"\x04ICMP\x10\x05Bc\n" +
 	"\x19com.v2ray.core.common.netP\x01Z,github.com/frogwall/f2ray-core/v5/common/net\xaa\x02\x15V2Ray.Core.Common.Netb\x06proto3"
 
 var (
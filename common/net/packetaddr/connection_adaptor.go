@@ -99,10 +99,12 @@ func (c *packetConnectionAdaptor) WriteTo(p []byte, addr gonet.Addr) (n int, err
 }
 
 func (c *packetConnectionAdaptor) Close() error {
+	// Interrupt the link first, to unblock a pending ReadFrom holding readerAccess.
+	err := errors.Combine(common.Interrupt(c.link.Reader), common.Interrupt(c.link.Writer))
 	c.readerAccess.Lock()
 	defer c.readerAccess.Unlock()
 	c.readerBuffer = buf.ReleaseMulti(c.readerBuffer)
-	return common.Interrupt(c.link)
+	return err
 }
 
 func (c packetConnectionAdaptor) LocalAddr() gonet.Addr {
@@ -15,6 +15,7 @@ var (
 	DialUnix        = net.DialUnix
 	FileConn        = net.FileConn
 	FileListener    = net.FileListener
+	FilePacketConn  = net.FilePacketConn
 	Listen          = net.Listen
 	ListenTCP       = net.ListenTCP
 	ListenUDP       = net.ListenUDP
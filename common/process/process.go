@@ -0,0 +1,118 @@
+// Package process finds the local process that opened a connection.
+package process
+
+import (
+	gonet "net"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+//go:generate go run github.com/frogwall/f2ray-core/v5/common/errors/errorgen
+
+// Info is the information of the local process that owns a socket.
+type Info struct {
+	// UID is the user ID of the owner of the socket.
+	UID uint32
+	// PID is the process ID, or 0 if the process is not found, for example,
+	// without the permission to inspect processes of other users.
+	PID int
+	// Path is the path of the executable of the process, if found.
+	Path string
+}
+
+// Name returns the file name of the executable of the process.
+func (i *Info) Name() string {
+	if i.Path == "" {
+		return ""
+	}
+	return filepath.Base(i.Path)
+}
+
+// cacheTTL is how long a lookup is reused. A lookup dumps the sockets and
+// scans the file descriptors of all processes, while connections from the same
+// source are often routed several times in a short period.
+const cacheTTL = time.Second * 2
+
+type cacheEntry struct {
+	info   *Info
+	err    error
+	expire time.Time
+}
+
+var cache = struct {
+	sync.Mutex
+	entries     map[net.Destination]cacheEntry
+	cleanup     time.Time
+	localIPs    []net.IP
+	localExpire time.Time
+}{
+	entries: make(map[net.Destination]cacheEntry),
+}
+
+// FindProcess returns the local process that owns the TCP or UDP socket with
+// the local address source. Sources that are not addresses of this host are
+// rejected, as the socket on the same port belongs to an unrelated process.
+func FindProcess(source net.Destination) (*Info, error) {
+	if !source.IsValid() || !source.Address.Family().IsIP() {
+		return nil, newError("invalid source ", source)
+	}
+	switch source.Network {
+	case net.Network_TCP, net.Network_UDP:
+	default:
+		return nil, newError("unsupported network ", source.Network)
+	}
+
+	now := time.Now()
+	cache.Lock()
+	entry, found := cache.entries[source]
+	local := isLocalLocked(source.Address.IP(), now)
+	cache.Unlock()
+	if found && now.Before(entry.expire) {
+		return entry.info, entry.err
+	}
+	if !local {
+		return nil, newError("source ", source, " is not a local address")
+	}
+
+	info, err := findProcess(source)
+	cache.Lock()
+	if now.Sub(cache.cleanup) > cacheTTL {
+		for key, entry := range cache.entries {
+			if now.After(entry.expire) {
+				delete(cache.entries, key)
+			}
+		}
+		cache.cleanup = now
+	}
+	cache.entries[source] = cacheEntry{info: info, err: err, expire: now.Add(cacheTTL)}
+	cache.Unlock()
+	return info, err
+}
+
+// isLocalLocked returns whether ip is an address of this host, including the
+// address of a TUN interface. It must be called with cache locked.
+func isLocalLocked(ip net.IP, now time.Time) bool {
+	if ip.IsLoopback() {
+		return true
+	}
+	if now.After(cache.localExpire) {
+		cache.localIPs = cache.localIPs[:0]
+		if addrs, err := gonet.InterfaceAddrs(); err == nil {
+			for _, addr := range addrs {
+				if ipNet, ok := addr.(*net.IPNet); ok {
+					cache.localIPs = append(cache.localIPs, ipNet.IP)
+				}
+			}
+		}
+		cache.localExpire = now.Add(cacheTTL)
+	}
+	for _, localIP := range cache.localIPs {
+		if localIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
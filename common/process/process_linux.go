@@ -0,0 +1,161 @@
+package process
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+const (
+	// sizeofInetDiagReqV2 is the size of struct inet_diag_req_v2.
+	sizeofInetDiagReqV2 = 56
+	// sizeofInetDiagMsg is the size of struct inet_diag_msg.
+	sizeofInetDiagMsg = 72
+)
+
+func findProcess(source net.Destination) (*Info, error) {
+	protocol := uint8(unix.IPPROTO_TCP)
+	if source.Network == net.Network_UDP {
+		protocol = unix.IPPROTO_UDP
+	}
+	ip := source.Address.IP()
+
+	// IPv4 connections may be opened by dual-stack IPv6 sockets.
+	var uid, inode uint32
+	var err error
+	if ip4 := ip.To4(); ip4 != nil {
+		uid, inode, err = findSocket(unix.AF_INET, protocol, ip4, source.Port)
+		if err != nil {
+			uid, inode, err = findSocket(unix.AF_INET6, protocol, ip.To16(), source.Port)
+		}
+	} else {
+		uid, inode, err = findSocket(unix.AF_INET6, protocol, ip, source.Port)
+	}
+	if err != nil {
+		return nil, newError("failed to find socket at ", source).Base(err)
+	}
+
+	info := &Info{UID: uid}
+	if pid, err := findProcessBySocket(inode); err == nil {
+		info.PID = pid
+		info.Path, _ = os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "exe"))
+	}
+	return info, nil
+}
+
+// findSocket returns the owner and inode of the socket with the local address
+// ip and port, with netlink sock_diag. ip must be an address of this host, as
+// a UDP socket bound to the wildcard address on the port is also matched.
+func findSocket(family uint8, protocol uint8, ip net.IP, port net.Port) (uid uint32, inode uint32, err error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unix.Close(fd)
+
+	// Sockets are dumped, as a lookup of TCP sockets requires the remote address,
+	// while sockets of UDP may not be connected.
+	request := make([]byte, unix.SizeofNlMsghdr+sizeofInetDiagReqV2)
+	binary.NativeEndian.PutUint32(request[0:], uint32(len(request)))
+	binary.NativeEndian.PutUint16(request[4:], unix.SOCK_DIAG_BY_FAMILY)
+	binary.NativeEndian.PutUint16(request[6:], unix.NLM_F_REQUEST|unix.NLM_F_DUMP)
+	request[unix.SizeofNlMsghdr] = family
+	request[unix.SizeofNlMsghdr+1] = protocol
+	binary.NativeEndian.PutUint32(request[unix.SizeofNlMsghdr+4:], 0xffffffff)
+	if err := unix.Sendto(fd, request, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return 0, 0, err
+	}
+
+	var found, wildcard bool
+	buffer := make([]byte, 64*1024)
+	for {
+		n, _, err := unix.Recvfrom(fd, buffer, 0)
+		if err != nil {
+			return 0, 0, err
+		}
+		messages, err := syscall.ParseNetlinkMessage(buffer[:n])
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, message := range messages {
+			switch message.Header.Type {
+			case unix.NLMSG_DONE:
+				if !found {
+					return 0, 0, newError("socket not found")
+				}
+				return uid, inode, nil
+			case unix.NLMSG_ERROR:
+				if len(message.Data) >= 4 {
+					if errno := int32(binary.NativeEndian.Uint32(message.Data)); errno != 0 {
+						return 0, 0, unix.Errno(-errno)
+					}
+				}
+				return 0, 0, newError("invalid sock_diag response")
+			}
+			data := message.Data
+			if found && !wildcard || len(data) < sizeofInetDiagMsg {
+				continue
+			}
+			// struct inet_diag_msg, of which the local port and address are
+			// at the start of struct inet_diag_sockid.
+			if net.Port(binary.BigEndian.Uint16(data[4:])) != port {
+				continue
+			}
+			// A TCP socket bound to the wildcard address is a listener, which
+			// does not open connections. An unconnected UDP socket sends from
+			// the wildcard address.
+			local := data[8 : 8+len(ip)]
+			isWildcard := protocol == unix.IPPROTO_UDP && isZero(local)
+			if !isWildcard && !bytes.Equal(local, ip) {
+				continue
+			}
+			if !found || !isWildcard {
+				found, wildcard = true, isWildcard
+				uid = binary.NativeEndian.Uint32(data[64:])
+				inode = binary.NativeEndian.Uint32(data[68:])
+			}
+		}
+	}
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// findProcessBySocket returns the process with a file descriptor of the socket.
+func findProcessBySocket(inode uint32) (int, error) {
+	target := "socket:[" + strconv.FormatUint(uint64(inode), 10) + "]"
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, err
+	}
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil || !proc.IsDir() {
+			continue
+		}
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if link, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && link == target {
+				return pid, nil
+			}
+		}
+	}
+	return 0, newError("process of socket ", inode, " not found")
+}
@@ -0,0 +1,101 @@
+package process_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/process"
+)
+
+func checkProcess(t *testing.T, source net.Destination) {
+	info, err := process.FindProcess(source)
+	if err != nil {
+		t.Fatal("failed to find process of ", source, ": ", err)
+	}
+	if info.PID != os.Getpid() || info.UID != uint32(os.Getuid()) {
+		t.Error("unexpected process of ", source, ": ", info.PID, " of user ", info.UID)
+	}
+	executable, err := os.Executable()
+	common.Must(err)
+	if info.Path != executable {
+		t.Error("unexpected path of process: ", info.Path)
+	}
+}
+
+func TestFindProcessTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	common.Must(err)
+	defer conn.Close()
+
+	checkProcess(t, net.DestinationFromAddr(conn.LocalAddr()))
+}
+
+func TestFindProcessUDP(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IP{127, 0, 0, 1}})
+	common.Must(err)
+	defer conn.Close()
+
+	checkProcess(t, net.DestinationFromAddr(conn.LocalAddr()))
+}
+
+func TestFindProcessNotFound(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	source := net.DestinationFromAddr(listener.Addr())
+	listener.Close()
+
+	if _, err := process.FindProcess(source); err == nil {
+		t.Error("expect error for closed socket")
+	}
+	if _, err := process.FindProcess(net.UDPDestination(net.DomainAddress("example.com"), 53)); err == nil {
+		t.Error("expect error for domain source")
+	}
+}
+
+func TestFindProcessNotLocal(t *testing.T) {
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	common.Must(err)
+	defer listener.Close()
+	port := net.Port(listener.Addr().(*net.TCPAddr).Port)
+
+	// A wildcard listener does not own connections from its port.
+	if _, err := process.FindProcess(net.TCPDestination(net.LocalHostIP, port)); err == nil {
+		t.Error("expect error for wildcard listener")
+	}
+	if _, err := process.FindProcess(net.TCPDestination(net.ParseAddress("203.0.113.1"), port)); err == nil {
+		t.Error("expect error for remote source")
+	}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{})
+	common.Must(err)
+	defer conn.Close()
+	port = net.Port(conn.LocalAddr().(*net.UDPAddr).Port)
+	if _, err := process.FindProcess(net.UDPDestination(net.ParseAddress("203.0.113.1"), port)); err == nil {
+		t.Error("expect error for remote source")
+	}
+	checkProcess(t, net.UDPDestination(net.LocalHostIP, port))
+}
+
+func BenchmarkFindProcess(b *testing.B) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	common.Must(err)
+	defer conn.Close()
+	source := net.DestinationFromAddr(conn.LocalAddr())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := process.FindProcess(source); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package process
+
+import (
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+func findProcess(source net.Destination) (*Info, error) {
+	return nil, newError("process lookup is not supported on this platform")
+}
@@ -1,4 +1,4 @@
-package f2ray-core
+package core
 
 import "github.com/frogwall/f2ray-core/v5/common/errors"
 
@@ -21,3 +21,12 @@ type TaggedFeatures interface {
 	GetFeaturesByTag(tag string) (Feature, error)
 	common.Runnable
 }
+
+// Reloadable is the interface for features that can switch to a new config
+// without being recreated.
+type Reloadable interface {
+	// PrepareReload builds the feature state from the config, and returns a
+	// function that switches the feature to it. The feature is left unchanged
+	// if an error is returned.
+	PrepareReload(config interface{}) (func(), error)
+}
@@ -40,3 +40,22 @@ type Manager interface {
 func ManagerType() interface{} {
 	return (*Manager)(nil)
 }
+
+// HandlerDetacher is implemented by Managers that can swap handlers without
+// starting or closing them, so that the caller controls their lifetime.
+type HandlerDetacher interface {
+	// AttachHandler adds a handler, which is expected to be started already if
+	// the Manager is running, and returns the handler with the same tag it
+	// replaces, if any.
+	AttachHandler(ctx context.Context, handler Handler) (Handler, error)
+	// DetachHandler removes the handler with the given tag without closing it,
+	// and returns it.
+	DetachHandler(ctx context.Context, tag string) (Handler, error)
+}
+
+// ListenerCloser is implemented by Handlers that can stop accepting new
+// connections, while their existing connections are left to finish.
+type ListenerCloser interface {
+	// CloseListeners closes the listeners of the handler. Start listens again.
+	CloseListeners() error
+}
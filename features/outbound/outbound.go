@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/errors"
+	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/features"
 	"github.com/frogwall/f2ray-core/v5/transport"
 )
@@ -17,6 +19,15 @@ type Handler interface {
 	Dispatch(ctx context.Context, link *transport.Link)
 }
 
+// ErrPingNotSupported is returned by Pingers whose outbounds do not forward ICMP echo requests.
+var ErrPingNotSupported = errors.New("ICMP echo is not supported")
+
+// Pinger is implemented by Handlers that may forward ICMP echo requests.
+type Pinger interface {
+	// Ping sends an ICMP echo request with the payload to dest, and returns the payload of its reply.
+	Ping(ctx context.Context, dest net.Address, payload []byte) ([]byte, error)
+}
+
 type HandlerSelector interface {
 	Select([]string) []string
 }
@@ -43,3 +54,12 @@ type Manager interface {
 func ManagerType() interface{} {
 	return (*Manager)(nil)
 }
+
+// HandlerDetacher is implemented by Managers that can take out a handler
+// without closing it, so that its connections can be drained.
+type HandlerDetacher interface {
+	// ReplaceHandler adds the given handler, and returns the handler with the same tag it replaces, if any.
+	ReplaceHandler(ctx context.Context, handler Handler) (Handler, error)
+	// DetachHandler removes the handler with the given tag, and returns it.
+	DetachHandler(ctx context.Context, tag string) (Handler, error)
+}
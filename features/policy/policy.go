@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/frogwall/f2ray-core/v5/common/platform"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
 	"github.com/frogwall/f2ray-core/v5/features"
 )
 
@@ -45,6 +46,12 @@ type SystemStats struct {
 	OutboundUplink bool
 	// Whether or not to enable stat counter for downlink traffic in outbound handlers.
 	OutboundDownlink bool
+	// Whether or not to enable stat counter for active connections in inbound handlers.
+	InboundConnection bool
+	// Whether or not to enable stat counter for active connections in outbound handlers.
+	OutboundConnection bool
+	// Whether or not to track individual active connections, as listed by the connection API.
+	ConnectionTracking bool
 }
 
 // System contains policy settings at system level.
@@ -74,6 +81,31 @@ type Manager interface {
 	ForSystem() System
 }
 
+// RateLimiter limits the rate of traffic. It may be shared by many connections.
+type RateLimiter interface {
+	// WaitN blocks until n bytes of traffic are allowed, or the context is done.
+	WaitN(ctx context.Context, n int) error
+}
+
+// RateLimiters contains the rate limiters of a connection. Traffic of the
+// connection passes all of them.
+type RateLimiters struct {
+	Uplink   []RateLimiter
+	Downlink []RateLimiter
+}
+
+// RateLimitManager is an optional interface of Manager, which limits the
+// bandwidth of connections.
+type RateLimitManager interface {
+	// ForConnection returns the rate limiters for a connection of the user
+	// from the inbound. The user may be nil.
+	ForConnection(inboundTag string, user *protocol.MemoryUser) RateLimiters
+	// ReleaseUser is called when a user is removed from an inbound, so that
+	// its limits are looked up again if it is added back. Existing connections
+	// of the user keep their limiter.
+	ReleaseUser(email string)
+}
+
 // ManagerType returns the type of Manager interface. Can be used to implement common.HasType.
 //
 // v2ray:api:stable
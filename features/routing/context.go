@@ -2,6 +2,7 @@ package routing
 
 import (
 	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/process"
 )
 
 // Context is a feature to store connection information for routing.
@@ -40,4 +41,7 @@ type Context interface {
 
 	// GetSkipDNSResolve returns a flag switch for weather skip dns resolve during route pick.
 	GetSkipDNSResolve() bool
+
+	// GetProcess returns the local process that opened the connection, if it can be found.
+	GetProcess() *process.Info
 }
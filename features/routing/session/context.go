@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/process"
 	"github.com/frogwall/f2ray-core/v5/common/session"
 	"github.com/frogwall/f2ray-core/v5/features/routing"
 )
@@ -13,6 +14,9 @@ type Context struct {
 	Inbound  *session.Inbound
 	Outbound *session.Outbound
 	Content  *session.Content
+
+	process         *process.Info
+	processResolved bool
 }
 
 // GetInboundTag implements routing.Context.
@@ -117,6 +121,18 @@ func (ctx *Context) GetSkipDNSResolve() bool {
 	return ctx.Content.SkipDNSResolve
 }
 
+// GetProcess implements routing.Context. The process is looked up at the first
+// call, as it is costly.
+func (ctx *Context) GetProcess() *process.Info {
+	if !ctx.processResolved {
+		ctx.processResolved = true
+		if ctx.Inbound != nil && ctx.Inbound.Source.IsValid() {
+			ctx.process, _ = process.FindProcess(ctx.Inbound.Source)
+		}
+	}
+	return ctx.process
+}
+
 // AsRoutingContext creates a context from context.context with session info.
 func AsRoutingContext(ctx context.Context) routing.Context {
 	return &Context{
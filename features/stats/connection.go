@@ -0,0 +1,53 @@
+package stats
+
+import "time"
+
+// ConnectionInfo is the information of an active connection.
+type ConnectionInfo struct {
+	// ID identifies the connection in its ConnectionTracker.
+	ID uint64
+	// Source is the source address of the connection, as of net.Destination.
+	Source string
+	// Target is the destination of the connection, as of net.Destination. It
+	// may be overridden by sniffing.
+	Target string
+	// Domain is the sniffed domain of the connection, if any.
+	Domain string
+	// Protocol is the sniffed protocol of the connection, if any.
+	Protocol string
+	// InboundTag is the tag of the inbound the connection was from.
+	InboundTag string
+	// OutboundTag is the tag of the outbound the connection is routed to, once routed.
+	OutboundTag string
+	// User is the email of the user of the connection, if any.
+	User string
+	// Start is the time when the connection was dispatched.
+	Start time.Time
+	// Uplink is the number of bytes sent from the inbound.
+	Uplink int64
+	// Downlink is the number of bytes sent to the inbound.
+	Downlink int64
+}
+
+// TrackedConnection is a connection tracked by a ConnectionTracker.
+//
+// v2ray:api:beta
+type TrackedConnection interface {
+	// Update updates the information of the connection with f.
+	Update(f func(info *ConnectionInfo))
+	// UplinkCounter returns the counter of bytes sent from the inbound.
+	UplinkCounter() Counter
+	// DownlinkCounter returns the counter of bytes sent to the inbound.
+	DownlinkCounter() Counter
+	// Untrack removes the connection from its ConnectionTracker.
+	Untrack()
+}
+
+// ConnectionTracker is the interface for Managers that track active connections.
+//
+// v2ray:api:beta
+type ConnectionTracker interface {
+	// TrackConnection starts tracking a connection with info, except its ID and
+	// byte counts. closer closes the connection if it is requested to.
+	TrackConnection(info ConnectionInfo, closer func()) TrackedConnection
+}
@@ -204,17 +204,23 @@ func toCidrList(ctx context.Context, ips cfgcommon.StringList) ([]*routercommon.
 func parseFieldRule(ctx context.Context, msg json.RawMessage) (*router.RoutingRule, error) {
 	type RawFieldRule struct {
 		RouterRule
-		Domain     *cfgcommon.StringList  `json:"domain"`
-		Domains    *cfgcommon.StringList  `json:"domains"`
-		IP         *cfgcommon.StringList  `json:"ip"`
-		Port       *cfgcommon.PortList    `json:"port"`
-		Network    *cfgcommon.NetworkList `json:"network"`
-		SourceIP   *cfgcommon.StringList  `json:"source"`
-		SourcePort *cfgcommon.PortList    `json:"sourcePort"`
-		User       *cfgcommon.StringList  `json:"user"`
-		InboundTag *cfgcommon.StringList  `json:"inboundTag"`
-		Protocols  *cfgcommon.StringList  `json:"protocol"`
-		Attributes string                 `json:"attrs"`
+		Domain        *cfgcommon.StringList  `json:"domain"`
+		Domains       *cfgcommon.StringList  `json:"domains"`
+		IP            *cfgcommon.StringList  `json:"ip"`
+		Port          *cfgcommon.PortList    `json:"port"`
+		Network       *cfgcommon.NetworkList `json:"network"`
+		SourceIP      *cfgcommon.StringList  `json:"source"`
+		SourcePort    *cfgcommon.PortList    `json:"sourcePort"`
+		User          *cfgcommon.StringList  `json:"user"`
+		InboundTag    *cfgcommon.StringList  `json:"inboundTag"`
+		Protocols     *cfgcommon.StringList  `json:"protocol"`
+		Attributes    string                 `json:"attrs"`
+		ProcessName   *cfgcommon.StringList  `json:"processName"`
+		ProcessPath   *cfgcommon.StringList  `json:"processPath"`
+		UID           []uint32               `json:"uid"`
+		RuleSet       *cfgcommon.StringList  `json:"ruleSet"`
+		SourceRuleSet *cfgcommon.StringList  `json:"sourceRuleSet"`
+		Time          *TimeConfig            `json:"time"`
 	}
 	rawFieldRule := new(RawFieldRule)
 	err := json.Unmarshal(msg, rawFieldRule)
@@ -236,6 +242,8 @@ func parseFieldRule(ctx context.Context, msg json.RawMessage) (*router.RoutingRu
 		return nil, newError("neither outboundTag nor balancerTag is specified in routing rule")
 	}
 
+	rule.RuleTag = rawFieldRule.RuleTag
+
 	if rawFieldRule.DomainMatcher != "" {
 		rule.DomainMatcher = rawFieldRule.DomainMatcher
 	}
@@ -310,6 +318,40 @@ func parseFieldRule(ctx context.Context, msg json.RawMessage) (*router.RoutingRu
 		rule.Attributes = rawFieldRule.Attributes
 	}
 
+	if rawFieldRule.ProcessName != nil {
+		for _, s := range *rawFieldRule.ProcessName {
+			rule.ProcessName = append(rule.ProcessName, s)
+		}
+	}
+
+	if rawFieldRule.ProcessPath != nil {
+		for _, s := range *rawFieldRule.ProcessPath {
+			rule.ProcessPath = append(rule.ProcessPath, s)
+		}
+	}
+
+	rule.Uid = rawFieldRule.UID
+
+	if rawFieldRule.RuleSet != nil {
+		for _, s := range *rawFieldRule.RuleSet {
+			rule.RuleSet = append(rule.RuleSet, s)
+		}
+	}
+
+	if rawFieldRule.SourceRuleSet != nil {
+		for _, s := range *rawFieldRule.SourceRuleSet {
+			rule.SourceRuleSet = append(rule.SourceRuleSet, s)
+		}
+	}
+
+	if rawFieldRule.Time != nil {
+		time, err := rawFieldRule.Time.Build()
+		if err != nil {
+			return nil, err
+		}
+		rule.Time = time
+	}
+
 	return rule, nil
 }
 
@@ -386,10 +428,34 @@ func ToCidrList(ctx context.Context, ips cfgcommon.StringList) ([]*routercommon.
 	return toCidrList(ctx, ips)
 }
 
+// TimeConfig is the config of the time condition of a routing rule.
+type TimeConfig struct {
+	Range    *cfgcommon.StringList `json:"range"`
+	Weekday  *cfgcommon.StringList `json:"weekday"`
+	Timezone string                `json:"timezone"`
+}
+
+// Build builds the time condition.
+func (c *TimeConfig) Build() (*router.TimeCondition, error) {
+	condition := &router.SimplifiedTimeCondition{Timezone: c.Timezone}
+	if c.Range != nil {
+		condition.Range = *c.Range
+	}
+	if c.Weekday != nil {
+		condition.Weekday = *c.Weekday
+	}
+	time, err := condition.Build()
+	if err != nil {
+		return nil, newError("invalid time condition").Base(err)
+	}
+	return time, nil
+}
+
 type RouterRule struct {
 	Type        string `json:"type"`
 	OutboundTag string `json:"outboundTag"`
 	BalancerTag string `json:"balancerTag"`
+	RuleTag     string `json:"ruleTag"`
 
 	DomainMatcher string `json:"domainMatcher"`
 }
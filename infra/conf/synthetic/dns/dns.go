@@ -16,6 +16,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/geodata"
 	rule2 "github.com/frogwall/f2ray-core/v5/infra/conf/rule"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/synthetic/router"
 )
 
 type NameServerConfig struct {
@@ -29,6 +30,7 @@ type NameServerConfig struct {
 	SkipFallback     bool
 	Domains          []string
 	ExpectIPs        cfgcommon.StringList
+	RuleSet          cfgcommon.StringList
 	FakeDNS          FakeDNSConfigExtend
 
 	cfgctx context.Context
@@ -52,6 +54,7 @@ func (c *NameServerConfig) UnmarshalJSON(data []byte) error {
 		SkipFallback     bool                 `json:"skipFallback"`
 		Domains          []string             `json:"domains"`
 		ExpectIPs        cfgcommon.StringList `json:"expectIps"`
+		RuleSet          cfgcommon.StringList `json:"ruleSet"`
 		FakeDNS          FakeDNSConfigExtend  `json:"fakedns"`
 	}
 	if err := json.Unmarshal(data, &advanced); err == nil {
@@ -65,6 +68,7 @@ func (c *NameServerConfig) UnmarshalJSON(data []byte) error {
 		c.SkipFallback = advanced.SkipFallback
 		c.Domains = advanced.Domains
 		c.ExpectIPs = advanced.ExpectIPs
+		c.RuleSet = advanced.RuleSet
 		c.FakeDNS = advanced.FakeDNS
 		return nil
 	}
@@ -191,6 +195,7 @@ func (c *NameServerConfig) Build() (*dns.NameServer, error) {
 		PrioritizedDomain: domains,
 		Geoip:             geoipList,
 		OriginalRules:     originalRules,
+		RuleSet:           c.RuleSet,
 		FakeDns:           fakeDNS,
 	}, nil
 }
@@ -216,9 +221,36 @@ type DNSConfig struct { // nolint: revive
 	DisableCache           bool                    `json:"disableCache"`
 	DisableFallback        bool                    `json:"disableFallback"`
 	DisableFallbackIfMatch bool                    `json:"disableFallbackIfMatch"`
+	Cache                  *DNSCacheConfig         `json:"cache"`
+	RuleSets               []*router.RuleSetConfig `json:"ruleSets"`
 	cfgctx                 context.Context
 }
 
+// DNSCacheConfig is a JSON serializable object for dns.CacheConfig.
+type DNSCacheConfig struct { // nolint: revive
+	ServeStale bool   `json:"serveStale"`
+	StaleTTL   uint32 `json:"staleTtl"`
+	Prefetch   bool   `json:"prefetch"`
+	MinTTL     uint32 `json:"minTtl"`
+	MaxTTL     uint32 `json:"maxTtl"`
+	Persistent bool   `json:"persistent"`
+}
+
+// Build implements Buildable
+func (c *DNSCacheConfig) Build() (*dns.CacheConfig, error) {
+	if c.MinTTL > 0 && c.MaxTTL > 0 && c.MinTTL > c.MaxTTL {
+		return nil, newError("minTtl ", c.MinTTL, " is larger than maxTtl ", c.MaxTTL)
+	}
+	return &dns.CacheConfig{
+		ServeStale: c.ServeStale,
+		StaleTtl:   c.StaleTTL,
+		Prefetch:   c.Prefetch,
+		MinTtl:     c.MinTTL,
+		MaxTtl:     c.MaxTTL,
+		Persistent: c.Persistent,
+	}, nil
+}
+
 type HostAddress struct {
 	addr  *cfgcommon.Address
 	addrs []*cfgcommon.Address
@@ -332,6 +364,22 @@ func (c *DNSConfig) Build() (*dns.Config, error) {
 		config.FallbackStrategy = dns.FallbackStrategy_DisabledIfAnyMatch
 	}
 
+	if c.Cache != nil {
+		cache, err := c.Cache.Build()
+		if err != nil {
+			return nil, newError("failed to build cache settings").Base(err)
+		}
+		config.Cache = cache
+	}
+
+	for _, rawRuleSet := range c.RuleSets {
+		ruleSet, err := rawRuleSet.Build()
+		if err != nil {
+			return nil, newError("failed to build rule-set").Base(err)
+		}
+		config.RuleSet = append(config.RuleSet, ruleSet)
+	}
+
 	for _, server := range c.Servers {
 		server.cfgctx = c.cfgctx
 		ns, err := server.Build()
@@ -286,5 +286,20 @@ func TestDNSConfigParsing(t *testing.T) {
 				FallbackStrategy: dns.FallbackStrategy_Enabled,
 			},
 		},
+		{
+			Input: `{
+				"fakedns": [
+					{ "ipPool": "198.18.0.0/16", "poolSize": 32768, "persistent": true }
+				]
+			}`,
+			Parser: parserCreator(),
+			Output: &dns.Config{
+				FakeDns: &fakedns.FakeDnsPoolMulti{
+					Pools: []*fakedns.FakeDnsPool{
+						{IpPool: "198.18.0.0/16", LruSize: 32768, Persistent: true},
+					},
+				},
+			},
+		},
 	})
 }
@@ -8,8 +8,9 @@ import (
 )
 
 type FakeDNSPoolElementConfig struct {
-	IPPool  string `json:"ipPool"`
-	LRUSize int64  `json:"poolSize"`
+	IPPool     string `json:"ipPool"`
+	LRUSize    int64  `json:"poolSize"`
+	Persistent bool   `json:"persistent"`
 }
 
 type FakeDNSConfig struct {
@@ -55,15 +56,16 @@ func (f *FakeDNSConfig) Build() (*fakedns.FakeDnsPoolMulti, error) {
 
 	if f.pool != nil {
 		fakeDNSPool.Pools = append(fakeDNSPool.Pools, &fakedns.FakeDnsPool{
-			IpPool:  f.pool.IPPool,
-			LruSize: f.pool.LRUSize,
+			IpPool:     f.pool.IPPool,
+			LruSize:    f.pool.LRUSize,
+			Persistent: f.pool.Persistent,
 		})
 		return &fakeDNSPool, nil
 	}
 
 	if f.pools != nil {
 		for _, v := range f.pools {
-			fakeDNSPool.Pools = append(fakeDNSPool.Pools, &fakedns.FakeDnsPool{IpPool: v.IPPool, LruSize: v.LRUSize})
+			fakeDNSPool.Pools = append(fakeDNSPool.Pools, &fakedns.FakeDnsPool{IpPool: v.IPPool, LruSize: v.LRUSize, Persistent: v.Persistent})
 		}
 		return &fakeDNSPool, nil
 	}
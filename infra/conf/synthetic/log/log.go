@@ -2,9 +2,11 @@ package log
 
 import (
 	"strings"
+	"time"
 
 	"github.com/frogwall/f2ray-core/v5/app/log"
 	clog "github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/duration"
 )
 
 func DefaultLogConfig() *log.Config {
@@ -14,10 +16,31 @@ func DefaultLogConfig() *log.Config {
 	}
 }
 
+type LogRotationConfig struct { // nolint: revive
+	MaxSize    uint32            `json:"maxSize"`
+	Interval   duration.Duration `json:"interval"`
+	MaxBackups uint32            `json:"maxBackups"`
+	MaxAge     uint32            `json:"maxAge"`
+}
+
+func (c *LogRotationConfig) Build() *log.LogRotation {
+	if c == nil {
+		return nil
+	}
+	return &log.LogRotation{
+		MaxSize:    c.MaxSize,
+		Interval:   uint32(time.Duration(c.Interval) / time.Second),
+		MaxBackups: c.MaxBackups,
+		MaxAge:     c.MaxAge,
+	}
+}
+
 type LogConfig struct { // nolint: revive
-	AccessLog string `json:"access"`
-	ErrorLog  string `json:"error"`
-	LogLevel  string `json:"loglevel"`
+	AccessLog string             `json:"access"`
+	ErrorLog  string             `json:"error"`
+	LogLevel  string             `json:"loglevel"`
+	Format    string             `json:"format"`
+	Rotation  *LogRotationConfig `json:"rotation"`
 }
 
 func (v *LogConfig) Build() *log.Config {
@@ -29,17 +52,24 @@ func (v *LogConfig) Build() *log.Config {
 		Error:  &log.LogSpecification{Type: log.LogType_Console},
 	}
 
+	if strings.ToLower(v.Format) == "json" {
+		config.Access.Format = log.LogFormat_JSON
+		config.Error.Format = log.LogFormat_JSON
+	}
+
 	if v.AccessLog == "none" {
 		config.Access.Type = log.LogType_None
 	} else if len(v.AccessLog) > 0 {
 		config.Access.Path = v.AccessLog
 		config.Access.Type = log.LogType_File
+		config.Access.Rotation = v.Rotation.Build()
 	}
 	if v.ErrorLog == "none" {
 		config.Error.Type = log.LogType_None
 	} else if len(v.ErrorLog) > 0 {
 		config.Error.Path = v.ErrorLog
 		config.Error.Type = log.LogType_File
+		config.Error.Rotation = v.Rotation.Build()
 	}
 
 	level := strings.ToLower(v.LogLevel)
@@ -0,0 +1,84 @@
+package log_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/frogwall/f2ray-core/v5/app/log"
+	clog "github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/testassist"
+	conflog "github.com/frogwall/f2ray-core/v5/infra/conf/synthetic/log"
+)
+
+func TestLogConfig(t *testing.T) {
+	parser := func(s string) (proto.Message, error) {
+		config := new(conflog.LogConfig)
+		if err := json.Unmarshal([]byte(s), config); err != nil {
+			return nil, err
+		}
+		return config.Build(), nil
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"access": "/var/log/v2ray/access.log",
+				"loglevel": "error",
+				"error": "/var/log/v2ray/error.log",
+				"format": "json",
+				"rotation": {
+					"maxSize": 100,
+					"interval": "24h",
+					"maxBackups": 7
+				}
+			}`,
+			Parser: parser,
+			Output: &log.Config{
+				Error: &log.LogSpecification{
+					Type:   log.LogType_File,
+					Level:  clog.Severity_Error,
+					Path:   "/var/log/v2ray/error.log",
+					Format: log.LogFormat_JSON,
+					Rotation: &log.LogRotation{
+						MaxSize:    100,
+						Interval:   86400,
+						MaxBackups: 7,
+					},
+				},
+				Access: &log.LogSpecification{
+					Type:   log.LogType_File,
+					Path:   "/var/log/v2ray/access.log",
+					Format: log.LogFormat_JSON,
+					Rotation: &log.LogRotation{
+						MaxSize:    100,
+						Interval:   86400,
+						MaxBackups: 7,
+					},
+				},
+			},
+		},
+		{
+			Input: `{
+				"loglevel": "info",
+				"format": "json",
+				"rotation": {
+					"maxSize": 100
+				}
+			}`,
+			Parser: parser,
+			Output: &log.Config{
+				Error: &log.LogSpecification{
+					Type:   log.LogType_Console,
+					Level:  clog.Severity_Info,
+					Format: log.LogFormat_JSON,
+				},
+				Access: &log.LogSpecification{
+					Type:   log.LogType_Console,
+					Format: log.LogFormat_JSON,
+				},
+			},
+		},
+	})
+}
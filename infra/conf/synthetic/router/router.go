@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/common/platform"
 	"github.com/frogwall/f2ray-core/v5/common/serial"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/duration"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/geodata"
 	rule2 "github.com/frogwall/f2ray-core/v5/infra/conf/rule"
 )
@@ -82,11 +84,62 @@ func (r *BalancingRule) Build() (*router.BalancingRule, error) {
 	}, nil
 }
 
+// RuleSetConfig is the config of a rule-set, of domains and IP ranges.
+type RuleSetConfig struct {
+	Tag           string             `json:"tag"`
+	Format        string             `json:"format"`
+	Path          string             `json:"path"`
+	URL           string             `json:"url"`
+	Code          string             `json:"code"`
+	OutboundTag   string             `json:"outboundTag"`
+	Interval      *duration.Duration `json:"interval"`
+	DomainMatcher string             `json:"domainMatcher"`
+}
+
+// Build builds the rule-set.
+func (c *RuleSetConfig) Build() (*router.RuleSet, error) {
+	if c.Tag == "" {
+		return nil, newError("empty rule-set tag")
+	}
+	if (c.Path == "") == (c.URL == "") {
+		return nil, newError("either path or url of rule-set ", c.Tag, " must be set")
+	}
+
+	config := &router.RuleSet{
+		Tag:           c.Tag,
+		Path:          c.Path,
+		Url:           c.URL,
+		Code:          c.Code,
+		OutboundTag:   c.OutboundTag,
+		DomainMatcher: c.DomainMatcher,
+	}
+	switch strings.ToLower(c.Format) {
+	case "text", "":
+		config.Format = router.RuleSet_Text
+	case "geosite":
+		config.Format = router.RuleSet_GeoSite
+	case "geoip":
+		config.Format = router.RuleSet_GeoIP
+	case "srs", "binary":
+		config.Format = router.RuleSet_SRS
+	default:
+		return nil, newError("unknown format of rule-set: ", c.Format)
+	}
+	if (config.Format == router.RuleSet_GeoSite || config.Format == router.RuleSet_GeoIP) && c.Code == "" {
+		return nil, newError("empty code of rule-set ", c.Tag)
+	}
+	if c.Interval != nil {
+		config.UpdateInterval = uint32(time.Duration(*c.Interval) / time.Second)
+	}
+	return config, nil
+}
+
 type RouterConfig struct { // nolint: revive
 	Settings       *RouterRulesConfig `json:"settings"` // Deprecated
 	RuleList       []json.RawMessage  `json:"rules"`
 	DomainStrategy *string            `json:"domainStrategy"`
 	Balancers      []*BalancingRule   `json:"balancers"`
+	RuleSets       []*RuleSetConfig   `json:"ruleSets"`
 
 	DomainMatcher string `json:"domainMatcher"`
 
@@ -164,5 +217,15 @@ func (c *RouterConfig) Build() (*router.Config, error) {
 		}
 		config.BalancingRule = append(config.BalancingRule, balancer)
 	}
+	for _, rawRuleSet := range c.RuleSets {
+		ruleSet, err := rawRuleSet.Build()
+		if err != nil {
+			return nil, err
+		}
+		if ruleSet.DomainMatcher == "" {
+			ruleSet.DomainMatcher = c.DomainMatcher
+		}
+		config.RuleSet = append(config.RuleSet, ruleSet)
+	}
 	return config, nil
 }
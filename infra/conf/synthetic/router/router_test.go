@@ -388,5 +388,100 @@ func TestRouterConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			Input: `{
+				"domainMatcher": "mph",
+				"ruleSets": [
+					{
+						"tag": "ads",
+						"url": "https://example.com/ads.srs",
+						"format": "srs",
+						"outboundTag": "direct",
+						"interval": "24h"
+					},
+					{
+						"tag": "cn",
+						"path": "geoip.dat",
+						"format": "geoip",
+						"code": "cn"
+					}
+				],
+				"rules": [
+					{
+						"type": "field",
+						"ruleSet": ["ads"],
+						"sourceRuleSet": "cn",
+						"outboundTag": "block"
+					}
+				]
+			}`,
+			Parser: createParser(),
+			Output: &router.Config{
+				DomainStrategy: router.DomainStrategy_AsIs,
+				RuleSet: []*router.RuleSet{
+					{
+						Tag:            "ads",
+						Format:         router.RuleSet_SRS,
+						Url:            "https://example.com/ads.srs",
+						OutboundTag:    "direct",
+						UpdateInterval: 86400,
+						DomainMatcher:  "mph",
+					},
+					{
+						Tag:           "cn",
+						Format:        router.RuleSet_GeoIP,
+						Path:          "geoip.dat",
+						Code:          "cn",
+						DomainMatcher: "mph",
+					},
+				},
+				Rule: []*router.RoutingRule{
+					{
+						RuleSet:       []string{"ads"},
+						SourceRuleSet: []string{"cn"},
+						DomainMatcher: "mph",
+						TargetTag: &router.RoutingRule_Tag{
+							Tag: "block",
+						},
+					},
+				},
+			},
+		},
+		{
+			Input: `{
+				"rules": [
+					{
+						"type": "field",
+						"network": "tcp",
+						"time": {
+							"range": ["22:00-06:00", "12:00:00-13:30:00"],
+							"weekday": ["sat", "Sunday"],
+							"timezone": "Asia/Shanghai"
+						},
+						"outboundTag": "metered"
+					}
+				]
+			}`,
+			Parser: createParser(),
+			Output: &router.Config{
+				DomainStrategy: router.DomainStrategy_AsIs,
+				Rule: []*router.RoutingRule{
+					{
+						Networks: []net.Network{net.Network_TCP},
+						Time: &router.TimeCondition{
+							Range: []*router.TimeCondition_Range{
+								{Begin: 22 * 3600, End: 6 * 3600},
+								{Begin: 12 * 3600, End: 13*3600 + 30*60},
+							},
+							Weekday:  []uint32{6, 0},
+							Timezone: "Asia/Shanghai",
+						},
+						TargetTag: &router.RoutingRule_Tag{
+							Tag: "metered",
+						},
+					},
+				},
+			},
+		},
 	})
 }
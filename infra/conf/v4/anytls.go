@@ -1,8 +1,13 @@
 package v4
 
 import (
+	"strings"
+
+	"github.com/anytls/sing-anytls/padding"
 	"github.com/golang/protobuf/proto"
 
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
 	anytls "github.com/frogwall/f2ray-core/v5/proxy/anytls"
 )
 
@@ -68,3 +73,42 @@ func (v *AnyTLSClientConfig) Build() (proto.Message, error) {
 
 	return config, nil
 }
+
+// AnyTLSUserConfig represents a user of an AnyTLS inbound
+type AnyTLSUserConfig struct {
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// AnyTLSInboundConfig represents the JSON configuration for AnyTLS inbound
+type AnyTLSInboundConfig struct {
+	Clients       []*AnyTLSUserConfig `json:"clients"`
+	PaddingScheme []string            `json:"paddingScheme"`
+}
+
+// Build implements Buildable interface
+func (v *AnyTLSInboundConfig) Build() (proto.Message, error) {
+	config := new(anytls.ServerConfig)
+	config.Users = make([]*protocol.User, len(v.Clients))
+	for idx, rawUser := range v.Clients {
+		if rawUser.Password == "" {
+			return nil, newError("anytls client password is required")
+		}
+		config.Users[idx] = &protocol.User{
+			Email: rawUser.Email,
+			Level: uint32(rawUser.Level),
+			Account: serial.ToTypedMessage(&anytls.Account{
+				Password: rawUser.Password,
+			}),
+		}
+	}
+	if len(v.PaddingScheme) > 0 {
+		scheme := strings.Join(v.PaddingScheme, "\n")
+		if padding.NewPaddingFactory([]byte(scheme)) == nil {
+			return nil, newError("invalid anytls padding scheme")
+		}
+		config.PaddingScheme = scheme
+	}
+	return config, nil
+}
@@ -0,0 +1,57 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/frogwall/f2ray-core/v5/infra/conf/v4"
+	"github.com/frogwall/f2ray-core/v5/proxy/anytls"
+)
+
+func TestAnyTLSInboundConfigParsing(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.AnyTLSInboundConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"clients": [
+					{
+						"password": "anytls-password",
+						"email": "love@v2fly.org",
+						"level": 1
+					}
+				],
+				"paddingScheme": [
+					"stop=2",
+					"0=30-30",
+					"1=100-400"
+				]
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &anytls.ServerConfig{
+				Users: []*protocol.User{
+					{
+						Email: "love@v2fly.org",
+						Level: 1,
+						Account: serial.ToTypedMessage(&anytls.Account{
+							Password: "anytls-password",
+						}),
+					},
+				},
+				PaddingScheme: "stop=2\n0=30-30\n1=100-400",
+			},
+		},
+	})
+
+	if _, err := testassist.LoadJSON(creator)(`{
+		"clients": [{"password": "anytls-password"}],
+		"paddingScheme": ["0=30-30"]
+	}`); err == nil {
+		t.Error("expected error for padding scheme without stop")
+	}
+}
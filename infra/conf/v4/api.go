@@ -10,6 +10,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/app/commander"
 	loggerservice "github.com/frogwall/f2ray-core/v5/app/log/command"
 	observatoryservice "github.com/frogwall/f2ray-core/v5/app/observatory/command"
+	policyservice "github.com/frogwall/f2ray-core/v5/app/policy/command"
 	handlerservice "github.com/frogwall/f2ray-core/v5/app/proxyman/command"
 	routerservice "github.com/frogwall/f2ray-core/v5/app/router/command"
 	statsservice "github.com/frogwall/f2ray-core/v5/app/stats/command"
@@ -41,6 +42,8 @@ func (c *APIConfig) Build() (*commander.Config, error) {
 			services = append(services, serial.ToTypedMessage(&observatoryservice.Config{}))
 		case "routingservice":
 			services = append(services, serial.ToTypedMessage(&routerservice.Config{}))
+		case "policyservice":
+			services = append(services, serial.ToTypedMessage(&policyservice.Config{}))
 		default:
 			if !strings.HasPrefix(s, "#") {
 				continue
@@ -84,12 +84,22 @@ func (c *BrookClientConfig) Build() (proto.Message, error) {
 
 // BrookServerConfig is Inbound configuration
 type BrookServerConfig struct {
-	Password string `json:"password"`
+	Password     string `json:"password"`
+	WithoutBrook bool   `json:"withoutBrook"`
+	Path         string `json:"path"` // for websocket
 }
 
 // Build implements Buildable
 func (c *BrookServerConfig) Build() (proto.Message, error) {
+	if c.Password == "" {
+		return nil, newError("Brook password is not set.")
+	}
+	if c.Path != "" && c.Path[0] != '/' {
+		return nil, newError("Brook path must start with '/': ", c.Path)
+	}
 	config := new(brook.ServerConfig)
 	config.Password = c.Password
+	config.WithoutBrook = c.WithoutBrook
+	config.Path = c.Path
 	return config, nil
 }
@@ -0,0 +1,45 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/frogwall/f2ray-core/v5/infra/conf/v4"
+	"github.com/frogwall/f2ray-core/v5/proxy/brook"
+)
+
+func TestBrookServerConfigParsing(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.BrookServerConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"password": "brook-password"
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &brook.ServerConfig{
+				Password: "brook-password",
+			},
+		},
+		{
+			Input: `{
+				"password": "brook-password",
+				"withoutBrook": true,
+				"path": "/ws"
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &brook.ServerConfig{
+				Password:     "brook-password",
+				WithoutBrook: true,
+				Path:         "/ws",
+			},
+		},
+	})
+
+	if _, err := testassist.LoadJSON(creator)(`{"password": "brook-password", "path": "ws"}`); err == nil {
+		t.Error("expected error for path without leading slash")
+	}
+}
@@ -2,9 +2,11 @@ package v4
 
 import (
 	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
 	"github.com/frogwall/f2ray-core/v5/proxy/juicity"
 )
@@ -82,3 +84,39 @@ func (c *JuicityClientConfig) Build() (proto.Message, error) {
 
 	return config, nil
 }
+
+// JuicityUserConfig is configuration of a user of juicity server
+type JuicityUserConfig struct {
+	UUID     string `json:"uuid"`
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// JuicityServerConfig is configuration of juicity server
+type JuicityServerConfig struct {
+	Clients []*JuicityUserConfig `json:"clients"`
+}
+
+// Build implements Buildable
+func (c *JuicityServerConfig) Build() (proto.Message, error) {
+	config := new(juicity.ServerConfig)
+	config.Users = make([]*protocol.User, len(c.Clients))
+	for idx, rawUser := range c.Clients {
+		if _, err := uuid.Parse(rawUser.UUID); err != nil {
+			return nil, newError("Juicity clients: invalid uuid ", rawUser.UUID).Base(err)
+		}
+		if rawUser.Password == "" {
+			return nil, newError("Juicity password is not set.")
+		}
+		config.Users[idx] = &protocol.User{
+			Email: rawUser.Email,
+			Level: uint32(rawUser.Level),
+			Account: serial.ToTypedMessage(&juicity.Account{
+				Uuid:     rawUser.UUID,
+				Password: rawUser.Password,
+			}),
+		}
+	}
+	return config, nil
+}
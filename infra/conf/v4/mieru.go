@@ -1,8 +1,11 @@
 package v4
 
 import (
-	"github.com/frogwall/f2ray-core/v5/proxy/mieru"
 	"github.com/golang/protobuf/proto"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/proxy/mieru"
 )
 
 type MieruServer struct {
@@ -34,3 +37,42 @@ func (v *MieruClientConfig) Build() (proto.Message, error) {
 
 	return config, nil
 }
+
+// MieruUserConfig is configuration of a user of mieru server
+type MieruUserConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// MieruServerConfig is configuration of mieru server
+type MieruServerConfig struct {
+	Clients []*MieruUserConfig `json:"clients"`
+	MTU     int                `json:"mtu"`
+}
+
+// Build implements Buildable
+func (c *MieruServerConfig) Build() (proto.Message, error) {
+	config := &mieru.ServerConfig{
+		Mtu: int32(c.MTU),
+	}
+	config.Users = make([]*protocol.User, len(c.Clients))
+	for idx, rawUser := range c.Clients {
+		if rawUser.Username == "" {
+			return nil, newError("Mieru username is not set.")
+		}
+		if rawUser.Password == "" {
+			return nil, newError("Mieru password is not set.")
+		}
+		config.Users[idx] = &protocol.User{
+			Email: rawUser.Email,
+			Level: uint32(rawUser.Level),
+			Account: serial.ToTypedMessage(&mieru.Account{
+				Username: rawUser.Username,
+				Password: rawUser.Password,
+			}),
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,54 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/frogwall/f2ray-core/v5/infra/conf/v4"
+	"github.com/frogwall/f2ray-core/v5/proxy/mieru"
+)
+
+func TestMieruServerConfigParsing(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.MieruServerConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"clients": [
+					{
+						"username": "mieru",
+						"password": "mieru-password",
+						"email": "love@v2fly.org",
+						"level": 1
+					}
+				],
+				"mtu": 1400
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &mieru.ServerConfig{
+				Users: []*protocol.User{
+					{
+						Email: "love@v2fly.org",
+						Level: 1,
+						Account: serial.ToTypedMessage(&mieru.Account{
+							Username: "mieru",
+							Password: "mieru-password",
+						}),
+					},
+				},
+				Mtu: 1400,
+			},
+		},
+	})
+
+	if _, err := testassist.LoadJSON(creator)(`{
+		"clients": [{"username": "mieru"}]
+	}`); err == nil {
+		t.Error("expected error for client without password")
+	}
+}
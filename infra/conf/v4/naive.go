@@ -6,6 +6,8 @@ import (
 
 	"github.com/golang/protobuf/proto"
 
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
 	naive "github.com/frogwall/f2ray-core/v5/proxy/naive"
 )
 
@@ -86,3 +88,48 @@ func (v *NaiveClientConfig) Build() (proto.Message, error) {
 	}
 	return config, nil
 }
+
+// NaiveUserConfig is configuration of a user of naive server
+type NaiveUserConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// NaiveServerConfig is configuration of naive server
+type NaiveServerConfig struct {
+	Clients  []*NaiveUserConfig `json:"clients"`
+	Fallback string             `json:"fallback"`
+}
+
+// Build implements Buildable
+func (c *NaiveServerConfig) Build() (proto.Message, error) {
+	config := new(naive.ServerConfig)
+	config.Users = make([]*protocol.User, len(c.Clients))
+	for idx, rawUser := range c.Clients {
+		if rawUser.Username == "" {
+			return nil, newError("Naive username is not set.")
+		}
+		config.Users[idx] = &protocol.User{
+			Email: rawUser.Email,
+			Level: uint32(rawUser.Level),
+			Account: serial.ToTypedMessage(&naive.Account{
+				Username: rawUser.Username,
+				Password: rawUser.Password,
+			}),
+		}
+	}
+
+	if c.Fallback != "" {
+		u, err := url.Parse(c.Fallback)
+		if err != nil {
+			return nil, newError("invalid naive fallback URL").Base(err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, newError("naive fallback must be an http or https URL")
+		}
+		config.FallbackUrl = c.Fallback
+	}
+	return config, nil
+}
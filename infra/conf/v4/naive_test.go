@@ -0,0 +1,55 @@
+package v4_test
+
+import (
+	"testing"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
+	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/testassist"
+	v4 "github.com/frogwall/f2ray-core/v5/infra/conf/v4"
+	"github.com/frogwall/f2ray-core/v5/proxy/naive"
+)
+
+func TestNaiveServerConfigParsing(t *testing.T) {
+	creator := func() cfgcommon.Buildable {
+		return new(v4.NaiveServerConfig)
+	}
+
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"clients": [
+					{
+						"username": "naive",
+						"password": "naive-password",
+						"email": "love@v2fly.org",
+						"level": 1
+					}
+				],
+				"fallback": "http://127.0.0.1:8080"
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &naive.ServerConfig{
+				Users: []*protocol.User{
+					{
+						Email: "love@v2fly.org",
+						Level: 1,
+						Account: serial.ToTypedMessage(&naive.Account{
+							Username: "naive",
+							Password: "naive-password",
+						}),
+					},
+				},
+				FallbackUrl: "http://127.0.0.1:8080",
+			},
+		},
+	})
+
+	if _, err := testassist.LoadJSON(creator)(`{
+		"clients": [{"username": "naive", "password": "naive-password"}],
+		"fallback": "/var/www"
+	}`); err == nil {
+		t.Error("expected error for fallback which is not an http URL")
+	}
+}
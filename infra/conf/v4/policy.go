@@ -5,13 +5,28 @@ import (
 )
 
 type Policy struct {
-	Handshake         *uint32 `json:"handshake"`
-	ConnectionIdle    *uint32 `json:"connIdle"`
-	UplinkOnly        *uint32 `json:"uplinkOnly"`
-	DownlinkOnly      *uint32 `json:"downlinkOnly"`
-	StatsUserUplink   bool    `json:"statsUserUplink"`
-	StatsUserDownlink bool    `json:"statsUserDownlink"`
-	BufferSize        *int32  `json:"bufferSize"`
+	Handshake         *uint32    `json:"handshake"`
+	ConnectionIdle    *uint32    `json:"connIdle"`
+	UplinkOnly        *uint32    `json:"uplinkOnly"`
+	DownlinkOnly      *uint32    `json:"downlinkOnly"`
+	StatsUserUplink   bool       `json:"statsUserUplink"`
+	StatsUserDownlink bool       `json:"statsUserDownlink"`
+	BufferSize        *int32     `json:"bufferSize"`
+	Bandwidth         *Bandwidth `json:"bandwidth"`
+}
+
+// Bandwidth is the rate limits of traffic, in bytes per second. 0 for
+// unlimited.
+type Bandwidth struct {
+	Uplink   uint64 `json:"uplink"`
+	Downlink uint64 `json:"downlink"`
+}
+
+func (b *Bandwidth) Build() *policy.Policy_Bandwidth {
+	return &policy.Policy_Bandwidth{
+		Uplink:   b.Uplink,
+		Downlink: b.Downlink,
+	}
 }
 
 func (t *Policy) Build() (*policy.Policy, error) {
@@ -47,32 +62,44 @@ func (t *Policy) Build() (*policy.Policy, error) {
 		}
 	}
 
+	if t.Bandwidth != nil {
+		p.Bandwidth = t.Bandwidth.Build()
+	}
+
 	return p, nil
 }
 
 type SystemPolicy struct {
-	StatsInboundUplink    bool `json:"statsInboundUplink"`
-	StatsInboundDownlink  bool `json:"statsInboundDownlink"`
-	StatsOutboundUplink   bool `json:"statsOutboundUplink"`
-	StatsOutboundDownlink bool `json:"statsOutboundDownlink"`
-	OverrideAccessLogDest bool `json:"overrideAccessLogDest"`
+	StatsInboundUplink      bool `json:"statsInboundUplink"`
+	StatsInboundDownlink    bool `json:"statsInboundDownlink"`
+	StatsOutboundUplink     bool `json:"statsOutboundUplink"`
+	StatsOutboundDownlink   bool `json:"statsOutboundDownlink"`
+	StatsInboundConnection  bool `json:"statsInboundConnection"`
+	StatsOutboundConnection bool `json:"statsOutboundConnection"`
+	StatsConnectionTracking bool `json:"statsConnectionTracking"`
+	OverrideAccessLogDest   bool `json:"overrideAccessLogDest"`
 }
 
 func (p *SystemPolicy) Build() (*policy.SystemPolicy, error) {
 	return &policy.SystemPolicy{
 		Stats: &policy.SystemPolicy_Stats{
-			InboundUplink:    p.StatsInboundUplink,
-			InboundDownlink:  p.StatsInboundDownlink,
-			OutboundUplink:   p.StatsOutboundUplink,
-			OutboundDownlink: p.StatsOutboundDownlink,
+			InboundUplink:      p.StatsInboundUplink,
+			InboundDownlink:    p.StatsInboundDownlink,
+			OutboundUplink:     p.StatsOutboundUplink,
+			OutboundDownlink:   p.StatsOutboundDownlink,
+			InboundConnection:  p.StatsInboundConnection,
+			OutboundConnection: p.StatsOutboundConnection,
+			ConnectionTracking: p.StatsConnectionTracking,
 		},
 		OverrideAccessLogDest: p.OverrideAccessLogDest,
 	}, nil
 }
 
 type PolicyConfig struct {
-	Levels map[uint32]*Policy `json:"levels"`
-	System *SystemPolicy      `json:"system"`
+	Levels   map[uint32]*Policy    `json:"levels"`
+	System   *SystemPolicy         `json:"system"`
+	Users    map[string]*Bandwidth `json:"users"`
+	Inbounds map[string]*Bandwidth `json:"inbounds"`
 }
 
 func (c *PolicyConfig) Build() (*policy.Config, error) {
@@ -90,6 +117,23 @@ func (c *PolicyConfig) Build() (*policy.Config, error) {
 		Level: levels,
 	}
 
+	for email, b := range c.Users {
+		if b != nil {
+			if config.User == nil {
+				config.User = make(map[string]*policy.Policy_Bandwidth)
+			}
+			config.User[email] = b.Build()
+		}
+	}
+	for tag, b := range c.Inbounds {
+		if b != nil {
+			if config.Inbound == nil {
+				config.Inbound = make(map[string]*policy.Policy_Bandwidth)
+			}
+			config.Inbound[tag] = b.Build()
+		}
+	}
+
 	if c.System != nil {
 		sc, err := c.System.Build()
 		if err != nil {
@@ -38,3 +38,28 @@ func TestBufferSize(t *testing.T) {
 		}
 	}
 }
+
+func TestPolicyBandwidth(t *testing.T) {
+	pConf := v4.PolicyConfig{
+		Levels: map[uint32]*v4.Policy{
+			0: {Bandwidth: &v4.Bandwidth{Uplink: 1024, Downlink: 2048}},
+		},
+		Users: map[string]*v4.Bandwidth{
+			"love@v2fly.org": {Downlink: 4096},
+		},
+		Inbounds: map[string]*v4.Bandwidth{
+			"in": {Uplink: 8192},
+		},
+	}
+	config, err := pConf.Build()
+	common.Must(err)
+	if b := config.Level[0].Bandwidth; b.Uplink != 1024 || b.Downlink != 2048 {
+		t.Error("unexpected level bandwidth ", b)
+	}
+	if b := config.User["love@v2fly.org"]; b.Uplink != 0 || b.Downlink != 4096 {
+		t.Error("unexpected user bandwidth ", b)
+	}
+	if b := config.Inbound["in"]; b.Uplink != 8192 || b.Downlink != 0 {
+		t.Error("unexpected inbound bandwidth ", b)
+	}
+}
@@ -100,7 +100,7 @@ func (v *ShadowsocksServerConfig) buildShadowsocks2022Config() (proto.Message, e
 	switch v.Cipher {
 	case "2022-blake3-aes-128-gcm":
 		expectedLen = 16
-	case "2022-blake3-aes-256-gcm":
+	case "2022-blake3-aes-256-gcm", "2022-blake3-chacha20-poly1305":
 		expectedLen = 32
 	default:
 		return nil, newError("unsupported Shadowsocks-2022 method: ", v.Cipher)
@@ -142,6 +142,9 @@ func (v *ShadowsocksServerConfig) buildShadowsocks2022Config() (proto.Message, e
 	}
 
 	// Parse users (multi-user mode)
+	if len(v.Users) > 0 && v.Cipher == "2022-blake3-chacha20-poly1305" {
+		return nil, newError(v.Cipher, " does not support multiple users")
+	}
 	if len(v.Users) > 0 {
 		// Multi-user mode
 		for _, user := range v.Users {
@@ -1,8 +1,10 @@
 package v4_test
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/protocol"
 	"github.com/frogwall/f2ray-core/v5/common/serial"
@@ -10,6 +12,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon/testassist"
 	v4 "github.com/frogwall/f2ray-core/v5/infra/conf/v4"
 	"github.com/frogwall/f2ray-core/v5/proxy/shadowsocks"
+	"github.com/frogwall/f2ray-core/v5/proxy/shadowsocks2022"
 )
 
 func TestShadowsocksServerConfigParsing(t *testing.T) {
@@ -34,5 +37,33 @@ func TestShadowsocksServerConfigParsing(t *testing.T) {
 				Network: []net.Network{net.Network_TCP},
 			},
 		},
+		{
+			Input: `{
+				"method": "2022-blake3-chacha20-poly1305",
+				"password": "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+				"network": "tcp,udp"
+			}`,
+			Parser: testassist.LoadJSON(creator),
+			Output: &shadowsocks2022.ServerConfig{
+				Method: "2022-blake3-chacha20-poly1305",
+				Psk: []byte{
+					0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+					16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
+				},
+				Network: []net.Network{net.Network_TCP, net.Network_UDP},
+			},
+		},
 	})
 }
+
+func TestShadowsocks2022ChaCha20MultiUser(t *testing.T) {
+	config := new(v4.ShadowsocksServerConfig)
+	common.Must(json.Unmarshal([]byte(`{
+		"method": "2022-blake3-chacha20-poly1305",
+		"password": "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+		"users": [{"password": "HyAhIiMkJSYnKCkqKywtLi8wMTIzNDU2Nzg5Ojs8PT4=", "email": "love@v2fly.org"}]
+	}`), config))
+	if _, err := config.Build(); err == nil {
+		t.Error("expected error for multiple users with 2022-blake3-chacha20-poly1305")
+	}
+}
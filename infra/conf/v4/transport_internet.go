@@ -4,6 +4,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/golang/protobuf/proto"
@@ -307,8 +308,8 @@ func (c *DomainSocketConfig) Build() (proto.Message, error) {
 	}, nil
 }
 
-// RealitySecurityConfig is the v4 JSON for REALITY security (client side only for now).
-// Note: actual proto.Config will be built after protobuf generation is added.
+// RealitySecurityConfig is the v4 JSON for REALITY security.
+// Client side uses publicKey and shortId, server side is enabled by privateKey.
 type RealitySecurityConfig struct {
 	ServerName  string `json:"serverName"`
 	PublicKey   string `json:"publicKey"`   // hex
@@ -316,6 +317,33 @@ type RealitySecurityConfig struct {
 	Fingerprint string `json:"fingerprint"` // utls fingerprint name
 	Show        bool   `json:"show"`
 	SpiderX     string `json:"spiderX"`
+
+	PrivateKey   string   `json:"privateKey"`   // hex or base64
+	ServerNames  []string `json:"serverNames"`  // accepted SNI
+	Dest         string   `json:"dest"`         // decoy target, host:port or unix path
+	ShortIds     []string `json:"shortIds"`     // hex (<= 8 bytes)
+	MaxTimeDiff  uint64   `json:"maxTimeDiff"`  // milliseconds
+	MinClientVer string   `json:"minClientVer"` // x.y.z
+	MaxClientVer string   `json:"maxClientVer"` // x.y.z
+	Xver         uint32   `json:"xver"`
+}
+
+func parseRealityClientVersion(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ver := make([]byte, 3)
+	for i, part := range strings.Split(s, ".") {
+		if i >= len(ver) {
+			return nil, newError("invalid REALITY client version: ", s)
+		}
+		n, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return nil, newError("invalid REALITY client version: ", s).Base(err)
+		}
+		ver[i] = byte(n)
+	}
+	return ver, nil
 }
 
 // Build implements Buildable.
@@ -365,14 +393,57 @@ func (c *RealitySecurityConfig) Build() (proto.Message, error) {
 			return nil, newError("REALITY shortId must be <= 16 bytes")
 		}
 	}
-	return &reality.Config{
+	config := &reality.Config{
 		ServerName:  c.ServerName,
 		PublicKey:   pub,
 		ShortId:     sid,
 		Fingerprint: c.Fingerprint,
 		Show:        c.Show,
 		SpiderX:     c.SpiderX,
-	}, nil
+	}
+	if c.PrivateKey == "" {
+		return config, nil
+	}
+
+	if config.PrivateKey, err = decodeFlexible(c.PrivateKey); err != nil {
+		return nil, newError("Failed to decode REALITY privateKey").Base(err)
+	}
+	if len(config.PrivateKey) != 32 {
+		return nil, newError("REALITY privateKey must be 32 bytes (X25519)")
+	}
+	if c.Dest == "" {
+		return nil, newError("REALITY dest is required on server side")
+	}
+	if len(c.ServerNames) == 0 {
+		return nil, newError("REALITY serverNames is required on server side")
+	}
+	if len(c.ShortIds) == 0 {
+		return nil, newError("REALITY shortIds is required on server side")
+	}
+	for _, s := range c.ShortIds {
+		id, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, newError("Failed to decode REALITY shortId ", s).Base(err)
+		}
+		if len(id) > 8 {
+			return nil, newError("REALITY shortId ", s, " must be <= 8 bytes")
+		}
+		config.ShortIds = append(config.ShortIds, id)
+	}
+	if config.MinClientVer, err = parseRealityClientVersion(c.MinClientVer); err != nil {
+		return nil, err
+	}
+	if config.MaxClientVer, err = parseRealityClientVersion(c.MaxClientVer); err != nil {
+		return nil, err
+	}
+	if c.Xver > 2 {
+		return nil, newError("invalid PROXY protocol version for REALITY dest: ", c.Xver)
+	}
+	config.ServerNames = c.ServerNames
+	config.Dest = c.Dest
+	config.MaxTimeDiff = c.MaxTimeDiff
+	config.Xver = c.Xver
+	return config, nil
 }
 
 type TransportProtocol string
@@ -398,6 +469,8 @@ func (p TransportProtocol) Build() (string, error) {
 		return "hysteria2", nil
 	case "shadowtls":
 		return "shadowtls", nil
+	case "tuic", "juicity":
+		return "tuic", nil
 	default:
 		return "", newError("Config: unknown transport protocol: ", p)
 	}
@@ -418,6 +491,7 @@ type StreamConfig struct {
 	GRPCSettings      *GunConfig              `json:"grpcSettings"`
 	Hy2Settings       *Hy2Config              `json:"hy2Settings"`
 	ShadowTLSSettings *ShadowTLSConfig        `json:"shadowtlsSettings"`
+	TUICSettings      *TUICTransportConfig    `json:"tuicSettings"`
 	SocketSettings    *socketcfg.SocketConfig `json:"sockopt"`
 }
 
@@ -552,6 +626,16 @@ func (c *StreamConfig) Build() (*internet.StreamConfig, error) {
 			Settings:     serial.ToTypedMessage(st),
 		})
 	}
+	if c.TUICSettings != nil {
+		ts, err := c.TUICSettings.Build()
+		if err != nil {
+			return nil, newError("Failed to build TUIC config.").Base(err)
+		}
+		config.TransportSettings = append(config.TransportSettings, &internet.TransportConfig{
+			ProtocolName: "tuic",
+			Settings:     serial.ToTypedMessage(ts),
+		})
+	}
 	if c.SocketSettings != nil {
 		ss, err := c.SocketSettings.Build()
 		if err != nil {
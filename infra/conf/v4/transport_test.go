@@ -18,6 +18,7 @@ import (
 	"github.com/frogwall/f2ray-core/v5/transport/internet/headers/tls"
 	"github.com/frogwall/f2ray-core/v5/transport/internet/kcp"
 	"github.com/frogwall/f2ray-core/v5/transport/internet/quic"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/reality"
 	"github.com/frogwall/f2ray-core/v5/transport/internet/tcp"
 	"github.com/frogwall/f2ray-core/v5/transport/internet/websocket"
 )
@@ -174,3 +175,51 @@ func TestTransportConfig(t *testing.T) {
 		},
 	})
 }
+
+func TestRealityServerConfig(t *testing.T) {
+	createParser := func() func(string) (proto.Message, error) {
+		return func(s string) (proto.Message, error) {
+			config := new(v4.RealitySecurityConfig)
+			if err := json.Unmarshal([]byte(s), config); err != nil {
+				return nil, err
+			}
+			return config.Build()
+		}
+	}
+
+	privateKey := make([]byte, 32)
+	privateKey[0] = 1
+	testassist.RunMultiTestCase(t, []testassist.TestCase{
+		{
+			Input: `{
+				"privateKey": "0100000000000000000000000000000000000000000000000000000000000000",
+				"dest": "example.com:443",
+				"serverNames": ["example.com"],
+				"shortIds": ["", "0123456789abcdef"],
+				"maxTimeDiff": 60000,
+				"minClientVer": "1.8.0",
+				"xver": 1
+			}`,
+			Parser: createParser(),
+			Output: &reality.Config{
+				PrivateKey:   privateKey,
+				Dest:         "example.com:443",
+				ServerNames:  []string{"example.com"},
+				ShortIds:     [][]byte{{}, {0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef}},
+				MaxTimeDiff:  60000,
+				MinClientVer: []byte{1, 8, 0},
+				Xver:         1,
+			},
+		},
+	})
+
+	for _, input := range []string{
+		`{"privateKey": "0100000000000000000000000000000000000000000000000000000000000000", "serverNames": ["example.com"], "shortIds": [""]}`,
+		`{"privateKey": "0100000000000000000000000000000000000000000000000000000000000000", "dest": "example.com:443", "serverNames": ["example.com"], "shortIds": ["0123456789abcdef01"]}`,
+		`{"privateKey": "0100", "dest": "example.com:443", "serverNames": ["example.com"], "shortIds": [""]}`,
+	} {
+		if _, err := createParser()(input); err == nil {
+			t.Error("expected error for ", input)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package v4
 
 import (
+	"strings"
+
 	"github.com/golang/protobuf/proto"
+	"github.com/google/uuid"
 
 	"github.com/frogwall/f2ray-core/v5/common/protocol"
 	"github.com/frogwall/f2ray-core/v5/common/serial"
 	"github.com/frogwall/f2ray-core/v5/infra/conf/cfgcommon"
 	"github.com/frogwall/f2ray-core/v5/proxy/tuic"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
 )
 
 type TUICServerTarget struct {
@@ -91,3 +95,74 @@ func (c *TUICClientConfig) Build() (proto.Message, error) {
 
 	return config, nil
 }
+
+type TUICUserConfig struct {
+	UUID     string `json:"uuid"`
+	Password string `json:"password"`
+	Level    byte   `json:"level"`
+	Email    string `json:"email"`
+}
+
+// TUICServerConfig is Inbound configuration
+type TUICServerConfig struct {
+	Clients               []*TUICUserConfig `json:"clients"`
+	AuthTimeout           int64             `json:"authTimeout"`
+	MaxUdpRelayPacketSize int32             `json:"maxUdpRelayPacketSize"`
+	UDPIdleTimeout        int64             `json:"udpIdleTimeout"`
+}
+
+// Build implements Buildable
+func (c *TUICServerConfig) Build() (proto.Message, error) {
+	config := &tuic.ServerConfig{
+		AuthTimeout:           c.AuthTimeout,
+		MaxUdpRelayPacketSize: c.MaxUdpRelayPacketSize,
+		UdpIdleTimeout:        c.UDPIdleTimeout,
+	}
+	for _, rawUser := range c.Clients {
+		if _, err := uuid.Parse(rawUser.UUID); err != nil {
+			return nil, newError("TUIC clients: invalid uuid ", rawUser.UUID).Base(err)
+		}
+		account := &tuic.Account{
+			Uuid:     rawUser.UUID,
+			Password: rawUser.Password,
+		}
+		config.Users = append(config.Users, &protocol.User{
+			Email:   rawUser.Email,
+			Level:   uint32(rawUser.Level),
+			Account: serial.ToTypedMessage(account),
+		})
+	}
+	return config, nil
+}
+
+type TUICTransportConfig struct {
+	CongestionControl              string `json:"congestionControl"`
+	ZeroRTTHandshake               bool   `json:"zeroRttHandshake"`
+	MaxIdleTimeout                 int64  `json:"maxIdleTimeout"`
+	KeepAlivePeriod                int64  `json:"keepAlivePeriod"`
+	InitialStreamReceiveWindow     uint64 `json:"initialStreamReceiveWindow"`
+	MaxStreamReceiveWindow         uint64 `json:"maxStreamReceiveWindow"`
+	InitialConnectionReceiveWindow uint64 `json:"initialConnectionReceiveWindow"`
+	MaxConnectionReceiveWindow     uint64 `json:"maxConnectionReceiveWindow"`
+	DisablePathMTUDiscovery        bool   `json:"disablePathMtuDiscovery"`
+}
+
+// Build implements Buildable.
+func (c *TUICTransportConfig) Build() (proto.Message, error) {
+	switch strings.ToLower(c.CongestionControl) {
+	case "", "bbr", "cubic":
+	default:
+		return nil, newError("TUIC transport: unsupported congestion control ", c.CongestionControl)
+	}
+	return &tuicTransport.Config{
+		CongestionControl:              strings.ToLower(c.CongestionControl),
+		ZeroRttHandshake:               c.ZeroRTTHandshake,
+		MaxIdleTimeout:                 c.MaxIdleTimeout,
+		KeepAlivePeriod:                c.KeepAlivePeriod,
+		InitialStreamReceiveWindow:     c.InitialStreamReceiveWindow,
+		MaxStreamReceiveWindow:         c.MaxStreamReceiveWindow,
+		InitialConnectionReceiveWindow: c.InitialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:     c.MaxConnectionReceiveWindow,
+		DisablePathMtuDiscovery:        c.DisablePathMTUDiscovery,
+	}, nil
+}
@@ -89,3 +89,54 @@ func TestTUICConfigWithStreamSettingsNative(t *testing.T) {
 
 	assert.Equal(t, "native", tuicConfig.UdpRelayMode)
 }
+
+func TestTUICInboundConfig(t *testing.T) {
+	jsonConfig := `{
+		"protocol": "tuic",
+		"port": 8443,
+		"settings": {
+			"clients": [
+				{
+					"uuid": "fe35d05b-8803-45c4-bae6-723ad2cd5d3d",
+					"password": "password",
+					"email": "love@v2fly.org"
+				}
+			],
+			"authTimeout": 5000
+		},
+		"streamSettings": {
+			"network": "tuic",
+			"security": "tls",
+			"tuicSettings": {
+				"congestionControl": "bbr",
+				"zeroRttHandshake": true
+			}
+		}
+	}`
+
+	config := new(v4.InboundDetourConfig)
+	err := json.Unmarshal([]byte(jsonConfig), config)
+	assert.NoError(t, err)
+
+	inboundConfig, err := config.Build()
+	assert.NoError(t, err)
+
+	tuicConfig := new(tuic.ServerConfig)
+	err = inboundConfig.ProxySettings.UnmarshalTo(tuicConfig)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(5000), tuicConfig.AuthTimeout)
+	assert.Len(t, tuicConfig.Users, 1)
+	assert.Equal(t, "love@v2fly.org", tuicConfig.Users[0].Email)
+}
+
+func TestTUICTransportConfigCongestionControl(t *testing.T) {
+	for _, congestionControl := range []string{"", "bbr", "cubic", "BBR"} {
+		_, err := (&v4.TUICTransportConfig{CongestionControl: congestionControl}).Build()
+		assert.NoError(t, err, congestionControl)
+	}
+	for _, congestionControl := range []string{"new_reno", "brutal"} {
+		_, err := (&v4.TUICTransportConfig{CongestionControl: congestionControl}).Build()
+		assert.Error(t, err, congestionControl)
+	}
+}
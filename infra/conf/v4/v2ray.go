@@ -36,6 +36,12 @@ var (
 		"vmess":         func() interface{} { return new(VMessInboundConfig) },
 		"trojan":        func() interface{} { return new(TrojanServerConfig) },
 		"hysteria2":     func() interface{} { return new(Hysteria2ServerConfig) },
+		"tuic":          func() interface{} { return new(TUICServerConfig) },
+		"anytls":        func() interface{} { return new(AnyTLSInboundConfig) },
+		"juicity":       func() interface{} { return new(JuicityServerConfig) },
+		"naive":         func() interface{} { return new(NaiveServerConfig) },
+		"mieru":         func() interface{} { return new(MieruServerConfig) },
+		"brook":         func() interface{} { return new(BrookServerConfig) },
 	}, "protocol", "settings")
 
 	outboundConfigLoader = loader.NewJSONConfigLoader(loader.ConfigCreatorCache{
@@ -15,5 +15,6 @@ var CmdAPI = &base.Command{
 		cmdStats,
 		cmdBalancerInfo,
 		cmdBalancerOverride,
+		cmdListRules,
 	},
 }
@@ -7,5 +7,7 @@ func init() {
 		cmdAddInbounds,
 		cmdAddOutbounds,
 		cmdRemoveInbounds,
-		cmdRemoveOutbounds)
+		cmdRemoveOutbounds,
+		cmdAddRules,
+		cmdRemoveRules)
 }
@@ -0,0 +1,115 @@
+package jsonv4
+
+import (
+	"fmt"
+
+	routerService "github.com/frogwall/f2ray-core/v5/app/router/command"
+	"github.com/frogwall/f2ray-core/v5/main/commands/all/api"
+	"github.com/frogwall/f2ray-core/v5/main/commands/base"
+	"github.com/frogwall/f2ray-core/v5/main/commands/helpers"
+)
+
+var cmdAddRules = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} api adr [--server=127.0.0.1:8080] [c1.json] [dir1]...",
+	Short:       "add routing rules",
+	Long: `
+Add routing rules and balancers in "routing" of the configs to V2Ray. 
+Balancers are added before rules, so that rules can target them.
+
+> Make sure you have "RoutingService" set in "config.api.services" 
+of server config.
+
+Arguments:
+
+	-format <format>
+		The input format.
+		Available values: "auto", "json", "toml", "yaml"
+		Default: "auto"
+
+	-r
+		Load folders recursively.
+
+	-i, -insert <position>
+		Insert the rules before the rule at the position, as listed 
+		by "{{.Exec}} api lsr". Rules are appended by default.
+
+	-replace
+		Replace the existing rules and balancers with the same tags, 
+		in place. Rules without "ruleTag" are added.
+
+	-s, -server <server:port>
+		The API server address. Default 127.0.0.1:8080
+
+	-t, -timeout <seconds>
+		Timeout seconds to call API. Default 3
+
+Example:
+
+    {{.Exec}} {{.LongName}} rules.json
+    {{.Exec}} {{.LongName}} -i 1 rules.json
+    {{.Exec}} {{.LongName}} -replace rules.json
+`,
+	Run: executeAddRules,
+}
+
+func executeAddRules(cmd *base.Command, args []string) {
+	var (
+		position int
+		replace  bool
+	)
+	api.SetSharedFlags(cmd)
+	api.SetSharedConfigFlags(cmd)
+	cmd.Flag.IntVar(&position, "i", 0, "")
+	cmd.Flag.IntVar(&position, "insert", 0, "")
+	cmd.Flag.BoolVar(&replace, "replace", false, "")
+	cmd.Flag.Parse(args)
+
+	c, err := helpers.LoadConfig(cmd.Flag.Args(), api.APIConfigFormat, api.APIConfigRecursively)
+	if err != nil {
+		base.Fatalf("failed to load: %s", err)
+	}
+	if c.RouterConfig == nil {
+		base.Fatalf("no routing config found")
+	}
+	config, err := c.RouterConfig.Build()
+	if err != nil {
+		base.Fatalf("failed to build conf: %s", err)
+	}
+	if len(config.Rule) == 0 && len(config.BalancingRule) == 0 {
+		base.Fatalf("no valid rule found")
+	}
+
+	conn, ctx, close := api.DialAPIServer()
+	defer close()
+
+	client := routerService.NewRoutingServiceClient(conn)
+	for _, b := range config.BalancingRule {
+		if replace {
+			fmt.Println("replacing balancer:", b.Tag)
+			_, err = client.ReplaceBalancer(ctx, &routerService.ReplaceBalancerRequest{Tag: b.Tag, Balancer: b})
+		} else {
+			fmt.Println("adding balancer:", b.Tag)
+			_, err = client.AddBalancer(ctx, &routerService.AddBalancerRequest{Balancer: b})
+		}
+		if err != nil {
+			base.Fatalf("failed to add balancer: %s", err)
+		}
+	}
+	for i, rule := range config.Rule {
+		switch {
+		case replace && rule.RuleTag != "":
+			fmt.Println("replacing rule:", rule.RuleTag)
+			_, err = client.ReplaceRule(ctx, &routerService.ReplaceRuleRequest{Tag: rule.RuleTag, Rule: rule})
+		case position > 0:
+			fmt.Println("inserting rule:", rule.RuleTag)
+			_, err = client.InsertRule(ctx, &routerService.InsertRuleRequest{Index: int32(position - 1 + i), Rule: rule})
+		default:
+			fmt.Println("adding rule:", rule.RuleTag)
+			_, err = client.AddRule(ctx, &routerService.AddRuleRequest{Rule: rule})
+		}
+		if err != nil {
+			base.Fatalf("failed to add rule: %s", err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"os"
+	"strings"
+
+	routerService "github.com/frogwall/f2ray-core/v5/app/router/command"
+	"github.com/frogwall/f2ray-core/v5/main/commands/base"
+)
+
+var cmdListRules = &base.Command{
+	CustomFlags: true,
+	UsageLine:   "{{.Exec}} api lsr [--server=127.0.0.1:8080]",
+	Short:       "list routing rules",
+	Long: `
+List routing rules in order, and balancers of the running router.
+
+> Make sure you have "RoutingService" set in "config.api.services" 
+of server config.
+
+Arguments:
+
+	-json
+		Use json output.
+
+	-s, -server <server:port>
+		The API server address. Default 127.0.0.1:8080
+
+	-t, -timeout <seconds>
+		Timeout seconds to call API. Default 3
+
+Example:
+
+    {{.Exec}} {{.LongName}} --server=127.0.0.1:8080
+`,
+	Run: executeListRules,
+}
+
+func executeListRules(cmd *base.Command, args []string) {
+	setSharedFlags(cmd)
+	cmd.Flag.Parse(args)
+
+	conn, ctx, close := dialAPIServer()
+	defer close()
+
+	client := routerService.NewRoutingServiceClient(conn)
+	resp, err := client.ListRules(ctx, &routerService.ListRulesRequest{})
+	if err != nil {
+		base.Fatalf("failed to list rules: %s", err)
+	}
+
+	if apiJSON {
+		showJSONResponse(resp)
+		return
+	}
+
+	const tableIndent = 4
+	sb := new(strings.Builder)
+	sb.WriteString("  - Rules:\n")
+	titles := []string{"Tag", "Target"}
+	formats := getColumnFormats([]string{"Tag            ", "Target"})
+	writeRow(sb, tableIndent, 0, titles, formats)
+	for i, rule := range resp.Rules {
+		target := rule.GetTag()
+		if b := rule.GetBalancingTag(); b != "" {
+			target = "balancer:" + b
+		}
+		writeRow(sb, tableIndent, i+1, []string{rule.RuleTag, target}, formats)
+	}
+	sb.WriteString("  - Balancers:\n")
+	titles = []string{"Tag", "Strategy", "Selectors"}
+	formats = getColumnFormats([]string{"Tag            ", "Strategy  ", "Selectors"})
+	writeRow(sb, tableIndent, 0, titles, formats)
+	for i, balancer := range resp.Balancers {
+		strategy := balancer.Strategy
+		if strategy == "" {
+			strategy = "random"
+		}
+		writeRow(sb, tableIndent, i+1, []string{balancer.Tag, strategy, strings.Join(balancer.OutboundSelector, ",")}, formats)
+	}
+	os.Stdout.WriteString(sb.String())
+}
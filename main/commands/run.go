@@ -12,6 +12,7 @@ import (
 
 	core "github.com/frogwall/f2ray-core/v5"
 	"github.com/frogwall/f2ray-core/v5/common/cmdarg"
+	"github.com/frogwall/f2ray-core/v5/common/errors"
 	"github.com/frogwall/f2ray-core/v5/common/platform"
 	"github.com/frogwall/f2ray-core/v5/main/commands/base"
 	"github.com/frogwall/f2ray-core/v5/main/plugins"
@@ -35,6 +36,10 @@ to load config from one of below:
 
 The process writes its PID to /tmp/f2ray.pid and supports SIGHUP
 for configuration reload. Use "{{.Exec}} reload" to reload config.
+On reload, only the changed inbounds, outbounds, routing, DNS and log
+settings are replaced, and live connections of others are kept. Other
+changes restart the instance. A bad config is rejected and the running
+instance is kept.
 
 Arguments:
 
@@ -87,6 +92,7 @@ func executeRun(cmd *base.Command, args []string) {
 	}
 	cmd.Flag.Parse(args)
 	printVersion()
+	flagConfigFiles := append(cmdarg.Arg(nil), configFiles...)
 	configFiles = getConfigFilePath()
 	server, err := startV2Ray()
 	if err != nil {
@@ -130,15 +136,11 @@ func executeRun(cmd *base.Command, args []string) {
 		case syscall.SIGHUP:
 			// Reload configuration
 			log.Println("Received SIGHUP, reloading configuration...")
-			server.Close()
+			configFiles = append(cmdarg.Arg(nil), flagConfigFiles...)
 			configFiles = getConfigFilePath()
-			s, err := startV2Ray()
+			s, err := reloadV2Ray(server)
 			if err != nil {
-				log.Printf("reload failed to create server: %v", err)
-				continue
-			}
-			if err := s.Start(); err != nil {
-				log.Printf("reload failed to start: %v", err)
+				log.Printf("reload failed: %v", err)
 				continue
 			}
 			server = s
@@ -243,7 +245,7 @@ func getConfigFilePath() cmdarg.Arg {
 	return nil
 }
 
-func startV2Ray() (core.Server, error) {
+func loadConfig() (*core.Config, error) {
 	config, err := core.LoadConfig(*configFormat, configFiles)
 	if err != nil {
 		if len(configFiles) == 0 {
@@ -253,6 +255,14 @@ func startV2Ray() (core.Server, error) {
 		}
 		return nil, err
 	}
+	return config, nil
+}
+
+func startV2Ray() (core.Server, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
 
 	server, err := core.New(config)
 	if err != nil {
@@ -261,3 +271,34 @@ func startV2Ray() (core.Server, error) {
 
 	return server, nil
 }
+
+// reloadV2Ray applies the config to the running server, only the changed
+// parts are replaced. If that is not possible, the server is restarted with
+// the config. A bad config is rejected and the running server is kept.
+func reloadV2Ray(server core.Server) (core.Server, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if instance, ok := server.(*core.Instance); ok {
+		err := instance.Reload(config)
+		if err == nil {
+			return server, nil
+		}
+		if errors.Cause(err) != core.ErrRestartRequired {
+			return nil, err
+		}
+		log.Printf("Restarting to apply the config: %v", err)
+	}
+
+	newServer, err := core.New(config)
+	if err != nil {
+		return nil, newError("failed to create server").Base(err)
+	}
+	server.Close()
+	if err := newServer.Start(); err != nil {
+		return nil, newError("failed to start server").Base(err)
+	}
+	return newServer, nil
+}
@@ -11,6 +11,7 @@ import (
 	// Default commander and all its services. This is an optional feature.
 	_ "github.com/frogwall/f2ray-core/v5/app/commander"
 	_ "github.com/frogwall/f2ray-core/v5/app/log/command"
+	_ "github.com/frogwall/f2ray-core/v5/app/policy/command"
 	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/command"
 	_ "github.com/frogwall/f2ray-core/v5/app/stats/command"
 
@@ -22,6 +23,7 @@ import (
 	_ "github.com/frogwall/f2ray-core/v5/app/dns"
 	_ "github.com/frogwall/f2ray-core/v5/app/dns/fakedns"
 	_ "github.com/frogwall/f2ray-core/v5/app/log"
+	_ "github.com/frogwall/f2ray-core/v5/app/metrics"
 	_ "github.com/frogwall/f2ray-core/v5/app/policy"
 	_ "github.com/frogwall/f2ray-core/v5/app/restfulapi"
 	_ "github.com/frogwall/f2ray-core/v5/app/reverse"
@@ -61,7 +63,9 @@ import (
 	_ "github.com/frogwall/f2ray-core/v5/proxy/hysteria2"
 	_ "github.com/frogwall/f2ray-core/v5/proxy/juicity"
 	_ "github.com/frogwall/f2ray-core/v5/proxy/mieru"
+	_ "github.com/frogwall/f2ray-core/v5/proxy/naive"
 	_ "github.com/frogwall/f2ray-core/v5/proxy/shadowsocks2022"
+	_ "github.com/frogwall/f2ray-core/v5/proxy/tuic"
 
 	// Transports
 	_ "github.com/frogwall/f2ray-core/v5/transport/internet/domainsocket"
@@ -94,6 +98,8 @@ import (
 
 	_ "github.com/frogwall/f2ray-core/v5/transport/internet/shadowtls"
 
+	_ "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+
 	_ "github.com/frogwall/f2ray-core/v5/transport/internet/tlsmirror/server"
 
 	// Transport headers
@@ -0,0 +1,32 @@
+package anytls
+
+import (
+	"crypto/sha256"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// MemoryAccount is an account type converted from Account.
+type MemoryAccount struct {
+	Password string
+	Key      [sha256.Size]byte
+}
+
+// AsAccount implements protocol.AsAccount.
+func (a *Account) AsAccount() (protocol.Account, error) {
+	if a.Password == "" {
+		return nil, newError("password is required")
+	}
+	return &MemoryAccount{
+		Password: a.Password,
+		Key:      sha256.Sum256([]byte(a.Password)),
+	}, nil
+}
+
+// Equals implements protocol.Account.Equals().
+func (a *MemoryAccount) Equals(another protocol.Account) bool {
+	if account, ok := another.(*MemoryAccount); ok {
+		return a.Password == account.Password
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package anytls
 
 import (
+	protocol "github.com/frogwall/f2ray-core/v5/common/protocol"
+	_ "github.com/frogwall/f2ray-core/v5/common/protoext"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -194,11 +196,66 @@ func (x *ClientConfig) GetMinIdleSession() uint32 {
 	return 0
 }
 
+// ServerConfig is the protobuf config for AnyTLS inbound server
+type ServerConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Users allowed to connect, with Account as their account type
+	Users []*protocol.User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Padding scheme sent to clients, the default scheme is used if empty
+	PaddingScheme string `protobuf:"bytes,2,opt,name=padding_scheme,json=paddingScheme,proto3" json:"padding_scheme,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerConfig) Reset() {
+	*x = ServerConfig{}
+	mi := &file_proxy_anytls_config_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerConfig) ProtoMessage() {}
+
+func (x *ServerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_anytls_config_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerConfig.ProtoReflect.Descriptor instead.
+func (*ServerConfig) Descriptor() ([]byte, []int) {
+	return file_proxy_anytls_config_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ServerConfig) GetUsers() []*protocol.User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetPaddingScheme() string {
+	if x != nil {
+		return x.PaddingScheme
+	}
+	return ""
+}
+
 var File_proxy_anytls_config_proto protoreflect.FileDescriptor
 
 const file_proxy_anytls_config_proto_rawDesc = "" +
 	"\n" +
-	"\x19proxy/anytls/config.proto\x12\x17v2ray.core.proxy.anytls\"%\n" +
+	"\x19proxy/anytls/config.proto\x12\x17v2ray.core.proxy.anytls\x1a common/protoext/extensions.proto\x1a\x1acommon/protocol/user.proto\"%\n" +
 	"\aAccount\x12\x1a\n" +
 	"\bpassword\x18\x01 \x01(\tR\bpassword\"Z\n" +
 	"\x0eServerEndpoint\x12\x18\n" +
@@ -209,7 +266,11 @@ const file_proxy_anytls_config_proto_rawDesc = "" +
 	"\aservers\x18\x01 \x03(\v2'.v2ray.core.proxy.anytls.ServerEndpointR\aservers\x12=\n" +
 	"\x1bidle_session_check_interval\x18\x02 \x01(\rR\x18idleSessionCheckInterval\x120\n" +
 	"\x14idle_session_timeout\x18\x03 \x01(\rR\x12idleSessionTimeout\x12(\n" +
-	"\x10min_idle_session\x18\x04 \x01(\rR\x0eminIdleSessionBi\n" +
+	"\x10min_idle_session\x18\x04 \x01(\rR\x0eminIdleSession\"\x84\x01\n" +
+	"\fServerConfig\x126\n" +
+	"\x05users\x18\x01 \x03(\v2 .v2ray.core.common.protocol.UserR\x05users\x12%\n" +
+	"\x0epadding_scheme\x18\x02 \x01(\tR\rpaddingScheme:\x15\x82\xb5\x18\x11\n" +
+	"\ainbound\x12\x06anytlsBi\n" +
 	"\x1bcom.v2ray.core.proxy.anytlsP\x01Z.github.com/frogwall/f2ray-core/v5/proxy/anytls\xaa\x02\x17V2Ray.Core.Proxy.AnyTLSb\x06proto3"
 
 var (
@@ -224,19 +285,22 @@ func file_proxy_anytls_config_proto_rawDescGZIP() []byte {
 	return file_proxy_anytls_config_proto_rawDescData
 }
 
-var file_proxy_anytls_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proxy_anytls_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_proxy_anytls_config_proto_goTypes = []any{
 	(*Account)(nil),        // 0: v2ray.core.proxy.anytls.Account
 	(*ServerEndpoint)(nil), // 1: v2ray.core.proxy.anytls.ServerEndpoint
 	(*ClientConfig)(nil),   // 2: v2ray.core.proxy.anytls.ClientConfig
+	(*ServerConfig)(nil),   // 3: v2ray.core.proxy.anytls.ServerConfig
+	(*protocol.User)(nil),  // 4: v2ray.core.common.protocol.User
 }
 var file_proxy_anytls_config_proto_depIdxs = []int32{
 	1, // 0: v2ray.core.proxy.anytls.ClientConfig.servers:type_name -> v2ray.core.proxy.anytls.ServerEndpoint
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4, // 1: v2ray.core.proxy.anytls.ServerConfig.users:type_name -> v2ray.core.common.protocol.User
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proxy_anytls_config_proto_init() }
@@ -250,7 +314,7 @@ func file_proxy_anytls_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_anytls_config_proto_rawDesc), len(file_proxy_anytls_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
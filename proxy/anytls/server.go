@@ -0,0 +1,303 @@
+package anytls
+
+import (
+	"context"
+	"encoding/hex"
+	stdnet "net"
+	"strings"
+	"sync"
+	"time"
+
+	anytls "github.com/anytls/sing-anytls"
+	"github.com/anytls/sing-anytls/padding"
+	"github.com/sagernet/sing/common/auth"
+	M "github.com/sagernet/sing/common/metadata"
+	N "github.com/sagernet/sing/common/network"
+	"github.com/sagernet/sing/common/uot"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/common/task"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler that handles messages in AnyTLS protocol.
+type Server struct {
+	policyManager policy.Manager
+	service       *anytls.Service
+
+	access sync.RWMutex
+	// users are keyed by the hex encoded SHA-256 hash of their passwords,
+	// which is also the user name known by the AnyTLS service.
+	users map[string]*protocol.MemoryUser
+}
+
+// NewServer creates a new AnyTLS inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	v := core.MustFromContext(ctx)
+	server := &Server{
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		users:         make(map[string]*protocol.MemoryUser),
+	}
+
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, newError("failed to get AnyTLS user").Base(err).AtError()
+		}
+		if err := server.addUser(u); err != nil {
+			return nil, newError("failed to add user").Base(err).AtError()
+		}
+	}
+
+	paddingScheme := padding.DefaultPaddingScheme
+	if config.PaddingScheme != "" {
+		paddingScheme = []byte(config.PaddingScheme)
+	}
+	service, err := anytls.NewService(anytls.ServiceConfig{
+		PaddingScheme: paddingScheme,
+		Users:         server.serviceUsers(),
+		Handler:       server,
+		Logger:        newAnytlsLogger(ctx),
+	})
+	if err != nil {
+		return nil, newError("failed to create AnyTLS service").Base(err)
+	}
+	server.service = service
+	return server, nil
+}
+
+func (s *Server) addUser(u *protocol.MemoryUser) error {
+	account, ok := u.Account.(*MemoryAccount)
+	if !ok {
+		return newError("not an AnyTLS account")
+	}
+	name := hex.EncodeToString(account.Key[:])
+
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	if _, found := s.users[name]; found {
+		return newError("User with the same password already exists.")
+	}
+	if u.Email != "" {
+		for _, user := range s.users {
+			if strings.EqualFold(user.Email, u.Email) {
+				return newError("User ", u.Email, " already exists.")
+			}
+		}
+	}
+	s.users[name] = u
+	return nil
+}
+
+// serviceUsers returns the users in the form of the AnyTLS service.
+func (s *Server) serviceUsers() []anytls.User {
+	s.access.RLock()
+	defer s.access.RUnlock()
+
+	users := make([]anytls.User, 0, len(s.users))
+	for name, user := range s.users {
+		users = append(users, anytls.User{
+			Name:     name,
+			Password: user.Account.(*MemoryAccount).Password,
+		})
+	}
+	return users
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	if err := s.addUser(u); err != nil {
+		return err
+	}
+	s.service.UpdateUsers(s.serviceUsers())
+	return nil
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	if e == "" {
+		return newError("Email must not be empty.")
+	}
+
+	s.access.Lock()
+	found := false
+	for name, user := range s.users {
+		if strings.EqualFold(user.Email, e) {
+			delete(s.users, name)
+			found = true
+			break
+		}
+	}
+	s.access.Unlock()
+
+	if !found {
+		return newError("User ", e, " not found.")
+	}
+	s.service.UpdateUsers(s.serviceUsers())
+	return nil
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP, net.Network_UNIX}
+}
+
+type dispatcherKey struct{}
+
+// handshakeConn clears the handshake read deadline after the first read, which
+// carries the authentication of AnyTLS.
+type handshakeConn struct {
+	stdnet.Conn
+	once sync.Once
+}
+
+func (c *handshakeConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Time{})
+	})
+	return n, err
+}
+
+// Process implements proxy.Inbound.Process().
+func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	sessionPolicy := s.policyManager.ForLevel(0)
+	if err := conn.SetReadDeadline(time.Now().Add(sessionPolicy.Timeouts.Handshake)); err != nil {
+		return newError("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	ctx = context.WithValue(ctx, dispatcherKey{}, dispatcher)
+	source := M.SocksaddrFromNet(conn.RemoteAddr())
+	if err := s.service.NewConnection(ctx, &handshakeConn{Conn: conn}, source, nil); err != nil {
+		return newError("failed to handle AnyTLS connection").Base(err)
+	}
+	return nil
+}
+
+// NewConnectionEx implements N.TCPConnectionHandlerEx. It is called by the
+// AnyTLS service for each stream of an authenticated session.
+func (s *Server) NewConnectionEx(ctx context.Context, conn stdnet.Conn, source M.Socksaddr, destination M.Socksaddr, onClose N.CloseHandlerFunc) {
+	defer conn.Close()
+
+	ctx = streamContext(ctx)
+	err := s.handleStream(ctx, conn, destination)
+	if err != nil {
+		newError("stream ends").Base(err).WriteToLog(session.ExportIDToError(ctx))
+	}
+	if onClose != nil {
+		onClose(err)
+	}
+}
+
+// streamContext returns a context for a stream of the session, each stream
+// has its own session ID and content.
+func streamContext(ctx context.Context) context.Context {
+	parent := session.InboundFromContext(ctx)
+	ctx = session.ContextWithID(ctx, session.NewID())
+	if parent != nil {
+		inbound := *parent
+		inbound.Conn = nil
+		inbound.CanSpliceCopy = 3
+		ctx = session.ContextWithInbound(ctx, &inbound)
+	}
+	if content := session.ContentFromContext(ctx); content != nil {
+		ctx = session.ContextWithContent(ctx, &session.Content{
+			SniffingRequest: content.SniffingRequest,
+		})
+	}
+	return ctx
+}
+
+func (s *Server) handleStream(ctx context.Context, conn stdnet.Conn, target M.Socksaddr) error {
+	name, _ := auth.UserFromContext[string](ctx)
+	s.access.RLock()
+	user := s.users[name]
+	s.access.RUnlock()
+	if user == nil {
+		return newError("user has been removed")
+	}
+	dispatcher, ok := ctx.Value(dispatcherKey{}).(routing.Dispatcher)
+	if !ok {
+		return newError("no dispatcher in context")
+	}
+
+	if target.Fqdn == uot.MagicAddress || target.Fqdn == uot.LegacyMagicAddress {
+		return newError("UDP over TCP of sing-box is not supported")
+	}
+	destination := net.Destination{
+		Network: net.Network_TCP,
+		Address: net.ParseAddress(target.AddrString()),
+		Port:    net.Port(target.Port),
+	}
+	if !destination.IsValid() {
+		return newError("invalid destination ", target)
+	}
+
+	inbound := session.InboundFromContext(ctx)
+	if inbound == nil {
+		panic("no inbound metadata")
+	}
+	inbound.User = user
+
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     destination,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  user.Email,
+	})
+	newError("received request for ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+	sessionPolicy := s.policyManager.ForLevel(user.Level)
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
+
+	link, err := dispatcher.Dispatch(ctx, destination)
+	if err != nil {
+		return newError("failed to dispatch request to ", destination).Base(err)
+	}
+
+	requestDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+		if err := buf.Copy(buf.NewReader(conn), link.Writer, buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+		if err := buf.Copy(link.Reader, buf.NewWriter(conn), buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to write response").Base(err)
+		}
+		return nil
+	}
+
+	requestDonePost := task.OnSuccess(requestDone, task.Close(link.Writer))
+	if err := task.Run(ctx, requestDonePost, responseDone); err != nil {
+		common.Must(common.Interrupt(link.Reader))
+		common.Must(common.Interrupt(link.Writer))
+		return newError("connection ends").Base(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package anytls_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	stdnet "net"
+	"sync"
+	"testing"
+	"time"
+
+	anytls "github.com/anytls/sing-anytls"
+	"github.com/sagernet/sing/common/logger"
+	M "github.com/sagernet/sing/common/metadata"
+	"github.com/sagernet/sing/common/uot"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/dispatcher"
+	"github.com/frogwall/f2ray-core/v5/app/proxyman"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/inbound"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/outbound"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	. "github.com/frogwall/f2ray-core/v5/proxy/anytls"
+	"github.com/frogwall/f2ray-core/v5/proxy/dokodemo"
+	"github.com/frogwall/f2ray-core/v5/proxy/freedom"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/tcp"
+)
+
+// paddingScheme pads the authentication of a session with 77 bytes, instead
+// of the 30 bytes of the default scheme.
+const paddingScheme = "stop=1\n0=77-77"
+
+func startInstance(t *testing.T, config *core.Config) {
+	server, err := core.New(config)
+	common.Must(err)
+	common.Must(server.Start())
+	t.Cleanup(func() { server.Close() })
+}
+
+// recordingDialer dials the server, and records the padding length of the
+// authentication sent over each connection.
+type recordingDialer struct {
+	address string
+
+	access   sync.Mutex
+	paddings []int
+}
+
+func (d *recordingDialer) dial(ctx context.Context) (stdnet.Conn, error) {
+	conn, err := stdnet.Dial("tcp", d.address)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingConn{Conn: conn, dialer: d}, nil
+}
+
+func (d *recordingDialer) Paddings() []int {
+	d.access.Lock()
+	defer d.access.Unlock()
+	return append([]int(nil), d.paddings...)
+}
+
+type recordingConn struct {
+	stdnet.Conn
+	dialer *recordingDialer
+	once   sync.Once
+}
+
+func (c *recordingConn) Write(b []byte) (int, error) {
+	c.once.Do(func() {
+		// The authentication is the SHA-256 of the password, followed by the
+		// length of its padding.
+		c.dialer.access.Lock()
+		c.dialer.paddings = append(c.dialer.paddings, int(binary.BigEndian.Uint16(b[32:34])))
+		c.dialer.access.Unlock()
+	})
+	return c.Conn.Write(b)
+}
+
+func testEcho(t *testing.T, conn stdnet.Conn) {
+	payload := bytes.Repeat([]byte("anytls"), 1024)
+	common.Must2(conn.Write(payload))
+	common.Must(conn.SetReadDeadline(time.Now().Add(10 * time.Second)))
+	response := make([]byte, len(payload))
+	common.Must2(io.ReadFull(conn, response))
+	if !bytes.Equal(response, bytes.ToUpper(payload)) {
+		t.Fatal("unexpected response")
+	}
+}
+
+func TestAnyTLSClientServer(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: bytes.ToUpper,
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	apps := []*anypb.Any{
+		serial.ToTypedMessage(&dispatcher.Config{}),
+		serial.ToTypedMessage(&proxyman.InboundConfig{}),
+		serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+	}
+
+	serverPort := tcp.PickPort()
+	startInstance(t, &core.Config{
+		App: apps,
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(serverPort),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&ServerConfig{
+					Users: []*protocol.User{
+						{
+							Email:   "love@v2fly.org",
+							Account: serial.ToTypedMessage(&Account{Password: "password"}),
+						},
+					},
+					PaddingScheme: paddingScheme,
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{ProxySettings: serial.ToTypedMessage(&freedom.Config{})},
+		},
+	})
+	serverAddress := net.TCPDestination(net.LocalHostIP, serverPort).NetAddr()
+
+	newClient := func(t *testing.T, password string) (*anytls.Client, *recordingDialer) {
+		dialer := &recordingDialer{address: serverAddress}
+		client, err := anytls.NewClient(context.Background(), anytls.ClientConfig{
+			Password: password,
+			DialOut:  dialer.dial,
+			Logger:   logger.NOP(),
+		})
+		common.Must(err)
+		t.Cleanup(func() { client.Close() })
+		return client, dialer
+	}
+	target := M.ParseSocksaddrHostPort(dest.Address.String(), dest.Port.Value())
+
+	t.Run("outbound", func(t *testing.T) {
+		clientPort := tcp.PickPort()
+		startInstance(t, &core.Config{
+			App: apps,
+			Inbound: []*core.InboundHandlerConfig{
+				{
+					ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+						PortRange: net.SinglePortRange(clientPort),
+						Listen:    net.NewIPOrDomain(net.LocalHostIP),
+					}),
+					ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+						Address:     net.NewIPOrDomain(dest.Address),
+						Port:        uint32(dest.Port),
+						NetworkList: &net.NetworkList{Network: []net.Network{net.Network_TCP}},
+					}),
+				},
+			},
+			Outbound: []*core.OutboundHandlerConfig{
+				{
+					ProxySettings: serial.ToTypedMessage(&ClientConfig{
+						Servers: []*ServerEndpoint{
+							{
+								Address:  net.LocalHostIP.String(),
+								Port:     uint32(serverPort),
+								Password: "password",
+							},
+						},
+					}),
+				},
+			},
+		})
+
+		conn, err := stdnet.Dial("tcp", net.TCPDestination(net.LocalHostIP, clientPort).NetAddr())
+		common.Must(err)
+		defer conn.Close()
+		testEcho(t, conn)
+	})
+
+	t.Run("multiplexing and padding", func(t *testing.T) {
+		client, dialer := newClient(t, "password")
+
+		// A closed stream returns its session to the client, which carries
+		// the next stream.
+		conn, err := client.CreateProxy(context.Background(), target)
+		common.Must(err)
+		testEcho(t, conn)
+		conn.Close()
+		conn, err = client.CreateProxy(context.Background(), target)
+		common.Must(err)
+		defer conn.Close()
+		testEcho(t, conn)
+		if n := len(dialer.Paddings()); n != 1 {
+			t.Fatal("expect streams in one session, but got ", n, " sessions")
+		}
+
+		// The first session is busy, so a new one is authenticated with the
+		// padding scheme sent by the server.
+		another, err := client.CreateProxy(context.Background(), target)
+		common.Must(err)
+		defer another.Close()
+		testEcho(t, another)
+		paddings := dialer.Paddings()
+		if len(paddings) != 2 || paddings[0] != 30 || paddings[1] != 77 {
+			t.Error("unexpected padding of authentication: ", paddings)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		client, _ := newClient(t, "another password")
+		conn, err := client.CreateProxy(context.Background(), target)
+		common.Must(err)
+		defer conn.Close()
+		common.Must2(conn.Write([]byte("ping")))
+		common.Must(conn.SetReadDeadline(time.Now().Add(10 * time.Second)))
+		if _, err := conn.Read(make([]byte, 1)); err == nil {
+			t.Error("expect the session to be closed")
+		}
+	})
+
+	t.Run("udp over tcp", func(t *testing.T) {
+		client, _ := newClient(t, "password")
+		conn, err := client.CreateProxy(context.Background(), M.Socksaddr{Fqdn: uot.MagicAddress, Port: 443})
+		common.Must(err)
+		defer conn.Close()
+		common.Must2(conn.Write([]byte("ping")))
+		common.Must(conn.SetReadDeadline(time.Now().Add(10 * time.Second)))
+		if _, err := conn.Read(make([]byte, 1)); err == nil {
+			t.Error("expect the stream to be closed")
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/transport"
 	"github.com/frogwall/f2ray-core/v5/transport/internet"
 )
@@ -39,6 +40,11 @@ func (h *Handler) Process(ctx context.Context, link *transport.Link, dialer inte
 	return nil
 }
 
+// Ping implements proxy.Pinger. ICMP echo requests are dropped.
+func (h *Handler) Ping(ctx context.Context, dest net.Address, payload []byte, sockopt *internet.SocketConfig) ([]byte, error) {
+	return nil, newError("ICMP echo to ", dest, " is blocked")
+}
+
 func init() {
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
 		return New(ctx, config.(*Config))
@@ -2,6 +2,7 @@ package brook
 
 import (
 	protocol "github.com/frogwall/f2ray-core/v5/common/protocol"
+	_ "github.com/frogwall/f2ray-core/v5/common/protoext"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -139,6 +140,8 @@ func (x *ClientConfig) GetTlsFingerprint() string {
 type ServerConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Password      string                 `protobuf:"bytes,1,opt,name=password,proto3" json:"password,omitempty"`
+	WithoutBrook  bool                   `protobuf:"varint,2,opt,name=without_brook,json=withoutBrook,proto3" json:"without_brook,omitempty"`
+	Path          string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"` // for websocket
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -180,11 +183,25 @@ func (x *ServerConfig) GetPassword() string {
 	return ""
 }
 
+func (x *ServerConfig) GetWithoutBrook() bool {
+	if x != nil {
+		return x.WithoutBrook
+	}
+	return false
+}
+
+func (x *ServerConfig) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
 var File_proxy_brook_config_proto protoreflect.FileDescriptor
 
 const file_proxy_brook_config_proto_rawDesc = "" +
 	"\n" +
-	"\x18proxy/brook/config.proto\x12\x16v2ray.core.proxy.brook\x1a!common/protocol/server_spec.proto\"%\n" +
+	"\x18proxy/brook/config.proto\x12\x16v2ray.core.proxy.brook\x1a!common/protocol/server_spec.proto\x1a common/protoext/extensions.proto\"%\n" +
 	"\aAccount\x12\x1a\n" +
 	"\bpassword\x18\x01 \x01(\tR\bpassword\"\xd0\x01\n" +
 	"\fClientConfig\x12B\n" +
@@ -192,9 +209,12 @@ const file_proxy_brook_config_proto_rawDesc = "" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\x12#\n" +
 	"\rwithout_brook\x18\x03 \x01(\bR\fwithoutBrook\x12\x12\n" +
 	"\x04path\x18\x04 \x01(\tR\x04path\x12'\n" +
-	"\x0ftls_fingerprint\x18\x05 \x01(\tR\x0etlsFingerprint\"*\n" +
+	"\x0ftls_fingerprint\x18\x05 \x01(\tR\x0etlsFingerprint\"y\n" +
 	"\fServerConfig\x12\x1a\n" +
-	"\bpassword\x18\x01 \x01(\tR\bpasswordB/Z-github.com/frogwall/f2ray-core/v5/proxy/brookb\x06proto3"
+	"\bpassword\x18\x01 \x01(\tR\bpassword\x12#\n" +
+	"\rwithout_brook\x18\x02 \x01(\bR\fwithoutBrook\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path:\x14\x82\xb5\x18\x10\n" +
+	"\ainbound\x12\x05brookB/Z-github.com/frogwall/f2ray-core/v5/proxy/brookb\x06proto3"
 
 var (
 	file_proxy_brook_config_proto_rawDescOnce sync.Once
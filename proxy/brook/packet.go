@@ -0,0 +1,84 @@
+//go:build !confonly
+// +build !confonly
+
+package brook
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+// packetCodec decodes the UDP packets received by the server, and encodes the
+// responses.
+//
+// With the brook protocol, a request is Nonce(12) [Unix Timestamp(4) DST Address Data] Tag(16),
+// and a response is Nonce(12) [DST Address Data] Tag(16).
+// Without the brook protocol, a request is PasswordHash(32) Unix Timestamp(4) DST Address Data,
+// and a response is DST Address Data.
+type packetCodec struct {
+	password     []byte
+	passwordHash []byte
+	withoutBrook bool
+}
+
+func (c *packetCodec) decode(b []byte) (net.Destination, []byte, error) {
+	if c.withoutBrook {
+		if len(b) < sha256.Size || subtle.ConstantTimeCompare(b[:sha256.Size], c.passwordHash) != 1 {
+			return net.Destination{}, nil, newError("invalid password")
+		}
+		b = b[sha256.Size:]
+	} else {
+		if len(b) < NonceSize+16 {
+			return net.Destination{}, nil, newError("packet is too short")
+		}
+		aead, err := newAEAD(c.password, b[:NonceSize])
+		if err != nil {
+			return net.Destination{}, nil, err
+		}
+		b, err = aead.Open(b[NonceSize:NonceSize], b[:NonceSize], b[NonceSize:], nil)
+		if err != nil {
+			return net.Destination{}, nil, newError("failed to decrypt packet").Base(err)
+		}
+	}
+
+	dest, payload, err := parseRequest(b)
+	if err != nil {
+		return net.Destination{}, nil, err
+	}
+	dest.Network = net.Network_UDP
+	return dest, payload, nil
+}
+
+func (c *packetCodec) encode(source net.Destination, payload []byte) (*buf.Buffer, error) {
+	b := buf.New()
+	if !c.withoutBrook {
+		common.Must2(b.ReadFullFrom(rand.Reader, NonceSize))
+	}
+	if err := addrParser.WriteAddressPort(b, source.Address, source.Port); err != nil {
+		b.Release()
+		return nil, err
+	}
+	if b.Len()+int32(len(payload))+16 > buf.Size {
+		b.Release()
+		return nil, newError("packet is too large")
+	}
+	common.Must2(b.Write(payload))
+	if c.withoutBrook {
+		return b, nil
+	}
+
+	aead, err := newAEAD(c.password, b.BytesTo(NonceSize))
+	if err != nil {
+		b.Release()
+		return nil, err
+	}
+	plaintext := b.BytesFrom(NonceSize)
+	b.Extend(int32(aead.Overhead()))
+	aead.Seal(plaintext[:0], b.BytesTo(NonceSize), plaintext, nil)
+	return b, nil
+}
@@ -0,0 +1,288 @@
+//go:build !confonly
+// +build !confonly
+
+package brook
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"sync"
+	"time"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/errors"
+	"github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	udp_proto "github.com/frogwall/f2ray-core/v5/common/protocol/udp"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/common/task"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/udp"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler that handles messages in brook protocol.
+type Server struct {
+	config        *ServerConfig
+	user          *protocol.MemoryUser
+	passwordHash  []byte
+	codec         *packetCodec
+	policyManager policy.Manager
+}
+
+// NewServer creates a new brook inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	if config.Password == "" {
+		return nil, newError("brook password is not set")
+	}
+	passwordHash := sha256.Sum256([]byte(config.Password))
+
+	v := core.MustFromContext(ctx)
+	return &Server{
+		config: config,
+		user: &protocol.MemoryUser{
+			Account: &Account{Password: config.Password},
+		},
+		passwordHash: passwordHash[:],
+		codec: &packetCodec{
+			password:     []byte(config.Password),
+			passwordHash: passwordHash[:],
+			withoutBrook: config.WithoutBrook,
+		},
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+	}, nil
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	if s.config.Path != "" {
+		return []net.Network{net.Network_TCP}
+	}
+	return []net.Network{net.Network_TCP, net.Network_UDP}
+}
+
+// Process implements proxy.Inbound.Process().
+func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	inbound := session.InboundFromContext(ctx)
+	if inbound == nil {
+		panic("no inbound metadata")
+	}
+	inbound.User = s.user
+
+	if network == net.Network_UDP {
+		return s.handlePackets(ctx, conn, dispatcher)
+	}
+	return s.handleStream(ctx, conn, dispatcher)
+}
+
+func (s *Server) handleStream(ctx context.Context, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	sessionPolicy := s.policyManager.ForLevel(s.user.Level)
+	if err := conn.SetReadDeadline(time.Now().Add(sessionPolicy.Timeouts.Handshake)); err != nil {
+		return newError("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	var rw io.ReadWriter = conn
+	if s.config.Path != "" {
+		wsStream, err := upgradeWebSocket(conn, s.config.Path)
+		if err != nil {
+			return newError("failed to serve WebSocket").Base(err).AtInfo()
+		}
+		rw = wsStream
+	}
+
+	var stream serverStream
+	var request []byte
+	var err error
+	if s.config.WithoutBrook {
+		stream, request, err = newSimpleStream(rw, s.passwordHash)
+	} else {
+		stream, request, err = newBrookStream(rw, s.config.Password)
+	}
+	if err != nil {
+		log.Record(&log.AccessMessage{
+			From:   conn.RemoteAddr(),
+			To:     "",
+			Status: log.AccessRejected,
+			Reason: err,
+		})
+		return newError("failed to read request").Base(err).AtInfo()
+	}
+	destination, _, err := parseRequest(request)
+	if err != nil {
+		return newError("invalid request").Base(err).AtInfo()
+	}
+
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return newError("unable to set read deadline").Base(err).AtWarning()
+	}
+
+	inbound := session.InboundFromContext(ctx)
+	if destination.Network == net.Network_UDP {
+		return s.handleUDPStream(ctx, sessionPolicy, stream, destination, dispatcher)
+	}
+
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     destination,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  inbound.User.Email,
+	})
+	newError("received request for ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
+
+	link, err := dispatcher.Dispatch(ctx, destination)
+	if err != nil {
+		return newError("failed to dispatch request to ", destination).Base(err)
+	}
+
+	requestDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+		if err := buf.Copy(stream, link.Writer, buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+		if err := buf.Copy(link.Reader, stream, buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to write response").Base(err)
+		}
+		return nil
+	}
+
+	requestDonePost := task.OnSuccess(requestDone, task.Close(link.Writer))
+	if err := task.Run(ctx, requestDonePost, responseDone); err != nil {
+		common.Must(common.Interrupt(link.Reader))
+		common.Must(common.Interrupt(link.Writer))
+		return newError("connection ends").Base(err)
+	}
+
+	return nil
+}
+
+// handleUDPStream relays the UDP packets carried by a stream, all of which
+// are sent to the destination of the request.
+func (s *Server) handleUDPStream(ctx context.Context, sessionPolicy policy.Session, stream serverStream, destination net.Destination, dispatcher routing.Dispatcher) error {
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+
+	var writeAccess sync.Mutex
+	udpServer := udp.NewSplitDispatcher(dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
+		defer packet.Payload.Release()
+		timer.Update()
+		writeAccess.Lock()
+		defer writeAccess.Unlock()
+		if err := stream.writeFrame(packet.Payload.Bytes()); err != nil {
+			newError("failed to write response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	})
+	defer udpServer.Close()
+
+	inbound := session.InboundFromContext(ctx)
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     destination,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  inbound.User.Email,
+	})
+	newError("tunnelling request to ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			b, err := stream.readFrame()
+			if err != nil {
+				if errors.Cause(err) != io.EOF {
+					return newError("unexpected EOF").Base(err)
+				}
+				return nil
+			}
+			timer.Update()
+			udpServer.Dispatch(ctx, destination, b)
+		}
+	}
+}
+
+// handlePackets relays the UDP packets received from a client, each of which
+// carries its own destination.
+func (s *Server) handlePackets(ctx context.Context, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	sessionPolicy := s.policyManager.ForLevel(s.user.Level)
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+
+	udpServer := udp.NewSplitDispatcher(dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
+		defer packet.Payload.Release()
+		timer.Update()
+		b, err := s.codec.encode(packet.Source, packet.Payload.Bytes())
+		if err != nil {
+			newError("failed to encode response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+			return
+		}
+		defer b.Release()
+		if _, err := conn.Write(b.Bytes()); err != nil {
+			newError("failed to write response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	})
+	defer udpServer.Close()
+
+	inbound := session.InboundFromContext(ctx)
+	reader := buf.NewPacketReader(conn)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			mb, err := reader.ReadMultiBuffer()
+			if err != nil {
+				if errors.Cause(err) != io.EOF {
+					return newError("unexpected EOF").Base(err)
+				}
+				return nil
+			}
+			timer.Update()
+			for _, b := range mb {
+				destination, payload, err := s.codec.decode(b.Bytes())
+				if err != nil {
+					b.Release()
+					newError("dropping invalid packet from ", inbound.Source).Base(err).AtInfo().WriteToLog(session.ExportIDToError(ctx))
+					continue
+				}
+				currentPacketCtx := log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+					From:   inbound.Source,
+					To:     destination,
+					Status: log.AccessAccepted,
+					Reason: "",
+					Email:  inbound.User.Email,
+				})
+				newError("tunnelling request to ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+				data := buf.New()
+				common.Must2(data.Write(payload))
+				b.Release()
+				udpServer.Dispatch(currentPacketCtx, destination, data)
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package brook
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	stdnet "net"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// requestHeader returns Unix Timestamp(4) DST Address, the timestamp is even
+// for TCP and odd for UDP.
+func requestHeader(dest net.Destination) []byte {
+	timestamp := uint32(time.Now().Unix())
+	if (timestamp%2 != 0) != (dest.Network == net.Network_UDP) {
+		timestamp++
+	}
+	b := buf.New()
+	defer b.Release()
+	common.Must(binary.Write(b, binary.BigEndian, timestamp))
+	common.Must(addrParser.WriteAddressPort(b, dest.Address, dest.Port))
+	return append([]byte(nil), b.Bytes()...)
+}
+
+func TestBrookStream(t *testing.T) {
+	clientConn, serverConn := stdnet.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	go func() {
+		stream, request, err := newBrookStream(serverConn, "password")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		dest, _, err := parseRequest(request)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, net.TCPDestination(net.DomainAddress("example.com"), 443), dest)
+		buf.Copy(stream, stream)
+	}()
+
+	client, err := NewTCPStreamClient(clientConn, "password", &protocol.RequestHeader{
+		Address: net.DomainAddress("example.com"),
+		Port:    443,
+	})
+	common.Must(err)
+
+	payload := make([]byte, 5000)
+	common.Must2(rand.Read(payload))
+	go client.Write(payload)
+
+	received := make([]byte, 0, len(payload))
+	b := make([]byte, MaxFragmentSize)
+	for len(received) < len(payload) {
+		n, err := client.Read(b)
+		common.Must(err)
+		received = append(received, b[:n]...)
+	}
+	assert.Equal(t, payload, received)
+}
+
+func TestBrookStreamWrongPassword(t *testing.T) {
+	clientConn, serverConn := stdnet.Pipe()
+	defer clientConn.Close()
+
+	go func() {
+		defer serverConn.Close()
+		if _, _, err := newBrookStream(serverConn, "password"); err == nil {
+			t.Error("expected error for wrong password")
+		}
+	}()
+
+	_, err := NewTCPStreamClient(clientConn, "wrong-password", &protocol.RequestHeader{
+		Address: net.DomainAddress("example.com"),
+		Port:    443,
+	})
+	assert.Error(t, err)
+}
+
+func TestSimpleStreamOverWebSocket(t *testing.T) {
+	clientConn, serverConn := stdnet.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	passwordHash := sha256.Sum256([]byte("password"))
+	dest := net.UDPDestination(net.ParseAddress("8.8.8.8"), 53)
+
+	go func() {
+		wsStream, err := upgradeWebSocket(serverConn, "/ws")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		stream, request, err := newSimpleStream(wsStream, passwordHash[:])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		requestDest, _, err := parseRequest(request)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		assert.Equal(t, dest, requestDest)
+
+		packet, err := stream.readFrame()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer packet.Release()
+		common.Must(stream.writeFrame(packet.Bytes()))
+	}()
+
+	dialer := &websocket.Dialer{
+		NetDial: func(network, addr string) (net.Conn, error) {
+			return clientConn, nil
+		},
+	}
+	wsConn, _, err := dialer.Dial("ws://example.com/ws", nil)
+	common.Must(err)
+
+	frame := func(b []byte) []byte {
+		return append(binary.BigEndian.AppendUint16(nil, uint16(len(b))), b...)
+	}
+	request := append(passwordHash[:], requestHeader(dest)...)
+	common.Must(wsConn.WriteMessage(websocket.BinaryMessage, frame(request)))
+	common.Must(wsConn.WriteMessage(websocket.BinaryMessage, frame([]byte("query"))))
+
+	_, response, err := wsConn.ReadMessage()
+	common.Must(err)
+	assert.Equal(t, frame([]byte("query")), response)
+}
+
+func TestWebSocketWrongPath(t *testing.T) {
+	clientConn, serverConn := stdnet.Pipe()
+	defer clientConn.Close()
+
+	go func() {
+		defer serverConn.Close()
+		if _, err := upgradeWebSocket(serverConn, "/ws"); err == nil {
+			t.Error("expected error for wrong path")
+		}
+	}()
+
+	dialer := &websocket.Dialer{
+		NetDial: func(network, addr string) (net.Conn, error) {
+			return clientConn, nil
+		},
+	}
+	_, response, err := dialer.Dial("ws://example.com/other", nil)
+	assert.Error(t, err)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, 404, response.StatusCode)
+	}
+}
+
+func TestPacketCodec(t *testing.T) {
+	dest := net.UDPDestination(net.ParseAddress("8.8.8.8"), 53)
+	payload := []byte("query")
+	passwordHash := sha256.Sum256([]byte("password"))
+
+	t.Run("brook", func(t *testing.T) {
+		codec := &packetCodec{password: []byte("password"), passwordHash: passwordHash[:]}
+
+		nonce := make([]byte, NonceSize)
+		common.Must2(rand.Read(nonce))
+		aead, err := newAEAD([]byte("password"), nonce)
+		common.Must(err)
+		packet := aead.Seal(nonce, nonce, append(requestHeader(dest), payload...), nil)
+
+		packetDest, packetPayload, err := codec.decode(packet)
+		common.Must(err)
+		assert.Equal(t, dest, packetDest)
+		assert.Equal(t, payload, packetPayload)
+
+		b, err := codec.encode(dest, payload)
+		common.Must(err)
+		defer b.Release()
+		aead, err = newAEAD([]byte("password"), b.BytesTo(NonceSize))
+		common.Must(err)
+		plaintext, err := aead.Open(nil, b.BytesTo(NonceSize), b.BytesFrom(NonceSize), nil)
+		common.Must(err)
+		address, port, err := addrParser.ReadAddressPort(nil, bytes.NewReader(plaintext))
+		common.Must(err)
+		assert.Equal(t, dest, net.UDPDestination(address, port))
+		assert.True(t, bytes.HasSuffix(plaintext, payload))
+
+		packet[len(packet)-1] ^= 1
+		_, _, err = codec.decode(packet)
+		assert.Error(t, err)
+	})
+
+	t.Run("withoutBrook", func(t *testing.T) {
+		codec := &packetCodec{password: []byte("password"), passwordHash: passwordHash[:], withoutBrook: true}
+
+		packet := append(append(passwordHash[:], requestHeader(dest)...), payload...)
+		packetDest, packetPayload, err := codec.decode(packet)
+		common.Must(err)
+		assert.Equal(t, dest, packetDest)
+		assert.Equal(t, payload, packetPayload)
+
+		b, err := codec.encode(dest, payload)
+		common.Must(err)
+		defer b.Release()
+		reader := bytes.NewReader(b.Bytes())
+		_, _, err = addrParser.ReadAddressPort(nil, reader)
+		common.Must(err)
+		assert.Equal(t, payload, b.BytesFrom(-int32(reader.Len())))
+
+		_, _, err = codec.decode(append(make([]byte, sha256.Size), requestHeader(dest)...))
+		assert.Error(t, err)
+	})
+}
+
+func TestParseRequestExpired(t *testing.T) {
+	header := requestHeader(net.TCPDestination(net.DomainAddress("example.com"), 443))
+	binary.BigEndian.PutUint32(header[:4], uint32(time.Now().Add(-time.Hour).Unix()))
+	_, _, err := parseRequest(header)
+	assert.Error(t, err)
+	_, _, err = parseRequest(header[:3])
+	assert.Error(t, err)
+}
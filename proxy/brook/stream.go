@@ -0,0 +1,256 @@
+//go:build !confonly
+// +build !confonly
+
+package brook
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+const (
+	// maxFramePayload is the maximum size of the data carried by a fragment,
+	// same as the one used by TCPStreamClient.
+	maxFramePayload = MaxFragmentSize - 2 - 16 - 4 - 16
+
+	// timestampTolerance is the maximum difference in seconds between the
+	// timestamp of a request and the local clock.
+	timestampTolerance = 60
+)
+
+var addrParser = protocol.NewAddressParser(
+	protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv4),
+	protocol.AddressFamilyByte(0x03, net.AddressFamilyDomain),
+	protocol.AddressFamilyByte(0x04, net.AddressFamilyIPv6),
+)
+
+// newAEAD creates the AES-256-GCM cipher of a direction, whose key is derived
+// from the password and the nonce sent at the beginning of the direction.
+func newAEAD(password, nonce []byte) (cipher.AEAD, error) {
+	key := make([]byte, KeySize)
+	if _, err := hkdf.New(sha256.New, password, nonce, []byte(ServerHKDFInfo)).Read(key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// parseRequest parses the first fragment of a stream, or the header of a
+// packet: Unix Timestamp(4) DST Address. The timestamp of a stream is even for
+// TCP, and odd for UDP.
+func parseRequest(b []byte) (net.Destination, []byte, error) {
+	if len(b) < 4 {
+		return net.Destination{}, nil, newError("request is too short")
+	}
+	timestamp := int64(binary.BigEndian.Uint32(b[:4]))
+	if d := time.Now().Unix() - timestamp; d > timestampTolerance || d < -timestampTolerance {
+		return net.Destination{}, nil, newError("expired request with timestamp ", timestamp)
+	}
+
+	reader := bytes.NewReader(b[4:])
+	address, port, err := addrParser.ReadAddressPort(nil, reader)
+	if err != nil {
+		return net.Destination{}, nil, newError("failed to read address").Base(err)
+	}
+	dest := net.TCPDestination(address, port)
+	if timestamp%2 != 0 {
+		dest.Network = net.Network_UDP
+	}
+	return dest, b[len(b)-reader.Len():], nil
+}
+
+// serverStream is a stream received by the server.
+type serverStream interface {
+	buf.Reader
+	buf.Writer
+
+	// readFrame reads a UDP packet carried by the stream.
+	readFrame() (*buf.Buffer, error)
+	// writeFrame writes a UDP packet to the stream.
+	writeFrame(b []byte) error
+}
+
+// brookStream is a stream encrypted with the brook protocol:
+// Nonce(12) [Length(2) Tag(16)] [Fragment Tag(16)]...
+type brookStream struct {
+	conn      io.ReadWriter
+	encryptor *BrookEncryptor
+	decryptor *BrookDecryptor
+}
+
+// newBrookStream reads the nonce and the first fragment sent by the client,
+// and replies with the nonce of the server.
+func newBrookStream(conn io.ReadWriter, password string) (*brookStream, []byte, error) {
+	decryptor, err := NewBrookDecryptor(password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.ReadFull(conn, decryptor.nonce); err != nil {
+		return nil, nil, newError("failed to read nonce").Base(err)
+	}
+	if decryptor.aead, err = newAEAD(decryptor.password, decryptor.nonce); err != nil {
+		return nil, nil, err
+	}
+
+	s := &brookStream{
+		conn:      conn,
+		decryptor: decryptor,
+	}
+	request, err := s.readFrame()
+	if err != nil {
+		return nil, nil, newError("failed to read request").Base(err)
+	}
+	defer request.Release()
+
+	if s.encryptor, err = NewBrookEncryptor(password); err != nil {
+		return nil, nil, err
+	}
+	if _, err := conn.Write(s.encryptor.nonce); err != nil {
+		return nil, nil, newError("failed to write nonce").Base(err)
+	}
+	return s, append([]byte(nil), request.Bytes()...), nil
+}
+
+func (s *brookStream) readFrame() (*buf.Buffer, error) {
+	b := buf.New()
+	header := b.Extend(2 + 16)
+	if _, err := io.ReadFull(s.conn, header); err != nil {
+		b.Release()
+		return nil, err
+	}
+	if _, err := s.decryptor.aead.Open(header[:0], s.decryptor.nonce, header, nil); err != nil {
+		b.Release()
+		return nil, newError("failed to decrypt length").Base(err)
+	}
+	s.decryptor.incrementNonce()
+
+	length := int32(binary.BigEndian.Uint16(header[:2]))
+	if 2+16+length+16 > MaxFragmentSize {
+		b.Release()
+		return nil, newError("fragment is too large: ", length)
+	}
+	b.Clear()
+	payload := b.Extend(length + 16)
+	if _, err := io.ReadFull(s.conn, payload); err != nil {
+		b.Release()
+		return nil, err
+	}
+	if _, err := s.decryptor.aead.Open(payload[:0], s.decryptor.nonce, payload, nil); err != nil {
+		b.Release()
+		return nil, newError("failed to decrypt fragment").Base(err)
+	}
+	s.decryptor.incrementNonce()
+	b.Resize(0, length)
+	return b, nil
+}
+
+func (s *brookStream) writeFrame(b []byte) error {
+	frame := make([]byte, 2+16+len(b)+16)
+	binary.BigEndian.PutUint16(frame[:2], uint16(len(b)))
+	s.encryptor.aead.Seal(frame[:0], s.encryptor.nonce, frame[:2], nil)
+	s.encryptor.incrementNonce()
+	copy(frame[2+16:], b)
+	s.encryptor.aead.Seal(frame[2+16:2+16], s.encryptor.nonce, frame[2+16:2+16+len(b)], nil)
+	s.encryptor.incrementNonce()
+	_, err := s.conn.Write(frame)
+	return err
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (s *brookStream) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	b, err := s.readFrame()
+	if err != nil {
+		return nil, err
+	}
+	return buf.MultiBuffer{b}, nil
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (s *brookStream) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	defer buf.ReleaseMulti(mb)
+	for _, b := range mb {
+		for data := b.Bytes(); len(data) > 0; {
+			n := min(len(data), maxFramePayload)
+			if err := s.writeFrame(data[:n]); err != nil {
+				return err
+			}
+			data = data[n:]
+		}
+	}
+	return nil
+}
+
+// simpleStream is a stream without the brook protocol, whose data is sent as
+// is after the request: Length(2) PasswordHash(32) Unix Timestamp(4) DST Address.
+// UDP packets are sent as Length(2) Packet.
+type simpleStream struct {
+	conn   io.ReadWriter
+	reader buf.Reader
+	writer buf.Writer
+}
+
+// newSimpleStream reads and authenticates the request sent by the client.
+func newSimpleStream(conn io.ReadWriter, passwordHash []byte) (*simpleStream, []byte, error) {
+	s := &simpleStream{
+		conn:   conn,
+		reader: buf.NewReader(conn),
+		writer: buf.NewWriter(conn),
+	}
+	request, err := s.readFrame()
+	if err != nil {
+		return nil, nil, newError("failed to read request").Base(err)
+	}
+	defer request.Release()
+
+	if request.Len() < sha256.Size || subtle.ConstantTimeCompare(request.BytesTo(sha256.Size), passwordHash) != 1 {
+		return nil, nil, newError("invalid password")
+	}
+	return s, append([]byte(nil), request.BytesFrom(sha256.Size)...), nil
+}
+
+func (s *simpleStream) readFrame() (*buf.Buffer, error) {
+	var lengthBuf [2]byte
+	if _, err := io.ReadFull(s.conn, lengthBuf[:]); err != nil {
+		return nil, err
+	}
+	length := int32(binary.BigEndian.Uint16(lengthBuf[:]))
+	b := buf.NewWithSize(length)
+	if _, err := b.ReadFullFrom(s.conn, length); err != nil {
+		b.Release()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *simpleStream) writeFrame(b []byte) error {
+	frame := make([]byte, 2+len(b))
+	binary.BigEndian.PutUint16(frame[:2], uint16(len(b)))
+	copy(frame[2:], b)
+	_, err := s.conn.Write(frame)
+	return err
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (s *simpleStream) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	return s.reader.ReadMultiBuffer()
+}
+
+// WriteMultiBuffer implements buf.Writer.
+func (s *simpleStream) WriteMultiBuffer(mb buf.MultiBuffer) error {
+	return s.writer.WriteMultiBuffer(mb)
+}
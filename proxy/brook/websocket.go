@@ -0,0 +1,118 @@
+//go:build !confonly
+// +build !confonly
+
+package brook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+var upgrader = &websocket.Upgrader{
+	ReadBufferSize:   4 * 1024,
+	WriteBufferSize:  4 * 1024,
+	HandshakeTimeout: 0, // The deadline of the connection is set by the caller.
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// upgradeWebSocket serves the WebSocket handshake of a brook wsserver on the
+// connection, and returns the stream carried by the binary messages.
+func upgradeWebSocket(conn net.Conn, path string) (io.ReadWriter, error) {
+	reader := bufio.NewReader(conn)
+	request, err := http.ReadRequest(reader)
+	if err != nil {
+		return nil, newError("failed to read HTTP request").Base(err)
+	}
+	writer := &responseWriter{
+		conn:   conn,
+		reader: reader,
+		header: make(http.Header),
+	}
+	if request.URL.Path != path {
+		http.NotFound(writer, request)
+		return nil, newError("unexpected path ", request.URL.Path)
+	}
+
+	wsConn, err := upgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		return nil, newError("failed to upgrade to WebSocket").Base(err)
+	}
+	return &webSocketStream{conn: wsConn}, nil
+}
+
+// responseWriter is an http.ResponseWriter on a raw connection, which can be
+// hijacked by the WebSocket upgrader.
+type responseWriter struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	header      http.Header
+	wroteHeader bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	fmt.Fprintf(w.conn, "HTTP/1.1 %03d %s\r\n", statusCode, http.StatusText(statusCode))
+	w.header.Set("Connection", "close")
+	w.header.Write(w.conn)
+	io.WriteString(w.conn, "\r\n")
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.conn.Write(b)
+}
+
+// Hijack implements http.Hijacker.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(w.reader, bufio.NewWriter(w.conn)), nil
+}
+
+// webSocketStream is a stream carried by the binary messages of a WebSocket
+// connection.
+type webSocketStream struct {
+	conn   *websocket.Conn
+	reader io.Reader
+}
+
+func (s *webSocketStream) Read(b []byte) (int, error) {
+	for {
+		if s.reader == nil {
+			_, reader, err := s.conn.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			s.reader = reader
+		}
+		n, err := s.reader.Read(b)
+		if err == io.EOF {
+			s.reader = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (s *webSocketStream) Write(b []byte) (int, error) {
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
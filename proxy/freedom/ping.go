@@ -0,0 +1,85 @@
+package freedom
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+
+	"github.com/frogwall/f2ray-core/v5/common/dice"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+)
+
+const (
+	// pingTimeout is the time to wait for an echo reply if the context has no deadline.
+	pingTimeout = time.Second * 5
+
+	protocolICMP   = 1
+	protocolICMPv6 = 58
+)
+
+// Ping implements proxy.Pinger. It sends an ICMP echo request to dest with
+// a ping socket of the system, and returns the data of the echo reply.
+func (h *Handler) Ping(ctx context.Context, dest net.Address, payload []byte, sockopt *internet.SocketConfig) ([]byte, error) {
+	if dest.Family().IsDomain() {
+		ip := h.resolveIP(ctx, dest.Domain(), nil)
+		if ip == nil {
+			return nil, newError("failed to resolve ", dest)
+		}
+		dest = ip
+	}
+	isIPv6 := dest.Family().IsIPv6()
+	conn, err := internet.ListenSystemPing(ctx, isIPv6, sockopt)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(pingTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	// The identifier of the echo request is replaced with the port of the
+	// ping socket by the kernel, so replies are matched by sequence number.
+	seq := int(dice.RollUint16())
+	request := icmp.Message{Type: ipv4.ICMPTypeEcho, Body: &icmp.Echo{Seq: seq, Data: payload}}
+	protocol := protocolICMP
+	if isIPv6 {
+		request.Type = ipv6.ICMPTypeEchoRequest
+		protocol = protocolICMPv6
+	}
+	b, err := request.Marshal(nil)
+	if err != nil {
+		return nil, err
+	}
+	newError("sending ICMP echo request to ", dest).WriteToLog(session.ExportIDToError(ctx))
+	if _, err := conn.WriteTo(b, &net.UDPAddr{IP: dest.IP()}); err != nil {
+		return nil, newError("failed to send ICMP echo request to ", dest).Base(err)
+	}
+
+	buffer := make([]byte, len(b)+256)
+	for {
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return nil, newError("failed to receive ICMP echo reply from ", dest).Base(err)
+		}
+		reply, err := icmp.ParseMessage(protocol, buffer[:n])
+		if err != nil {
+			continue
+		}
+		if reply.Type != ipv4.ICMPTypeEchoReply && reply.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq {
+			return echo.Data, nil
+		}
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"io"
 	gonet "net"
+	"sync"
 	"time"
 
 	"github.com/daeuniverse/outbound/netproxy"
@@ -36,6 +37,7 @@ import (
 // Client is a Juicity outbound handler
 type Client struct {
 	config        *ClientConfig
+	access        sync.Mutex
 	dialer        *juicity.Dialer
 	policyManager policy.Manager
 }
@@ -71,14 +73,18 @@ func (c *Client) Process(ctx context.Context, link *transport.Link, dialer inter
 	dest := server.Address.AsAddress()
 
 	// Initialize dialer if not already done
+	c.access.Lock()
 	if c.dialer == nil {
 		if err := c.initDialer(ctx, server, dialer); err != nil {
+			c.access.Unlock()
 			return newError("failed to initialize dialer").Base(err)
 		}
 	}
+	juicityDialer := c.dialer
+	c.access.Unlock()
 
 	// Dial to destination
-	conn, err := c.dialer.DialContext(ctx, network.SystemString(), destination.NetAddr())
+	conn, err := juicityDialer.DialContext(ctx, network.SystemString(), destination.NetAddr())
 	if err != nil {
 		return newError("failed to dial to ", destination).Base(err)
 	}
@@ -0,0 +1,23 @@
+package juicity
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+)
+
+// MemoryAccount is an account type converted from Account.
+type MemoryAccount = tuicTransport.MemoryAccount
+
+// AsAccount implements protocol.AsAccount.
+func (a *Account) AsAccount() (protocol.Account, error) {
+	id, err := uuid.Parse(a.Uuid)
+	if err != nil {
+		return nil, newError("invalid UUID").Base(err)
+	}
+	return &MemoryAccount{
+		UUID:     id,
+		Password: a.Password,
+	}, nil
+}
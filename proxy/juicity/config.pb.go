@@ -82,16 +82,120 @@ func (x *ClientConfig) GetPinnedCertchainSha256() string {
 	return ""
 }
 
+// Account is the account of a user of the Juicity inbound.
+type Account struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_proxy_juicity_config_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_juicity_config_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_proxy_juicity_config_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Account) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Account) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type ServerConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Users with Account as their account type
+	Users         []*protocol.User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerConfig) Reset() {
+	*x = ServerConfig{}
+	mi := &file_proxy_juicity_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerConfig) ProtoMessage() {}
+
+func (x *ServerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_juicity_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerConfig.ProtoReflect.Descriptor instead.
+func (*ServerConfig) Descriptor() ([]byte, []int) {
+	return file_proxy_juicity_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ServerConfig) GetUsers() []*protocol.User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
 var File_proxy_juicity_config_proto protoreflect.FileDescriptor
 
 const file_proxy_juicity_config_proto_rawDesc = "" +
 	"\n" +
-	"\x1aproxy/juicity/config.proto\x12\x18v2ray.core.proxy.juicity\x1a!common/protocol/server_spec.proto\x1a common/protoext/extensions.proto\"\xd2\x01\n" +
+	"\x1aproxy/juicity/config.proto\x12\x18v2ray.core.proxy.juicity\x1a!common/protocol/server_spec.proto\x1a\x1acommon/protocol/user.proto\x1a common/protoext/extensions.proto\"\xd2\x01\n" +
 	"\fClientConfig\x12B\n" +
 	"\x06server\x18\x01 \x03(\v2*.v2ray.core.common.protocol.ServerEndpointR\x06server\x12-\n" +
 	"\x12congestion_control\x18\x02 \x01(\tR\x11congestionControl\x126\n" +
 	"\x17pinned_certchain_sha256\x18\x03 \x01(\tR\x15pinnedCertchainSha256:\x17\x82\xb5\x18\x13\n" +
-	"\boutbound\x12\ajuicityBJ\n" +
+	"\boutbound\x12\ajuicity\"9\n" +
+	"\aAccount\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"^\n" +
+	"\fServerConfig\x126\n" +
+	"\x05users\x18\x01 \x03(\v2 .v2ray.core.common.protocol.UserR\x05users:\x16\x82\xb5\x18\x12\n" +
+	"\ainbound\x12\ajuicityBJ\n" +
 	"\x1ccom.v2ray.core.proxy.juicityP\x01Z\rproxy/juicity\xaa\x02\x18V2Ray.Core.Proxy.Juicityb\x06proto3"
 
 var (
@@ -106,18 +210,22 @@ func file_proxy_juicity_config_proto_rawDescGZIP() []byte {
 	return file_proxy_juicity_config_proto_rawDescData
 }
 
-var file_proxy_juicity_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_proxy_juicity_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_proxy_juicity_config_proto_goTypes = []any{
 	(*ClientConfig)(nil),            // 0: v2ray.core.proxy.juicity.ClientConfig
-	(*protocol.ServerEndpoint)(nil), // 1: v2ray.core.common.protocol.ServerEndpoint
+	(*Account)(nil),                 // 1: v2ray.core.proxy.juicity.Account
+	(*ServerConfig)(nil),            // 2: v2ray.core.proxy.juicity.ServerConfig
+	(*protocol.ServerEndpoint)(nil), // 3: v2ray.core.common.protocol.ServerEndpoint
+	(*protocol.User)(nil),           // 4: v2ray.core.common.protocol.User
 }
 var file_proxy_juicity_config_proto_depIdxs = []int32{
-	1, // 0: v2ray.core.proxy.juicity.ClientConfig.server:type_name -> v2ray.core.common.protocol.ServerEndpoint
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 0: v2ray.core.proxy.juicity.ClientConfig.server:type_name -> v2ray.core.common.protocol.ServerEndpoint
+	4, // 1: v2ray.core.proxy.juicity.ServerConfig.users:type_name -> v2ray.core.common.protocol.User
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proxy_juicity_config_proto_init() }
@@ -131,7 +239,7 @@ func file_proxy_juicity_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_juicity_config_proto_rawDesc), len(file_proxy_juicity_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,144 @@
+package juicity
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+)
+
+const (
+	// version is the version of the authentication command, which shares its
+	// layout with TUIC: VER(1) TYPE(1) UUID(16) TOKEN(32).
+	version             byte = 0x00
+	commandAuthenticate byte = 0x00
+
+	networkTCP byte = 0x01
+	networkUDP byte = 0x03
+)
+
+var addrParser = protocol.NewAddressParser(
+	protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv4),
+	protocol.AddressFamilyByte(0x03, net.AddressFamilyDomain),
+	protocol.AddressFamilyByte(0x04, net.AddressFamilyIPv6),
+)
+
+// readAuthenticate reads an authentication command from a uni stream.
+func readAuthenticate(r io.Reader) ([16]byte, []byte, error) {
+	var id [16]byte
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return id, nil, err
+	}
+	if header[0] != version || header[1] != commandAuthenticate {
+		return id, nil, newError("unexpected command ", header[0], ":", header[1])
+	}
+	if _, err := io.ReadFull(r, id[:]); err != nil {
+		return id, nil, err
+	}
+	token := make([]byte, tuicTransport.TokenLength)
+	if _, err := io.ReadFull(r, token); err != nil {
+		return id, nil, err
+	}
+	return id, token, nil
+}
+
+// readRequestHeader reads the network and the target at the beginning of a stream.
+func readRequestHeader(r io.Reader) (net.Destination, error) {
+	var network [1]byte
+	if _, err := io.ReadFull(r, network[:]); err != nil {
+		return net.Destination{}, err
+	}
+
+	var dest net.Destination
+	switch network[0] {
+	case networkTCP:
+		dest.Network = net.Network_TCP
+	case networkUDP:
+		dest.Network = net.Network_UDP
+	default:
+		return net.Destination{}, newError("unknown network ", network[0])
+	}
+
+	b := buf.New()
+	defer b.Release()
+	address, port, err := addrParser.ReadAddressPort(b, r)
+	if err != nil {
+		return net.Destination{}, newError("failed to read address").Base(err)
+	}
+	dest.Address = address
+	dest.Port = port
+	return dest, nil
+}
+
+// PacketPayload combines udp payload and destination
+type PacketPayload struct {
+	Target net.Destination
+	Buffer buf.MultiBuffer
+}
+
+// PacketReader reads UDP packets framed as ADDR LENGTH(2) PAYLOAD from a stream.
+type PacketReader struct {
+	io.Reader
+}
+
+// ReadMultiBufferWithMetadata reads udp packet with destination
+func (r *PacketReader) ReadMultiBufferWithMetadata() (*PacketPayload, error) {
+	addr, port, err := addrParser.ReadAddressPort(nil, r)
+	if err != nil {
+		return nil, newError("failed to read address and port").Base(err)
+	}
+
+	var lengthBuf [2]byte
+	if _, err := io.ReadFull(r, lengthBuf[:]); err != nil {
+		return nil, newError("failed to read payload length").Base(err)
+	}
+	length := binary.BigEndian.Uint16(lengthBuf[:])
+
+	b := buf.NewWithSize(int32(length))
+	if _, err := b.ReadFullFrom(r, int32(length)); err != nil {
+		b.Release()
+		return nil, newError("failed to read payload").Base(err)
+	}
+
+	return &PacketPayload{Target: net.UDPDestination(addr, port), Buffer: buf.MultiBuffer{b}}, nil
+}
+
+// PacketWriter writes UDP packets framed as ADDR LENGTH(2) PAYLOAD to a stream.
+type PacketWriter struct {
+	io.Writer
+}
+
+// WriteMultiBufferWithMetadata writes udp packet with destination specified
+func (w *PacketWriter) WriteMultiBufferWithMetadata(mb buf.MultiBuffer, dest net.Destination) error {
+	defer buf.ReleaseMulti(mb)
+	for _, b := range mb {
+		if b.IsEmpty() {
+			continue
+		}
+		if err := w.writePacket(b.Bytes(), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *PacketWriter) writePacket(payload []byte, dest net.Destination) error {
+	b := buf.New()
+	defer b.Release()
+	if err := addrParser.WriteAddressPort(b, dest.Address, dest.Port); err != nil {
+		return err
+	}
+	var lengthBuf [2]byte
+	binary.BigEndian.PutUint16(lengthBuf[:], uint16(len(payload)))
+	common.Must2(b.Write(lengthBuf[:]))
+
+	message := make([]byte, 0, int(b.Len())+len(payload))
+	message = append(append(message, b.Bytes()...), payload...)
+	_, err := w.Writer.Write(message)
+	return err
+}
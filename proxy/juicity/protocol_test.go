@@ -0,0 +1,77 @@
+package juicity
+
+import (
+	"bytes"
+	"testing"
+
+	outboundprotocol "github.com/daeuniverse/outbound/protocol"
+	"github.com/daeuniverse/outbound/protocol/juicity"
+	"github.com/daeuniverse/outbound/protocol/trojanc"
+	"github.com/daeuniverse/outbound/protocol/tuic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+func TestReadRequestHeader(t *testing.T) {
+	for _, tc := range []struct {
+		address string
+		network string
+		dest    net.Destination
+	}{
+		{"example.com:443", "tcp", net.TCPDestination(net.DomainAddress("example.com"), 443)},
+		{"1.2.3.4:53", "udp", net.UDPDestination(net.IPAddress([]byte{1, 2, 3, 4}), 53)},
+		{"[2001:db8::1]:80", "tcp", net.TCPDestination(net.ParseAddress("2001:db8::1"), 80)},
+	} {
+		m, err := outboundprotocol.ParseMetadata(tc.address)
+		common.Must(err)
+		metadata := &trojanc.Metadata{Metadata: m, Network: tc.network}
+		header := make([]byte, 1+metadata.Len())
+		header[0] = trojanc.NetworkToByte(tc.network)
+		metadata.PackTo(header[1:])
+
+		dest, err := readRequestHeader(bytes.NewReader(header))
+		common.Must(err)
+		assert.Equal(t, tc.dest, dest)
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	m, err := outboundprotocol.ParseMetadata("8.8.8.8:53")
+	common.Must(err)
+	metadata := trojanc.Metadata{Metadata: m, Network: "udp"}
+	payload := []byte("query")
+	packet := juicity.SealUDP(metadata, make([]byte, metadata.Len()+2+len(payload)), payload)
+
+	reader := &PacketReader{Reader: bytes.NewReader(packet)}
+	p, err := reader.ReadMultiBufferWithMetadata()
+	common.Must(err)
+	assert.Equal(t, net.UDPDestination(net.IPAddress([]byte{8, 8, 8, 8}), 53), p.Target)
+	assert.Equal(t, payload, p.Buffer[0].Bytes())
+
+	b := new(bytes.Buffer)
+	writer := &PacketWriter{Writer: b}
+	common.Must(writer.WriteMultiBufferWithMetadata(p.Buffer, p.Target))
+	assert.Equal(t, packet, b.Bytes())
+
+	common.Must2(b.Write(packet))
+	reader = &PacketReader{Reader: b}
+	p, err = reader.ReadMultiBufferWithMetadata()
+	common.Must(err)
+	assert.Equal(t, payload, p.Buffer[0].Bytes())
+	buf.ReleaseMulti(p.Buffer)
+}
+
+func TestReadAuthenticate(t *testing.T) {
+	id := [16]byte{1, 2, 3, 4}
+	token := [32]byte{5, 6, 7, 8}
+	b := new(bytes.Buffer)
+	common.Must(tuic.NewAuthenticate(id, token, juicity.Version0).WriteTo(b))
+
+	readID, readToken, err := readAuthenticate(b)
+	common.Must(err)
+	assert.Equal(t, id, readID)
+	assert.Equal(t, token[:], readToken)
+}
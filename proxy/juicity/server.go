@@ -0,0 +1,183 @@
+package juicity
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/apernet/quic-go"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/errors"
+	"github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	udp_proto "github.com/frogwall/f2ray-core/v5/common/protocol/udp"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/udp"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler that handles messages in Juicity protocol.
+type Server struct {
+	policyManager policy.Manager
+	validator     *tuicTransport.Validator
+}
+
+// NewServer creates a new Juicity inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	validator := new(tuicTransport.Validator)
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, newError("failed to get Juicity user").Base(err).AtError()
+		}
+		if err := validator.Add(u); err != nil {
+			return nil, newError("failed to add user").Base(err).AtError()
+		}
+	}
+
+	v := core.MustFromContext(ctx)
+	server := &Server{
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:     validator,
+	}
+	return server, nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	return s.validator.Add(u)
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP}
+}
+
+// Process implements proxy.Inbound.Process(). It serves a whole QUIC
+// connection accepted by the tuic transport.
+func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	iConn := conn
+	if statConn, ok := conn.(*internet.StatCouterConnection); ok {
+		iConn = statConn.Connection // will not count the traffic of streams.
+	}
+	quicConn, ok := iConn.(*tuicTransport.Conn)
+	if !ok {
+		return newError("Juicity inbound must be used with the tuic transport")
+	}
+
+	ss := &serverSession{
+		Session: tuicTransport.NewSession(ctx, quicConn, dispatcher, s.policyManager, s.validator),
+	}
+	go ss.AcceptUniStreams(ss.handleUniStream)
+	go ss.AcceptStreams(ss.handleStream)
+	return ss.Serve(s.policyManager.ForLevel(0).Timeouts.Handshake)
+}
+
+// serverSession is the state of a single Juicity connection.
+type serverSession struct {
+	*tuicTransport.Session
+}
+
+func (ss *serverSession) handleUniStream(stream quic.ReceiveStream) error {
+	id, token, err := readAuthenticate(stream)
+	if err != nil {
+		return newError("failed to read authentication").Base(err)
+	}
+	if err := ss.Authenticate(id, token); err != nil {
+		return err
+	}
+
+	// The client keeps the stream open for the authentication of its
+	// underlay UDP transport, which is not supported. Drain it so that the
+	// client is not blocked.
+	io.Copy(io.Discard, stream)
+	return nil
+}
+
+func (ss *serverSession) handleStream(ctx context.Context, stream quic.Stream) error {
+	destination, err := readRequestHeader(stream)
+	if err != nil {
+		return newError("failed to read request header").Base(err)
+	}
+	if !ss.WaitForAuth() {
+		return newError("connection closed before authentication")
+	}
+
+	if destination.Network == net.Network_UDP {
+		inbound := ss.RefreshUser(ctx)
+		return ss.handleUDPPayload(ctx, ss.PolicyManager.ForLevel(inbound.User.Level), stream)
+	}
+	return ss.RelayStream(ctx, stream, destination)
+}
+
+func (ss *serverSession) handleUDPPayload(ctx context.Context, sessionPolicy policy.Session, stream quic.Stream) error {
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	go func() {
+		<-ctx.Done()
+		stream.CancelRead(0)
+	}()
+
+	clientReader := &PacketReader{Reader: stream}
+	clientWriter := &PacketWriter{Writer: stream}
+
+	var writeAccess sync.Mutex
+	udpServer := udp.NewSplitDispatcher(ss.Dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
+		timer.Update()
+		writeAccess.Lock()
+		defer writeAccess.Unlock()
+		if err := clientWriter.WriteMultiBufferWithMetadata(buf.MultiBuffer{packet.Payload}, packet.Source); err != nil {
+			newError("failed to write response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	})
+	defer udpServer.Close()
+
+	inbound := session.InboundFromContext(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			p, err := clientReader.ReadMultiBufferWithMetadata()
+			if err != nil {
+				if errors.Cause(err) != io.EOF {
+					return newError("unexpected EOF").Base(err)
+				}
+				return nil
+			}
+			timer.Update()
+			currentPacketCtx := log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+				From:   inbound.Source,
+				To:     p.Target,
+				Status: log.AccessAccepted,
+				Reason: "",
+				Email:  inbound.User.Email,
+			})
+			newError("tunnelling request to ", p.Target).WriteToLog(session.ExportIDToError(ctx))
+
+			for _, b := range p.Buffer {
+				udpServer.Dispatch(currentPacketCtx, p.Target, b)
+			}
+		}
+	}
+}
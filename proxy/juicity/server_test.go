@@ -0,0 +1,190 @@
+package juicity_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/dispatcher"
+	"github.com/frogwall/f2ray-core/v5/app/proxyman"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/inbound"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/outbound"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/protocol/tls/cert"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/proxy/dokodemo"
+	"github.com/frogwall/f2ray-core/v5/proxy/freedom"
+	"github.com/frogwall/f2ray-core/v5/proxy/juicity"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/tcp"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/udp"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/tls"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+)
+
+func startInstance(t *testing.T, config *core.Config) {
+	server, err := core.New(config)
+	common.Must(err)
+	common.Must(server.Start())
+	t.Cleanup(func() { server.Close() })
+}
+
+func TestJuicityClientServer(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: bytes.ToUpper,
+	}
+	tcpDest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	udpServer := udp.Server{
+		MsgProcessor: bytes.ToUpper,
+	}
+	udpDest, err := udpServer.Start()
+	common.Must(err)
+	defer udpServer.Close()
+
+	id := uuid.New()
+	apps := []*anypb.Any{
+		serial.ToTypedMessage(&dispatcher.Config{}),
+		serial.ToTypedMessage(&proxyman.InboundConfig{}),
+		serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+	}
+
+	serverPort := udp.PickPort()
+	startInstance(t, &core.Config{
+		App: apps,
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(serverPort),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+					StreamSettings: &internet.StreamConfig{
+						ProtocolName: "tuic",
+						SecurityType: serial.GetMessageType(&tls.Config{}),
+						SecuritySettings: []*anypb.Any{
+							serial.ToTypedMessage(&tls.Config{
+								Certificate: []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil))},
+							}),
+						},
+						TransportSettings: []*internet.TransportConfig{
+							{
+								ProtocolName: "tuic",
+								Settings:     serial.ToTypedMessage(&tuicTransport.Config{}),
+							},
+						},
+					},
+				}),
+				ProxySettings: serial.ToTypedMessage(&juicity.ServerConfig{
+					Users: []*protocol.User{
+						{
+							Email: "love@v2fly.org",
+							Account: serial.ToTypedMessage(&juicity.Account{
+								Uuid:     id.String(),
+								Password: "password",
+							}),
+						},
+					},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{ProxySettings: serial.ToTypedMessage(&freedom.Config{})},
+		},
+	})
+
+	tcpPort := tcp.PickPort()
+	udpPort := udp.PickPort()
+	dokodemoInbound := func(port net.Port, dest net.Destination) *core.InboundHandlerConfig {
+		return &core.InboundHandlerConfig{
+			ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+				PortRange: net.SinglePortRange(port),
+				Listen:    net.NewIPOrDomain(net.LocalHostIP),
+			}),
+			ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+				Address:     net.NewIPOrDomain(dest.Address),
+				Port:        uint32(dest.Port),
+				NetworkList: &net.NetworkList{Network: []net.Network{dest.Network}},
+			}),
+		}
+	}
+	startInstance(t, &core.Config{
+		App: apps,
+		Inbound: []*core.InboundHandlerConfig{
+			dokodemoInbound(tcpPort, tcpDest),
+			dokodemoInbound(udpPort, udpDest),
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				SenderSettings: serial.ToTypedMessage(&proxyman.SenderConfig{
+					StreamSettings: &internet.StreamConfig{
+						SecurityType: serial.GetMessageType(&tls.Config{}),
+						SecuritySettings: []*anypb.Any{
+							serial.ToTypedMessage(&tls.Config{
+								ServerName:    "www.v2fly.org",
+								AllowInsecure: true,
+							}),
+						},
+					},
+				}),
+				ProxySettings: serial.ToTypedMessage(&juicity.ClientConfig{
+					Server: []*protocol.ServerEndpoint{
+						{
+							Address: net.NewIPOrDomain(net.LocalHostIP),
+							Port:    uint32(serverPort),
+							User: []*protocol.User{
+								{
+									// The client takes the UUID from the email and the raw
+									// password from the account.
+									Email:   id.String(),
+									Account: &anypb.Any{Value: []byte("password")},
+								},
+							},
+						},
+					},
+					CongestionControl: "bbr",
+				}),
+			},
+		},
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		conn, err := net.DialTCP("tcp", nil, &net.TCPAddr{IP: []byte{127, 0, 0, 1}, Port: int(tcpPort)})
+		common.Must(err)
+		defer conn.Close()
+
+		payload := make([]byte, 64*1024)
+		common.Must2(io.ReadFull(bytes.NewReader(bytes.Repeat([]byte("juicity"), len(payload))), payload))
+		common.Must2(conn.Write(payload))
+		common.Must(conn.SetReadDeadline(time.Now().Add(10 * time.Second)))
+		response := make([]byte, len(payload))
+		common.Must2(io.ReadFull(conn, response))
+		if !bytes.Equal(response, bytes.ToUpper(payload)) {
+			t.Error("unexpected response over TCP")
+		}
+	})
+
+	t.Run("udp", func(t *testing.T) {
+		conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: []byte{127, 0, 0, 1}, Port: int(udpPort)})
+		common.Must(err)
+		defer conn.Close()
+
+		for _, payload := range []string{"first", "second", "third"} {
+			common.Must2(conn.Write([]byte(payload)))
+			common.Must(conn.SetReadDeadline(time.Now().Add(10 * time.Second)))
+			response := make([]byte, 1024)
+			n, err := conn.Read(response)
+			common.Must(err)
+			if string(response[:n]) != string(bytes.ToUpper([]byte(payload))) {
+				t.Error("unexpected response over UDP: ", string(response[:n]))
+			}
+		}
+	})
+}
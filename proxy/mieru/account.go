@@ -0,0 +1,29 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// Equals implements protocol.Account.Equals().
+func (a *Account) Equals(account protocol.Account) bool {
+	mieruAccount, ok := account.(*Account)
+	if !ok {
+		return false
+	}
+	return a.Username == mieruAccount.Username && a.Password == mieruAccount.Password
+}
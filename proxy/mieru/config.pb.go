@@ -1,6 +1,8 @@
 package mieru
 
 import (
+	protocol "github.com/frogwall/f2ray-core/v5/common/protocol"
+	_ "github.com/frogwall/f2ray-core/v5/common/protoext"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -135,11 +137,118 @@ func (x *ClientConfig) GetMtu() int32 {
 	return 0
 }
 
+type Account struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Account) Reset() {
+	*x = Account{}
+	mi := &file_proxy_mieru_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Account) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Account) ProtoMessage() {}
+
+func (x *Account) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_mieru_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Account.ProtoReflect.Descriptor instead.
+func (*Account) Descriptor() ([]byte, []int) {
+	return file_proxy_mieru_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Account) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *Account) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type ServerConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Users with Account as their account type
+	Users []*protocol.User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// Maximum size of UDP packets sent to the clients. DefaultMTU is used if
+	// not set.
+	Mtu           int32 `protobuf:"varint,2,opt,name=mtu,proto3" json:"mtu,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerConfig) Reset() {
+	*x = ServerConfig{}
+	mi := &file_proxy_mieru_config_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerConfig) ProtoMessage() {}
+
+func (x *ServerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_mieru_config_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerConfig.ProtoReflect.Descriptor instead.
+func (*ServerConfig) Descriptor() ([]byte, []int) {
+	return file_proxy_mieru_config_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ServerConfig) GetUsers() []*protocol.User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetMtu() int32 {
+	if x != nil {
+		return x.Mtu
+	}
+	return 0
+}
+
 var File_proxy_mieru_config_proto protoreflect.FileDescriptor
 
 const file_proxy_mieru_config_proto_rawDesc = "" +
 	"\n" +
-	"\x18proxy/mieru/config.proto\x12\x16v2ray.core.proxy.mieru\"n\n" +
+	"\x18proxy/mieru/config.proto\x12\x16v2ray.core.proxy.mieru\x1a\x1acommon/protocol/user.proto\x1a common/protoext/extensions.proto\"n\n" +
 	"\x06Server\x12\x18\n" +
 	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x12\n" +
 	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x1a\n" +
@@ -147,7 +256,14 @@ const file_proxy_mieru_config_proto_rawDesc = "" +
 	"\bpassword\x18\x04 \x01(\tR\bpassword\"Z\n" +
 	"\fClientConfig\x128\n" +
 	"\aservers\x18\x01 \x03(\v2\x1e.v2ray.core.proxy.mieru.ServerR\aservers\x12\x10\n" +
-	"\x03mtu\x18\x02 \x01(\x05R\x03mtuBf\n" +
+	"\x03mtu\x18\x02 \x01(\x05R\x03mtu\"A\n" +
+	"\aAccount\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"n\n" +
+	"\fServerConfig\x126\n" +
+	"\x05users\x18\x01 \x03(\v2 .v2ray.core.common.protocol.UserR\x05users\x12\x10\n" +
+	"\x03mtu\x18\x02 \x01(\x05R\x03mtu:\x14\x82\xb5\x18\x10\n" +
+	"\ainbound\x12\x05mieruBf\n" +
 	"\x1acom.v2ray.core.proxy.mieruP\x01Z-github.com/frogwall/f2ray-core/v5/proxy/mieru\xaa\x02\x16V2Ray.Core.Proxy.Mierub\x06proto3"
 
 var (
@@ -162,18 +278,22 @@ func file_proxy_mieru_config_proto_rawDescGZIP() []byte {
 	return file_proxy_mieru_config_proto_rawDescData
 }
 
-var file_proxy_mieru_config_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proxy_mieru_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_proxy_mieru_config_proto_goTypes = []any{
-	(*Server)(nil),       // 0: v2ray.core.proxy.mieru.Server
-	(*ClientConfig)(nil), // 1: v2ray.core.proxy.mieru.ClientConfig
+	(*Server)(nil),        // 0: v2ray.core.proxy.mieru.Server
+	(*ClientConfig)(nil),  // 1: v2ray.core.proxy.mieru.ClientConfig
+	(*Account)(nil),       // 2: v2ray.core.proxy.mieru.Account
+	(*ServerConfig)(nil),  // 3: v2ray.core.proxy.mieru.ServerConfig
+	(*protocol.User)(nil), // 4: v2ray.core.common.protocol.User
 }
 var file_proxy_mieru_config_proto_depIdxs = []int32{
 	0, // 0: v2ray.core.proxy.mieru.ClientConfig.servers:type_name -> v2ray.core.proxy.mieru.Server
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4, // 1: v2ray.core.proxy.mieru.ServerConfig.users:type_name -> v2ray.core.common.protocol.User
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proxy_mieru_config_proto_init() }
@@ -187,7 +307,7 @@ func file_proxy_mieru_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_mieru_config_proto_rawDesc), len(file_proxy_mieru_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -980,46 +980,10 @@ func (s *MieruSession) writeOneSegment(seg *Segment) error {
 	// fmt.Printf("[MIERU DEBUG] Writing segment with protocol: %d\n", seg.protocolType())
 
 	// Generate padding BEFORE serializing metadata (so suffixLen is included)
-	// Use MaxPaddingSize exactly like mieru-main with MTU support
-	_ = DefaultMTU        // Use DefaultMTU constant (kept for reference)
-	maxPaddingSize := 255 // MaxPaddingSize for stream transport (no limit)
-	// Use exactly the same logic as mieru-main: 24 + rng.FixedIntPerHost(17)
-	// For simplicity, use 24 + 8 (middle value of 0-16 range)
-	minConsecutiveASCIILen := 24 + 8 // recommendedConsecutiveASCIILen = 24 + rng.FixedIntPerHost(17)
-	if maxPaddingSize < minConsecutiveASCIILen {
-		maxPaddingSize = minConsecutiveASCIILen
-	}
-	// fmt.Printf("[MIERU V2RAY DEBUG] Padding calculation: mtu=%d, maxPaddingSize=%d, minConsecutiveASCIILen=%d, payloadLen=%d\n", mtu, maxPaddingSize, minConsecutiveASCIILen, len(seg.payload))
-	// Use the same random number generation as mieru-main
-	// Use rng.Intn with scale down distribution exactly like mieru-main
-	paddingSize := rngIntn(maxPaddingSize-minConsecutiveASCIILen+1) + minConsecutiveASCIILen
-	padding := make([]byte, paddingSize)
-
-	// Generate padding exactly like mieru-main
 	// Choose strategy randomly like mieru-main (ASCII or entropy)
 	strategy := rngFixedInt(2, "mieru-client") // Use same strategy source as mieru-main
-	if strategy == 0 {
-		// Use ASCII padding exactly like mieru-main
-		// First generate random bytes using crypto/rand exactly like mieru-main
-		if _, err := rand.Read(padding); err != nil {
-			return fmt.Errorf("failed to generate random padding: %w", err)
-		}
-
-		// Convert part of the padding to printable ASCII characters exactly like mieru-main
-		// Use ToPrintableChar for the first minConsecutiveASCIILen bytes
-		beginIdx := 0
-		if len(padding) > minConsecutiveASCIILen {
-			beginIdx = mathrand.Intn(len(padding) - minConsecutiveASCIILen)
-		}
-		toPrintableChar(padding, beginIdx, beginIdx+minConsecutiveASCIILen)
-	} else {
-		// Use entropy padding exactly like mieru-main
-		// Generate random bytes using crypto/rand exactly like mieru-main
-		if _, err := rand.Read(padding); err != nil {
-			return fmt.Errorf("failed to generate random padding: %w", err)
-		}
-		// No additional processing for entropy padding
-	}
+	padding := newPadding(streamMaxPaddingSize, recommendedConsecutiveASCIILen, strategy == 0)
+	paddingSize := len(padding)
 
 	// Update suffixLen in metadata BEFORE serializing
 	// Support both sessionStruct and dataAckStruct
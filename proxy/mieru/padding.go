@@ -0,0 +1,68 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"crypto/rand"
+	mathrand "math/rand"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+)
+
+const (
+	// recommendedConsecutiveASCIILen is the length of printable characters
+	// in the padding of segments, mieru-main uses 24 + rng.FixedIntPerHost(17).
+	recommendedConsecutiveASCIILen = 24 + 8
+
+	// streamMaxPaddingSize is the maximum padding size of stream transport.
+	streamMaxPaddingSize = 255
+
+	// packetOverhead is the size of a packet without payload and padding:
+	// nonce, encrypted metadata and authentication tag of the payload.
+	packetOverhead = DefaultNonceSize + MetadataLength + DefaultOverhead*2
+)
+
+// newPadding returns random padding of at most maxLen bytes. If ascii is
+// true, minConsecutiveASCIILen printable characters are placed at a random
+// position of the padding, which is also the minimum length of the padding.
+func newPadding(maxLen, minConsecutiveASCIILen int, ascii bool) []byte {
+	if maxLen <= 0 {
+		return nil
+	}
+	if minConsecutiveASCIILen > maxLen {
+		minConsecutiveASCIILen = maxLen
+	}
+	padding := make([]byte, rngIntn(maxLen-minConsecutiveASCIILen+1)+minConsecutiveASCIILen)
+	common.Must2(rand.Read(padding))
+	if ascii {
+		beginIdx := 0
+		if len(padding) > minConsecutiveASCIILen {
+			beginIdx = mathrand.Intn(len(padding) - minConsecutiveASCIILen)
+		}
+		toPrintableChar(padding, beginIdx, beginIdx+minConsecutiveASCIILen)
+	}
+	return padding
+}
+
+// packetMaxPaddingSize returns the maximum padding size of a packet carrying
+// payloadLen bytes, which is also limited by the MTU.
+func packetMaxPaddingSize(mtu, payloadLen, existingPaddingSize int) int {
+	res := mtu - payloadLen - packetOverhead - existingPaddingSize
+	if res <= 0 {
+		return 0
+	}
+	return min(res, 255)
+}
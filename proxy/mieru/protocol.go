@@ -110,39 +110,23 @@ func GenerateKey(username, password string) ([]byte, error) {
 
 // Generate multiple keys for time tolerance exactly like mieru-main
 func GenerateKeysWithTolerance(username, password string) ([][]byte, error) {
-	// fmt.Printf("[MIERU V2RAY DEBUG] GenerateKeysWithTolerance called with username=%s, password=%s\n", username, password)
-	var keys [][]byte
+	return generateKeysFromTime(username, password, time.Now()), nil
+}
 
-	// Generate keys for current time and tolerance range
-	// Use exactly the same logic as mieru-main saltFromTime
-	// CRITICAL FIX: Use rounded time exactly like mieru-main server
-	baseTime := time.Now()
-	// fmt.Printf("[MIERU V2RAY DEBUG] Base time: %s\n", baseTime.Format("2006-01-02 15:04:05"))
-
-	// Use exactly the same saltFromTime function as mieru-main
-	// This will round the time to the nearest 2-minute interval
-	salts := saltFromTime(baseTime)
-	// fmt.Printf("[MIERU V2RAY DEBUG] Generated %d salts from time %s\n", len(salts), baseTime.Format("2006-01-02 15:04:05"))
-
-	// Generate keys using the same logic as mieru-main
-	for _, salt := range salts {
-		// fmt.Printf("[MIERU V2RAY DEBUG] Using salt %d: %x\n", i, salt[:8])
-
-		// Generate hashed password exactly like mieru-main HashPassword function
-		// Server uses: HashPassword([]byte(user.GetPassword()), []byte(user.GetName()))
-		// Which does: sha256.Sum256(append(append(rawPassword, 0x00), uniqueValue...))
-		p := append([]byte(password), 0x00) // 0x00 separates the password and username
-		p = append(p, []byte(username)...)
-		hashedPassword := sha256.Sum256(p)
-		// fmt.Printf("[MIERU V2RAY DEBUG] Hashed password: %x\n", hashedPassword[:8])
-
-		// Generate key using PBKDF2 exactly like mieru-main
-		key := pbkdf2.Key(hashedPassword[:], salt, 64, 32, sha256.New)
-		keys = append(keys, key)
-		// fmt.Printf("[MIERU V2RAY DEBUG] Generated key %d: %x (full key: %x)\n", i, key[:8], key)
-	}
+// generateKeysFromTime returns the keys of the time windows before, at and
+// after t, exactly like mieru-main.
+func generateKeysFromTime(username, password string, t time.Time) [][]byte {
+	// Server uses: HashPassword([]byte(user.GetPassword()), []byte(user.GetName()))
+	// Which does: sha256.Sum256(append(append(rawPassword, 0x00), uniqueValue...))
+	p := append([]byte(password), 0x00) // 0x00 separates the password and username
+	p = append(p, []byte(username)...)
+	hashedPassword := sha256.Sum256(p)
 
-	return keys, nil
+	var keys [][]byte
+	for _, salt := range saltFromTime(t) {
+		keys = append(keys, pbkdf2.Key(hashedPassword[:], salt, 64, 32, sha256.New))
+	}
+	return keys
 }
 
 // saltFromTime generates time-based salts exactly like mieru-main
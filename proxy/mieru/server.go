@@ -0,0 +1,277 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/antireplay"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/errors"
+	"github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	udp_proto "github.com/frogwall/f2ray-core/v5/common/protocol/udp"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/common/task"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/udp"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewInbound(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Inbound is an inbound connection handler that handles messages in Mieru protocol.
+type Inbound struct {
+	policyManager policy.Manager
+	validator     *Validator
+	filter        *antireplay.ReplayFilter
+	mtu           int
+}
+
+// NewInbound creates a new Mieru inbound handler.
+func NewInbound(ctx context.Context, config *ServerConfig) (*Inbound, error) {
+	validator := new(Validator)
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, newError("failed to get Mieru user").Base(err).AtError()
+		}
+		if err := validator.Add(u); err != nil {
+			return nil, newError("failed to add user").Base(err).AtError()
+		}
+	}
+
+	mtu := int(config.Mtu)
+	if mtu == 0 {
+		mtu = DefaultMTU
+	}
+	if mtu < 1280 || mtu > 1500 {
+		return nil, newError("invalid MTU ", mtu)
+	}
+
+	v := core.MustFromContext(ctx)
+	server := &Inbound{
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:     validator,
+		filter:        antireplay.NewReplayFilter(replayInterval),
+		mtu:           mtu,
+	}
+	return server, nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Inbound) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	return s.validator.Add(u)
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Inbound) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Inbound) Network() []net.Network {
+	return []net.Network{net.Network_TCP, net.Network_UDP}
+}
+
+// Process implements proxy.Inbound.Process(). It serves an underlay
+// connection, which may carry multiple sessions.
+func (s *Inbound) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	inbound := session.InboundFromContext(ctx)
+	if inbound == nil {
+		panic("no inbound metadata")
+	}
+
+	onSession := func(ss *serverSession) {
+		ctx := sessionContext(ctx, ss.user)
+		if err := s.handleSession(ctx, ss, dispatcher); err != nil {
+			newError("session ends").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		}
+	}
+
+	switch network {
+	case net.Network_TCP:
+		u := newStreamUnderlay(conn, s.validator, s.filter, onSession)
+		if err := u.serve(); err != nil && errors.Cause(err) != io.EOF {
+			return newError("connection ends").Base(err)
+		}
+		return nil
+	case net.Network_UDP:
+		u := newPacketUnderlay(conn, s.mtu, s.validator, s.filter, onSession)
+		if err := u.serve(buf.NewPacketReader(conn)); err != nil && errors.Cause(err) != io.EOF {
+			return newError("connection ends").Base(err)
+		}
+		return nil
+	default:
+		return newError("unknown network: ", network)
+	}
+}
+
+// sessionContext returns a context for a session carried by the underlay,
+// each session has its own session ID and content.
+func sessionContext(ctx context.Context, user *protocol.MemoryUser) context.Context {
+	inbound := *session.InboundFromContext(ctx)
+	inbound.Conn = nil
+	inbound.CanSpliceCopy = 3
+	inbound.User = user
+	newCtx := session.ContextWithID(ctx, session.NewID())
+	newCtx = session.ContextWithInbound(newCtx, &inbound)
+	if content := session.ContentFromContext(ctx); content != nil {
+		newCtx = session.ContextWithContent(newCtx, &session.Content{
+			SniffingRequest: content.SniffingRequest,
+		})
+	}
+	return newCtx
+}
+
+func (s *Inbound) handleSession(ctx context.Context, ss *serverSession, dispatcher routing.Dispatcher) error {
+	defer ss.Close()
+
+	inbound := session.InboundFromContext(ctx)
+	sessionPolicy := s.policyManager.ForLevel(inbound.User.Level)
+
+	handshakeTimer := time.AfterFunc(sessionPolicy.Timeouts.Handshake, func() {
+		ss.Close()
+	})
+	cmd, destination, err := readSocks5Request(ss)
+	handshakeTimer.Stop()
+	if err != nil {
+		return newError("failed to read request").Base(err)
+	}
+	switch cmd {
+	case socks5CmdConnect, socks5CmdUDPAssociate:
+	default:
+		writeSocks5Reply(ss, socks5ReplyCommandNotSupported)
+		return newError("unsupported command ", cmd)
+	}
+	if err := writeSocks5Reply(ss, socks5ReplySucceeded); err != nil {
+		return newError("failed to write reply").Base(err)
+	}
+
+	if destination.Network == net.Network_UDP {
+		return s.handleUDPPayload(ctx, sessionPolicy, ss, dispatcher)
+	}
+
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     destination,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  inbound.User.Email,
+	})
+	newError("received request for ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
+
+	link, err := dispatcher.Dispatch(ctx, destination)
+	if err != nil {
+		return newError("failed to dispatch request to ", destination).Base(err)
+	}
+
+	requestDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+		if err := buf.Copy(ss, link.Writer, buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+		if err := buf.Copy(link.Reader, buf.NewWriter(ss), buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to write response").Base(err)
+		}
+		return nil
+	}
+
+	requestDonePost := task.OnSuccess(requestDone, task.Close(link.Writer))
+	if err := task.Run(ctx, requestDonePost, responseDone); err != nil {
+		common.Must(common.Interrupt(link.Reader))
+		common.Must(common.Interrupt(link.Writer))
+		return newError("connection ends").Base(err)
+	}
+
+	return nil
+}
+
+func (s *Inbound) handleUDPPayload(ctx context.Context, sessionPolicy policy.Session, ss *serverSession, dispatcher routing.Dispatcher) error {
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	go func() {
+		<-ctx.Done()
+		ss.Close()
+	}()
+
+	clientReader := &PacketReader{Reader: ss}
+	clientWriter := &PacketWriter{Writer: ss}
+
+	var writeAccess sync.Mutex
+	udpServer := udp.NewSplitDispatcher(dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
+		timer.Update()
+		writeAccess.Lock()
+		defer writeAccess.Unlock()
+		if err := clientWriter.WriteMultiBufferWithMetadata(buf.MultiBuffer{packet.Payload}, packet.Source); err != nil {
+			newError("failed to write response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	})
+	defer udpServer.Close()
+
+	inbound := session.InboundFromContext(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			p, err := clientReader.ReadMultiBufferWithMetadata()
+			if err != nil {
+				if errors.Cause(err) != io.EOF {
+					return newError("unexpected EOF").Base(err)
+				}
+				return nil
+			}
+			timer.Update()
+			currentPacketCtx := log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+				From:   inbound.Source,
+				To:     p.Target,
+				Status: log.AccessAccepted,
+				Reason: "",
+				Email:  inbound.User.Email,
+			})
+			newError("tunnelling request to ", p.Target).WriteToLog(session.ExportIDToError(ctx))
+
+			for _, b := range p.Buffer {
+				udpServer.Dispatch(currentPacketCtx, p.Target, b)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"io"
+	"sync"
+
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+	"github.com/frogwall/f2ray-core/v5/transport/pipe"
+)
+
+// sessionBufferSize is the maximum size of data received from the client
+// that is not read by the session handler yet.
+const sessionBufferSize = 256 * 1024
+
+// serverSession is a session opened by a client. It is read and written by
+// the session handler, while the segments are sent and received by its
+// underlay.
+type serverSession struct {
+	id       uint32
+	user     *protocol.MemoryUser
+	underlay underlay
+
+	reader *buf.BufferedReader
+	input  *pipe.Writer
+	done   *done.Instance
+
+	access   sync.Mutex
+	nextSend uint32
+	nextRecv uint32
+}
+
+func newServerSession(id uint32, user *protocol.MemoryUser, u underlay) *serverSession {
+	reader, writer := pipe.New(pipe.WithSizeLimit(sessionBufferSize))
+	return &serverSession{
+		id:       id,
+		user:     user,
+		underlay: u,
+		reader:   &buf.BufferedReader{Reader: reader},
+		input:    writer,
+		done:     done.New(),
+	}
+}
+
+// deliver passes the payload received from the client to the handler. It
+// blocks if the handler doesn't keep up.
+func (s *serverSession) deliver(payload []byte) error {
+	if len(payload) == 0 {
+		return nil
+	}
+	return s.input.WriteMultiBuffer(buf.MergeBytes(nil, payload))
+}
+
+// closeByClient closes the session closed by the client. Data already
+// received is still available to the handler.
+func (s *serverSession) closeByClient() {
+	s.access.Lock()
+	defer s.access.Unlock()
+	s.done.Close()
+	s.input.Close()
+}
+
+// ReadMultiBuffer implements buf.Reader.
+func (s *serverSession) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	return s.reader.ReadMultiBuffer()
+}
+
+// Read implements io.Reader.
+func (s *serverSession) Read(b []byte) (int, error) {
+	return s.reader.Read(b)
+}
+
+// Write implements io.Writer.
+func (s *serverSession) Write(b []byte) (int, error) {
+	n := 0
+	for len(b) > 0 {
+		if s.done.Done() {
+			return n, io.ErrClosedPipe
+		}
+		size := min(len(b), MaxPDU)
+		if err := s.underlay.writeData(s, b[:size]); err != nil {
+			return n, err
+		}
+		n += size
+		b = b[size:]
+	}
+	return n, nil
+}
+
+// Close implements io.Closer.
+func (s *serverSession) Close() error {
+	s.access.Lock()
+	if s.done.Done() {
+		s.access.Unlock()
+		return nil
+	}
+	s.done.Close()
+	s.input.Interrupt()
+	s.access.Unlock()
+	return s.underlay.closeSession(s)
+}
@@ -0,0 +1,197 @@
+package mieru
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mieruclient "github.com/enfein/mieru/v3/apis/client"
+	"github.com/enfein/mieru/v3/pkg/appctl/appctlpb"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/antireplay"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// echoSession answers the request of a session, and echoes the data.
+func echoSession(ss *serverSession) {
+	defer ss.Close()
+	if _, _, err := readSocks5Request(ss); err != nil {
+		return
+	}
+	if err := writeSocks5Reply(ss, socks5ReplySucceeded); err != nil {
+		return
+	}
+	buf.Copy(ss, buf.NewWriter(ss))
+}
+
+// packetConn serves a single client of a UDP socket. The client opens a new
+// socket for each underlay, so responses go to the latest source address.
+type packetConn struct {
+	conn net.PacketConn
+	addr atomic.Value
+}
+
+func (c *packetConn) ReadMultiBuffer() (buf.MultiBuffer, error) {
+	b := buf.New()
+	n, addr, err := c.conn.ReadFrom(b.Extend(buf.Size))
+	if err != nil {
+		b.Release()
+		return nil, err
+	}
+	b.Resize(0, int32(n))
+	c.addr.Store(addr)
+	return buf.MultiBuffer{b}, nil
+}
+
+func (c *packetConn) Write(b []byte) (int, error) {
+	return c.conn.WriteTo(b, c.addr.Load().(net.Addr))
+}
+
+func newTestValidator(t *testing.T) *Validator {
+	validator := new(Validator)
+	common.Must(validator.Add(&protocol.MemoryUser{
+		Email:   "love@v2fly.org",
+		Account: &Account{Username: "user", Password: "password"},
+	}))
+	return validator
+}
+
+func newTestClient(t *testing.T, port int, transport appctlpb.TransportProtocol) mieruclient.Client {
+	client := mieruclient.NewClient()
+	common.Must(client.Store(&mieruclient.ClientConfig{
+		Profile: &appctlpb.ClientProfile{
+			ProfileName: proto.String("default"),
+			User: &appctlpb.User{
+				Name:     proto.String("user"),
+				Password: proto.String("password"),
+			},
+			Servers: []*appctlpb.ServerEndpoint{
+				{
+					IpAddress: proto.String("127.0.0.1"),
+					PortBindings: []*appctlpb.PortBinding{
+						{
+							Port:     proto.Int32(int32(port)),
+							Protocol: transport.Enum(),
+						},
+					},
+				},
+			},
+			Mtu: proto.Int32(DefaultMTU),
+		},
+	}))
+	common.Must(client.Start())
+	// The client is never stopped, as closing its sessions and underlays races
+	// with their event loops inside the mieru library. It exits with the test.
+	return client
+}
+
+// echoChunkSize is the size of data written before its echo is read.
+const echoChunkSize = 16 * 1024
+
+func testEcho(t *testing.T, client mieruclient.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, size := range []int{16, 100 * 1024} {
+		conn, err := client.DialContext(ctx, &net.TCPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 80})
+		common.Must(err)
+
+		payload := make([]byte, size)
+		common.Must2(rand.Read(payload))
+
+		// The session of the client is not safe for concurrent use, so the
+		// payload is written and echoed chunk by chunk in this goroutine.
+		response := make([]byte, size)
+		for offset := 0; offset < size; offset += echoChunkSize {
+			end := min(offset+echoChunkSize, size)
+			common.Must2(conn.Write(payload[offset:end]))
+			conn.SetReadDeadline(time.Now().Add(20 * time.Second))
+			if _, err := io.ReadFull(conn, response[offset:end]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !bytes.Equal(payload, response) {
+			t.Fatal("response doesn't match the request")
+		}
+		// The session is not closed, see newTestClient.
+	}
+}
+
+func TestStreamUnderlay(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	validator := newTestValidator(t)
+	filter := antireplay.NewReplayFilter(replayInterval)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				newStreamUnderlay(conn, validator, filter, echoSession).serve()
+			}()
+		}
+	}()
+
+	client := newTestClient(t, listener.Addr().(*net.TCPAddr).Port, appctlpb.TransportProtocol_TCP)
+	testEcho(t, client)
+}
+
+func TestPacketUnderlay(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	common.Must(err)
+	defer conn.Close()
+
+	pc := &packetConn{conn: conn}
+	u := newPacketUnderlay(pc, DefaultMTU, newTestValidator(t), antireplay.NewReplayFilter(replayInterval), echoSession)
+	go u.serve(pc)
+
+	client := newTestClient(t, conn.LocalAddr().(*net.UDPAddr).Port, appctlpb.TransportProtocol_UDP)
+	testEcho(t, client)
+}
+
+func TestUnknownUser(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer listener.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		validator := new(Validator)
+		common.Must(validator.Add(&protocol.MemoryUser{
+			Account: &Account{Username: "user", Password: "another password"},
+		}))
+		errCh <- newStreamUnderlay(conn, validator, antireplay.NewReplayFilter(replayInterval), echoSession).serve()
+	}()
+
+	client := newTestClient(t, listener.Addr().(*net.TCPAddr).Port, appctlpb.TransportProtocol_TCP)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client.DialContext(ctx, &net.TCPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 80})
+
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("expected authentication error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+}
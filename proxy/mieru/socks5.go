@@ -0,0 +1,166 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// Sessions carry SOCKS5 requests without authentication, as the client is
+// already authenticated by the underlay.
+const (
+	socks5Version byte = 0x05
+
+	socks5CmdConnect      byte = 0x01
+	socks5CmdUDPAssociate byte = 0x03
+
+	socks5ReplySucceeded           byte = 0x00
+	socks5ReplyCommandNotSupported byte = 0x07
+
+	packetPrefix byte = 0x00
+	packetSuffix byte = 0xff
+)
+
+var addrParser = protocol.NewAddressParser(
+	protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv4),
+	protocol.AddressFamilyByte(0x03, net.AddressFamilyDomain),
+	protocol.AddressFamilyByte(0x04, net.AddressFamilyIPv6),
+)
+
+// readSocks5Request reads the SOCKS5 request at the beginning of a session.
+func readSocks5Request(r io.Reader) (byte, net.Destination, error) {
+	var header [3]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, net.Destination{}, newError("failed to read request header").Base(err)
+	}
+	if header[0] != socks5Version {
+		return 0, net.Destination{}, newError("unexpected SOCKS version ", header[0])
+	}
+
+	b := buf.New()
+	defer b.Release()
+	address, port, err := addrParser.ReadAddressPort(b, r)
+	if err != nil {
+		return 0, net.Destination{}, newError("failed to read address").Base(err)
+	}
+
+	dest := net.TCPDestination(address, port)
+	if header[1] == socks5CmdUDPAssociate {
+		dest.Network = net.Network_UDP
+	}
+	return header[1], dest, nil
+}
+
+// writeSocks5Reply writes a SOCKS5 reply with an unspecified bind address.
+func writeSocks5Reply(w io.Writer, reply byte) error {
+	_, err := w.Write([]byte{socks5Version, reply, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// PacketPayload combines udp payload and destination
+type PacketPayload struct {
+	Target net.Destination
+	Buffer buf.MultiBuffer
+}
+
+// PacketReader reads SOCKS5 UDP datagrams framed as 0x00 LENGTH(2) DATAGRAM 0xff
+// from a session.
+type PacketReader struct {
+	io.Reader
+}
+
+// ReadMultiBufferWithMetadata reads udp packet with destination
+func (r *PacketReader) ReadMultiBufferWithMetadata() (*PacketPayload, error) {
+	var header [3]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if header[0] != packetPrefix {
+		return nil, newError("unexpected packet prefix ", header[0])
+	}
+	length := int32(binary.BigEndian.Uint16(header[1:]))
+
+	b := buf.NewWithSize(length + 1)
+	if _, err := b.ReadFullFrom(r, length+1); err != nil {
+		b.Release()
+		return nil, newError("failed to read packet").Base(err)
+	}
+	if b.Byte(length) != packetSuffix {
+		b.Release()
+		return nil, newError("unexpected packet suffix ", b.Byte(length))
+	}
+	b.Resize(0, length)
+
+	// RSV(2) FRAG(1)
+	if b.Len() < 3 || b.Byte(2) != 0 {
+		b.Release()
+		return nil, newError("fragmented or malformed datagram")
+	}
+	b.Advance(3)
+	addr, port, err := addrParser.ReadAddressPort(nil, b)
+	if err != nil {
+		b.Release()
+		return nil, newError("failed to read address and port").Base(err)
+	}
+
+	return &PacketPayload{Target: net.UDPDestination(addr, port), Buffer: buf.MultiBuffer{b}}, nil
+}
+
+// PacketWriter writes SOCKS5 UDP datagrams framed as 0x00 LENGTH(2) DATAGRAM 0xff
+// to a session.
+type PacketWriter struct {
+	io.Writer
+}
+
+// WriteMultiBufferWithMetadata writes udp packet with destination specified
+func (w *PacketWriter) WriteMultiBufferWithMetadata(mb buf.MultiBuffer, dest net.Destination) error {
+	defer buf.ReleaseMulti(mb)
+	for _, b := range mb {
+		if b.IsEmpty() {
+			continue
+		}
+		if err := w.writePacket(b.Bytes(), dest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *PacketWriter) writePacket(payload []byte, dest net.Destination) error {
+	header := buf.New()
+	defer header.Release()
+	common.Must2(header.Write([]byte{0, 0, 0}))
+	if err := addrParser.WriteAddressPort(header, dest.Address, dest.Port); err != nil {
+		return err
+	}
+
+	length := int(header.Len()) + len(payload)
+	if length > 65535 {
+		return newError("packet is too large: ", length)
+	}
+	message := make([]byte, 0, length+4)
+	message = append(message, packetPrefix, byte(length>>8), byte(length))
+	message = append(append(message, header.Bytes()...), payload...)
+	message = append(message, packetSuffix)
+	_, err := w.Writer.Write(message)
+	return err
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"time"
+)
+
+const (
+	// sessionWindowSize is the receive window of a session, in number of
+	// segments.
+	sessionWindowSize = 256
+
+	// sessionHeartbeatInterval is the interval of acknowledges sent when a
+	// session is idle, same as mieru-main.
+	sessionHeartbeatInterval = 5 * time.Second
+
+	// idleSessionTimeout closes a packet session that the client doesn't
+	// send anything, including heartbeats, for the duration.
+	idleSessionTimeout = time.Minute
+
+	// replayInterval is the time in seconds that a nonce opening an underlay
+	// or a session is remembered. Timestamps of metadata older than that are
+	// rejected anyway.
+	replayInterval = 120
+)
+
+// underlay is a connection from a client which carries the segments of
+// sessions.
+type underlay interface {
+	// writeData sends data of the session to the client.
+	writeData(s *serverSession, b []byte) error
+
+	// closeSession requests the client to close the session, and removes
+	// the session from the underlay.
+	closeSession(s *serverSession) error
+}
+
+// unmarshalMetadata parses decrypted metadata of a segment sent by a client.
+func unmarshalMetadata(b []byte) (metadata, error) {
+	if len(b) != MetadataLength {
+		return nil, newError("unexpected metadata size ", len(b))
+	}
+	p := protocolType(b[0])
+	switch {
+	case isSessionProtocol(p):
+		ss := new(sessionStruct)
+		if err := ss.Unmarshal(b); err != nil {
+			return nil, newError("failed to unmarshal session metadata").Base(err)
+		}
+		return ss, nil
+	case isDataAckProtocol(p):
+		das := new(dataAckStruct)
+		if err := das.Unmarshal(b); err != nil {
+			return nil, newError("failed to unmarshal data metadata").Base(err)
+		}
+		return das, nil
+	default:
+		return nil, newError("unknown protocol ", b[0])
+	}
+}
+
+// segmentLayout returns the length of prefix padding, payload and suffix
+// padding of a segment.
+func segmentLayout(m metadata) (prefixLen, payloadLen, suffixLen int) {
+	switch m := m.(type) {
+	case *sessionStruct:
+		return 0, int(m.payloadLen), int(m.suffixLen)
+	case *dataAckStruct:
+		return int(m.prefixLen), int(m.payloadLen), int(m.suffixLen)
+	}
+	return 0, 0, 0
+}
+
+// segmentSeq returns the sequence number of a segment.
+func segmentSeq(m metadata) uint32 {
+	switch m := m.(type) {
+	case *sessionStruct:
+		return m.seq
+	case *dataAckStruct:
+		return m.seq
+	}
+	return 0
+}
+
+func newSessionSegment(protocol protocolType, sessionID, seq uint32) *Segment {
+	return &Segment{
+		metadata: &sessionStruct{
+			baseStruct: baseStruct{protocol: uint8(protocol)},
+			sessionID:  sessionID,
+			seq:        seq,
+			statusCode: uint8(statusOK),
+		},
+	}
+}
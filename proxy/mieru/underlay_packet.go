@@ -0,0 +1,597 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common/antireplay"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+)
+
+const (
+	// packetNonHeaderPosition is the position of payload or prefix padding
+	// in a packet.
+	packetNonHeaderPosition = DefaultNonceSize + MetadataLength + DefaultOverhead
+
+	packetTickInterval = 20 * time.Millisecond
+	minRTO             = 200 * time.Millisecond
+	maxRTO             = 10 * time.Second
+	initialRTO         = time.Second
+
+	// txCountLimit is the number of transmissions before a session is
+	// considered broken, same as mieru-main.
+	txCountLimit = 20
+
+	// minSendWindow is the number of segments that can be sent without
+	// acknowledge regardless of the window announced by the client.
+	minSendWindow = 16
+)
+
+// pendingSegment is a segment sent but not acknowledged yet.
+type pendingSegment struct {
+	seg     *Segment
+	seq     uint32
+	txTime  time.Time
+	txCount int
+}
+
+// packetSession is a session carried by UDP packets, which needs
+// acknowledges and retransmissions.
+type packetSession struct {
+	*serverSession
+	cipher Cipher
+
+	// Fields below are protected by serverSession.access.
+	sendBuf      []*pendingSegment
+	recvBuf      map[uint32]*Segment
+	remoteWindow int
+	srtt         time.Duration
+	rttvar       time.Duration
+	rto          time.Duration
+	lastRX       time.Time
+	lastTX       time.Time
+
+	acked *signal.Notifier
+}
+
+// packetUnderlay is the UDP packets from an address of a client. Sessions
+// carried by the packets may belong to different users.
+type packetUnderlay struct {
+	conn      io.Writer
+	mtu       int
+	validator *Validator
+	filter    *antireplay.ReplayFilter
+	onSession func(*serverSession)
+
+	writeAccess sync.Mutex
+	access      sync.Mutex
+	sessions    map[uint32]*packetSession
+	done        *done.Instance
+}
+
+func newPacketUnderlay(conn io.Writer, mtu int, validator *Validator, filter *antireplay.ReplayFilter, onSession func(*serverSession)) *packetUnderlay {
+	return &packetUnderlay{
+		conn:      conn,
+		mtu:       mtu,
+		validator: validator,
+		filter:    filter,
+		onSession: onSession,
+		sessions:  make(map[uint32]*packetSession),
+		done:      done.New(),
+	}
+}
+
+// serve reads packets from the client until the reader fails.
+func (u *packetUnderlay) serve(reader buf.Reader) error {
+	go u.runTicker()
+	defer u.closeSessions()
+	defer u.done.Close()
+
+	for {
+		mb, err := reader.ReadMultiBuffer()
+		if err != nil {
+			return err
+		}
+		for _, b := range mb {
+			if err := u.handlePacket(b.Bytes()); err != nil {
+				newError("dropped packet").Base(err).AtDebug().WriteToLog()
+			}
+		}
+		buf.ReleaseMulti(mb)
+	}
+}
+
+// decryptMetadata decrypts the metadata of a packet with the ciphers of
+// existing sessions, or the ciphers of all users.
+func (u *packetUnderlay) decryptMetadata(encryptedMetadata []byte) ([]byte, Cipher, *protocol.MemoryUser, error) {
+	u.access.Lock()
+	for _, ps := range u.sessions {
+		if plaintext, err := ps.cipher.Decrypt(encryptedMetadata); err == nil {
+			u.access.Unlock()
+			return plaintext, ps.cipher, ps.user, nil
+		}
+	}
+	u.access.Unlock()
+
+	for _, uc := range u.validator.userCiphers() {
+		plaintext, err := uc.cipher.Decrypt(encryptedMetadata)
+		if err != nil {
+			continue
+		}
+		if !u.filter.Check(encryptedMetadata[:DefaultNonceSize]) {
+			return nil, nil, nil, newError("replayed nonce")
+		}
+		return plaintext, uc.cipher, uc.user, nil
+	}
+	return nil, nil, nil, newError("no matching user")
+}
+
+func (u *packetUnderlay) handlePacket(b []byte) error {
+	if len(b) < packetNonHeaderPosition {
+		return newError("packet is too short: ", len(b))
+	}
+	nonce := b[:DefaultNonceSize]
+	plaintext, c, user, err := u.decryptMetadata(b[:packetNonHeaderPosition])
+	if err != nil {
+		return err
+	}
+	m, err := unmarshalMetadata(plaintext)
+	if err != nil {
+		return err
+	}
+
+	remaining := b[packetNonHeaderPosition:]
+	prefixLen, payloadLen, suffixLen := segmentLayout(m)
+	encryptedPayloadLen := 0
+	if payloadLen > 0 {
+		encryptedPayloadLen = payloadLen + DefaultOverhead
+	}
+	if prefixLen+encryptedPayloadLen+suffixLen != len(remaining) {
+		return newError("unexpected packet size ", len(b))
+	}
+	var payload []byte
+	if payloadLen > 0 {
+		payload, err = c.DecryptWithNonce(remaining[prefixLen:prefixLen+encryptedPayloadLen], nonce)
+		if err != nil {
+			return newError("failed to decrypt payload").Base(err)
+		}
+	}
+
+	return u.handleSegment(&Segment{
+		metadata:  m,
+		payload:   payload,
+		transport: "udp",
+		block:     c,
+	}, user)
+}
+
+func (u *packetUnderlay) session(id uint32) *packetSession {
+	u.access.Lock()
+	defer u.access.Unlock()
+	return u.sessions[id]
+}
+
+func (u *packetUnderlay) removeSession(id uint32) *packetSession {
+	u.access.Lock()
+	defer u.access.Unlock()
+	ps := u.sessions[id]
+	delete(u.sessions, id)
+	return ps
+}
+
+func (u *packetUnderlay) handleSegment(seg *Segment, user *protocol.MemoryUser) error {
+	switch m := seg.metadata.(type) {
+	case *sessionStruct:
+		switch m.Protocol() {
+		case openSessionRequest:
+			return u.openSession(m, seg, user)
+		case closeSessionRequest:
+			ps := u.removeSession(m.sessionID)
+			if ps == nil {
+				return nil
+			}
+			ps.access.Lock()
+			response := newSessionSegment(closeSessionResponse, ps.id, ps.nextSend)
+			ps.nextSend++
+			err := u.writeSegment(ps, response)
+			ps.access.Unlock()
+			ps.closeByClient()
+			return err
+		case closeSessionResponse:
+			if ps := u.removeSession(m.sessionID); ps != nil {
+				ps.closeByClient()
+			}
+			return nil
+		}
+	case *dataAckStruct:
+		switch m.Protocol() {
+		case dataClientToServer, ackClientToServer:
+			ps := u.session(m.sessionID)
+			if ps == nil {
+				if m.Protocol() == ackClientToServer {
+					// Late acknowledgements of a closed session.
+					return nil
+				}
+				// Request the client to close the unknown session.
+				request := newSessionSegment(closeSessionRequest, m.sessionID, m.unAckSeq)
+				return u.writePacket(seg.block, request)
+			}
+			u.input(ps, seg)
+			return nil
+		}
+	}
+	return newError("unexpected protocol ", seg.protocolType())
+}
+
+func (u *packetUnderlay) openSession(m *sessionStruct, seg *Segment, user *protocol.MemoryUser) error {
+	if m.sessionID == 0 {
+		return newError("reserved session ID 0 is used")
+	}
+
+	u.access.Lock()
+	ps, found := u.sessions[m.sessionID]
+	if !found {
+		now := time.Now()
+		ps = &packetSession{
+			serverSession: newServerSession(m.sessionID, user, u),
+			cipher:        seg.block,
+			recvBuf:       make(map[uint32]*Segment),
+			remoteWindow:  minSendWindow,
+			rto:           initialRTO,
+			lastRX:        now,
+			lastTX:        now,
+			acked:         signal.NewNotifier(),
+		}
+		ps.nextRecv = m.seq
+		u.sessions[m.sessionID] = ps
+	}
+	u.access.Unlock()
+
+	// Retransmitted requests are acknowledged again.
+	u.input(ps, seg)
+	if !found {
+		go u.onSession(ps.serverSession)
+	}
+	return nil
+}
+
+// input handles a segment of the session. Segments are reordered, and
+// delivered to the handler in sequence.
+func (u *packetUnderlay) input(ps *packetSession, seg *Segment) {
+	ps.access.Lock()
+	ps.lastRX = time.Now()
+	if das, ok := seg.metadata.(*dataAckStruct); ok {
+		u.acknowledge(ps, das.unAckSeq)
+		ps.remoteWindow = int(das.windowSize)
+		if das.Protocol() == ackClientToServer {
+			ps.access.Unlock()
+			return
+		}
+	}
+
+	if seq := segmentSeq(seg.metadata); seq >= ps.nextRecv && seq-ps.nextRecv < sessionWindowSize {
+		ps.recvBuf[seq] = seg
+	}
+	var ready []*Segment
+	for {
+		next, found := ps.recvBuf[ps.nextRecv]
+		if !found {
+			break
+		}
+		delete(ps.recvBuf, ps.nextRecv)
+		ps.nextRecv++
+		ready = append(ready, next)
+	}
+	ps.access.Unlock()
+
+	for _, s := range ready {
+		if s.metadata.Protocol() == openSessionRequest {
+			u.sendReliable(ps, newSessionSegment(openSessionResponse, ps.id, 0))
+		}
+		// The session may be closed by the handler, drop the data then.
+		ps.deliver(s.payload)
+	}
+
+	ps.access.Lock()
+	u.sendAck(ps)
+	ps.access.Unlock()
+}
+
+// acknowledge removes the segments before unAckSeq from the send buffer.
+// ps.access must be held.
+func (u *packetUnderlay) acknowledge(ps *packetSession, unAckSeq uint32) {
+	n := 0
+	now := time.Now()
+	for _, p := range ps.sendBuf {
+		if p.seq >= unAckSeq {
+			break
+		}
+		if p.txCount == 1 {
+			ps.updateRTT(now.Sub(p.txTime))
+		}
+		n++
+	}
+	if n > 0 {
+		ps.sendBuf = ps.sendBuf[n:]
+		ps.acked.Signal()
+	}
+}
+
+// updateRTT updates the retransmission timeout like RFC 6298.
+func (ps *packetSession) updateRTT(rtt time.Duration) {
+	if ps.srtt == 0 {
+		ps.srtt = rtt
+		ps.rttvar = rtt / 2
+	} else {
+		delta := ps.srtt - rtt
+		if delta < 0 {
+			delta = -delta
+		}
+		ps.rttvar = (3*ps.rttvar + delta) / 4
+		ps.srtt = (7*ps.srtt + rtt) / 8
+	}
+	ps.rto = ps.srtt + 4*ps.rttvar
+	if ps.rto < minRTO {
+		ps.rto = minRTO
+	} else if ps.rto > maxRTO {
+		ps.rto = maxRTO
+	}
+}
+
+func (ps *packetSession) sendWindow() int {
+	return max(min(ps.remoteWindow, sessionWindowSize), minSendWindow)
+}
+
+// sendReliable sends a segment which is retransmitted until acknowledged.
+// It blocks if the send window is full.
+func (u *packetUnderlay) sendReliable(ps *packetSession, seg *Segment) error {
+	ps.access.Lock()
+	for len(ps.sendBuf) >= ps.sendWindow() {
+		ps.access.Unlock()
+		select {
+		case <-ps.acked.Wait():
+		case <-time.After(packetTickInterval):
+		case <-u.done.Wait():
+			return io.ErrClosedPipe
+		}
+		ps.access.Lock()
+		if u.session(ps.id) == nil {
+			ps.access.Unlock()
+			return io.ErrClosedPipe
+		}
+	}
+	defer ps.access.Unlock()
+
+	seq := ps.nextSend
+	ps.nextSend++
+	switch m := seg.metadata.(type) {
+	case *sessionStruct:
+		m.seq = seq
+	case *dataAckStruct:
+		m.seq = seq
+	}
+	ps.sendBuf = append(ps.sendBuf, &pendingSegment{
+		seg:     seg,
+		seq:     seq,
+		txTime:  time.Now(),
+		txCount: 1,
+	})
+	return u.writeSegment(ps, seg)
+}
+
+// sendAck sends an acknowledge of the received segments. ps.access must be
+// held.
+func (u *packetUnderlay) sendAck(ps *packetSession) error {
+	seq := uint32(0)
+	if ps.nextSend > 0 {
+		seq = ps.nextSend - 1
+	}
+	return u.writeSegment(ps, &Segment{
+		metadata: &dataAckStruct{
+			baseStruct: baseStruct{protocol: uint8(ackServerToClient)},
+			sessionID:  ps.id,
+			seq:        seq,
+		},
+	})
+}
+
+// writeSegment sends a segment of the session with the current receive
+// state. ps.access must be held.
+func (u *packetUnderlay) writeSegment(ps *packetSession, seg *Segment) error {
+	if das, ok := seg.metadata.(*dataAckStruct); ok {
+		das.unAckSeq = ps.nextRecv
+		das.windowSize = uint16(sessionWindowSize - len(ps.recvBuf))
+	}
+	ps.lastTX = time.Now()
+	return u.writePacket(ps.cipher, seg)
+}
+
+// writePacket encrypts a segment into a packet and sends it.
+func (u *packetUnderlay) writePacket(c Cipher, seg *Segment) error {
+	var prefix, suffix []byte
+	switch m := seg.metadata.(type) {
+	case *sessionStruct:
+		maxLen := packetMaxPaddingSize(u.mtu, len(seg.payload), 0)
+		suffix = newPadding(maxLen, recommendedConsecutiveASCIILen, rngFixedInt(2, c.BlockContext().UserName) == 0)
+		m.payloadLen = uint16(len(seg.payload))
+		m.suffixLen = uint8(len(suffix))
+	case *dataAckStruct:
+		prefix = newPadding(packetMaxPaddingSize(u.mtu, len(seg.payload), 0), 0, true)
+		suffix = newPadding(packetMaxPaddingSize(u.mtu, len(seg.payload), len(prefix)), 0, true)
+		m.payloadLen = uint16(len(seg.payload))
+		m.prefixLen = uint8(len(prefix))
+		m.suffixLen = uint8(len(suffix))
+	}
+
+	data, err := c.Encrypt(seg.metadata.Marshal())
+	if err != nil {
+		return newError("failed to encrypt metadata").Base(err)
+	}
+	nonce := data[:DefaultNonceSize]
+	data = append(data, prefix...)
+	if len(seg.payload) > 0 {
+		encryptedPayload, err := c.EncryptWithNonce(seg.payload, nonce)
+		if err != nil {
+			return newError("failed to encrypt payload").Base(err)
+		}
+		data = append(data, encryptedPayload...)
+	}
+	data = append(data, suffix...)
+
+	u.writeAccess.Lock()
+	defer u.writeAccess.Unlock()
+	_, err = u.conn.Write(data)
+	return err
+}
+
+// runTicker retransmits segments not acknowledged in time, sends heartbeats,
+// and removes broken or idle sessions.
+func (u *packetUnderlay) runTicker() {
+	ticker := time.NewTicker(packetTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-u.done.Wait():
+			return
+		case <-ticker.C:
+		}
+
+		u.access.Lock()
+		sessions := make([]*packetSession, 0, len(u.sessions))
+		for _, ps := range u.sessions {
+			sessions = append(sessions, ps)
+		}
+		u.access.Unlock()
+
+		for _, ps := range sessions {
+			if err := u.tick(ps); err != nil {
+				newError("session ", ps.id, " is broken").Base(err).AtDebug().WriteToLog()
+				u.removeSession(ps.id)
+				ps.closeByClient()
+			}
+		}
+	}
+}
+
+func (u *packetUnderlay) tick(ps *packetSession) error {
+	ps.access.Lock()
+	defer ps.access.Unlock()
+
+	now := time.Now()
+	if now.Sub(ps.lastRX) > idleSessionTimeout {
+		return newError("session is idle")
+	}
+	for _, p := range ps.sendBuf {
+		timeout := ps.rto << (p.txCount - 1)
+		if timeout > maxRTO || timeout <= 0 {
+			timeout = maxRTO
+		}
+		if now.Sub(p.txTime) < timeout {
+			continue
+		}
+		if p.txCount >= txCountLimit {
+			return newError("too many retransmissions of segment ", p.seq)
+		}
+		p.txCount++
+		p.txTime = now
+		if err := u.writeSegment(ps, p.seg); err != nil {
+			return err
+		}
+	}
+	if now.Sub(ps.lastTX) > sessionHeartbeatInterval {
+		return u.sendAck(ps)
+	}
+	return nil
+}
+
+func (u *packetUnderlay) closeSessions() {
+	u.access.Lock()
+	sessions := u.sessions
+	u.sessions = make(map[uint32]*packetSession)
+	u.access.Unlock()
+
+	for _, ps := range sessions {
+		ps.closeByClient()
+	}
+}
+
+// writeData implements underlay.
+func (u *packetUnderlay) writeData(s *serverSession, b []byte) error {
+	ps := u.session(s.id)
+	if ps == nil {
+		return io.ErrClosedPipe
+	}
+
+	fragmentSize := u.mtu - packetOverhead
+	fragment := (len(b) - 1) / fragmentSize
+	for len(b) > 0 {
+		size := min(len(b), fragmentSize)
+		seg := &Segment{
+			metadata: &dataAckStruct{
+				baseStruct: baseStruct{protocol: uint8(dataServerToClient)},
+				sessionID:  s.id,
+				fragment:   uint8(fragment),
+			},
+			payload:   append([]byte(nil), b[:size]...),
+			transport: "udp",
+		}
+		if err := u.sendReliable(ps, seg); err != nil {
+			return err
+		}
+		b = b[size:]
+		fragment--
+	}
+	return nil
+}
+
+// closeSession implements underlay. It waits for the data sent to be
+// acknowledged, as the session is not retransmitted once removed.
+func (u *packetUnderlay) closeSession(s *serverSession) error {
+	ps := u.session(s.id)
+	if ps == nil {
+		return nil
+	}
+	for {
+		ps.access.Lock()
+		pending := len(ps.sendBuf)
+		ps.access.Unlock()
+		if pending == 0 || u.session(s.id) == nil {
+			break
+		}
+		select {
+		case <-ps.acked.Wait():
+		case <-time.After(packetTickInterval):
+		case <-u.done.Wait():
+			return nil
+		}
+	}
+
+	if u.removeSession(s.id) == nil {
+		return nil
+	}
+	ps.access.Lock()
+	defer ps.access.Unlock()
+	request := newSessionSegment(closeSessionRequest, ps.id, ps.nextSend)
+	ps.nextSend++
+	return u.writeSegment(ps, request)
+}
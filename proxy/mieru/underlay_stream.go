@@ -0,0 +1,314 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"io"
+	"sync"
+
+	"github.com/frogwall/f2ray-core/v5/common/antireplay"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// streamUnderlay is a TCP connection from a client. All sessions carried by
+// the connection belong to the user identified by the first segment.
+type streamUnderlay struct {
+	conn      io.ReadWriter
+	validator *Validator
+	filter    *antireplay.ReplayFilter
+	onSession func(*serverSession)
+
+	user *protocol.MemoryUser
+	recv Cipher
+
+	// send is protected by sendAccess, as nonces are implicit and the
+	// segments must be written in the order of encryption.
+	sendAccess sync.Mutex
+	send       Cipher
+
+	access   sync.Mutex
+	sessions map[uint32]*serverSession
+}
+
+func newStreamUnderlay(conn io.ReadWriter, validator *Validator, filter *antireplay.ReplayFilter, onSession func(*serverSession)) *streamUnderlay {
+	return &streamUnderlay{
+		conn:      conn,
+		validator: validator,
+		filter:    filter,
+		onSession: onSession,
+		sessions:  make(map[uint32]*serverSession),
+	}
+}
+
+// serve reads segments from the client until the connection fails.
+func (u *streamUnderlay) serve() error {
+	defer u.closeSessions()
+
+	for {
+		seg, err := u.readSegment()
+		if err != nil {
+			return err
+		}
+		if err := u.handleSegment(seg); err != nil {
+			return err
+		}
+	}
+}
+
+// authenticate decrypts the first metadata with the ciphers of all users,
+// and initializes the ciphers of the connection with the one that works.
+func (u *streamUnderlay) authenticate(encryptedMetadata []byte) ([]byte, error) {
+	for _, uc := range u.validator.userCiphers() {
+		recv := uc.cipher.Clone()
+		recv.SetImplicitNonceMode(true)
+		plaintext, err := recv.Decrypt(encryptedMetadata)
+		if err != nil {
+			continue
+		}
+		if !u.filter.Check(encryptedMetadata[:DefaultNonceSize]) {
+			return nil, newError("replayed nonce")
+		}
+
+		// The server starts its own nonce sequence.
+		send := recv.Clone()
+		send.SetImplicitNonceMode(false)
+		send.SetImplicitNonceMode(true)
+
+		u.user = uc.user
+		u.recv = recv
+		u.send = send
+		return plaintext, nil
+	}
+	return nil, newError("no matching user")
+}
+
+func (u *streamUnderlay) readSegment() (*Segment, error) {
+	encryptedMetadata := make([]byte, MetadataLength+DefaultOverhead, DefaultNonceSize+MetadataLength+DefaultOverhead)
+	if u.recv == nil {
+		// The first segment starts with the nonce.
+		encryptedMetadata = encryptedMetadata[:cap(encryptedMetadata)]
+	}
+	if _, err := io.ReadFull(u.conn, encryptedMetadata); err != nil {
+		return nil, newError("failed to read metadata").Base(err)
+	}
+
+	var plaintext []byte
+	var err error
+	if u.recv == nil {
+		plaintext, err = u.authenticate(encryptedMetadata)
+		if err != nil {
+			return nil, newError("failed to authenticate").Base(err)
+		}
+	} else {
+		plaintext, err = u.recv.Decrypt(encryptedMetadata)
+		if err != nil {
+			return nil, newError("failed to decrypt metadata").Base(err)
+		}
+	}
+	m, err := unmarshalMetadata(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixLen, payloadLen, suffixLen := segmentLayout(m)
+	if _, err := io.CopyN(io.Discard, u.conn, int64(prefixLen)); err != nil {
+		return nil, newError("failed to read padding").Base(err)
+	}
+	var payload []byte
+	if payloadLen > 0 {
+		encryptedPayload := make([]byte, payloadLen+DefaultOverhead)
+		if _, err := io.ReadFull(u.conn, encryptedPayload); err != nil {
+			return nil, newError("failed to read payload").Base(err)
+		}
+		payload, err = u.recv.Decrypt(encryptedPayload)
+		if err != nil {
+			return nil, newError("failed to decrypt payload").Base(err)
+		}
+	}
+	if _, err := io.CopyN(io.Discard, u.conn, int64(suffixLen)); err != nil {
+		return nil, newError("failed to read padding").Base(err)
+	}
+
+	return &Segment{
+		metadata:  m,
+		payload:   payload,
+		transport: "tcp",
+	}, nil
+}
+
+func (u *streamUnderlay) writeSegment(seg *Segment) error {
+	u.sendAccess.Lock()
+	defer u.sendAccess.Unlock()
+
+	var prefix, suffix []byte
+	switch m := seg.metadata.(type) {
+	case *sessionStruct:
+		suffix = newPadding(streamMaxPaddingSize, recommendedConsecutiveASCIILen, rngFixedInt(2, u.send.BlockContext().UserName) == 0)
+		m.payloadLen = uint16(len(seg.payload))
+		m.suffixLen = uint8(len(suffix))
+	case *dataAckStruct:
+		prefix = newPadding(streamMaxPaddingSize, 0, true)
+		suffix = newPadding(streamMaxPaddingSize, 0, true)
+		m.payloadLen = uint16(len(seg.payload))
+		m.prefixLen = uint8(len(prefix))
+		m.suffixLen = uint8(len(suffix))
+	}
+
+	data, err := u.send.Encrypt(seg.metadata.Marshal())
+	if err != nil {
+		return newError("failed to encrypt metadata").Base(err)
+	}
+	data = append(data, prefix...)
+	if len(seg.payload) > 0 {
+		encryptedPayload, err := u.send.Encrypt(seg.payload)
+		if err != nil {
+			return newError("failed to encrypt payload").Base(err)
+		}
+		data = append(data, encryptedPayload...)
+	}
+	data = append(data, suffix...)
+	_, err = u.conn.Write(data)
+	return err
+}
+
+func (u *streamUnderlay) session(id uint32) *serverSession {
+	u.access.Lock()
+	defer u.access.Unlock()
+	return u.sessions[id]
+}
+
+func (u *streamUnderlay) removeSession(id uint32) *serverSession {
+	u.access.Lock()
+	defer u.access.Unlock()
+	s := u.sessions[id]
+	delete(u.sessions, id)
+	return s
+}
+
+func (u *streamUnderlay) handleSegment(seg *Segment) error {
+	switch m := seg.metadata.(type) {
+	case *sessionStruct:
+		switch m.Protocol() {
+		case openSessionRequest:
+			return u.openSession(m, seg.payload)
+		case closeSessionRequest:
+			s := u.removeSession(m.sessionID)
+			if s == nil {
+				return nil
+			}
+			s.access.Lock()
+			response := newSessionSegment(closeSessionResponse, s.id, s.nextSend)
+			s.nextSend++
+			s.access.Unlock()
+			s.closeByClient()
+			return u.writeSegment(response)
+		case closeSessionResponse:
+			if s := u.removeSession(m.sessionID); s != nil {
+				s.closeByClient()
+			}
+			return nil
+		}
+	case *dataAckStruct:
+		switch m.Protocol() {
+		case dataClientToServer:
+			s := u.session(m.sessionID)
+			if s == nil {
+				// Request the client to close the unknown session.
+				return u.writeSegment(newSessionSegment(closeSessionRequest, m.sessionID, m.unAckSeq))
+			}
+			s.access.Lock()
+			s.nextRecv = m.seq + 1
+			s.access.Unlock()
+			// The session may be closed by the handler, drop the data then.
+			s.deliver(seg.payload)
+			return nil
+		case ackClientToServer:
+			// TCP is reliable, acknowledges are not needed.
+			return nil
+		}
+	}
+	return newError("unexpected protocol ", seg.protocolType())
+}
+
+func (u *streamUnderlay) openSession(m *sessionStruct, payload []byte) error {
+	if m.sessionID == 0 {
+		return newError("reserved session ID 0 is used")
+	}
+
+	u.access.Lock()
+	if _, found := u.sessions[m.sessionID]; found {
+		u.access.Unlock()
+		return nil
+	}
+	s := newServerSession(m.sessionID, u.user, u)
+	s.nextRecv = m.seq + 1
+	u.sessions[m.sessionID] = s
+	u.access.Unlock()
+
+	s.deliver(payload)
+	s.access.Lock()
+	response := newSessionSegment(openSessionResponse, s.id, s.nextSend)
+	s.nextSend++
+	s.access.Unlock()
+	if err := u.writeSegment(response); err != nil {
+		return err
+	}
+
+	go u.onSession(s)
+	return nil
+}
+
+func (u *streamUnderlay) closeSessions() {
+	u.access.Lock()
+	sessions := u.sessions
+	u.sessions = make(map[uint32]*serverSession)
+	u.access.Unlock()
+
+	for _, s := range sessions {
+		s.closeByClient()
+	}
+}
+
+// writeData implements underlay.
+func (u *streamUnderlay) writeData(s *serverSession, b []byte) error {
+	s.access.Lock()
+	seg := &Segment{
+		metadata: &dataAckStruct{
+			baseStruct: baseStruct{protocol: uint8(dataServerToClient)},
+			sessionID:  s.id,
+			seq:        s.nextSend,
+			unAckSeq:   s.nextRecv,
+			windowSize: sessionWindowSize,
+		},
+		payload:   b,
+		transport: "tcp",
+	}
+	s.nextSend++
+	s.access.Unlock()
+	return u.writeSegment(seg)
+}
+
+// closeSession implements underlay.
+func (u *streamUnderlay) closeSession(s *serverSession) error {
+	if u.removeSession(s.id) == nil {
+		return nil
+	}
+	s.access.Lock()
+	request := newSessionSegment(closeSessionRequest, s.id, s.nextSend)
+	s.nextSend++
+	s.access.Unlock()
+	return u.writeSegment(request)
+}
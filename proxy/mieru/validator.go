@@ -0,0 +1,122 @@
+// Copyright (C) 2024  v2ray-core authors
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package mieru
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// userCipher is a cipher derived from the credential of a user.
+type userCipher struct {
+	user   *protocol.MemoryUser
+	cipher Cipher
+}
+
+// Validator stores valid Mieru users.
+type Validator struct {
+	// Considering email's usage here, map + sync.Mutex/RWMutex may have better performance.
+	email sync.Map
+	users sync.Map
+
+	// The ciphers of all users are cached for the key refresh interval, as
+	// key derivation is expensive.
+	access     sync.Mutex
+	cipherTime time.Time
+	ciphers    []userCipher
+}
+
+// Add a Mieru user, Email must be empty or unique.
+func (v *Validator) Add(u *protocol.MemoryUser) error {
+	account, ok := u.Account.(*Account)
+	if !ok {
+		return newError("not a Mieru account")
+	}
+	if account.Username == "" {
+		return newError("Mieru username must not be empty.")
+	}
+	if u.Email != "" {
+		_, loaded := v.email.LoadOrStore(strings.ToLower(u.Email), u)
+		if loaded {
+			return newError("User ", u.Email, " already exists.")
+		}
+	}
+	if _, loaded := v.users.LoadOrStore(account.Username, u); loaded {
+		if u.Email != "" {
+			v.email.Delete(strings.ToLower(u.Email))
+		}
+		return newError("User with username ", account.Username, " already exists.")
+	}
+	v.resetCiphers()
+	return nil
+}
+
+// Del a Mieru user with a non-empty Email.
+func (v *Validator) Del(e string) error {
+	if e == "" {
+		return newError("Email must not be empty.")
+	}
+	le := strings.ToLower(e)
+	u, _ := v.email.Load(le)
+	if u == nil {
+		return newError("User ", e, " not found.")
+	}
+	v.email.Delete(le)
+	v.users.Delete(u.(*protocol.MemoryUser).Account.(*Account).Username)
+	v.resetCiphers()
+	return nil
+}
+
+func (v *Validator) resetCiphers() {
+	v.access.Lock()
+	defer v.access.Unlock()
+	v.ciphers = nil
+}
+
+// userCiphers returns the ciphers of all users for the time windows around now,
+// which may be used by a client. The returned ciphers are in stateless mode,
+// and must be cloned before enabling implicit nonce.
+func (v *Validator) userCiphers() []userCipher {
+	now := time.Now().Round(KeyRefreshInterval)
+
+	v.access.Lock()
+	defer v.access.Unlock()
+
+	if v.ciphers != nil && v.cipherTime.Equal(now) {
+		return v.ciphers
+	}
+
+	ciphers := make([]userCipher, 0)
+	v.users.Range(func(key, value interface{}) bool {
+		user := value.(*protocol.MemoryUser)
+		account := user.Account.(*Account)
+		for _, k := range generateKeysFromTime(account.Username, account.Password, now) {
+			c, err := NewXChaCha20Poly1305Cipher(k)
+			if err != nil {
+				continue
+			}
+			c.SetBlockContext(BlockContext{UserName: account.Username})
+			ciphers = append(ciphers, userCipher{user: user, cipher: c})
+		}
+		return true
+	})
+	v.cipherTime = now
+	v.ciphers = ciphers
+	return ciphers
+}
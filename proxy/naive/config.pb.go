@@ -1,6 +1,8 @@
 package naive
 
 import (
+	protocol "github.com/frogwall/f2ray-core/v5/common/protocol"
+	_ "github.com/frogwall/f2ray-core/v5/common/protoext"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -182,11 +184,66 @@ func (x *ClientConfig) GetServers() []*NaiveServerEndpoint {
 	return nil
 }
 
+type ServerConfig struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Users with Account as their account type
+	Users []*protocol.User `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// URL of the web site which serves the requests that are not authenticated
+	// proxy requests, e.g. "http://127.0.0.1:8080". A 404 page is served if empty.
+	FallbackUrl   string `protobuf:"bytes,2,opt,name=fallback_url,json=fallbackUrl,proto3" json:"fallback_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerConfig) Reset() {
+	*x = ServerConfig{}
+	mi := &file_proxy_naive_config_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerConfig) ProtoMessage() {}
+
+func (x *ServerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_naive_config_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerConfig.ProtoReflect.Descriptor instead.
+func (*ServerConfig) Descriptor() ([]byte, []int) {
+	return file_proxy_naive_config_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ServerConfig) GetUsers() []*protocol.User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetFallbackUrl() string {
+	if x != nil {
+		return x.FallbackUrl
+	}
+	return ""
+}
+
 var File_proxy_naive_config_proto protoreflect.FileDescriptor
 
 const file_proxy_naive_config_proto_rawDesc = "" +
 	"\n" +
-	"\x18proxy/naive/config.proto\x12\x16v2ray.core.proxy.naive\"A\n" +
+	"\x18proxy/naive/config.proto\x12\x16v2ray.core.proxy.naive\x1a\x1acommon/protocol/user.proto\x1a common/protoext/extensions.proto\"A\n" +
 	"\aAccount\x12\x1a\n" +
 	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\"{\n" +
@@ -196,7 +253,11 @@ const file_proxy_naive_config_proto_rawDesc = "" +
 	"\busername\x18\x03 \x01(\tR\busername\x12\x1a\n" +
 	"\bpassword\x18\x04 \x01(\tR\bpassword\"U\n" +
 	"\fClientConfig\x12E\n" +
-	"\aservers\x18\x01 \x03(\v2+.v2ray.core.proxy.naive.NaiveServerEndpointR\aserversBf\n" +
+	"\aservers\x18\x01 \x03(\v2+.v2ray.core.proxy.naive.NaiveServerEndpointR\aservers\"\x7f\n" +
+	"\fServerConfig\x126\n" +
+	"\x05users\x18\x01 \x03(\v2 .v2ray.core.common.protocol.UserR\x05users\x12!\n" +
+	"\ffallback_url\x18\x02 \x01(\tR\vfallbackUrl:\x14\x82\xb5\x18\x10\n" +
+	"\ainbound\x12\x05naiveBf\n" +
 	"\x1acom.v2ray.core.proxy.naiveP\x01Z-github.com/frogwall/f2ray-core/v5/proxy/naive\xaa\x02\x16V2Ray.Core.Proxy.Naiveb\x06proto3"
 
 var (
@@ -211,19 +272,22 @@ func file_proxy_naive_config_proto_rawDescGZIP() []byte {
 	return file_proxy_naive_config_proto_rawDescData
 }
 
-var file_proxy_naive_config_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_proxy_naive_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_proxy_naive_config_proto_goTypes = []any{
 	(*Account)(nil),             // 0: v2ray.core.proxy.naive.Account
 	(*NaiveServerEndpoint)(nil), // 1: v2ray.core.proxy.naive.NaiveServerEndpoint
 	(*ClientConfig)(nil),        // 2: v2ray.core.proxy.naive.ClientConfig
+	(*ServerConfig)(nil),        // 3: v2ray.core.proxy.naive.ServerConfig
+	(*protocol.User)(nil),       // 4: v2ray.core.common.protocol.User
 }
 var file_proxy_naive_config_proto_depIdxs = []int32{
 	1, // 0: v2ray.core.proxy.naive.ClientConfig.servers:type_name -> v2ray.core.proxy.naive.NaiveServerEndpoint
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4, // 1: v2ray.core.proxy.naive.ServerConfig.users:type_name -> v2ray.core.common.protocol.User
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_proxy_naive_config_proto_init() }
@@ -237,7 +301,7 @@ func file_proxy_naive_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_naive_config_proto_rawDesc), len(file_proxy_naive_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,3 @@
+package naive
+
+//go:generate go run github.com/frogwall/f2ray-core/v5/common/errors/errorgen
@@ -5,6 +5,8 @@ import (
 	"io"
 	"math/rand"
 	"net"
+	"net/http"
+	"strings"
 )
 
 const kFirstPaddings = 8
@@ -26,6 +28,38 @@ func generatePaddingHeader() string {
 	return string(padding)
 }
 
+// Padding types of the naive padding negotiation.
+const (
+	paddingTypeNone     = "0"
+	paddingTypeVariant1 = "1"
+)
+
+// negotiatePadding chooses the padding type for a CONNECT request and sets the
+// corresponding headers of the reply. It returns whether the tunnel is padded.
+//
+// Clients which request padding always send a "Padding" header. Newer clients
+// also list the padding types they support in "Padding-Type-Request", while
+// older ones expect variant1 once the reply carries a "Padding" header.
+func negotiatePadding(request http.Header, reply http.Header) bool {
+	if request.Get("Padding") == "" {
+		return false
+	}
+	reply.Set("Padding", generatePaddingHeader())
+
+	typeRequest := request.Get("Padding-Type-Request")
+	if typeRequest == "" {
+		return true
+	}
+	for _, paddingType := range strings.Split(typeRequest, ",") {
+		switch paddingType = strings.TrimSpace(paddingType); paddingType {
+		case paddingTypeVariant1, paddingTypeNone:
+			reply.Set("Padding-Type-Reply", paddingType)
+			return paddingType == paddingTypeVariant1
+		}
+	}
+	return false
+}
+
 // PaddingConn wraps a net.Conn and applies naive variant1 padding for the first 8
 // reads/writes to be compatible with naiveproxy server.
 type PaddingConn struct {
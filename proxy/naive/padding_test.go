@@ -0,0 +1,55 @@
+package naive
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+)
+
+func TestNegotiatePadding(t *testing.T) {
+	for _, tc := range []struct {
+		request   http.Header
+		padded    bool
+		typeReply string
+	}{
+		{http.Header{}, false, ""},
+		{http.Header{"Padding": {"~~~"}}, true, ""},
+		{http.Header{"Padding": {"~~~"}, "Padding-Type-Request": {"1, 0"}}, true, "1"},
+		{http.Header{"Padding": {"~~~"}, "Padding-Type-Request": {"2, 0"}}, false, "0"},
+		{http.Header{"Padding": {"~~~"}, "Padding-Type-Request": {"2"}}, false, ""},
+	} {
+		reply := http.Header{}
+		assert.Equal(t, tc.padded, negotiatePadding(tc.request, reply))
+		assert.Equal(t, tc.request.Get("Padding") != "", reply.Get("Padding") != "")
+		assert.Equal(t, tc.typeReply, reply.Get("Padding-Type-Reply"))
+	}
+}
+
+func TestPaddingConnRoundTrip(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	client := &PaddingConn{Conn: clientConn}
+	server := &PaddingConn{Conn: serverConn}
+
+	messages := make([][]byte, kFirstPaddings+2)
+	for i := range messages {
+		messages[i] = []byte{byte(i), 'n', 'a', 'i', 'v', 'e'}
+	}
+
+	go func() {
+		for _, message := range messages {
+			common.Must2(client.Write(message))
+		}
+		client.Close()
+	}()
+
+	for _, message := range messages {
+		b := make([]byte, len(message))
+		common.Must2(io.ReadFull(server, b))
+		assert.Equal(t, message, b)
+	}
+}
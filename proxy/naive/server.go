@@ -0,0 +1,447 @@
+package naive
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	stdnet "net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apernet/quic-go/http3"
+	"golang.org/x/net/http2"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+	"github.com/frogwall/f2ray-core/v5/common/task"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/security"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler that handles naive CONNECT requests
+// over HTTP/2 and HTTP/3. Requests which are not authenticated CONNECT
+// requests are served by the fallback web site.
+type Server struct {
+	policyManager policy.Manager
+	validator     *Validator
+	fallback      http.Handler
+}
+
+// NewServer creates a new Naive inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	validator := new(Validator)
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, newError("failed to get Naive user").Base(err).AtError()
+		}
+		if err := validator.Add(u); err != nil {
+			return nil, newError("failed to add user").Base(err).AtError()
+		}
+	}
+
+	fallback := http.NotFoundHandler()
+	if config.FallbackUrl != "" {
+		fallbackURL, err := url.Parse(config.FallbackUrl)
+		if err != nil {
+			return nil, newError("invalid fallback URL ", config.FallbackUrl).Base(err).AtError()
+		}
+		if fallbackURL.Scheme != "http" && fallbackURL.Scheme != "https" {
+			return nil, newError("unsupported scheme of fallback URL: ", fallbackURL.Scheme).AtError()
+		}
+		fallback = &httputil.ReverseProxy{
+			Rewrite: func(r *httputil.ProxyRequest) {
+				r.SetURL(fallbackURL)
+			},
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				newError("failed to serve fallback request").Base(err).AtWarning().WriteToLog(session.ExportIDToError(r.Context()))
+				w.WriteHeader(http.StatusBadGateway)
+			},
+		}
+	}
+
+	v := core.MustFromContext(ctx)
+	server := &Server{
+		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:     validator,
+		fallback:      fallback,
+	}
+	return server, nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	return s.validator.Add(u)
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP, net.Network_UNIX}
+}
+
+// Process implements proxy.Inbound.Process(). HTTP/3 is served on the QUIC
+// connections accepted by the tuic transport, HTTP/2 or HTTP/1.1 is served on
+// the others according to the negotiated application protocol.
+func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	iConn := conn
+	if statConn, ok := conn.(*internet.StatCouterConnection); ok {
+		iConn = statConn.Connection
+	}
+
+	handler := &connHandler{
+		server:     s,
+		ctx:        ctx,
+		conn:       conn,
+		dispatcher: dispatcher,
+	}
+
+	if quicConn, ok := iConn.(*tuicTransport.Conn); ok {
+		h3Server := &http3.Server{Handler: handler}
+		if err := h3Server.ServeQUICConn(quicConn.EarlyConnection); err != nil {
+			return newError("failed to serve HTTP/3 connection").Base(err)
+		}
+		return nil
+	}
+
+	nextProto := ""
+	if connALPNGetter, ok := iConn.(security.ConnectionApplicationProtocol); ok {
+		proto, err := connALPNGetter.GetConnectionApplicationProtocol()
+		if err != nil {
+			return newError("failed to get ALPN").Base(err).AtWarning()
+		}
+		nextProto = proto
+	}
+
+	sessionPolicy := s.policyManager.ForLevel(0)
+	if nextProto == "h2" {
+		h2Server := &http2.Server{
+			IdleTimeout: sessionPolicy.Timeouts.ConnectionIdle,
+		}
+		h2Server.ServeConn(conn, &http2.ServeConnOpts{
+			Context: ctx,
+			Handler: handler,
+		})
+		return nil
+	}
+
+	connClosed := done.New()
+	h1Server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: sessionPolicy.Timeouts.Handshake,
+		IdleTimeout:       sessionPolicy.Timeouts.ConnectionIdle,
+		BaseContext: func(stdnet.Listener) context.Context {
+			return ctx
+		},
+	}
+	go h1Server.Serve(&connListener{
+		conn:   &closeNotifyConn{Conn: conn, closed: connClosed},
+		closed: connClosed,
+	})
+	<-connClosed.Wait()
+	return nil
+}
+
+// connHandler handles the HTTP requests of a single inbound connection.
+type connHandler struct {
+	server     *Server
+	ctx        context.Context
+	conn       internet.Connection
+	dispatcher routing.Dispatcher
+}
+
+// ServeHTTP implements http.Handler.
+func (h *connHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user := h.authenticate(r)
+	if user == nil || r.Method != http.MethodConnect {
+		newError("serving fallback for ", r.Method, " ", r.Host, " from ", r.RemoteAddr).AtDebug().WriteToLog(session.ExportIDToError(h.ctx))
+		h.server.fallback.ServeHTTP(w, r)
+		return
+	}
+
+	ctx := streamContext(h.ctx)
+	inbound := session.InboundFromContext(ctx)
+	inbound.User = user
+
+	destination, err := net.ParseDestination("tcp:" + r.Host)
+	if err != nil || destination.Port == 0 {
+		log.Record(&log.AccessMessage{
+			From:   inbound.Source,
+			To:     r.Host,
+			Status: log.AccessRejected,
+			Reason: "invalid destination",
+			Email:  user.Email,
+		})
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var conn stdnet.Conn
+	padded := negotiatePadding(r.Header, w.Header())
+	if r.ProtoMajor == 1 {
+		conn, err = hijack(w)
+		if err != nil {
+			newError("failed to hijack connection").Base(err).WriteToLog(session.ExportIDToError(ctx))
+			return
+		}
+	} else {
+		w.WriteHeader(http.StatusOK)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			newError("response writer of ", r.Proto, " is not a flusher").AtError().WriteToLog(session.ExportIDToError(ctx))
+			return
+		}
+		flusher.Flush()
+		conn = &streamConn{
+			reader:  r.Body,
+			writer:  w,
+			flusher: flusher,
+			local:   h.conn.LocalAddr(),
+			remote:  h.conn.RemoteAddr(),
+		}
+	}
+	defer conn.Close()
+	if padded {
+		conn = &PaddingConn{Conn: conn}
+	}
+
+	if err := h.handleConnect(ctx, conn, destination); err != nil {
+		newError("stream ends").Base(err).WriteToLog(session.ExportIDToError(ctx))
+	}
+}
+
+// authenticate returns the user in the basic Proxy-Authorization header of
+// the request, nil if the request is not authenticated.
+func (h *connHandler) authenticate(r *http.Request) *protocol.MemoryUser {
+	credentials, ok := strings.CutPrefix(r.Header.Get("Proxy-Authorization"), "Basic ")
+	if !ok {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return nil
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return nil
+	}
+	return h.server.validator.Get(username, password)
+}
+
+// streamContext returns a context for a request carried by the connection,
+// each request has its own session ID and content.
+func streamContext(ctx context.Context) context.Context {
+	parent := session.InboundFromContext(ctx)
+	if parent == nil {
+		panic("no inbound metadata")
+	}
+	ctx = session.ContextWithID(ctx, session.NewID())
+	inbound := *parent
+	inbound.Conn = nil
+	inbound.CanSpliceCopy = 3
+	ctx = session.ContextWithInbound(ctx, &inbound)
+	if content := session.ContentFromContext(ctx); content != nil {
+		ctx = session.ContextWithContent(ctx, &session.Content{
+			SniffingRequest: content.SniffingRequest,
+		})
+	}
+	return ctx
+}
+
+func (h *connHandler) handleConnect(ctx context.Context, conn stdnet.Conn, destination net.Destination) error {
+	inbound := session.InboundFromContext(ctx)
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     destination,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  inbound.User.Email,
+	})
+	newError("received request for ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+	sessionPolicy := h.server.policyManager.ForLevel(inbound.User.Level)
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
+
+	link, err := h.dispatcher.Dispatch(ctx, destination)
+	if err != nil {
+		return newError("failed to dispatch request to ", destination).Base(err)
+	}
+
+	requestDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+		if err := buf.Copy(buf.NewReader(conn), link.Writer, buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+		if err := buf.Copy(link.Reader, buf.NewWriter(conn), buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to write response").Base(err)
+		}
+		return nil
+	}
+
+	requestDonePost := task.OnSuccess(requestDone, task.Close(link.Writer))
+	if err := task.Run(ctx, requestDonePost, responseDone); err != nil {
+		common.Must(common.Interrupt(link.Reader))
+		common.Must(common.Interrupt(link.Writer))
+		return newError("connection ends").Base(err)
+	}
+
+	return nil
+}
+
+// hijack takes over the connection of an HTTP/1.1 CONNECT request and replies
+// with the headers set on w.
+func hijack(w http.ResponseWriter) (stdnet.Conn, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, newError("response writer is not a hijacker")
+	}
+	header := w.Header().Clone()
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// Deadlines set by the HTTP server must not apply to the tunnel.
+	conn.SetDeadline(time.Time{})
+
+	response := new(strings.Builder)
+	response.WriteString("HTTP/1.1 200 Connection established\r\n")
+	common.Must(header.Write(response))
+	response.WriteString("\r\n")
+	if _, err := conn.Write([]byte(response.String())); err != nil {
+		conn.Close()
+		return nil, newError("failed to write response").Base(err)
+	}
+	return &bufferedConn{Conn: conn, reader: rw.Reader}, nil
+}
+
+// bufferedConn reads the data which have been buffered by the HTTP server
+// before the connection is hijacked.
+type bufferedConn struct {
+	stdnet.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// streamConn is a net.Conn over the request and response bodies of an HTTP/2
+// or HTTP/3 CONNECT stream.
+type streamConn struct {
+	reader  io.ReadCloser
+	writer  io.Writer
+	flusher http.Flusher
+	local   stdnet.Addr
+	remote  stdnet.Addr
+}
+
+func (c *streamConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *streamConn) Write(b []byte) (int, error) {
+	n, err := c.writer.Write(b)
+	if err != nil {
+		return n, err
+	}
+	c.flusher.Flush()
+	return n, nil
+}
+
+func (c *streamConn) Close() error {
+	return c.reader.Close()
+}
+
+func (c *streamConn) LocalAddr() stdnet.Addr {
+	return c.local
+}
+
+func (c *streamConn) RemoteAddr() stdnet.Addr {
+	return c.remote
+}
+
+func (c *streamConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *streamConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *streamConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+// connListener is a net.Listener that accepts a single connection, and blocks
+// until the connection is closed afterwards.
+type connListener struct {
+	conn   stdnet.Conn
+	closed *done.Instance
+	once   sync.Once
+}
+
+func (l *connListener) Accept() (stdnet.Conn, error) {
+	var conn stdnet.Conn
+	l.once.Do(func() {
+		conn = l.conn
+	})
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.closed.Wait()
+	return nil, stdnet.ErrClosed
+}
+
+func (l *connListener) Close() error {
+	return l.closed.Close()
+}
+
+func (l *connListener) Addr() stdnet.Addr {
+	return l.conn.LocalAddr()
+}
+
+// closeNotifyConn signals when the connection is closed by the HTTP server,
+// or by the tunnel after it is hijacked.
+type closeNotifyConn struct {
+	stdnet.Conn
+	closed *done.Instance
+}
+
+func (c *closeNotifyConn) Close() error {
+	c.closed.Close()
+	return c.Conn.Close()
+}
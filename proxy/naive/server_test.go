@@ -0,0 +1,238 @@
+package naive_test
+
+import (
+	"bytes"
+	gotls "crypto/tls"
+	"encoding/base64"
+	"io"
+	stdnet "net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/apernet/quic-go/http3"
+	"golang.org/x/net/http2"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/dispatcher"
+	"github.com/frogwall/f2ray-core/v5/app/proxyman"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/inbound"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/outbound"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/protocol/tls/cert"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/proxy/freedom"
+	. "github.com/frogwall/f2ray-core/v5/proxy/naive"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/tcp"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/udp"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/tls"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+)
+
+const fallbackResponse = "fallback"
+
+// tunnelConn is a net.Conn over the bodies of a CONNECT request and its
+// response.
+type tunnelConn struct {
+	stdnet.Conn
+	reader io.ReadCloser
+	writer io.WriteCloser
+}
+
+func (c *tunnelConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *tunnelConn) Write(b []byte) (int, error) {
+	return c.writer.Write(b)
+}
+
+func (c *tunnelConn) Close() error {
+	c.writer.Close()
+	return c.reader.Close()
+}
+
+// connect sends a CONNECT request for target to the server, and returns the
+// response and the tunnel of a successful request.
+func connect(t *testing.T, transport http.RoundTripper, server string, target net.Destination, header http.Header) (*http.Response, stdnet.Conn) {
+	reader, writer := io.Pipe()
+	response, err := transport.RoundTrip(&http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Scheme: "https", Host: server},
+		Host:   target.NetAddr(),
+		Header: header,
+		Body:   reader,
+	})
+	common.Must(err)
+	if response.StatusCode != http.StatusOK {
+		writer.Close()
+		response.Body.Close()
+		return response, nil
+	}
+	conn := &tunnelConn{reader: response.Body, writer: writer}
+	t.Cleanup(func() { conn.Close() })
+	return response, conn
+}
+
+func basicAuth(username, password string) http.Header {
+	return http.Header{
+		"Proxy-Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))},
+	}
+}
+
+func testEcho(t *testing.T, conn stdnet.Conn) {
+	payload := bytes.Repeat([]byte("naive"), 4*1024)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(payload)
+		errCh <- err
+	}()
+	response := make([]byte, len(payload))
+	common.Must2(io.ReadFull(conn, response))
+	common.Must(<-errCh)
+	if !bytes.Equal(response, bytes.ToUpper(payload)) {
+		t.Fatal("unexpected response")
+	}
+}
+
+func TestNaiveServer(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: bytes.ToUpper,
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	fallbackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, fallbackResponse)
+	}))
+	defer fallbackServer.Close()
+
+	securitySettings := []*anypb.Any{
+		serial.ToTypedMessage(&tls.Config{
+			Certificate: []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil))},
+		}),
+	}
+	proxySettings := serial.ToTypedMessage(&ServerConfig{
+		Users: []*protocol.User{
+			{
+				Email: "love@v2fly.org",
+				Account: serial.ToTypedMessage(&Account{
+					Username: "user",
+					Password: "password",
+				}),
+			},
+		},
+		FallbackUrl: fallbackServer.URL,
+	})
+
+	h2Port := tcp.PickPort()
+	h3Port := udp.PickPort()
+	server, err := core.New(&core.Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.InboundConfig{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+		},
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(h2Port),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+					StreamSettings: &internet.StreamConfig{
+						SecurityType:     serial.GetMessageType(&tls.Config{}),
+						SecuritySettings: securitySettings,
+					},
+				}),
+				ProxySettings: proxySettings,
+			},
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(h3Port),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+					StreamSettings: &internet.StreamConfig{
+						ProtocolName:     "tuic",
+						SecurityType:     serial.GetMessageType(&tls.Config{}),
+						SecuritySettings: securitySettings,
+						TransportSettings: []*internet.TransportConfig{
+							{
+								ProtocolName: "tuic",
+								Settings:     serial.ToTypedMessage(&tuicTransport.Config{}),
+							},
+						},
+					},
+				}),
+				ProxySettings: proxySettings,
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{ProxySettings: serial.ToTypedMessage(&freedom.Config{})},
+		},
+	})
+	common.Must(err)
+	common.Must(server.Start())
+	defer server.Close()
+
+	h2Server := net.TCPDestination(net.LocalHostIP, h2Port).NetAddr()
+	h2Transport := &http2.Transport{
+		TLSClientConfig: &gotls.Config{InsecureSkipVerify: true},
+	}
+	defer h2Transport.CloseIdleConnections()
+	h3Server := net.UDPDestination(net.LocalHostIP, h3Port).NetAddr()
+	h3Transport := &http3.Transport{
+		TLSClientConfig: &gotls.Config{InsecureSkipVerify: true},
+	}
+	defer h3Transport.Close()
+
+	t.Run("http2", func(t *testing.T) {
+		response, conn := connect(t, h2Transport, h2Server, dest, basicAuth("user", "password"))
+		if conn == nil {
+			t.Fatal("unexpected status ", response.Status)
+		}
+		testEcho(t, conn)
+	})
+
+	t.Run("http3", func(t *testing.T) {
+		response, conn := connect(t, h3Transport, h3Server, dest, basicAuth("user", "password"))
+		if conn == nil {
+			t.Fatal("unexpected status ", response.Status)
+		}
+		testEcho(t, conn)
+	})
+
+	t.Run("padding", func(t *testing.T) {
+		header := basicAuth("user", "password")
+		header.Set("Padding", "~~~~~~~~")
+		header.Set("Padding-Type-Request", "1, 0")
+		response, conn := connect(t, h2Transport, h2Server, dest, header)
+		if conn == nil {
+			t.Fatal("unexpected status ", response.Status)
+		}
+		if response.Header.Get("Padding") == "" {
+			t.Error("expect the padding header in the response")
+		}
+		if paddingType := response.Header.Get("Padding-Type-Reply"); paddingType != "1" {
+			t.Fatal("unexpected padding type ", paddingType)
+		}
+		testEcho(t, &PaddingConn{Conn: conn})
+	})
+
+	t.Run("probe", func(t *testing.T) {
+		// A request without credentials is served by the fallback web site.
+		client := &http.Client{Transport: h2Transport, Timeout: 10 * time.Second}
+		response, err := client.Get("https://" + h2Server + "/")
+		common.Must(err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		common.Must(err)
+		if string(body) != fallbackResponse {
+			t.Error("unexpected response of the probe: ", string(body))
+		}
+	})
+}
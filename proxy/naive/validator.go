@@ -0,0 +1,69 @@
+package naive
+
+import (
+	"crypto/subtle"
+	"strings"
+	"sync"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// Validator stores valid Naive users.
+type Validator struct {
+	// Considering email's usage here, map + sync.Mutex/RWMutex may have better performance.
+	email sync.Map
+	users sync.Map
+}
+
+// Add a Naive user, Email must be empty or unique.
+func (v *Validator) Add(u *protocol.MemoryUser) error {
+	account, ok := u.Account.(*Account)
+	if !ok {
+		return newError("not a Naive account")
+	}
+	if account.Username == "" {
+		return newError("Naive username must not be empty.")
+	}
+	if u.Email != "" {
+		_, loaded := v.email.LoadOrStore(strings.ToLower(u.Email), u)
+		if loaded {
+			return newError("User ", u.Email, " already exists.")
+		}
+	}
+	if _, loaded := v.users.LoadOrStore(account.Username, u); loaded {
+		if u.Email != "" {
+			v.email.Delete(strings.ToLower(u.Email))
+		}
+		return newError("User with username ", account.Username, " already exists.")
+	}
+	return nil
+}
+
+// Del a Naive user with a non-empty Email.
+func (v *Validator) Del(e string) error {
+	if e == "" {
+		return newError("Email must not be empty.")
+	}
+	le := strings.ToLower(e)
+	u, _ := v.email.Load(le)
+	if u == nil {
+		return newError("User ", e, " not found.")
+	}
+	v.email.Delete(le)
+	v.users.Delete(u.(*protocol.MemoryUser).Account.(*Account).Username)
+	return nil
+}
+
+// Get a Naive user with its username and password, nil if the user doesn't
+// exist or the password doesn't match.
+func (v *Validator) Get(username, password string) *protocol.MemoryUser {
+	u, _ := v.users.Load(username)
+	if u == nil {
+		return nil
+	}
+	user := u.(*protocol.MemoryUser)
+	if subtle.ConstantTimeCompare([]byte(user.Account.(*Account).Password), []byte(password)) != 1 {
+		return nil
+	}
+	return user
+}
@@ -44,6 +44,12 @@ type Outbound interface {
 	Process(context.Context, *transport.Link, internet.Dialer) error
 }
 
+// Pinger is an Outbound that can send ICMP echo requests.
+type Pinger interface {
+	// Ping sends an ICMP echo request with the payload to dest, and returns the payload of its reply.
+	Ping(ctx context.Context, dest net.Address, payload []byte, sockopt *internet.SocketConfig) ([]byte, error)
+}
+
 // UserManager is the interface for Inbounds and Outbounds that can manage their users.
 type UserManager interface {
 	// AddUser adds a new user.
@@ -82,6 +88,8 @@ func UnwrapRawConn(conn net.Conn) (net.Conn, stats.Counter, stats.Counter) {
 			// Check for REALITY UConn
 			if realityUConn, ok := conn.(*reality.UConn); ok {
 				conn = realityUConn.NetConn()
+			} else if realityConn, ok := conn.(*reality.Conn); ok {
+				conn = realityConn.NetConn()
 			}
 		}
 		if pc, ok := conn.(*proxyproto.Conn); ok {
@@ -96,6 +96,12 @@ func (a *Account) getCipher() (Cipher, error) {
 			IVBytes:         32,
 			AEADAuthCreator: createAesGcm,
 		}, nil
+	case CipherType_SS2022_BLAKE3_CHACHA20_POLY1305:
+		return &AEADCipher{
+			KeyBytes:        32,
+			IVBytes:         32,
+			AEADAuthCreator: createChaCha20Poly1305,
+		}, nil
 	default:
 		return nil, newError("Unsupported cipher.")
 	}
@@ -238,6 +244,8 @@ func CipherFromString(c string) CipherType {
 		return CipherType_SS2022_BLAKE3_AES_128_GCM
 	case "2022-blake3-aes-256-gcm", "ss2022-blake3-aes-256-gcm":
 		return CipherType_SS2022_BLAKE3_AES_256_GCM
+	case "2022-blake3-chacha20-poly1305", "ss2022-blake3-chacha20-poly1305":
+		return CipherType_SS2022_BLAKE3_CHACHA20_POLY1305
 	default:
 		return CipherType_UNKNOWN
 	}
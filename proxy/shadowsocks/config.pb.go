@@ -27,8 +27,9 @@ const (
 	CipherType_CHACHA20_POLY1305 CipherType = 3
 	CipherType_NONE              CipherType = 4
 	// Shadowsocks2022 methods
-	CipherType_SS2022_BLAKE3_AES_128_GCM CipherType = 5
-	CipherType_SS2022_BLAKE3_AES_256_GCM CipherType = 6
+	CipherType_SS2022_BLAKE3_AES_128_GCM       CipherType = 5
+	CipherType_SS2022_BLAKE3_AES_256_GCM       CipherType = 6
+	CipherType_SS2022_BLAKE3_CHACHA20_POLY1305 CipherType = 7
 )
 
 // Enum value maps for CipherType.
@@ -41,15 +42,17 @@ var (
 		4: "NONE",
 		5: "SS2022_BLAKE3_AES_128_GCM",
 		6: "SS2022_BLAKE3_AES_256_GCM",
+		7: "SS2022_BLAKE3_CHACHA20_POLY1305",
 	}
 	CipherType_value = map[string]int32{
-		"UNKNOWN":                   0,
-		"AES_128_GCM":               1,
-		"AES_256_GCM":               2,
-		"CHACHA20_POLY1305":         3,
-		"NONE":                      4,
-		"SS2022_BLAKE3_AES_128_GCM": 5,
-		"SS2022_BLAKE3_AES_256_GCM": 6,
+		"UNKNOWN":                         0,
+		"AES_128_GCM":                     1,
+		"AES_256_GCM":                     2,
+		"CHACHA20_POLY1305":               3,
+		"NONE":                            4,
+		"SS2022_BLAKE3_AES_128_GCM":       5,
+		"SS2022_BLAKE3_AES_256_GCM":       6,
+		"SS2022_BLAKE3_CHACHA20_POLY1305": 7,
 	}
 )
 
@@ -283,7 +286,7 @@ const file_proxy_shadowsocks_config_proto_rawDesc = "" +
 	"\anetwork\x18\x03 \x03(\x0e2\x1e.v2ray.core.common.net.NetworkR\anetwork\x12R\n" +
 	"\x0fpacket_encoding\x18\x04 \x01(\x0e2).v2ray.core.net.packetaddr.PacketAddrTypeR\x0epacketEncoding\"R\n" +
 	"\fClientConfig\x12B\n" +
-	"\x06server\x18\x01 \x03(\v2*.v2ray.core.common.protocol.ServerEndpointR\x06server*\x9a\x01\n" +
+	"\x06server\x18\x01 \x03(\v2*.v2ray.core.common.protocol.ServerEndpointR\x06server*\xbf\x01\n" +
 	"\n" +
 	"CipherType\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\x0f\n" +
@@ -292,7 +295,8 @@ const file_proxy_shadowsocks_config_proto_rawDesc = "" +
 	"\x11CHACHA20_POLY1305\x10\x03\x12\b\n" +
 	"\x04NONE\x10\x04\x12\x1d\n" +
 	"\x19SS2022_BLAKE3_AES_128_GCM\x10\x05\x12\x1d\n" +
-	"\x19SS2022_BLAKE3_AES_256_GCM\x10\x06Bx\n" +
+	"\x19SS2022_BLAKE3_AES_256_GCM\x10\x06\x12#\n" +
+	"\x1fSS2022_BLAKE3_CHACHA20_POLY1305\x10\aBx\n" +
 	" com.v2ray.core.proxy.shadowsocksP\x01Z3github.com/frogwall/f2ray-core/v5/proxy/shadowsocks\xaa\x02\x1cV2Ray.Core.Proxy.Shadowsocksb\x06proto3"
 
 var (
@@ -95,7 +95,8 @@ func (c *UnifiedClient) Process(ctx context.Context, link *transport.Link, diale
 // isSS2022Cipher checks if the cipher type is a Shadowsocks2022 method
 func isSS2022Cipher(cipher CipherType) bool {
 	return cipher == CipherType_SS2022_BLAKE3_AES_128_GCM ||
-		cipher == CipherType_SS2022_BLAKE3_AES_256_GCM
+		cipher == CipherType_SS2022_BLAKE3_AES_256_GCM ||
+		cipher == CipherType_SS2022_BLAKE3_CHACHA20_POLY1305
 }
 
 // convertToSS2022Config converts legacy Shadowsocks config to Shadowsocks2022 config
@@ -129,6 +130,8 @@ func convertToSS2022Config(config *ClientConfig) *shadowsocks2022.ClientConfig {
 		method = "2022-blake3-aes-128-gcm"
 	case CipherType_SS2022_BLAKE3_AES_256_GCM:
 		method = "2022-blake3-aes-256-gcm"
+	case CipherType_SS2022_BLAKE3_CHACHA20_POLY1305:
+		method = "2022-blake3-chacha20-poly1305"
 	default:
 		method = "2022-blake3-aes-128-gcm" // fallback
 	}
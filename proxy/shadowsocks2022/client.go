@@ -62,14 +62,9 @@ func (c *Client) Process(ctx context.Context, link *transport.Link, dialer inter
 	network := destination.Network
 
 	keyDerivation := newBLAKE3KeyDerivation()
-	var method Method
-	switch c.config.Method {
-	case "2022-blake3-aes-128-gcm":
-		method = newAES128GCMMethod()
-	case "2022-blake3-aes-256-gcm":
-		method = newAES256GCMMethod()
-	default:
-		return newError("unknown method: ", c.config.Method)
+	method, err := newMethod(c.config.Method)
+	if err != nil {
+		return err
 	}
 
 	effectivePsk := c.config.Psk
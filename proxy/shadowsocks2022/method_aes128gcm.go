@@ -84,3 +84,7 @@ func (a AES128GCMMethod) GetUDPClientProcessor(ipsk [][]byte, psk []byte, deriva
 	}
 	return NewAESUDPClientPacketProcessor(reqSeparateHeaderCipher, respSeparateHeaderCipher, getPacketAEAD, getEIH), nil
 }
+
+func (a AES128GCMMethod) GetUDPServerProcessor(psk []byte, userLookup UDPServerUserLookup, derivation KeyDerivation) (UDPServerPacketProcessor, error) {
+	return newAESUDPServerPacketProcessor(a, psk, userLookup, derivation)
+}
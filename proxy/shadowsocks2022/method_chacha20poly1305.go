@@ -0,0 +1,49 @@
+package shadowsocks2022
+
+import (
+	"crypto/cipher"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func newChaCha20Poly1305Method() *ChaCha20Poly1305Method {
+	return &ChaCha20Poly1305Method{}
+}
+
+// ChaCha20Poly1305Method is 2022-blake3-chacha20-poly1305, it does not
+// support identity headers, so a server with this method has only one user.
+type ChaCha20Poly1305Method struct{}
+
+func (c ChaCha20Poly1305Method) GetSessionSubKeyAndSaltLength() int {
+	return 32
+}
+
+func (c ChaCha20Poly1305Method) GetStreamAEAD(sessionSubKey []byte) (cipher.AEAD, error) {
+	aead, err := chacha20poly1305.New(sessionSubKey)
+	if err != nil {
+		return nil, newError("failed to create ChaCha20-Poly1305 AEAD").Base(err)
+	}
+	return aead, nil
+}
+
+func (c ChaCha20Poly1305Method) GenerateEIH(currentIdentitySubKey []byte, nextPskHash []byte, out []byte) error {
+	return newError("identity headers are not supported by 2022-blake3-chacha20-poly1305")
+}
+
+func (c ChaCha20Poly1305Method) DecryptEIH(currentIdentitySubKey []byte, eih []byte, out []byte) error {
+	return newError("identity headers are not supported by 2022-blake3-chacha20-poly1305")
+}
+
+func (c ChaCha20Poly1305Method) GetUDPClientProcessor(ipsk [][]byte, psk []byte, derivation KeyDerivation) (UDPClientPacketProcessor, error) {
+	if len(ipsk) != 0 {
+		return nil, newError("identity headers are not supported by 2022-blake3-chacha20-poly1305")
+	}
+	return newChaCha20UDPClientPacketProcessor(psk)
+}
+
+func (c ChaCha20Poly1305Method) GetUDPServerProcessor(psk []byte, userLookup UDPServerUserLookup, derivation KeyDerivation) (UDPServerPacketProcessor, error) {
+	if userLookup != nil {
+		return nil, newError("identity headers are not supported by 2022-blake3-chacha20-poly1305")
+	}
+	return newChaCha20UDPServerPacketProcessor(psk)
+}
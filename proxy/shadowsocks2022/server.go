@@ -3,14 +3,12 @@ package shadowsocks2022
 import (
 	"bytes"
 	"context"
-	"crypto/cipher"
 	cryptoRand "crypto/rand"
-	"encoding/binary"
-	"io"
+	"sync"
 	"time"
 
+	"github.com/pion/transport/v2/replaydetector"
 	"github.com/v2fly/struc"
-	"lukechampine.com/blake3"
 
 	core "github.com/frogwall/f2ray-core/v5"
 	"github.com/frogwall/f2ray-core/v5/common"
@@ -32,101 +30,111 @@ import (
 
 type Server struct {
 	config        *ServerConfig
-	users         map[string]*protocol.MemoryUser // PSK hash -> User
+	method        Method
+	keyDerivation KeyDerivation
 	policyManager policy.Manager
+
+	// defaultUser is the user of a single-user server, whose PSK is the
+	// server PSK.
+	defaultUser *protocol.MemoryUser
+	// multiUser is true if users are identified with EIH.
+	multiUser bool
+	validator *Validator
 }
 
 // NewServer creates a new Shadowsocks-2022 server
 func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	method, err := newMethod(config.Method)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Psk) != method.GetSessionSubKeyAndSaltLength() {
+		return nil, newError("invalid PSK length for ", config.Method)
+	}
+
 	v := core.MustFromContext(ctx)
 	s := &Server{
 		config:        config,
-		users:         make(map[string]*protocol.MemoryUser),
+		method:        method,
+		keyDerivation: newBLAKE3KeyDerivation(),
 		policyManager: v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:     new(Validator),
 	}
 
-	// Build user map with PSK hash as key
 	for _, user := range config.Users {
 		mUser, err := user.ToMemoryUser()
 		if err != nil {
 			return nil, newError("failed to parse user account").Base(err)
 		}
-
-		// Get user PSK from account
-		if account, ok := mUser.Account.(*Account); ok {
-			// Calculate PSK hash (first 16 bytes of BLAKE3)
-			pskHash := blake3.Sum512(account.UserPsk)
-			hashKey := string(pskHash[:16])
-			s.users[hashKey] = mUser
+		account, ok := mUser.Account.(*Account)
+		if !ok {
+			return nil, newError("not a Shadowsocks-2022 account")
+		}
+		if bytes.Equal(account.UserPsk, config.Psk) {
+			s.defaultUser = mUser
+			continue
 		}
+		if err := s.addUser(mUser); err != nil {
+			return nil, newError("failed to add user").Base(err)
+		}
+		s.multiUser = true
+	}
+	if _, ok := method.(*ChaCha20Poly1305Method); ok && s.multiUser {
+		return nil, newError(config.Method, " does not support multiple users")
+	}
+	if s.multiUser && s.defaultUser != nil {
+		return nil, newError("the server PSK must not be used as a user PSK")
 	}
 
 	return s, nil
 }
 
-func (s *Server) Network() []net.Network {
-	list := s.config.Network
-	if len(list) == 0 {
-		list = append(list, net.Network_TCP)
+func (s *Server) addUser(u *protocol.MemoryUser) error {
+	account, ok := u.Account.(*Account)
+	if !ok {
+		return newError("not a Shadowsocks-2022 account")
 	}
-	return list
+	if len(account.UserPsk) != s.method.GetSessionSubKeyAndSaltLength() {
+		return newError("invalid user PSK length for ", s.config.Method)
+	}
+	return s.validator.Add(u)
 }
 
-// findUserByPSKHash finds a user by their PSK hash from EIH
-func (s *Server) findUserByPSKHash(pskHash []byte) (*protocol.MemoryUser, []byte, error) {
-	hashKey := string(pskHash)
-	if user, ok := s.users[hashKey]; ok {
-		// Extract user PSK from account
-		if account, ok := user.Account.(*Account); ok {
-			return user, account.UserPsk, nil
-		}
-		return nil, nil, newError("invalid account type")
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	if !s.multiUser {
+		return newError("users can only be added to a multi-user server")
 	}
-	return nil, nil, newError("user not found for PSK hash")
+	return s.addUser(u)
 }
 
-// decodeEIH decodes and validates EIH, returns effective PSK and user
-func (s *Server) decodeEIH(eihData [][16]byte, salt []byte, method Method, keyDerivation KeyDerivation) ([]byte, *protocol.MemoryUser, error) {
-	if len(eihData) == 0 {
-		// No EIH, single-user mode (backward compatibility)
-		if len(s.config.Users) > 0 {
-			// Use first user
-			mUser, err := s.config.Users[0].ToMemoryUser()
-			if err != nil {
-				return nil, nil, newError("failed to get default user").Base(err)
-			}
-			if account, ok := mUser.Account.(*Account); ok {
-				return account.UserPsk, mUser, nil
-			}
-		}
-		// Fallback to server PSK
-		return s.config.Psk, nil, nil
-	}
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
 
-	// Decrypt last EIH with server PSK to get user PSK hash
-	lastEIH := eihData[len(eihData)-1]
+func (s *Server) Network() []net.Network {
+	list := s.config.Network
+	if len(list) == 0 {
+		list = append(list, net.Network_TCP)
+	}
+	return list
+}
 
-	// Derive identity key from server PSK
-	identityKey := make([]byte, method.GetSessionSubKeyAndSaltLength())
-	err := keyDerivation.GetIdentitySubKey(s.config.Psk, salt, identityKey)
+// identify finds the user of a TCP connection with the EIH following the
+// request salt, and returns its PSK.
+func (s *Server) identify(salt []byte, eih []byte) (*protocol.MemoryUser, []byte, error) {
+	identityKey := make([]byte, s.method.GetSessionSubKeyAndSaltLength())
+	err := s.keyDerivation.GetIdentitySubKey(s.config.Psk, salt, identityKey)
 	if err != nil {
 		return nil, nil, newError("failed to derive identity key").Base(err)
 	}
-
-	// Decrypt EIH to get PSK hash
-	pskHash := make([]byte, 16)
-	err = method.DecryptEIH(identityKey, lastEIH[:], pskHash)
+	pskHash := make([]byte, aesEIHPskHashSize)
+	err = s.method.DecryptEIH(identityKey, eih, pskHash)
 	if err != nil {
 		return nil, nil, newError("failed to decrypt EIH").Base(err)
 	}
-
-	// Look up user by PSK hash
-	user, userPsk, err := s.findUserByPSKHash(pskHash)
-	if err != nil {
-		return nil, nil, newError("user lookup failed").Base(err)
-	}
-
-	return userPsk, user, nil
+	return s.validator.Get(pskHash)
 }
 
 func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
@@ -144,23 +152,14 @@ func (s *Server) handleTCPConnection(ctx context.Context, conn internet.Connecti
 	sessionPolicy := s.policyManager.ForLevel(0)
 	conn.SetReadDeadline(time.Now().Add(sessionPolicy.Timeouts.Handshake))
 
-	keyDerivation := newBLAKE3KeyDerivation()
-	var method Method
-	switch s.config.Method {
-	case "2022-blake3-aes-128-gcm":
-		method = newAES128GCMMethod()
-	case "2022-blake3-aes-256-gcm":
-		method = newAES256GCMMethod()
-	default:
-		return newError("unknown method: ", s.config.Method)
-	}
+	keyDerivation := s.keyDerivation
+	method := s.method
 
 	// Read pre-session key header (salt + EIH)
 	var preSessionKeyHeader TCPRequestHeader1PreSessionKey
 	preSessionKeyHeader.Salt = newRequestSaltWithLength(method.GetSessionSubKeyAndSaltLength())
-	// Determine EIH count: 1 for multi-user mode (server PSK -> user PSK)
 	eihCount := 0
-	if len(s.config.Users) > 0 {
+	if s.multiUser {
 		eihCount = 1 // One EIH layer for user identification
 	}
 	preSessionKeyHeader.EIH = newAESEIH(eihCount)
@@ -179,13 +178,12 @@ func (s *Server) handleTCPConnection(ctx context.Context, conn internet.Connecti
 
 	c2sSalt := preSessionKeyHeader.Salt.Bytes()
 
-	// Decode EIH to get effective PSK and user
-	var effectivePsk []byte
-	var currentUser *protocol.MemoryUser
-	var err error
-
-	if eihData, ok := preSessionKeyHeader.EIH.(*aesEIH); ok && len(eihData.eih) > 0 {
-		effectivePsk, currentUser, err = s.decodeEIH(eihData.eih, c2sSalt, method, keyDerivation)
+	effectivePsk := s.config.Psk
+	currentUser := s.defaultUser
+	if s.multiUser {
+		var err error
+		eih := preSessionKeyHeader.EIH.(*aesEIH).eih[0]
+		currentUser, effectivePsk, err = s.identify(c2sSalt, eih[:])
 		if err != nil {
 			log.Record(&log.AccessMessage{
 				From:   conn.RemoteAddr(),
@@ -193,13 +191,7 @@ func (s *Server) handleTCPConnection(ctx context.Context, conn internet.Connecti
 				Status: log.AccessRejected,
 				Reason: err,
 			})
-			return newError("failed to decode EIH").Base(err)
-		}
-	} else {
-		// No EIH, use default user or server PSK
-		effectivePsk, currentUser, err = s.decodeEIH(nil, c2sSalt, method, keyDerivation)
-		if err != nil {
-			return newError("failed to get effective PSK").Base(err)
+			return newError("failed to identify user").Base(err)
 		}
 	}
 
@@ -307,8 +299,10 @@ func (s *Server) handleTCPConnection(ctx context.Context, conn internet.Connecti
 	}
 
 	// Set user in inbound context for statistics
+	var email string
 	if currentUser != nil {
 		inbound.User = currentUser
+		email = currentUser.Email
 	}
 
 	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
@@ -316,12 +310,7 @@ func (s *Server) handleTCPConnection(ctx context.Context, conn internet.Connecti
 		To:     dest,
 		Status: log.AccessAccepted,
 		Reason: "",
-		Email: func() string {
-			if currentUser != nil {
-				return currentUser.Email
-			}
-			return ""
-		}(),
+		Email:  email,
 	})
 	newError("tunnelling request to ", dest).WriteToLog(session.ExportIDToError(ctx))
 
@@ -461,6 +450,15 @@ func (s *Server) handleTCPConnection(ctx context.Context, conn internet.Connecti
 	return nil
 }
 
+// udpServerSession is a client UDP session with its own dispatcher, so that
+// responses are encoded for the session they belong to.
+type udpServerSession struct {
+	*UDPServerSession
+	ctx            context.Context
+	dispatcher     udp.DispatcherI
+	replayDetector replaydetector.ReplayDetector
+}
+
 func (s *Server) handleUDPPayload(ctx context.Context, conn internet.Connection, dispatcher routing.Dispatcher) error {
 	udpDispatcherConstructor := udp.NewSplitDispatcher
 	switch s.config.PacketEncoding {
@@ -471,123 +469,73 @@ func (s *Server) handleUDPPayload(ctx context.Context, conn internet.Connection,
 		udpDispatcherConstructor = packetAddrDispatcherFactory.NewPacketAddrDispatcher
 	}
 
-	keyDerivation := newBLAKE3KeyDerivation()
-	var method Method
-	switch s.config.Method {
-	case "2022-blake3-aes-128-gcm":
-		method = newAES128GCMMethod()
-	case "2022-blake3-aes-256-gcm":
-		method = newAES256GCMMethod()
-	default:
-		return newError("unknown method: ", s.config.Method)
+	var userLookup UDPServerUserLookup
+	if s.multiUser {
+		userLookup = s.validator.Get
 	}
-
-	effectivePsk := s.config.Psk
-
-	// UDP session management
-	type udpSession struct {
-		sessionID       [8]byte
-		serverSessionID [8]byte
-		sessionAEAD     cipher.AEAD
-		blockCipher     cipher.Block
+	processor, err := s.method.GetUDPServerProcessor(s.config.Psk, userLookup, s.keyDerivation)
+	if err != nil {
+		return newError("failed to create UDP packet processor").Base(err)
 	}
-	sessions := make(map[string]*udpSession)
 
-	udpServer := udpDispatcherConstructor(dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
-		// Find session for this response
-		request := protocol.RequestHeaderFromContext(ctx)
-		if request == nil {
-			return
-		}
+	inbound := session.InboundFromContext(ctx)
+	if inbound == nil {
+		panic("no inbound metadata")
+	}
 
-		sessionKey := string(request.User.Email) // Using email as session key
-		sess, ok := sessions[sessionKey]
-		if !ok {
-			newError("UDP session not found for response").WriteToLog(session.ExportIDToError(ctx))
-			return
+	var writeAccess sync.Mutex
+	sessions := make(map[[8]byte]*udpServerSession)
+	defer func() {
+		for _, sess := range sessions {
+			sess.dispatcher.Close()
 		}
-
-		// Encode server-to-client UDP packet
-		resp := &UDPResponse{
-			UDPRequest: UDPRequest{
-				SessionID: sess.serverSessionID,
-				PacketID:  0, // TODO: implement packet counter
-				TimeStamp: uint64(time.Now().Unix()),
-				Address:   packet.Source.Address,
-				Port:      int(packet.Source.Port),
-				Payload:   packet.Payload,
-			},
-			ClientSessionID: sess.sessionID,
+	}()
+	getSession := func(sessionID [8]byte) *UDPServerSession {
+		if sess, found := sessions[sessionID]; found {
+			return sess.UDPServerSession
 		}
+		return nil
+	}
 
-		out := buf.New()
-		defer out.Release()
-
-		// Encode separate header
-		separateHeaderStruct := separateHeader{
-			SessionID: sess.serverSessionID,
-			PacketID:  resp.PacketID,
+	newSession := func(serverSession *UDPServerSession) *udpServerSession {
+		if serverSession.User == nil {
+			serverSession.User = s.defaultUser
 		}
-		separateHeaderBuffer := buf.New()
-		defer separateHeaderBuffer.Release()
-		{
-			err := struc.Pack(separateHeaderBuffer, &separateHeaderStruct)
-			if err != nil {
-				newError("failed to pack separate header").Base(err).WriteToLog(session.ExportIDToError(ctx))
-				return
-			}
+		sessionInbound := *inbound
+		sessionInbound.User = serverSession.User
+		sess := &udpServerSession{
+			UDPServerSession: serverSession,
+			ctx:              session.ContextWithInbound(ctx, &sessionInbound),
+			replayDetector:   replaydetector.New(1024, ^uint64(0)),
 		}
+		sess.dispatcher = udpDispatcherConstructor(dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
+			defer packet.Payload.Release()
 
-		// Encrypt separate header
-		{
-			encryptedDest := out.Extend(16)
-			sess.blockCipher.Encrypt(encryptedDest, separateHeaderBuffer.Bytes())
-		}
+			writeAccess.Lock()
+			defer writeAccess.Unlock()
 
-		// Encode main header
-		headerStruct := respHeader{
-			Type:            UDPHeaderTypeServerToClientStream,
-			TimeStamp:       resp.TimeStamp,
-			ClientSessionID: resp.ClientSessionID,
-			PaddingLength:   0,
-			Padding:         nil,
-		}
-		responseBodyBuffer := buf.New()
-		defer responseBodyBuffer.Release()
-		{
-			err := struc.Pack(responseBodyBuffer, &headerStruct)
-			if err != nil {
-				newError("failed to pack response header").Base(err).WriteToLog(session.ExportIDToError(ctx))
-				return
+			resp := &UDPResponse{
+				UDPRequest: UDPRequest{
+					SessionID: serverSession.ServerSessionID,
+					PacketID:  serverSession.NextPacketID(),
+					TimeStamp: uint64(time.Now().Unix()),
+					Address:   packet.Source.Address,
+					Port:      int(packet.Source.Port),
+					Payload:   packet.Payload,
+				},
+				ClientSessionID: serverSession.ClientSessionID,
 			}
-		}
-		{
-			err := addrParser.WriteAddressPort(responseBodyBuffer, resp.Address, net.Port(resp.Port))
-			if err != nil {
-				newError("failed to write address port").Base(err).WriteToLog(session.ExportIDToError(ctx))
+			out := buf.NewWithSize(packet.Payload.Len() + 512)
+			defer out.Release()
+			if err := processor.EncodeUDPResponse(resp, out, serverSession); err != nil {
+				newError("failed to encode UDP response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
 				return
 			}
-		}
-		{
-			_, err := io.Copy(responseBodyBuffer, bytes.NewReader(resp.Payload.Bytes()))
-			if err != nil {
-				newError("failed to copy payload").Base(err).WriteToLog(session.ExportIDToError(ctx))
-				return
+			if _, err := conn.Write(out.Bytes()); err != nil {
+				newError("failed to write UDP response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
 			}
-		}
-
-		// Encrypt body
-		{
-			encryptedDest := out.Extend(int32(sess.sessionAEAD.Overhead()) + responseBodyBuffer.Len())
-			sess.sessionAEAD.Seal(encryptedDest[:0], separateHeaderBuffer.Bytes()[4:16], responseBodyBuffer.Bytes(), nil)
-		}
-
-		conn.Write(out.Bytes())
-	})
-
-	inbound := session.InboundFromContext(ctx)
-	if inbound == nil {
-		panic("no inbound metadata")
+		})
+		return sess
 	}
 
 	reader := buf.NewPacketReader(conn)
@@ -598,160 +546,56 @@ func (s *Server) handleUDPPayload(ctx context.Context, conn internet.Connection,
 		}
 
 		for _, payload := range mpayload {
-			if payload.Len() < 16 {
-				payload.Release()
-				continue
-			}
-
-			// Decrypt separate header
-			separateHeaderBuffer := buf.New()
-			defer separateHeaderBuffer.Release()
-			{
-				// For UDP, we use AES block cipher for separate header
-				// This is a simplified version - proper implementation needs AES block cipher
-				encryptedDest := separateHeaderBuffer.Extend(16)
-				copy(encryptedDest, payload.BytesRange(0, 16))
-			}
-
-			var separateHeaderStruct separateHeader
-			{
-				err := struc.Unpack(bytes.NewReader(payload.BytesRange(0, 16)), &separateHeaderStruct)
-				if err != nil {
-					newError("failed to unpack separate header").Base(err).WriteToLog(session.ExportIDToError(ctx))
-					payload.Release()
-					continue
-				}
-			}
-
-			// Get or create session
-			sessionKey := string(separateHeaderStruct.SessionID[:])
-			sess, ok := sessions[sessionKey]
-			if !ok {
-				// Create new session
-				var serverSessionID [8]byte
-				_, err := cryptoRand.Read(serverSessionID[:])
-				if err != nil {
-					newError("failed to generate server session ID").Base(err).WriteToLog(session.ExportIDToError(ctx))
-					payload.Release()
-					continue
-				}
-
-				sessionSubKey := make([]byte, method.GetSessionSubKeyAndSaltLength())
-				err = keyDerivation.GetSessionSubKey(effectivePsk, separateHeaderStruct.SessionID[:], sessionSubKey)
-				if err != nil {
-					newError("failed to derive session subkey").Base(err).WriteToLog(session.ExportIDToError(ctx))
-					payload.Release()
-					continue
-				}
-
-				sessionAEAD, err := method.GetStreamAEAD(sessionSubKey)
-				if err != nil {
-					newError("failed to create session AEAD").Base(err).WriteToLog(session.ExportIDToError(ctx))
-					payload.Release()
-					continue
-				}
-
-				blockCipher, err := method.GetStreamAEAD(effectivePsk)
-				if err != nil {
-					newError("failed to create block cipher").Base(err).WriteToLog(session.ExportIDToError(ctx))
-					payload.Release()
-					continue
-				}
-
-				sess = &udpSession{
-					sessionID:       separateHeaderStruct.SessionID,
-					serverSessionID: serverSessionID,
-					sessionAEAD:     sessionAEAD,
-					blockCipher:     blockCipher.(cipher.Block), // This needs proper type handling
-				}
-				sessions[sessionKey] = sess
-			}
-
-			// Decrypt body
-			bodyEncrypted := payload.BytesFrom(16)
-			bodyDecrypted := make([]byte, len(bodyEncrypted)-sess.sessionAEAD.Overhead())
-			// Construct nonce from session ID (bytes 4-16) and packet ID
-			nonce := make([]byte, 12)
-			copy(nonce, separateHeaderStruct.SessionID[4:])
-			// PacketID is uint64, convert to bytes for nonce
-			packetIDBytes := make([]byte, 8)
-			binary.BigEndian.PutUint64(packetIDBytes, separateHeaderStruct.PacketID)
-			copy(nonce[4:], packetIDBytes[:8])
-			_, err = sess.sessionAEAD.Open(bodyDecrypted[:0], nonce, bodyEncrypted, nil)
+			var req UDPRequest
+			serverSession, err := processor.DecodeUDPRequest(payload.Bytes(), &req, getSession)
+			payload.Release()
 			if err != nil {
-				newError("failed to decrypt UDP body").Base(err).WriteToLog(session.ExportIDToError(ctx))
-				payload.Release()
+				log.Record(&log.AccessMessage{
+					From:   inbound.Source,
+					To:     "",
+					Status: log.AccessRejected,
+					Reason: err,
+				})
+				newError("dropping invalid UDP packet").Base(err).AtInfo().WriteToLog(session.ExportIDToError(ctx))
 				continue
 			}
 
-			// Parse main header
-			var headerStruct header
-			bodyReader := bytes.NewReader(bodyDecrypted)
-			{
-				err := struc.Unpack(bodyReader, &headerStruct)
-				if err != nil {
-					newError("failed to unpack UDP header").Base(err).WriteToLog(session.ExportIDToError(ctx))
-					payload.Release()
-					continue
-				}
-			}
-
-			// Validate timestamp
-			timeDifference := int64(headerStruct.TimeStamp) - time.Now().Unix()
+			timeDifference := int64(req.TimeStamp) - time.Now().Unix()
 			if timeDifference < -30 || timeDifference > 30 {
-				newError("UDP timestamp too far away, timeDifference = ", timeDifference).WriteToLog(session.ExportIDToError(ctx))
-				payload.Release()
+				req.Payload.Release()
+				newError("UDP timestamp too far away, timeDifference = ", timeDifference).AtInfo().WriteToLog(session.ExportIDToError(ctx))
 				continue
 			}
 
-			// Parse destination address
-			addressBuffer := buf.New()
-			defer addressBuffer.Release()
-			address, port, err := addrParser.ReadAddressPort(addressBuffer, bodyReader)
-			if err != nil {
-				newError("failed to read UDP address port").Base(err).WriteToLog(session.ExportIDToError(ctx))
-				payload.Release()
-				continue
+			sess, found := sessions[req.SessionID]
+			if !found {
+				sess = newSession(serverSession)
+				sessions[req.SessionID] = sess
 			}
-
-			dest := net.Destination{
-				Network: net.Network_UDP,
-				Address: address,
-				Port:    port,
-			}
-
-			// Read remaining payload
-			remainingPayload := buf.New()
-			_, err = io.Copy(remainingPayload, bodyReader)
-			if err != nil {
-				newError("failed to read UDP payload").Base(err).WriteToLog(session.ExportIDToError(ctx))
-				payload.Release()
+			accept, ok := sess.replayDetector.Check(req.PacketID)
+			if ok {
+				accept()
+			} else {
+				req.Payload.Release()
+				newError("dropping replayed UDP packet ", req.PacketID).AtInfo().WriteToLog(session.ExportIDToError(ctx))
 				continue
 			}
 
-			currentPacketCtx := ctx
-			if inbound.Source.IsValid() {
-				currentPacketCtx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
-					From:   inbound.Source,
-					To:     dest,
-					Status: log.AccessAccepted,
-					Reason: "",
-				})
+			dest := net.UDPDestination(req.Address, net.Port(req.Port))
+			var email string
+			if sess.User != nil {
+				email = sess.User.Email
 			}
+			currentPacketCtx := log.ContextWithAccessMessage(sess.ctx, &log.AccessMessage{
+				From:   inbound.Source,
+				To:     dest,
+				Status: log.AccessAccepted,
+				Reason: "",
+				Email:  email,
+			})
 			newError("tunnelling UDP request to ", dest).WriteToLog(session.ExportIDToError(currentPacketCtx))
 
-			// Create request header for session tracking
-			request := &protocol.RequestHeader{
-				Address: dest.Address,
-				Port:    dest.Port,
-				User: &protocol.MemoryUser{
-					Email: sessionKey, // Use session ID as email for tracking
-				},
-			}
-			currentPacketCtx = protocol.ContextWithRequestHeader(currentPacketCtx, request)
-
-			udpServer.Dispatch(currentPacketCtx, dest, remainingPayload)
-			payload.Release()
+			sess.dispatcher.Dispatch(currentPacketCtx, dest, req.Payload)
 		}
 	}
 
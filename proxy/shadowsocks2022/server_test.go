@@ -0,0 +1,278 @@
+package shadowsocks2022
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/v2fly/struc"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+func newPSK(t *testing.T) []byte {
+	psk := make([]byte, 16)
+	common.Must2(rand.Read(psk))
+	return psk
+}
+
+func newMultiUserServer(t *testing.T, serverPsk []byte, users ...*protocol.MemoryUser) *Server {
+	method, err := newMethod("2022-blake3-aes-128-gcm")
+	common.Must(err)
+	s := &Server{
+		config:        &ServerConfig{Method: "2022-blake3-aes-128-gcm", Psk: serverPsk},
+		method:        method,
+		keyDerivation: newBLAKE3KeyDerivation(),
+		multiUser:     true,
+		validator:     new(Validator),
+	}
+	for _, user := range users {
+		common.Must(s.addUser(user))
+	}
+	return s
+}
+
+// clientStateContainer is an in-memory UDPClientPacketProcessorCachedStateContainer.
+type clientStateContainer struct {
+	states       map[string]UDPClientPacketProcessorCachedState
+	serverStates map[string]UDPClientPacketProcessorCachedState
+}
+
+func newClientStateContainer() *clientStateContainer {
+	return &clientStateContainer{
+		states:       make(map[string]UDPClientPacketProcessorCachedState),
+		serverStates: make(map[string]UDPClientPacketProcessorCachedState),
+	}
+}
+
+func (c *clientStateContainer) GetCachedState(sessionID string) UDPClientPacketProcessorCachedState {
+	return c.states[sessionID]
+}
+
+func (c *clientStateContainer) PutCachedState(sessionID string, cache UDPClientPacketProcessorCachedState) {
+	c.states[sessionID] = cache
+}
+
+func (c *clientStateContainer) GetCachedServerState(serverSessionID string) UDPClientPacketProcessorCachedState {
+	return c.serverStates[serverSessionID]
+}
+
+func (c *clientStateContainer) PutCachedServerState(serverSessionID string, cache UDPClientPacketProcessorCachedState) {
+	c.serverStates[serverSessionID] = cache
+}
+
+func TestValidator(t *testing.T) {
+	v := new(Validator)
+	psk := newPSK(t)
+	user := &protocol.MemoryUser{Email: "love@v2fly.org", Account: &Account{UserPsk: psk}}
+	common.Must(v.Add(user))
+
+	assert.Error(t, v.Add(&protocol.MemoryUser{Email: "LOVE@v2fly.org", Account: &Account{UserPsk: newPSK(t)}}))
+	assert.Error(t, v.Add(&protocol.MemoryUser{Email: "other@v2fly.org", Account: &Account{UserPsk: psk}}))
+
+	pskHash := []byte(pskHashKey(psk))
+	found, foundPsk, err := v.Get(pskHash)
+	common.Must(err)
+	assert.Equal(t, user, found)
+	assert.Equal(t, psk, foundPsk)
+
+	common.Must(v.Del("love@v2fly.org"))
+	_, _, err = v.Get(pskHash)
+	assert.Error(t, err)
+	assert.Error(t, v.Del("love@v2fly.org"))
+}
+
+func TestServerIdentifyTCP(t *testing.T) {
+	serverPsk := newPSK(t)
+	userPsk := newPSK(t)
+	user := &protocol.MemoryUser{Email: "love@v2fly.org", Level: 1, Account: &Account{UserPsk: userPsk}}
+	s := newMultiUserServer(t, serverPsk, user)
+
+	identify := func(psk []byte) (*protocol.MemoryUser, []byte, error) {
+		request := &TCPRequest{keyDerivation: s.keyDerivation, method: s.method}
+		out := buf.New()
+		defer out.Release()
+		common.Must(request.EncodeTCPRequestHeader(psk, [][]byte{serverPsk}, net.DomainAddress("example.com"), 443, nil, out))
+
+		var preSessionKeyHeader TCPRequestHeader1PreSessionKey
+		preSessionKeyHeader.Salt = newRequestSaltWithLength(s.method.GetSessionSubKeyAndSaltLength())
+		preSessionKeyHeader.EIH = newAESEIH(1)
+		common.Must(struc.Unpack(bytes.NewReader(out.Bytes()), &preSessionKeyHeader))
+		eih := preSessionKeyHeader.EIH.(*aesEIH).eih[0]
+		return s.identify(preSessionKeyHeader.Salt.Bytes(), eih[:])
+	}
+
+	found, foundPsk, err := identify(userPsk)
+	common.Must(err)
+	assert.Equal(t, user, found)
+	assert.Equal(t, userPsk, foundPsk)
+
+	_, _, err = identify(newPSK(t))
+	assert.Error(t, err)
+}
+
+func TestServerManageUsers(t *testing.T) {
+	s := newMultiUserServer(t, newPSK(t))
+	user := &protocol.MemoryUser{Email: "love@v2fly.org", Account: &Account{UserPsk: newPSK(t)}}
+	common.Must(s.AddUser(context.Background(), user))
+	assert.Error(t, s.AddUser(context.Background(), &protocol.MemoryUser{Email: "short@v2fly.org", Account: &Account{UserPsk: []byte("short")}}))
+	common.Must(s.RemoveUser(context.Background(), "love@v2fly.org"))
+
+	s.multiUser = false
+	assert.Error(t, s.AddUser(context.Background(), user))
+}
+
+func TestUDPServerPacketProcessor(t *testing.T) {
+	serverPsk := newPSK(t)
+	userPsk := newPSK(t)
+	user := &protocol.MemoryUser{Email: "love@v2fly.org", Account: &Account{UserPsk: userPsk}}
+	s := newMultiUserServer(t, serverPsk, user)
+
+	clientProcessor, err := s.method.GetUDPClientProcessor([][]byte{serverPsk}, userPsk, s.keyDerivation)
+	common.Must(err)
+	serverProcessor, err := s.method.GetUDPServerProcessor(serverPsk, s.validator.Get, s.keyDerivation)
+	common.Must(err)
+	clientState := newClientStateContainer()
+
+	var sessionID [8]byte
+	common.Must2(rand.Read(sessionID[:]))
+	sessions := make(map[[8]byte]*UDPServerSession)
+	getSession := func(sessionID [8]byte) *UDPServerSession {
+		return sessions[sessionID]
+	}
+
+	for packetID := uint64(0); packetID < 2; packetID++ {
+		payload := buf.New()
+		common.Must2(payload.WriteString("query"))
+		request := &UDPRequest{
+			SessionID: sessionID,
+			PacketID:  packetID,
+			TimeStamp: uint64(time.Now().Unix()),
+			Address:   net.ParseAddress("8.8.8.8"),
+			Port:      53,
+			Payload:   payload,
+		}
+		out := buf.New()
+		common.Must(clientProcessor.EncodeUDPRequest(request, out, clientState))
+		payload.Release()
+
+		var req UDPRequest
+		serverSession, err := serverProcessor.DecodeUDPRequest(out.Bytes(), &req, getSession)
+		out.Release()
+		common.Must(err)
+		sessions[req.SessionID] = serverSession
+		assert.Equal(t, user, serverSession.User)
+		assert.Equal(t, sessionID, req.SessionID)
+		assert.Equal(t, packetID, req.PacketID)
+		assert.Equal(t, net.ParseAddress("8.8.8.8"), req.Address)
+		assert.Equal(t, 53, req.Port)
+		assert.Equal(t, "query", req.Payload.String())
+		req.Payload.Release()
+
+		response := &UDPResponse{
+			UDPRequest: UDPRequest{
+				PacketID:  serverSession.NextPacketID(),
+				TimeStamp: uint64(time.Now().Unix()),
+				Address:   net.ParseAddress("8.8.8.8"),
+				Port:      53,
+				Payload:   buf.FromBytes([]byte("answer")),
+			},
+		}
+		out = buf.New()
+		common.Must(serverProcessor.EncodeUDPResponse(response, out, serverSession))
+
+		var resp UDPResponse
+		common.Must(clientProcessor.DecodeUDPResp(out.Bytes(), &resp, clientState))
+		out.Release()
+		assert.Equal(t, serverSession.ServerSessionID, resp.SessionID)
+		assert.Equal(t, packetID, resp.PacketID)
+		assert.Equal(t, sessionID, resp.ClientSessionID)
+		assert.Equal(t, "answer", resp.Payload.String())
+		resp.Payload.Release()
+	}
+	assert.Len(t, sessions, 1)
+
+	otherProcessor, err := s.method.GetUDPClientProcessor([][]byte{serverPsk}, newPSK(t), s.keyDerivation)
+	common.Must(err)
+	payload := buf.FromBytes([]byte("query"))
+	out := buf.New()
+	defer out.Release()
+	common.Must(otherProcessor.EncodeUDPRequest(&UDPRequest{
+		TimeStamp: uint64(time.Now().Unix()),
+		Address:   net.ParseAddress("8.8.8.8"),
+		Port:      53,
+		Payload:   payload,
+	}, out, newClientStateContainer()))
+	_, err = serverProcessor.DecodeUDPRequest(out.Bytes(), new(UDPRequest), getSession)
+	assert.Error(t, err)
+}
+
+func TestChaCha20UDPPacketProcessor(t *testing.T) {
+	psk := make([]byte, 32)
+	common.Must2(rand.Read(psk))
+	method, err := newMethod("2022-blake3-chacha20-poly1305")
+	common.Must(err)
+	derivation := newBLAKE3KeyDerivation()
+
+	_, err = method.GetUDPClientProcessor([][]byte{psk}, psk, derivation)
+	assert.Error(t, err)
+	_, err = method.GetUDPServerProcessor(psk, new(Validator).Get, derivation)
+	assert.Error(t, err)
+
+	clientProcessor, err := method.GetUDPClientProcessor(nil, psk, derivation)
+	common.Must(err)
+	serverProcessor, err := method.GetUDPServerProcessor(psk, nil, derivation)
+	common.Must(err)
+
+	var sessionID [8]byte
+	common.Must2(rand.Read(sessionID[:]))
+	out := buf.New()
+	defer out.Release()
+	common.Must(clientProcessor.EncodeUDPRequest(&UDPRequest{
+		SessionID: sessionID,
+		PacketID:  7,
+		TimeStamp: uint64(time.Now().Unix()),
+		Address:   net.DomainAddress("example.com"),
+		Port:      53,
+		Payload:   buf.FromBytes([]byte("query")),
+	}, out, newClientStateContainer()))
+
+	var req UDPRequest
+	serverSession, err := serverProcessor.DecodeUDPRequest(out.Bytes(), &req, func([8]byte) *UDPServerSession {
+		return nil
+	})
+	common.Must(err)
+	assert.Nil(t, serverSession.User)
+	assert.Equal(t, sessionID, serverSession.ClientSessionID)
+	assert.Equal(t, uint64(7), req.PacketID)
+	assert.Equal(t, net.DomainAddress("example.com"), req.Address)
+	assert.Equal(t, "query", req.Payload.String())
+	req.Payload.Release()
+
+	out.Clear()
+	common.Must(serverProcessor.EncodeUDPResponse(&UDPResponse{
+		UDPRequest: UDPRequest{
+			PacketID:  serverSession.NextPacketID(),
+			TimeStamp: uint64(time.Now().Unix()),
+			Address:   net.DomainAddress("example.com"),
+			Port:      53,
+			Payload:   buf.FromBytes([]byte("answer")),
+		},
+	}, out, serverSession))
+
+	var resp UDPResponse
+	common.Must(clientProcessor.DecodeUDPResp(out.Bytes(), &resp, newClientStateContainer()))
+	assert.Equal(t, serverSession.ServerSessionID, resp.SessionID)
+	assert.Equal(t, sessionID, resp.ClientSessionID)
+	assert.Equal(t, "answer", resp.Payload.String())
+	resp.Payload.Release()
+
+	out.Bytes()[out.Len()-1] ^= 1
+	assert.Error(t, clientProcessor.DecodeUDPResp(out.Bytes(), new(UDPResponse), newClientStateContainer()))
+}
@@ -24,6 +24,7 @@ type Method interface {
 	GenerateEIH(CurrentIdentitySubKey []byte, nextPskHash []byte, out []byte) error
 	DecryptEIH(CurrentIdentitySubKey []byte, eih []byte, out []byte) error
 	GetUDPClientProcessor(ipsk [][]byte, psk []byte, derivation KeyDerivation) (UDPClientPacketProcessor, error)
+	GetUDPServerProcessor(psk []byte, userLookup UDPServerUserLookup, derivation KeyDerivation) (UDPServerPacketProcessor, error)
 }
 
 type ExtensibleIdentityHeaders interface {
@@ -128,3 +129,48 @@ type UDPClientPacketProcessor interface {
 	EncodeUDPRequest(request *UDPRequest, out *buf.Buffer, cache UDPClientPacketProcessorCachedStateContainer) error
 	DecodeUDPResp(input []byte, resp *UDPResponse, cache UDPClientPacketProcessorCachedStateContainer) error
 }
+
+// UDPServerUserLookup finds a user and its PSK by the PSK hash carried in an
+// EIH. It is nil if the server is not in multi-user mode.
+type UDPServerUserLookup func(pskHash []byte) (*protocol.MemoryUser, []byte, error)
+
+// UDPServerSession is the state of a client UDP session on the server side.
+type UDPServerSession struct {
+	User            *protocol.MemoryUser
+	ClientSessionID [8]byte
+	ServerSessionID [8]byte
+
+	packetID uint64
+	state    UDPServerPacketProcessorCachedState
+}
+
+// NextPacketID returns the packet ID of the next response of the session.
+func (s *UDPServerSession) NextPacketID() uint64 {
+	id := s.packetID
+	s.packetID++
+	return id
+}
+
+type UDPServerPacketProcessorCachedState interface{}
+
+// UDPServerPacketProcessor
+// Caller retain and receive all ownership of the buffer
+type UDPServerPacketProcessor interface {
+	// DecodeUDPRequest decodes a request. The existing session of the request
+	// is returned by getSession, a new session is created otherwise.
+	DecodeUDPRequest(input []byte, req *UDPRequest, getSession func(sessionID [8]byte) *UDPServerSession) (*UDPServerSession, error)
+	EncodeUDPResponse(resp *UDPResponse, out *buf.Buffer, session *UDPServerSession) error
+}
+
+func newMethod(method string) (Method, error) {
+	switch method {
+	case "2022-blake3-aes-128-gcm":
+		return newAES128GCMMethod(), nil
+	case "2022-blake3-aes-256-gcm":
+		return newAES256GCMMethod(), nil
+	case "2022-blake3-chacha20-poly1305":
+		return newChaCha20Poly1305Method(), nil
+	default:
+		return nil, newError("unknown method: ", method)
+	}
+}
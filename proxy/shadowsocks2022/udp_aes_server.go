@@ -0,0 +1,216 @@
+package shadowsocks2022
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	cryptoRand "crypto/rand"
+	"crypto/subtle"
+	"io"
+
+	"github.com/v2fly/struc"
+
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+type AESUDPServerPacketProcessor struct {
+	method                    Method
+	derivation                KeyDerivation
+	psk                       []byte
+	separateHeaderBlockCipher cipher.Block
+	userLookup                UDPServerUserLookup
+}
+
+func newAESUDPServerPacketProcessor(method Method, psk []byte, userLookup UDPServerUserLookup, derivation KeyDerivation) (*AESUDPServerPacketProcessor, error) {
+	separateHeaderBlockCipher, err := aes.NewCipher(psk)
+	if err != nil {
+		return nil, newError("failed to create AES cipher").Base(err)
+	}
+	return &AESUDPServerPacketProcessor{
+		method:                    method,
+		derivation:                derivation,
+		psk:                       psk,
+		separateHeaderBlockCipher: separateHeaderBlockCipher,
+		userLookup:                userLookup,
+	}, nil
+}
+
+type aesUDPServerState struct {
+	sessionRecvAEAD                   cipher.AEAD
+	sessionSendAEAD                   cipher.AEAD
+	responseSeparateHeaderBlockCipher cipher.Block
+}
+
+func (p *AESUDPServerPacketProcessor) getPacketAEAD(psk, sessionID []byte) (cipher.AEAD, error) {
+	sessionKey := make([]byte, p.method.GetSessionSubKeyAndSaltLength())
+	if err := p.derivation.GetSessionSubKey(psk, sessionID, sessionKey); err != nil {
+		return nil, newError("failed to get session sub key").Base(err)
+	}
+	return p.method.GetStreamAEAD(sessionKey)
+}
+
+func (p *AESUDPServerPacketProcessor) newSession(clientSessionID [8]byte, user *protocol.MemoryUser, psk []byte) (*UDPServerSession, error) {
+	session := &UDPServerSession{
+		User:            user,
+		ClientSessionID: clientSessionID,
+	}
+	if _, err := io.ReadFull(cryptoRand.Reader, session.ServerSessionID[:]); err != nil {
+		return nil, newError("failed to generate server session ID").Base(err)
+	}
+
+	state := &aesUDPServerState{}
+	var err error
+	if state.sessionRecvAEAD, err = p.getPacketAEAD(psk, session.ClientSessionID[:]); err != nil {
+		return nil, err
+	}
+	if state.sessionSendAEAD, err = p.getPacketAEAD(psk, session.ServerSessionID[:]); err != nil {
+		return nil, err
+	}
+	if state.responseSeparateHeaderBlockCipher, err = aes.NewCipher(psk); err != nil {
+		return nil, newError("failed to create AES cipher").Base(err)
+	}
+	session.state = state
+	return session, nil
+}
+
+func (p *AESUDPServerPacketProcessor) DecodeUDPRequest(input []byte, req *UDPRequest,
+	getSession func(sessionID [8]byte) *UDPServerSession,
+) (*UDPServerSession, error) {
+	headerLength := 16
+	if p.userLookup != nil {
+		headerLength += aesEIHSize
+	}
+	if len(input) < headerLength {
+		return nil, newError("packet is too short")
+	}
+
+	separateHeaderBuffer := buf.New()
+	defer separateHeaderBuffer.Release()
+	separateHeaderBytes := separateHeaderBuffer.Extend(16)
+	p.separateHeaderBlockCipher.Decrypt(separateHeaderBytes, input[:16])
+	separateHeaderStruct := separateHeader{}
+	{
+		err := struc.Unpack(bytes.NewReader(separateHeaderBytes), &separateHeaderStruct)
+		if err != nil {
+			return nil, newError("failed to unpack separateHeader").Base(err)
+		}
+	}
+
+	session := getSession(separateHeaderStruct.SessionID)
+	if session == nil {
+		var user *protocol.MemoryUser
+		psk := p.psk
+		if p.userLookup != nil {
+			pskHash := make([]byte, aesEIHPskHashSize)
+			p.separateHeaderBlockCipher.Decrypt(pskHash, input[16:16+aesEIHSize])
+			subtle.XORBytes(pskHash, pskHash, separateHeaderBytes)
+			var err error
+			user, psk, err = p.userLookup(pskHash)
+			if err != nil {
+				return nil, newError("failed to identify user").Base(err)
+			}
+		}
+		var err error
+		session, err = p.newSession(separateHeaderStruct.SessionID, user, psk)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mainPacketAEADMaterialized := session.state.(*aesUDPServerState).sessionRecvAEAD
+	encrypted := input[headerLength:]
+	if len(encrypted) < mainPacketAEADMaterialized.Overhead() {
+		return nil, newError("packet is too short")
+	}
+	decryptedDestBuffer := buf.NewWithSize(int32(len(encrypted)))
+	decryptedDest := decryptedDestBuffer.Extend(int32(len(encrypted) - mainPacketAEADMaterialized.Overhead()))
+	_, err := mainPacketAEADMaterialized.Open(decryptedDest[:0], separateHeaderBytes[4:16], encrypted, nil)
+	if err != nil {
+		decryptedDestBuffer.Release()
+		return nil, newError("failed to open main packet").Base(err)
+	}
+	decryptedDestReader := bytes.NewReader(decryptedDest)
+	headerStruct := header{}
+	{
+		err := struc.Unpack(decryptedDestReader, &headerStruct)
+		if err != nil {
+			decryptedDestBuffer.Release()
+			return nil, newError("failed to unpack header").Base(err)
+		}
+	}
+	if headerStruct.Type != UDPHeaderTypeClientToServerStream {
+		decryptedDestBuffer.Release()
+		return nil, newError("unexpected UDP header type")
+	}
+	addressReaderBuf := buf.New()
+	defer addressReaderBuf.Release()
+	var port net.Port
+	req.Address, port, err = addrParser.ReadAddressPort(addressReaderBuf, decryptedDestReader)
+	if err != nil {
+		decryptedDestBuffer.Release()
+		return nil, newError("failed to read address port").Base(err)
+	}
+	req.Port = int(port)
+	req.SessionID = separateHeaderStruct.SessionID
+	req.PacketID = separateHeaderStruct.PacketID
+	req.TimeStamp = headerStruct.TimeStamp
+	readedLength := decryptedDestReader.Size() - int64(decryptedDestReader.Len())
+	decryptedDestBuffer.Advance(int32(readedLength))
+	req.Payload = decryptedDestBuffer
+	return session, nil
+}
+
+func (p *AESUDPServerPacketProcessor) EncodeUDPResponse(resp *UDPResponse, out *buf.Buffer, session *UDPServerSession) error {
+	state := session.state.(*aesUDPServerState)
+
+	separateHeaderStruct := separateHeader{PacketID: resp.PacketID, SessionID: session.ServerSessionID}
+	separateHeaderBuffer := buf.New()
+	defer separateHeaderBuffer.Release()
+	{
+		err := struc.Pack(separateHeaderBuffer, &separateHeaderStruct)
+		if err != nil {
+			return newError("failed to pack separateHeader").Base(err)
+		}
+	}
+	separateHeaderBufferBytes := separateHeaderBuffer.Bytes()
+	{
+		encryptedDest := out.Extend(16)
+		state.responseSeparateHeaderBlockCipher.Encrypt(encryptedDest, separateHeaderBufferBytes)
+	}
+
+	headerStruct := respHeader{
+		Type:            UDPHeaderTypeServerToClientStream,
+		TimeStamp:       resp.TimeStamp,
+		ClientSessionID: session.ClientSessionID,
+		PaddingLength:   0,
+		Padding:         nil,
+	}
+	responseBodyBuffer := buf.New()
+	defer responseBodyBuffer.Release()
+	{
+		err := struc.Pack(responseBodyBuffer, &headerStruct)
+		if err != nil {
+			return newError("failed to pack header").Base(err)
+		}
+	}
+	{
+		err := addrParser.WriteAddressPort(responseBodyBuffer, resp.Address, net.Port(resp.Port))
+		if err != nil {
+			return newError("failed to write address port").Base(err)
+		}
+	}
+	{
+		_, err := io.Copy(responseBodyBuffer, bytes.NewReader(resp.Payload.Bytes()))
+		if err != nil {
+			return newError("failed to copy payload").Base(err)
+		}
+	}
+	{
+		mainPacketAEADMaterialized := state.sessionSendAEAD
+		encryptedDest := out.Extend(int32(mainPacketAEADMaterialized.Overhead()) + responseBodyBuffer.Len())
+		mainPacketAEADMaterialized.Seal(encryptedDest[:0], separateHeaderBufferBytes[4:16], responseBodyBuffer.Bytes(), nil)
+	}
+	return nil
+}
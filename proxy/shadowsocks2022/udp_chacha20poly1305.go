@@ -0,0 +1,234 @@
+package shadowsocks2022
+
+import (
+	"bytes"
+	"crypto/cipher"
+	cryptoRand "crypto/rand"
+	"io"
+
+	"github.com/v2fly/struc"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+// The UDP packets of 2022-blake3-chacha20-poly1305 have no separate header,
+// the whole packet is sealed with XChaCha20-Poly1305 keyed by the PSK:
+//
+//	Nonce(24) | Seal(SessionID(8) | PacketID(8) | Header | Address | Payload)
+const chacha20UDPNonceSize = chacha20poly1305.NonceSizeX
+
+func newChaCha20UDPAEAD(psk []byte) (cipher.AEAD, error) {
+	aead, err := chacha20poly1305.NewX(psk)
+	if err != nil {
+		return nil, newError("failed to create XChaCha20-Poly1305 AEAD").Base(err)
+	}
+	return aead, nil
+}
+
+// sealChaCha20UDPPacket writes a random nonce and the sealed body to out.
+func sealChaCha20UDPPacket(aead cipher.AEAD, body []byte, out *buf.Buffer) error {
+	nonce := out.Extend(chacha20UDPNonceSize)
+	if _, err := io.ReadFull(cryptoRand.Reader, nonce); err != nil {
+		return newError("failed to generate nonce").Base(err)
+	}
+	encryptedDest := out.Extend(int32(len(body) + aead.Overhead()))
+	aead.Seal(encryptedDest[:0], nonce, body, nil)
+	return nil
+}
+
+// openChaCha20UDPPacket returns the body of a packet, the caller owns the
+// returned buffer.
+func openChaCha20UDPPacket(aead cipher.AEAD, input []byte) (*buf.Buffer, error) {
+	if len(input) < chacha20UDPNonceSize+aead.Overhead() {
+		return nil, newError("packet is too short")
+	}
+	encrypted := input[chacha20UDPNonceSize:]
+	decryptedDestBuffer := buf.NewWithSize(int32(len(encrypted)))
+	decryptedDest := decryptedDestBuffer.Extend(int32(len(encrypted) - aead.Overhead()))
+	_, err := aead.Open(decryptedDest[:0], input[:chacha20UDPNonceSize], encrypted, nil)
+	if err != nil {
+		decryptedDestBuffer.Release()
+		return nil, newError("failed to open main packet").Base(err)
+	}
+	return decryptedDestBuffer, nil
+}
+
+func writeChaCha20UDPBody(out *buf.Buffer, separateHeaderStruct *separateHeader, headerStruct interface{},
+	address DestinationAddress, port int, payload *buf.Buffer,
+) error {
+	{
+		err := struc.Pack(out, separateHeaderStruct)
+		if err != nil {
+			return newError("failed to pack separateHeader").Base(err)
+		}
+	}
+	{
+		err := struc.Pack(out, headerStruct)
+		if err != nil {
+			return newError("failed to pack header").Base(err)
+		}
+	}
+	{
+		err := addrParser.WriteAddressPort(out, address, net.Port(port))
+		if err != nil {
+			return newError("failed to write address port").Base(err)
+		}
+	}
+	{
+		_, err := io.Copy(out, bytes.NewReader(payload.Bytes()))
+		if err != nil {
+			return newError("failed to copy payload").Base(err)
+		}
+	}
+	return nil
+}
+
+// readChaCha20UDPBody parses a body opened by openChaCha20UDPPacket, and
+// advances the body to its payload.
+func readChaCha20UDPBody(body *buf.Buffer, separateHeaderStruct *separateHeader, headerStruct interface{},
+) (net.Address, int, error) {
+	reader := bytes.NewReader(body.Bytes())
+	{
+		err := struc.Unpack(reader, separateHeaderStruct)
+		if err != nil {
+			return nil, 0, newError("failed to unpack separateHeader").Base(err)
+		}
+	}
+	{
+		err := struc.Unpack(reader, headerStruct)
+		if err != nil {
+			return nil, 0, newError("failed to unpack header").Base(err)
+		}
+	}
+	addressReaderBuf := buf.New()
+	defer addressReaderBuf.Release()
+	address, port, err := addrParser.ReadAddressPort(addressReaderBuf, reader)
+	if err != nil {
+		return nil, 0, newError("failed to read address port").Base(err)
+	}
+	body.Advance(int32(reader.Size() - int64(reader.Len())))
+	return address, int(port), nil
+}
+
+type ChaCha20UDPClientPacketProcessor struct {
+	aead cipher.AEAD
+}
+
+func newChaCha20UDPClientPacketProcessor(psk []byte) (*ChaCha20UDPClientPacketProcessor, error) {
+	aead, err := newChaCha20UDPAEAD(psk)
+	if err != nil {
+		return nil, err
+	}
+	return &ChaCha20UDPClientPacketProcessor{aead: aead}, nil
+}
+
+func (p *ChaCha20UDPClientPacketProcessor) EncodeUDPRequest(request *UDPRequest, out *buf.Buffer,
+	cache UDPClientPacketProcessorCachedStateContainer,
+) error {
+	requestBodyBuffer := buf.New()
+	defer requestBodyBuffer.Release()
+	err := writeChaCha20UDPBody(requestBodyBuffer,
+		&separateHeader{SessionID: request.SessionID, PacketID: request.PacketID},
+		&header{Type: UDPHeaderTypeClientToServerStream, TimeStamp: request.TimeStamp},
+		request.Address, request.Port, request.Payload)
+	if err != nil {
+		return err
+	}
+	return sealChaCha20UDPPacket(p.aead, requestBodyBuffer.Bytes(), out)
+}
+
+func (p *ChaCha20UDPClientPacketProcessor) DecodeUDPResp(input []byte, resp *UDPResponse,
+	cache UDPClientPacketProcessorCachedStateContainer,
+) error {
+	body, err := openChaCha20UDPPacket(p.aead, input)
+	if err != nil {
+		return err
+	}
+	separateHeaderStruct := separateHeader{}
+	headerStruct := respHeader{}
+	address, port, err := readChaCha20UDPBody(body, &separateHeaderStruct, &headerStruct)
+	if err != nil {
+		body.Release()
+		return err
+	}
+	if headerStruct.Type != UDPHeaderTypeServerToClientStream {
+		body.Release()
+		return newError("unexpected UDP header type")
+	}
+	resp.SessionID = separateHeaderStruct.SessionID
+	resp.PacketID = separateHeaderStruct.PacketID
+	resp.TimeStamp = headerStruct.TimeStamp
+	resp.ClientSessionID = headerStruct.ClientSessionID
+	resp.Address = address
+	resp.Port = port
+	resp.Payload = body
+	return nil
+}
+
+type ChaCha20UDPServerPacketProcessor struct {
+	aead cipher.AEAD
+}
+
+func newChaCha20UDPServerPacketProcessor(psk []byte) (*ChaCha20UDPServerPacketProcessor, error) {
+	aead, err := newChaCha20UDPAEAD(psk)
+	if err != nil {
+		return nil, err
+	}
+	return &ChaCha20UDPServerPacketProcessor{aead: aead}, nil
+}
+
+func (p *ChaCha20UDPServerPacketProcessor) DecodeUDPRequest(input []byte, req *UDPRequest,
+	getSession func(sessionID [8]byte) *UDPServerSession,
+) (*UDPServerSession, error) {
+	body, err := openChaCha20UDPPacket(p.aead, input)
+	if err != nil {
+		return nil, err
+	}
+	separateHeaderStruct := separateHeader{}
+	headerStruct := header{}
+	address, port, err := readChaCha20UDPBody(body, &separateHeaderStruct, &headerStruct)
+	if err != nil {
+		body.Release()
+		return nil, err
+	}
+	if headerStruct.Type != UDPHeaderTypeClientToServerStream {
+		body.Release()
+		return nil, newError("unexpected UDP header type")
+	}
+
+	session := getSession(separateHeaderStruct.SessionID)
+	if session == nil {
+		session = &UDPServerSession{ClientSessionID: separateHeaderStruct.SessionID}
+		if _, err := io.ReadFull(cryptoRand.Reader, session.ServerSessionID[:]); err != nil {
+			body.Release()
+			return nil, newError("failed to generate server session ID").Base(err)
+		}
+	}
+
+	req.SessionID = separateHeaderStruct.SessionID
+	req.PacketID = separateHeaderStruct.PacketID
+	req.TimeStamp = headerStruct.TimeStamp
+	req.Address = address
+	req.Port = port
+	req.Payload = body
+	return session, nil
+}
+
+func (p *ChaCha20UDPServerPacketProcessor) EncodeUDPResponse(resp *UDPResponse, out *buf.Buffer, session *UDPServerSession) error {
+	responseBodyBuffer := buf.NewWithSize(resp.Payload.Len() + 512)
+	defer responseBodyBuffer.Release()
+	err := writeChaCha20UDPBody(responseBodyBuffer,
+		&separateHeader{SessionID: session.ServerSessionID, PacketID: resp.PacketID},
+		&respHeader{
+			Type:            UDPHeaderTypeServerToClientStream,
+			TimeStamp:       resp.TimeStamp,
+			ClientSessionID: session.ClientSessionID,
+		},
+		resp.Address, resp.Port, resp.Payload)
+	if err != nil {
+		return err
+	}
+	return sealChaCha20UDPPacket(p.aead, responseBodyBuffer.Bytes(), out)
+}
@@ -0,0 +1,69 @@
+package shadowsocks2022
+
+import (
+	"strings"
+	"sync"
+
+	"lukechampine.com/blake3"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// Validator stores valid Shadowsocks-2022 users of a multi-user server, they
+// are identified by the hash of their PSK carried in EIH.
+type Validator struct {
+	// Considering email's usage here, map + sync.Mutex/RWMutex may have better performance.
+	email sync.Map
+	users sync.Map
+}
+
+func pskHashKey(psk []byte) string {
+	hash := blake3.Sum512(psk)
+	return string(hash[:aesEIHPskHashSize])
+}
+
+// Add a Shadowsocks-2022 user, Email must be empty or unique.
+func (v *Validator) Add(u *protocol.MemoryUser) error {
+	account, ok := u.Account.(*Account)
+	if !ok {
+		return newError("not a Shadowsocks-2022 account")
+	}
+	if u.Email != "" {
+		_, loaded := v.email.LoadOrStore(strings.ToLower(u.Email), u)
+		if loaded {
+			return newError("User ", u.Email, " already exists.")
+		}
+	}
+	if _, loaded := v.users.LoadOrStore(pskHashKey(account.UserPsk), u); loaded {
+		if u.Email != "" {
+			v.email.Delete(strings.ToLower(u.Email))
+		}
+		return newError("User with the same PSK already exists.")
+	}
+	return nil
+}
+
+// Del a Shadowsocks-2022 user with a non-empty Email.
+func (v *Validator) Del(e string) error {
+	if e == "" {
+		return newError("Email must not be empty.")
+	}
+	le := strings.ToLower(e)
+	u, _ := v.email.Load(le)
+	if u == nil {
+		return newError("User ", e, " not found.")
+	}
+	v.email.Delete(le)
+	v.users.Delete(pskHashKey(u.(*protocol.MemoryUser).Account.(*Account).UserPsk))
+	return nil
+}
+
+// Get a Shadowsocks-2022 user and its PSK with the PSK hash in EIH.
+func (v *Validator) Get(pskHash []byte) (*protocol.MemoryUser, []byte, error) {
+	u, _ := v.users.Load(string(pskHash))
+	if u == nil {
+		return nil, nil, newError("user not found for PSK hash")
+	}
+	user := u.(*protocol.MemoryUser)
+	return user, user.Account.(*Account).UserPsk, nil
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/daeuniverse/outbound/netproxy"
 	outboundProtocol "github.com/daeuniverse/outbound/protocol"
+	_ "github.com/daeuniverse/outbound/protocol/tuic" // registers the TUIC dialer
 	tuicCommon "github.com/daeuniverse/outbound/protocol/tuic/common"
 	quic "github.com/daeuniverse/quic-go"
 )
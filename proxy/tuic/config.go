@@ -5,22 +5,12 @@ import (
 
 	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
 	"github.com/google/uuid"
 )
 
 // MemoryAccount is an account type converted from Account.
-type MemoryAccount struct {
-	UUID     uuid.UUID
-	Password string
-}
-
-// Equals implements protocol.Account.Equals().
-func (a *MemoryAccount) Equals(another protocol.Account) bool {
-	if account, ok := another.(*MemoryAccount); ok {
-		return a.UUID == account.UUID && a.Password == account.Password
-	}
-	return false
-}
+type MemoryAccount = tuicTransport.MemoryAccount
 
 // AsAccount implements protocol.AsAccount.
 func (a *Account) AsAccount() (protocol.Account, error) {
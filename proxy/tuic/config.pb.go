@@ -313,11 +313,79 @@ func (x *ClientConfig) GetTls() *TLSConfig {
 	return nil
 }
 
+type ServerConfig struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Users                 []*protocol.User       `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	AuthTimeout           int64                  `protobuf:"varint,2,opt,name=auth_timeout,json=authTimeout,proto3" json:"auth_timeout,omitempty"`                                     // in milliseconds
+	MaxUdpRelayPacketSize int32                  `protobuf:"varint,3,opt,name=max_udp_relay_packet_size,json=maxUdpRelayPacketSize,proto3" json:"max_udp_relay_packet_size,omitempty"` // max size of a relayed UDP datagram
+	UdpIdleTimeout        int64                  `protobuf:"varint,4,opt,name=udp_idle_timeout,json=udpIdleTimeout,proto3" json:"udp_idle_timeout,omitempty"`                          // in seconds
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ServerConfig) Reset() {
+	*x = ServerConfig{}
+	mi := &file_proxy_tuic_config_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerConfig) ProtoMessage() {}
+
+func (x *ServerConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proxy_tuic_config_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerConfig.ProtoReflect.Descriptor instead.
+func (*ServerConfig) Descriptor() ([]byte, []int) {
+	return file_proxy_tuic_config_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ServerConfig) GetUsers() []*protocol.User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetAuthTimeout() int64 {
+	if x != nil {
+		return x.AuthTimeout
+	}
+	return 0
+}
+
+func (x *ServerConfig) GetMaxUdpRelayPacketSize() int32 {
+	if x != nil {
+		return x.MaxUdpRelayPacketSize
+	}
+	return 0
+}
+
+func (x *ServerConfig) GetUdpIdleTimeout() int64 {
+	if x != nil {
+		return x.UdpIdleTimeout
+	}
+	return 0
+}
+
 var File_proxy_tuic_config_proto protoreflect.FileDescriptor
 
 const file_proxy_tuic_config_proto_rawDesc = "" +
 	"\n" +
-	"\x17proxy/tuic/config.proto\x12\x15v2ray.core.proxy.tuic\x1a!common/protocol/server_spec.proto\x1a common/protoext/extensions.proto\"9\n" +
+	"\x17proxy/tuic/config.proto\x12\x15v2ray.core.proxy.tuic\x1a!common/protocol/server_spec.proto\x1a\x1acommon/protocol/user.proto\x1a common/protoext/extensions.proto\"9\n" +
 	"\aAccount\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x1a\n" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\"\xab\x03\n" +
@@ -344,7 +412,13 @@ const file_proxy_tuic_config_proto_rawDesc = "" +
 	"reduce_rtt\x18\x05 \x01(\bR\treduceRtt\x128\n" +
 	"\x19max_udp_relay_packet_size\x18\x06 \x01(\x05R\x15maxUdpRelayPacketSize\x122\n" +
 	"\x03tls\x18\a \x01(\v2 .v2ray.core.proxy.tuic.TLSConfigR\x03tls:\x14\x82\xb5\x18\x10\n" +
-	"\boutbound\x12\x04tuicBA\n" +
+	"\boutbound\x12\x04tuic\"\xe2\x01\n" +
+	"\fServerConfig\x126\n" +
+	"\x05users\x18\x01 \x03(\v2 .v2ray.core.common.protocol.UserR\x05users\x12!\n" +
+	"\fauth_timeout\x18\x02 \x01(\x03R\vauthTimeout\x128\n" +
+	"\x19max_udp_relay_packet_size\x18\x03 \x01(\x05R\x15maxUdpRelayPacketSize\x12(\n" +
+	"\x10udp_idle_timeout\x18\x04 \x01(\x03R\x0eudpIdleTimeout:\x13\x82\xb5\x18\x0f\n" +
+	"\ainbound\x12\x04tuicBA\n" +
 	"\x19com.v2ray.core.proxy.tuicP\x01Z\n" +
 	"proxy/tuic\xaa\x02\x15V2Ray.Core.Proxy.Tuicb\x06proto3"
 
@@ -360,23 +434,26 @@ func file_proxy_tuic_config_proto_rawDescGZIP() []byte {
 	return file_proxy_tuic_config_proto_rawDescData
 }
 
-var file_proxy_tuic_config_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proxy_tuic_config_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_proxy_tuic_config_proto_goTypes = []any{
 	(*Account)(nil),                 // 0: v2ray.core.proxy.tuic.Account
 	(*QUICConfig)(nil),              // 1: v2ray.core.proxy.tuic.QUICConfig
 	(*TLSConfig)(nil),               // 2: v2ray.core.proxy.tuic.TLSConfig
 	(*ClientConfig)(nil),            // 3: v2ray.core.proxy.tuic.ClientConfig
-	(*protocol.ServerEndpoint)(nil), // 4: v2ray.core.common.protocol.ServerEndpoint
+	(*ServerConfig)(nil),            // 4: v2ray.core.proxy.tuic.ServerConfig
+	(*protocol.ServerEndpoint)(nil), // 5: v2ray.core.common.protocol.ServerEndpoint
+	(*protocol.User)(nil),           // 6: v2ray.core.common.protocol.User
 }
 var file_proxy_tuic_config_proto_depIdxs = []int32{
-	4, // 0: v2ray.core.proxy.tuic.ClientConfig.server:type_name -> v2ray.core.common.protocol.ServerEndpoint
+	5, // 0: v2ray.core.proxy.tuic.ClientConfig.server:type_name -> v2ray.core.common.protocol.ServerEndpoint
 	1, // 1: v2ray.core.proxy.tuic.ClientConfig.quic:type_name -> v2ray.core.proxy.tuic.QUICConfig
 	2, // 2: v2ray.core.proxy.tuic.ClientConfig.tls:type_name -> v2ray.core.proxy.tuic.TLSConfig
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	6, // 3: v2ray.core.proxy.tuic.ServerConfig.users:type_name -> v2ray.core.common.protocol.User
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_proxy_tuic_config_proto_init() }
@@ -390,7 +467,7 @@ func file_proxy_tuic_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proxy_tuic_config_proto_rawDesc), len(file_proxy_tuic_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,236 @@
+package tuic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+)
+
+// TUIC v5 command header: VER(1) TYPE(1) OPT(variable)
+const (
+	version byte = 0x05
+
+	commandAuthenticate byte = 0x00
+	commandConnect      byte = 0x01
+	commandPacket       byte = 0x02
+	commandDissociate   byte = 0x03
+	commandHeartbeat    byte = 0x04
+
+	addressTypeNone byte = 0xff
+)
+
+var addrParser = protocol.NewAddressParser(
+	protocol.AddressFamilyByte(0x00, net.AddressFamilyDomain),
+	protocol.AddressFamilyByte(0x01, net.AddressFamilyIPv4),
+	protocol.AddressFamilyByte(0x02, net.AddressFamilyIPv6),
+)
+
+// packet is a (fragment of a) UDP packet carried by the Packet command.
+type packet struct {
+	assocID   uint16
+	packetID  uint16
+	fragTotal uint8
+	fragID    uint8
+	target    net.Destination // invalid if the address type is None
+	payload   []byte
+}
+
+func readCommandHeader(r io.Reader) (byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	if header[0] != version {
+		return 0, newError("unsupported TUIC version ", header[0])
+	}
+	return header[1], nil
+}
+
+// readAddress reads a TUIC address, an invalid destination is returned for
+// the None address type.
+func readAddress(r io.Reader, network net.Network) (net.Destination, error) {
+	var addrType [1]byte
+	if _, err := io.ReadFull(r, addrType[:]); err != nil {
+		return net.Destination{}, err
+	}
+	if addrType[0] == addressTypeNone {
+		return net.Destination{}, nil
+	}
+
+	b := buf.New()
+	defer b.Release()
+	address, port, err := addrParser.ReadAddressPort(b, io.MultiReader(bytes.NewReader(addrType[:]), r))
+	if err != nil {
+		return net.Destination{}, newError("failed to read address").Base(err)
+	}
+	return net.Destination{
+		Network: network,
+		Address: address,
+		Port:    port,
+	}, nil
+}
+
+func writeAddress(w io.Writer, dest net.Destination) error {
+	if !dest.IsValid() {
+		_, err := w.Write([]byte{addressTypeNone})
+		return err
+	}
+	return addrParser.WriteAddressPort(w, dest.Address, dest.Port)
+}
+
+// readAuthenticate reads the options of an Authenticate command.
+func readAuthenticate(r io.Reader) ([16]byte, []byte, error) {
+	var id [16]byte
+	token := make([]byte, tuicTransport.TokenLength)
+	if _, err := io.ReadFull(r, id[:]); err != nil {
+		return id, nil, err
+	}
+	if _, err := io.ReadFull(r, token); err != nil {
+		return id, nil, err
+	}
+	return id, token, nil
+}
+
+// readPacket reads the options and payload of a Packet command.
+func readPacket(r io.Reader) (*packet, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	p := &packet{
+		assocID:   binary.BigEndian.Uint16(header[0:2]),
+		packetID:  binary.BigEndian.Uint16(header[2:4]),
+		fragTotal: header[4],
+		fragID:    header[5],
+	}
+	if p.fragTotal == 0 || p.fragID >= p.fragTotal {
+		return nil, newError("invalid fragment ", p.fragID, "/", p.fragTotal)
+	}
+	size := binary.BigEndian.Uint16(header[6:8])
+	target, err := readAddress(r, net.Network_UDP)
+	if err != nil {
+		return nil, err
+	}
+	p.target = target
+	p.payload = make([]byte, size)
+	if _, err := io.ReadFull(r, p.payload); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// writePacket writes a complete Packet command, including the command header.
+func writePacket(w io.Writer, p *packet) error {
+	b := buf.New()
+	defer b.Release()
+	fixed := [10]byte{version, commandPacket}
+	binary.BigEndian.PutUint16(fixed[2:4], p.assocID)
+	binary.BigEndian.PutUint16(fixed[4:6], p.packetID)
+	fixed[6] = p.fragTotal
+	fixed[7] = p.fragID
+	binary.BigEndian.PutUint16(fixed[8:10], uint16(len(p.payload)))
+	if _, err := b.Write(fixed[:]); err != nil {
+		return err
+	}
+	if err := writeAddress(b, p.target); err != nil {
+		return err
+	}
+	header := b.Bytes()
+	message := make([]byte, 0, len(header)+len(p.payload))
+	message = append(append(message, header...), p.payload...)
+	_, err := w.Write(message)
+	return err
+}
+
+// packetHeaderSize returns the size of a Packet command without its payload.
+func packetHeaderSize(target net.Destination) int {
+	size := 2 + 8 + 1
+	if !target.IsValid() {
+		return size
+	}
+	switch target.Address.Family() {
+	case net.AddressFamilyIPv4:
+		size += 4
+	case net.AddressFamilyIPv6:
+		size += 16
+	default:
+		size += 1 + len(target.Address.Domain())
+	}
+	return size + 2
+}
+
+// fragment splits a UDP payload into Packet commands no larger than maxSize.
+func fragment(assocID, packetID uint16, target net.Destination, payload []byte, maxSize int) []*packet {
+	if packetHeaderSize(target)+len(payload) <= maxSize {
+		return []*packet{{
+			assocID:   assocID,
+			packetID:  packetID,
+			fragTotal: 1,
+			target:    target,
+			payload:   payload,
+		}}
+	}
+
+	// Only the first fragment carries the address.
+	firstSize := maxSize - packetHeaderSize(target)
+	restSize := maxSize - packetHeaderSize(net.Destination{})
+	if firstSize <= 0 || restSize <= 0 {
+		return nil
+	}
+	total := 1 + (len(payload)-firstSize+restSize-1)/restSize
+	if total > 255 {
+		return nil
+	}
+	packets := make([]*packet, 0, total)
+	for i := 0; len(payload) > 0; i++ {
+		p := &packet{
+			assocID:   assocID,
+			packetID:  packetID,
+			fragTotal: uint8(total),
+			fragID:    uint8(i),
+		}
+		size := restSize
+		if i == 0 {
+			p.target = target
+			size = firstSize
+		}
+		if size > len(payload) {
+			size = len(payload)
+		}
+		p.payload = payload[:size]
+		payload = payload[size:]
+		packets = append(packets, p)
+	}
+	return packets
+}
+
+// fragmentBuffer reassembles fragmented packets of a single UDP packet.
+type fragmentBuffer struct {
+	target    net.Destination
+	fragments [][]byte
+	received  int
+}
+
+// add adds a fragment, and returns the whole packet once complete.
+func (f *fragmentBuffer) add(p *packet) (net.Destination, []byte, bool) {
+	if f.fragments == nil {
+		f.fragments = make([][]byte, p.fragTotal)
+	}
+	if int(p.fragTotal) != len(f.fragments) || f.fragments[p.fragID] != nil {
+		return net.Destination{}, nil, false
+	}
+	f.fragments[p.fragID] = p.payload
+	f.received++
+	if p.fragID == 0 {
+		f.target = p.target
+	}
+	if f.received < len(f.fragments) {
+		return net.Destination{}, nil, false
+	}
+	return f.target, bytes.Join(f.fragments, nil), true
+}
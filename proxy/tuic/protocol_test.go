@@ -0,0 +1,70 @@
+package tuic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+func TestPacketRoundTrip(t *testing.T) {
+	target := net.UDPDestination(net.DomainAddress("example.com"), 53)
+	p := &packet{
+		assocID:   7,
+		packetID:  42,
+		fragTotal: 1,
+		target:    target,
+		payload:   []byte("hello"),
+	}
+
+	b := new(bytes.Buffer)
+	common.Must(writePacket(b, p))
+	assert.Equal(t, packetHeaderSize(target)+len(p.payload), b.Len())
+
+	cmd, err := readCommandHeader(b)
+	common.Must(err)
+	assert.Equal(t, commandPacket, cmd)
+
+	decoded, err := readPacket(b)
+	common.Must(err)
+	assert.Equal(t, p, decoded)
+}
+
+func TestFragmentReassemble(t *testing.T) {
+	target := net.UDPDestination(net.IPAddress([]byte{8, 8, 8, 8}), 53)
+	payload := make([]byte, 3000)
+	common.Must2(rand.Read(payload))
+
+	packets := fragment(1, 2, target, payload, 1200)
+	assert.Len(t, packets, 3)
+
+	fb := new(fragmentBuffer)
+	for i := len(packets) - 1; i >= 0; i-- {
+		b := new(bytes.Buffer)
+		common.Must(writePacket(b, packets[i]))
+		assert.LessOrEqual(t, b.Len(), 1200)
+
+		_, err := readCommandHeader(b)
+		common.Must(err)
+		p, err := readPacket(b)
+		common.Must(err)
+
+		dest, data, complete := fb.add(p)
+		if i > 0 {
+			assert.False(t, complete)
+			continue
+		}
+		assert.True(t, complete)
+		assert.Equal(t, target, dest)
+		assert.Equal(t, payload, data)
+	}
+}
+
+func TestFragmentTooSmall(t *testing.T) {
+	target := net.UDPDestination(net.IPAddress([]byte{8, 8, 8, 8}), 53)
+	assert.Nil(t, fragment(1, 2, target, make([]byte, 100), 10))
+}
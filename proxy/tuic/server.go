@@ -0,0 +1,421 @@
+package tuic
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/apernet/quic-go"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	udp_proto "github.com/frogwall/f2ray-core/v5/common/protocol/udp"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/udp"
+)
+
+const (
+	defaultAuthTimeout           = 3 * time.Second
+	defaultMaxUDPRelayPacketSize = 1500
+	defaultUDPIdleTimeout        = 60 * time.Second
+
+	// maxPendingFragments limits the number of partially received packets of
+	// a UDP session.
+	maxPendingFragments = 64
+	// maxQueuedPackets limits the number of received packets of a UDP session
+	// waiting to be handled, others are dropped.
+	maxQueuedPackets = 128
+)
+
+func init() {
+	common.Must(common.RegisterConfig((*ServerConfig)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
+		return NewServer(ctx, config.(*ServerConfig))
+	}))
+}
+
+// Server is an inbound connection handler that handles messages in TUIC v5 protocol.
+type Server struct {
+	policyManager         policy.Manager
+	validator             *tuicTransport.Validator
+	authTimeout           time.Duration
+	maxUDPRelayPacketSize int
+	udpIdleTimeout        time.Duration
+}
+
+// NewServer creates a new TUIC inbound handler.
+func NewServer(ctx context.Context, config *ServerConfig) (*Server, error) {
+	validator := new(tuicTransport.Validator)
+	for _, user := range config.Users {
+		u, err := user.ToMemoryUser()
+		if err != nil {
+			return nil, newError("failed to get TUIC user").Base(err).AtError()
+		}
+		if err := validator.Add(u); err != nil {
+			return nil, newError("failed to add user").Base(err).AtError()
+		}
+	}
+
+	v := core.MustFromContext(ctx)
+	server := &Server{
+		policyManager:         v.GetFeature(policy.ManagerType()).(policy.Manager),
+		validator:             validator,
+		authTimeout:           defaultAuthTimeout,
+		maxUDPRelayPacketSize: defaultMaxUDPRelayPacketSize,
+		udpIdleTimeout:        defaultUDPIdleTimeout,
+	}
+	if config.AuthTimeout > 0 {
+		server.authTimeout = time.Duration(config.AuthTimeout) * time.Millisecond
+	}
+	if config.MaxUdpRelayPacketSize > 0 {
+		server.maxUDPRelayPacketSize = int(config.MaxUdpRelayPacketSize)
+	}
+	if config.UdpIdleTimeout > 0 {
+		server.udpIdleTimeout = time.Duration(config.UdpIdleTimeout) * time.Second
+	}
+	return server, nil
+}
+
+// AddUser implements proxy.UserManager.AddUser().
+func (s *Server) AddUser(ctx context.Context, u *protocol.MemoryUser) error {
+	return s.validator.Add(u)
+}
+
+// RemoveUser implements proxy.UserManager.RemoveUser().
+func (s *Server) RemoveUser(ctx context.Context, e string) error {
+	return s.validator.Del(e)
+}
+
+// Network implements proxy.Inbound.Network().
+func (s *Server) Network() []net.Network {
+	return []net.Network{net.Network_TCP}
+}
+
+// Process implements proxy.Inbound.Process(). It serves a whole QUIC
+// connection accepted by the TUIC transport.
+func (s *Server) Process(ctx context.Context, network net.Network, conn internet.Connection, dispatcher routing.Dispatcher) error {
+	iConn := conn
+	if statConn, ok := conn.(*internet.StatCouterConnection); ok {
+		iConn = statConn.Connection // will not count the traffic of streams and datagrams.
+	}
+	quicConn, ok := iConn.(*tuicTransport.Conn)
+	if !ok {
+		return newError("TUIC inbound must be used with the tuic transport")
+	}
+
+	ss := &serverSession{
+		Session:     tuicTransport.NewSession(ctx, quicConn, dispatcher, s.policyManager, s.validator),
+		server:      s,
+		udpSessions: make(map[uint16]*udpSession),
+	}
+	go ss.AcceptUniStreams(ss.handleUniStream)
+	go ss.AcceptStreams(ss.handleStream)
+	go ss.receiveDatagrams()
+	err := ss.Serve(s.authTimeout)
+	ss.closeUDPSessions()
+	return err
+}
+
+// serverSession is the state of a single TUIC connection.
+type serverSession struct {
+	*tuicTransport.Session
+	server *Server
+
+	access      sync.Mutex
+	udpSessions map[uint16]*udpSession
+}
+
+func (ss *serverSession) handleUniStream(stream quic.ReceiveStream) error {
+	cmd, err := readCommandHeader(stream)
+	if err != nil {
+		return newError("failed to read command").Base(err)
+	}
+	switch cmd {
+	case commandAuthenticate:
+		id, token, err := readAuthenticate(stream)
+		if err != nil {
+			return newError("failed to read authentication").Base(err)
+		}
+		return ss.Authenticate(id, token)
+	case commandPacket:
+		p, err := readPacket(stream)
+		if err != nil {
+			return newError("failed to read packet").Base(err)
+		}
+		if !ss.WaitForAuth() {
+			return nil
+		}
+		ss.queuePacket(p, false)
+		return nil
+	case commandDissociate:
+		var assocID [2]byte
+		if _, err := io.ReadFull(stream, assocID[:]); err != nil {
+			return newError("failed to read dissociate").Base(err)
+		}
+		if !ss.WaitForAuth() {
+			return nil
+		}
+		ss.dissociate(uint16(assocID[0])<<8 | uint16(assocID[1]))
+		return nil
+	default:
+		return newError("unexpected command ", cmd, " on uni stream")
+	}
+}
+
+func (ss *serverSession) handleStream(ctx context.Context, stream quic.Stream) error {
+	cmd, err := readCommandHeader(stream)
+	if err != nil {
+		return newError("failed to read command").Base(err)
+	}
+	if cmd != commandConnect {
+		return newError("unexpected command ", cmd, " on bidirectional stream")
+	}
+	destination, err := readAddress(stream, net.Network_TCP)
+	if err != nil {
+		return err
+	}
+	if !destination.IsValid() {
+		return newError("missing target address")
+	}
+	if !ss.WaitForAuth() {
+		return newError("connection closed before authentication")
+	}
+	return ss.RelayStream(ctx, stream, destination)
+}
+
+func (ss *serverSession) receiveDatagrams() {
+	// Datagrams are kept by the connection until the client is authenticated,
+	// and are then handled in the order they are received.
+	if !ss.WaitForAuth() {
+		return
+	}
+	for {
+		data, err := ss.Conn.ReceiveDatagram(ss.Ctx)
+		if err != nil {
+			return
+		}
+		r := bytes.NewReader(data)
+		cmd, err := readCommandHeader(r)
+		if err != nil {
+			newError("failed to read datagram").Base(err).WriteToLog(session.ExportIDToError(ss.Ctx))
+			continue
+		}
+		switch cmd {
+		case commandHeartbeat:
+		case commandPacket:
+			p, err := readPacket(r)
+			if err != nil {
+				newError("failed to read packet").Base(err).WriteToLog(session.ExportIDToError(ss.Ctx))
+				continue
+			}
+			ss.queuePacket(p, true)
+		default:
+			newError("unexpected command ", cmd, " in datagram").WriteToLog(session.ExportIDToError(ss.Ctx))
+		}
+	}
+}
+
+// udpSession is a UDP association of a TUIC connection.
+type udpSession struct {
+	assocID    uint16
+	native     bool
+	packetID   uint32
+	timer      *signal.ActivityTimer
+	dispatcher udp.DispatcherI
+	packets    chan *packet
+	done       *done.Instance
+
+	access    sync.Mutex
+	fragments map[uint16]*fragmentBuffer
+}
+
+func (ss *serverSession) getUDPSession(assocID uint16, native bool) *udpSession {
+	ss.access.Lock()
+	defer ss.access.Unlock()
+
+	if us, found := ss.udpSessions[assocID]; found {
+		return us
+	}
+
+	us := &udpSession{
+		assocID:   assocID,
+		native:    native,
+		packets:   make(chan *packet, maxQueuedPackets),
+		done:      done.New(),
+		fragments: make(map[uint16]*fragmentBuffer),
+	}
+	us.dispatcher = udp.NewSplitDispatcher(ss.Dispatcher, func(ctx context.Context, packet *udp_proto.Packet) {
+		defer packet.Payload.Release()
+		if err := ss.writePacket(us, packet.Source, packet.Payload.Bytes()); err != nil {
+			newError("failed to write response").Base(err).AtWarning().WriteToLog(session.ExportIDToError(ctx))
+		}
+	})
+	us.timer = signal.CancelAfterInactivity(ss.Ctx, func() {
+		ss.dissociate(assocID)
+	}, ss.server.udpIdleTimeout)
+	ss.udpSessions[assocID] = us
+	go ss.handlePackets(us)
+	return us
+}
+
+func (ss *serverSession) dissociate(assocID uint16) {
+	ss.access.Lock()
+	us, found := ss.udpSessions[assocID]
+	delete(ss.udpSessions, assocID)
+	ss.access.Unlock()
+
+	if found {
+		us.done.Close()
+		us.timer.SetTimeout(0)
+		us.dispatcher.Close()
+	}
+}
+
+func (ss *serverSession) closeUDPSessions() {
+	ss.access.Lock()
+	sessions := ss.udpSessions
+	ss.udpSessions = make(map[uint16]*udpSession)
+	ss.access.Unlock()
+
+	for _, us := range sessions {
+		us.done.Close()
+		us.timer.SetTimeout(0)
+		us.dispatcher.Close()
+	}
+}
+
+// queuePacket queues a received packet to its UDP session, whose packets are
+// handled one by one in a goroutine, so they are relayed in order.
+func (ss *serverSession) queuePacket(p *packet, native bool) {
+	us := ss.getUDPSession(p.assocID, native)
+	select {
+	case us.packets <- p:
+	default:
+		newError("dropping UDP packet of association ", p.assocID, " as the queue is full").AtDebug().WriteToLog(session.ExportIDToError(ss.Ctx))
+	}
+}
+
+func (ss *serverSession) handlePackets(us *udpSession) {
+	for {
+		select {
+		case p := <-us.packets:
+			ss.handlePacket(us, p)
+		case <-us.done.Wait():
+			return
+		}
+	}
+}
+
+func (ss *serverSession) handlePacket(us *udpSession, p *packet) {
+	us.timer.Update()
+
+	target, payload := p.target, p.payload
+	if p.fragTotal > 1 {
+		var complete bool
+		us.access.Lock()
+		fb, found := us.fragments[p.packetID]
+		if !found {
+			if len(us.fragments) >= maxPendingFragments {
+				us.fragments = make(map[uint16]*fragmentBuffer)
+			}
+			fb = new(fragmentBuffer)
+			us.fragments[p.packetID] = fb
+		}
+		target, payload, complete = fb.add(p)
+		if complete {
+			delete(us.fragments, p.packetID)
+		}
+		us.access.Unlock()
+		if !complete {
+			return
+		}
+	}
+
+	if !target.IsValid() {
+		newError("missing target address of UDP packet").WriteToLog(session.ExportIDToError(ss.Ctx))
+		return
+	}
+	if len(payload) > ss.server.maxUDPRelayPacketSize {
+		newError("dropping UDP packet of ", len(payload), " bytes to ", target).AtDebug().WriteToLog(session.ExportIDToError(ss.Ctx))
+		return
+	}
+
+	ctx := log.ContextWithAccessMessage(ss.Ctx, &log.AccessMessage{
+		From:   ss.Inbound.Source,
+		To:     target,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  ss.User().Email,
+	})
+	newError("tunnelling request to ", target).WriteToLog(session.ExportIDToError(ctx))
+
+	b := buf.New()
+	if _, err := b.Write(payload); err != nil {
+		b.Release()
+		newError("UDP packet of ", len(payload), " bytes is too large").Base(err).WriteToLog(session.ExportIDToError(ctx))
+		return
+	}
+	us.dispatcher.Dispatch(ctx, target, b)
+}
+
+// writePacket sends a UDP packet back to the client in the relay mode used by
+// the client for the association.
+func (ss *serverSession) writePacket(us *udpSession, source net.Destination, payload []byte) error {
+	us.timer.Update()
+	packetID := uint16(atomic.AddUint32(&us.packetID, 1))
+
+	if !us.native {
+		stream, err := ss.Conn.OpenUniStream()
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		return writePacket(stream, &packet{
+			assocID:   us.assocID,
+			packetID:  packetID,
+			fragTotal: 1,
+			target:    source,
+			payload:   payload,
+		})
+	}
+
+	maxSize := packetHeaderSize(source) + len(payload)
+	for {
+		packets := fragment(us.assocID, packetID, source, payload, maxSize)
+		if len(packets) == 0 {
+			return newError("UDP packet of ", len(payload), " bytes is too large to send")
+		}
+		err := ss.sendPackets(packets)
+		var tooLarge *quic.DatagramTooLargeError
+		if !errors.As(err, &tooLarge) || int(tooLarge.MaxDataLen) >= maxSize {
+			return err
+		}
+		maxSize = int(tooLarge.MaxDataLen)
+	}
+}
+
+func (ss *serverSession) sendPackets(packets []*packet) error {
+	for _, p := range packets {
+		b := new(bytes.Buffer)
+		if err := writePacket(b, p); err != nil {
+			return err
+		}
+		if err := ss.Conn.SendDatagram(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
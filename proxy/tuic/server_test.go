@@ -0,0 +1,213 @@
+package tuic_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	core "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/dispatcher"
+	"github.com/frogwall/f2ray-core/v5/app/proxyman"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/inbound"
+	_ "github.com/frogwall/f2ray-core/v5/app/proxyman/outbound"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/protocol/tls/cert"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/proxy/dokodemo"
+	"github.com/frogwall/f2ray-core/v5/proxy/freedom"
+	"github.com/frogwall/f2ray-core/v5/proxy/tuic"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/tcp"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/udp"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/tls"
+	tuicTransport "github.com/frogwall/f2ray-core/v5/transport/internet/tuic"
+)
+
+func startInstance(t *testing.T, config *core.Config) {
+	server, err := core.New(config)
+	common.Must(err)
+	common.Must(server.Start())
+	t.Cleanup(func() { server.Close() })
+}
+
+func TestTUICClientServer(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: bytes.ToUpper,
+	}
+	tcpDest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	// The UDP server records the packets in the order they are relayed by the
+	// TUIC server.
+	var udpAccess sync.Mutex
+	var udpReceived []string
+	udpServer := udp.Server{
+		MsgProcessor: func(msg []byte) []byte {
+			udpAccess.Lock()
+			udpReceived = append(udpReceived, string(msg))
+			udpAccess.Unlock()
+			return bytes.ToUpper(msg)
+		},
+	}
+	udpDest, err := udpServer.Start()
+	common.Must(err)
+	defer udpServer.Close()
+
+	id := uuid.New()
+	apps := []*anypb.Any{
+		serial.ToTypedMessage(&dispatcher.Config{}),
+		serial.ToTypedMessage(&proxyman.InboundConfig{}),
+		serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+	}
+
+	serverPort := udp.PickPort()
+	startInstance(t, &core.Config{
+		App: apps,
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(serverPort),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+					StreamSettings: &internet.StreamConfig{
+						ProtocolName: "tuic",
+						SecurityType: serial.GetMessageType(&tls.Config{}),
+						SecuritySettings: []*anypb.Any{
+							serial.ToTypedMessage(&tls.Config{
+								Certificate: []*tls.Certificate{tls.ParseCertificate(cert.MustGenerate(nil))},
+							}),
+						},
+						TransportSettings: []*internet.TransportConfig{
+							{
+								ProtocolName: "tuic",
+								Settings:     serial.ToTypedMessage(&tuicTransport.Config{}),
+							},
+						},
+					},
+				}),
+				ProxySettings: serial.ToTypedMessage(&tuic.ServerConfig{
+					Users: []*protocol.User{
+						{
+							Email: "love@v2fly.org",
+							Account: serial.ToTypedMessage(&tuic.Account{
+								Uuid:     id.String(),
+								Password: "password",
+							}),
+						},
+					},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{ProxySettings: serial.ToTypedMessage(&freedom.Config{})},
+		},
+	})
+
+	tcpPort := tcp.PickPort()
+	udpPort := udp.PickPort()
+	dokodemoInbound := func(port net.Port, dest net.Destination) *core.InboundHandlerConfig {
+		return &core.InboundHandlerConfig{
+			ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+				PortRange: net.SinglePortRange(port),
+				Listen:    net.NewIPOrDomain(net.LocalHostIP),
+			}),
+			ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+				Address:     net.NewIPOrDomain(dest.Address),
+				Port:        uint32(dest.Port),
+				NetworkList: &net.NetworkList{Network: []net.Network{dest.Network}},
+			}),
+		}
+	}
+	startInstance(t, &core.Config{
+		App: apps,
+		Inbound: []*core.InboundHandlerConfig{
+			dokodemoInbound(tcpPort, tcpDest),
+			dokodemoInbound(udpPort, udpDest),
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				ProxySettings: serial.ToTypedMessage(&tuic.ClientConfig{
+					Server: []*protocol.ServerEndpoint{
+						{
+							Address: net.NewIPOrDomain(net.LocalHostIP),
+							Port:    uint32(serverPort),
+							User: []*protocol.User{
+								{
+									Account: serial.ToTypedMessage(&tuic.Account{
+										Uuid:     id.String(),
+										Password: "password",
+									}),
+								},
+							},
+						},
+					},
+					UdpRelayMode: "native",
+					Tls: &tuic.TLSConfig{
+						ServerName:    "www.v2fly.org",
+						Alpn:          []string{"h3"},
+						AllowInsecure: true,
+					},
+				}),
+			},
+		},
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		conn, err := net.DialTCP("tcp", nil, &net.TCPAddr{IP: []byte{127, 0, 0, 1}, Port: int(tcpPort)})
+		common.Must(err)
+		defer conn.Close()
+
+		payload := bytes.Repeat([]byte("tuic"), 16*1024)
+		common.Must2(conn.Write(payload))
+		common.Must(conn.SetReadDeadline(time.Now().Add(10 * time.Second)))
+		response := make([]byte, len(payload))
+		common.Must2(io.ReadFull(conn, response))
+		if !bytes.Equal(response, bytes.ToUpper(payload)) {
+			t.Error("unexpected response over TCP")
+		}
+	})
+
+	t.Run("udp", func(t *testing.T) {
+		conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: []byte{127, 0, 0, 1}, Port: int(udpPort)})
+		common.Must(err)
+		defer conn.Close()
+
+		// The association is set up by the first packet.
+		common.Must2(conn.Write([]byte("first")))
+		common.Must(conn.SetReadDeadline(time.Now().Add(10 * time.Second)))
+		response := make([]byte, 1024)
+		n, err := conn.Read(response)
+		common.Must(err)
+		if string(response[:n]) != "FIRST" {
+			t.Fatal("unexpected response over UDP: ", string(response[:n]))
+		}
+
+		// Packets of an association are relayed by the server in order. The
+		// client may deliver the responses out of order.
+		const count = 32
+		expected := []string{"first"}
+		for i := 0; i < count; i++ {
+			packet := fmt.Sprint("packet ", i)
+			expected = append(expected, packet)
+			common.Must2(conn.Write([]byte(packet)))
+		}
+		for i := 0; i < count; i++ {
+			_, err := conn.Read(response)
+			common.Must(err)
+		}
+		udpAccess.Lock()
+		defer udpAccess.Unlock()
+		if !reflect.DeepEqual(udpReceived, expected) {
+			t.Error("unexpected order of relayed packets: ", udpReceived)
+		}
+	})
+}
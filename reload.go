@@ -0,0 +1,383 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/features"
+	"github.com/frogwall/f2ray-core/v5/features/inbound"
+	"github.com/frogwall/f2ray-core/v5/features/outbound"
+)
+
+// ErrRestartRequired is returned by Instance.Reload if the changes of the
+// config cannot be applied to a running instance.
+var ErrRestartRequired = newError("the changes of config require a restart")
+
+// ReloadDrainTime is how long a replaced or removed handler is kept open after
+// a reload, so that its connections can finish.
+var ReloadDrainTime = time.Minute
+
+type handlerConfig interface {
+	proto.Message
+	GetTag() string
+}
+
+// handlerDiff is the difference of tagged handlers between two configs.
+type handlerDiff struct {
+	removed []string
+	added   []handlerConfig
+	changed []handlerConfig
+}
+
+// diffHandlers compares handlers by tag. Untagged handlers cannot be told
+// apart, so they must stay the same.
+func diffHandlers(oldConfigs, newConfigs []handlerConfig) (*handlerDiff, error) {
+	var oldUntagged, newUntagged []handlerConfig
+	oldTagged := make(map[string]handlerConfig)
+	for _, c := range oldConfigs {
+		if c.GetTag() == "" {
+			oldUntagged = append(oldUntagged, c)
+		} else {
+			oldTagged[c.GetTag()] = c
+		}
+	}
+
+	diff := new(handlerDiff)
+	newTags := make(map[string]bool)
+	for _, c := range newConfigs {
+		tag := c.GetTag()
+		if tag == "" {
+			newUntagged = append(newUntagged, c)
+			continue
+		}
+		newTags[tag] = true
+		switch old, found := oldTagged[tag]; {
+		case !found:
+			diff.added = append(diff.added, c)
+		case !equalConfig(old, c):
+			diff.changed = append(diff.changed, c)
+		}
+	}
+	for tag := range oldTagged {
+		if !newTags[tag] {
+			diff.removed = append(diff.removed, tag)
+		}
+	}
+
+	if len(oldUntagged) != len(newUntagged) {
+		return nil, newError("untagged handlers are changed").Base(ErrRestartRequired)
+	}
+	for i := range oldUntagged {
+		if !equalConfig(oldUntagged[i], newUntagged[i]) {
+			return nil, newError("untagged handlers are changed").Base(ErrRestartRequired)
+		}
+	}
+	return diff, nil
+}
+
+// equalConfig compares two configs. Unlike proto.Equal, the messages in Any
+// fields are compared by content, as their encoding may differ even if the
+// same config is loaded twice.
+func equalConfig(a, b proto.Message) bool {
+	return cmp.Equal(a, b, protocmp.Transform())
+}
+
+func equalAnys(a, b []*anypb.Any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalConfig(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reload applies a new config to the running instance. Only the inbound and
+// outbound handlers that are changed are replaced, and the apps that are
+// changed are reloaded in place, such as the router and DNS. Replaced
+// handlers stop accepting connections at once, and their existing
+// connections are given ReloadDrainTime to finish.
+//
+// The changed apps and handlers are all built, and the new inbound handlers
+// are bound, before anything is replaced, so a bad config or a port in use
+// leaves the instance untouched. If the changes cannot be applied without a
+// restart, an error caused by ErrRestartRequired is returned, and nothing is
+// changed either.
+func (s *Instance) Reload(config *Config) error {
+	s.access.Lock()
+	defer s.access.Unlock()
+
+	if !equalConfig(s.config.Transport, config.Transport) {
+		return newError("global transport settings are changed").Base(ErrRestartRequired)
+	}
+	if !equalAnys(s.config.Extension, config.Extension) {
+		return newError("extensions are changed").Base(ErrRestartRequired)
+	}
+
+	var oldInbounds, newInbounds, oldOutbounds, newOutbounds []handlerConfig
+	for _, c := range s.config.Inbound {
+		oldInbounds = append(oldInbounds, c)
+	}
+	for _, c := range config.Inbound {
+		newInbounds = append(newInbounds, c)
+	}
+	for _, c := range s.config.Outbound {
+		oldOutbounds = append(oldOutbounds, c)
+	}
+	for _, c := range config.Outbound {
+		newOutbounds = append(newOutbounds, c)
+	}
+	inboundDiff, err := diffHandlers(oldInbounds, newInbounds)
+	if err != nil {
+		return err
+	}
+	outboundDiff, err := diffHandlers(oldOutbounds, newOutbounds)
+	if err != nil {
+		return err
+	}
+	if len(s.config.Outbound) > 0 && len(config.Outbound) > 0 && s.config.Outbound[0].Tag != config.Outbound[0].Tag {
+		return newError("default outbound is changed").Base(ErrRestartRequired)
+	}
+
+	ihm, ok := s.GetFeature(inbound.ManagerType()).(inbound.HandlerDetacher)
+	if !ok {
+		return newError("inbound handlers cannot be replaced").Base(ErrRestartRequired)
+	}
+	ohm, ok := s.GetFeature(outbound.ManagerType()).(outbound.HandlerDetacher)
+	if !ok {
+		return newError("outbound handlers cannot be replaced").Base(ErrRestartRequired)
+	}
+
+	commitApps, err := s.prepareApps(config.App)
+	if err != nil {
+		return err
+	}
+
+	inboundHandlers, err := s.createInboundHandlers(append(inboundDiff.added, inboundDiff.changed...))
+	if err != nil {
+		return err
+	}
+	outboundHandlers, err := s.createOutboundHandlers(append(outboundDiff.added, outboundDiff.changed...))
+	if err != nil {
+		return err
+	}
+
+	var replacedInbounds []string
+	replacedInbounds = append(replacedInbounds, inboundDiff.removed...)
+	for _, handler := range inboundHandlers {
+		replacedInbounds = append(replacedInbounds, handler.Tag())
+	}
+	unbindInbounds, err := s.bindInboundHandlers(replacedInbounds, inboundHandlers)
+	if err != nil {
+		return err
+	}
+
+	// New outbound handlers are added before the router is switched, so that
+	// new rules can use them.
+	oldOutboundHandlers := make([]outbound.Handler, 0, len(outboundHandlers))
+	for _, handler := range outboundHandlers {
+		oldHandler, err := ohm.ReplaceHandler(s.ctx, handler)
+		oldOutboundHandlers = append(oldOutboundHandlers, oldHandler)
+		if err != nil {
+			for i := len(oldOutboundHandlers) - 1; i >= 0; i-- {
+				if oldHandler := oldOutboundHandlers[i]; oldHandler != nil {
+					ohm.ReplaceHandler(s.ctx, oldHandler)
+				} else {
+					ohm.DetachHandler(s.ctx, outboundHandlers[i].Tag())
+				}
+				outboundHandlers[i].Close()
+			}
+			unbindInbounds()
+			return newError("failed to start outbound ", handler.Tag()).Base(err)
+		}
+	}
+
+	// From here on nothing fails.
+	for _, commit := range commitApps {
+		commit()
+	}
+
+	for _, tag := range inboundDiff.removed {
+		if oldHandler, err := ihm.DetachHandler(s.ctx, tag); err == nil {
+			s.drainInboundHandler(oldHandler)
+		}
+	}
+	for _, handler := range inboundHandlers {
+		if oldHandler, _ := ihm.AttachHandler(s.ctx, handler); oldHandler != nil {
+			s.drainInboundHandler(oldHandler)
+		}
+	}
+
+	for _, oldHandler := range oldOutboundHandlers {
+		if oldHandler != nil {
+			s.drainOutboundHandler(oldHandler)
+		}
+	}
+	for _, tag := range outboundDiff.removed {
+		if oldHandler, err := ohm.DetachHandler(s.ctx, tag); err == nil {
+			s.drainOutboundHandler(oldHandler)
+		}
+	}
+
+	s.config = config
+	newError("config reloaded: ",
+		len(inboundDiff.added), " inbound(s) added, ", len(inboundDiff.changed), " replaced, ", len(inboundDiff.removed), " removed; ",
+		len(outboundDiff.added), " outbound(s) added, ", len(outboundDiff.changed), " replaced, ", len(outboundDiff.removed), " removed; ",
+		len(commitApps), " app(s) reloaded").AtInfo().WriteToLog()
+	return nil
+}
+
+// bindInboundHandlers starts the new inbound handlers of a running instance.
+// The listeners of the handlers with the given tags are closed first, so that
+// the new handlers can listen on the same ports. If any of the new handlers
+// fails to start, the new handlers are closed and the old ones listen again.
+// Otherwise, the returned function does so.
+func (s *Instance) bindInboundHandlers(tags []string, handlers []inbound.Handler) (func(), error) {
+	if !s.running {
+		return func() {}, nil
+	}
+
+	ihm := s.GetFeature(inbound.ManagerType()).(inbound.Manager)
+	var paused, started []inbound.Handler
+	unbind := func() {
+		for _, handler := range started {
+			if err := handler.Close(); err != nil {
+				newError("failed to close inbound ", handler.Tag()).Base(err).AtWarning().WriteToLog()
+			}
+		}
+		for _, handler := range paused {
+			if err := handler.Start(); err != nil {
+				newError("failed to restart inbound ", handler.Tag()).Base(err).AtError().WriteToLog()
+			}
+		}
+	}
+
+	for _, tag := range tags {
+		handler, err := ihm.GetHandler(s.ctx, tag)
+		if err != nil {
+			continue
+		}
+		if closer, ok := handler.(inbound.ListenerCloser); ok {
+			err = closer.CloseListeners()
+		} else {
+			err = handler.Close()
+		}
+		paused = append(paused, handler)
+		if err != nil {
+			unbind()
+			return nil, newError("failed to stop inbound ", tag).Base(err)
+		}
+	}
+	for _, handler := range handlers {
+		started = append(started, handler)
+		if err := handler.Start(); err != nil {
+			unbind()
+			return nil, newError("failed to start inbound ", handler.Tag()).Base(err)
+		}
+	}
+	return unbind, nil
+}
+
+// prepareApps prepares the reload of the apps that are changed.
+func (s *Instance) prepareApps(apps []*anypb.Any) ([]func(), error) {
+	if len(s.config.App) != len(apps) {
+		return nil, newError("apps are added or removed").Base(ErrRestartRequired)
+	}
+	var commits []func()
+	for i, app := range apps {
+		oldApp := s.config.App[i]
+		if oldApp.TypeUrl != app.TypeUrl {
+			return nil, newError("apps are added or removed").Base(ErrRestartRequired)
+		}
+		if equalConfig(oldApp, app) {
+			continue
+		}
+		reloadable, ok := s.apps[i].(features.Reloadable)
+		if !ok {
+			return nil, newError("app ", app.TypeUrl, " cannot be reloaded").Base(ErrRestartRequired)
+		}
+		settings, err := serial.GetInstanceOf(app)
+		if err != nil {
+			return nil, err
+		}
+		commit, err := reloadable.PrepareReload(settings)
+		if err != nil {
+			return nil, newError("failed to reload app ", app.TypeUrl).Base(err)
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+func (s *Instance) createInboundHandlers(configs []handlerConfig) ([]inbound.Handler, error) {
+	handlers := make([]inbound.Handler, 0, len(configs))
+	for _, config := range configs {
+		rawHandler, err := CreateObjectWithEnvironment(s, config, s.env.ProxyEnvironment("i"+config.GetTag()))
+		if err != nil {
+			return nil, newError("failed to create inbound ", config.GetTag()).Base(err)
+		}
+		handler, ok := rawHandler.(inbound.Handler)
+		if !ok {
+			return nil, newError("not an InboundHandler")
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+func (s *Instance) createOutboundHandlers(configs []handlerConfig) ([]outbound.Handler, error) {
+	handlers := make([]outbound.Handler, 0, len(configs))
+	for _, config := range configs {
+		rawHandler, err := CreateObjectWithEnvironment(s, config, s.env.ProxyEnvironment("o"+config.GetTag()))
+		if err != nil {
+			return nil, newError("failed to create outbound ", config.GetTag()).Base(err)
+		}
+		handler, ok := rawHandler.(outbound.Handler)
+		if !ok {
+			return nil, newError("not an OutboundHandler")
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+// drainInboundHandler closes an inbound handler that is no longer in use
+// after ReloadDrainTime.
+func (s *Instance) drainInboundHandler(handler inbound.Handler) {
+	time.AfterFunc(ReloadDrainTime, func() {
+		tag := handler.Tag()
+		if err := handler.Close(); err != nil {
+			newError("failed to close inbound ", tag).Base(err).AtWarning().WriteToLog()
+		}
+		ihm := s.GetFeature(inbound.ManagerType()).(inbound.Manager)
+		if _, err := ihm.GetHandler(s.ctx, tag); err != nil {
+			if err := s.env.DropProxyEnvironment("i" + tag); err != nil {
+				newError("failed to drop environment of inbound ", tag).Base(err).AtDebug().WriteToLog()
+			}
+		}
+	})
+}
+
+// drainOutboundHandler closes an outbound handler that is no longer in use
+// after ReloadDrainTime.
+func (s *Instance) drainOutboundHandler(handler outbound.Handler) {
+	time.AfterFunc(ReloadDrainTime, func() {
+		tag := handler.Tag()
+		if err := handler.Close(); err != nil {
+			newError("failed to close outbound ", tag).Base(err).AtWarning().WriteToLog()
+		}
+		ohm := s.GetFeature(outbound.ManagerType()).(outbound.Manager)
+		if ohm.GetHandler(tag) == nil {
+			if err := s.env.DropProxyEnvironment("o" + tag); err != nil {
+				newError("failed to drop environment of outbound ", tag).Base(err).AtDebug().WriteToLog()
+			}
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package core_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	. "github.com/frogwall/f2ray-core/v5"
+	"github.com/frogwall/f2ray-core/v5/app/dispatcher"
+	"github.com/frogwall/f2ray-core/v5/app/policy"
+	"github.com/frogwall/f2ray-core/v5/app/proxyman"
+	"github.com/frogwall/f2ray-core/v5/app/router"
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/errors"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/features/outbound"
+	"github.com/frogwall/f2ray-core/v5/proxy/blackhole"
+	"github.com/frogwall/f2ray-core/v5/proxy/dokodemo"
+	"github.com/frogwall/f2ray-core/v5/proxy/freedom"
+	"github.com/frogwall/f2ray-core/v5/testing/servers/tcp"
+)
+
+func dokodemoInbound(tag string, port net.Port, dest net.Destination) *InboundHandlerConfig {
+	return &InboundHandlerConfig{
+		Tag: tag,
+		ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+			PortRange: net.SinglePortRange(port),
+			Listen:    net.NewIPOrDomain(net.LocalHostIP),
+		}),
+		ProxySettings: serial.ToTypedMessage(&dokodemo.Config{
+			Address:     net.NewIPOrDomain(dest.Address),
+			Port:        uint32(dest.Port),
+			NetworkList: &net.NetworkList{Network: []net.Network{net.Network_TCP}},
+		}),
+	}
+}
+
+func dialLocal(port net.Port) net.Conn {
+	conn, err := net.DialTCP("tcp", nil, &net.TCPAddr{IP: []byte{127, 0, 0, 1}, Port: int(port)})
+	common.Must(err)
+	return conn
+}
+
+func assertEcho(t *testing.T, conn net.Conn, msg, expected string) {
+	common.Must2(conn.Write([]byte(msg)))
+	common.Must(conn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	response := make([]byte, len(expected))
+	common.Must2(io.ReadFull(conn, response))
+	assert.Equal(t, expected, string(response))
+}
+
+func TestInstanceReload(t *testing.T) {
+	tcpServer := tcp.Server{
+		MsgProcessor: func(msg []byte) []byte { return msg },
+	}
+	dest, err := tcpServer.Start()
+	common.Must(err)
+	defer tcpServer.Close()
+
+	port := tcp.PickPort()
+	config := &Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.InboundConfig{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+			serial.ToTypedMessage(&router.Config{}),
+		},
+		Inbound: []*InboundHandlerConfig{dokodemoInbound("in", port, dest)},
+		Outbound: []*OutboundHandlerConfig{
+			{Tag: "direct", ProxySettings: serial.ToTypedMessage(&freedom.Config{})},
+			{Tag: "block", ProxySettings: serial.ToTypedMessage(&blackhole.Config{})},
+		},
+	}
+	server, err := New(config)
+	common.Must(err)
+	common.Must(server.Start())
+	defer server.Close()
+
+	conn := dialLocal(port)
+	defer conn.Close()
+	echo := func() {
+		assertEcho(t, conn, "ping", "ping")
+	}
+	echo()
+
+	newPort := tcp.PickPort()
+	newConfig := &Config{
+		App: []*anypb.Any{
+			serial.ToTypedMessage(&dispatcher.Config{}),
+			serial.ToTypedMessage(&proxyman.InboundConfig{}),
+			serial.ToTypedMessage(&proxyman.OutboundConfig{}),
+			serial.ToTypedMessage(&router.Config{
+				Rule: []*router.RoutingRule{
+					{TargetTag: &router.RoutingRule_Tag{Tag: "proxy"}, InboundTag: []string{"in2"}},
+				},
+			}),
+		},
+		Inbound: []*InboundHandlerConfig{
+			dokodemoInbound("in", port, dest),
+			dokodemoInbound("in2", newPort, dest),
+		},
+		Outbound: []*OutboundHandlerConfig{
+			{Tag: "direct", ProxySettings: serial.ToTypedMessage(&freedom.Config{})},
+			{Tag: "proxy", ProxySettings: serial.ToTypedMessage(&freedom.Config{})},
+		},
+	}
+	common.Must(server.Reload(newConfig))
+
+	// The connection of the unchanged inbound is kept.
+	echo()
+
+	ohm := server.GetFeature(outbound.ManagerType()).(outbound.Manager)
+	assert.NotNil(t, ohm.GetHandler("proxy"))
+	assert.Nil(t, ohm.GetHandler("block"))
+
+	dialLocal(newPort).Close()
+
+	badConfig := &Config{
+		App:      newConfig.App,
+		Inbound:  newConfig.Inbound,
+		Outbound: append(newConfig.Outbound, &OutboundHandlerConfig{Tag: "bad", ProxySettings: serial.ToTypedMessage(&router.Config{})}),
+	}
+	assert.Error(t, server.Reload(badConfig))
+	assert.Nil(t, ohm.GetHandler("bad"))
+	assert.NotNil(t, ohm.GetHandler("proxy"))
+
+	upperServer := tcp.Server{
+		MsgProcessor: bytes.ToUpper,
+	}
+	upperDest, err := upperServer.Start()
+	common.Must(err)
+	defer upperServer.Close()
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	defer busy.Close()
+	busyPort := net.Port(busy.Addr().(*net.TCPAddr).Port)
+	upperConfig := &Config{
+		App: newConfig.App,
+		Inbound: []*InboundHandlerConfig{
+			dokodemoInbound("in", port, upperDest),
+			dokodemoInbound("in2", newPort, dest),
+			dokodemoInbound("in3", busyPort, dest),
+		},
+		Outbound: newConfig.Outbound,
+	}
+	assert.Error(t, server.Reload(upperConfig))
+
+	// The inbound to be replaced listens again after the failed reload.
+	echo()
+	conn3 := dialLocal(port)
+	assertEcho(t, conn3, "ping", "ping")
+	conn3.Close()
+
+	// The config is not changed by the failed reload, so that all changes are
+	// applied once the port is free.
+	common.Must(busy.Close())
+	common.Must(server.Reload(upperConfig))
+	conn4 := dialLocal(busyPort)
+	assertEcho(t, conn4, "ping", "ping")
+	conn4.Close()
+
+	// The connection of the replaced inbound is drained, while new ones are
+	// handled by the new inbound.
+	echo()
+	conn5 := dialLocal(port)
+	assertEcho(t, conn5, "ping", "PING")
+	conn5.Close()
+
+	restartConfig := &Config{
+		App:      append(newConfig.App, serial.ToTypedMessage(&policy.Config{})),
+		Inbound:  newConfig.Inbound,
+		Outbound: newConfig.Outbound,
+	}
+	err = server.Reload(restartConfig)
+	assert.Equal(t, ErrRestartRequired, errors.Cause(err))
+	echo()
+}
@@ -122,7 +122,7 @@ func genTestBinaryPath() {
 }
 
 func GetSourcePath() string {
-	return filepath.Join("github.com", "v2fly", "v2ray-core", "v5", "main")
+	return filepath.Join("github.com", "frogwall", "f2ray-core", "v5", "main")
 }
 
 func CloseAllServers(servers []*exec.Cmd) {
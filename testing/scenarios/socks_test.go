@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	xproxy "golang.org/x/net/proxy"
 	"google.golang.org/protobuf/types/known/anypb"
 	socks4 "h12.io/socks"
@@ -12,13 +13,19 @@ import (
 	"github.com/frogwall/f2ray-core/v5/app/proxyman"
 	"github.com/frogwall/f2ray-core/v5/app/router"
 	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
 	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/net/packetaddr"
 	"github.com/frogwall/f2ray-core/v5/common/protocol"
 	"github.com/frogwall/f2ray-core/v5/common/serial"
+	"github.com/frogwall/f2ray-core/v5/common/uuid"
 	"github.com/frogwall/f2ray-core/v5/proxy/blackhole"
 	"github.com/frogwall/f2ray-core/v5/proxy/dokodemo"
 	"github.com/frogwall/f2ray-core/v5/proxy/freedom"
 	"github.com/frogwall/f2ray-core/v5/proxy/socks"
+	"github.com/frogwall/f2ray-core/v5/proxy/vmess"
+	"github.com/frogwall/f2ray-core/v5/proxy/vmess/inbound"
+	"github.com/frogwall/f2ray-core/v5/proxy/vmess/outbound"
 	"github.com/frogwall/f2ray-core/v5/testing/servers/tcp"
 	"github.com/frogwall/f2ray-core/v5/testing/servers/udp"
 )
@@ -370,3 +377,137 @@ func TestSocksConformanceMod(t *testing.T) {
 		}
 	}
 }
+
+func TestSocksUDPOverMux(t *testing.T) {
+	var dests []net.Destination
+	for range 2 {
+		udpServer := udp.Server{
+			MsgProcessor: xor,
+		}
+		dest, err := udpServer.Start()
+		common.Must(err)
+		defer udpServer.Close()
+		dests = append(dests, dest)
+	}
+
+	userID := protocol.NewID(uuid.New())
+	serverPort := tcp.PickPort()
+	serverConfig := &core.Config{
+		Inbound: []*core.InboundHandlerConfig{
+			{
+				ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+					PortRange: net.SinglePortRange(serverPort),
+					Listen:    net.NewIPOrDomain(net.LocalHostIP),
+				}),
+				ProxySettings: serial.ToTypedMessage(&inbound.Config{
+					User: []*protocol.User{
+						{
+							Account: serial.ToTypedMessage(&vmess.Account{
+								Id: userID.String(),
+							}),
+						},
+					},
+				}),
+			},
+		},
+		Outbound: []*core.OutboundHandlerConfig{
+			{
+				ProxySettings: serial.ToTypedMessage(&freedom.Config{}),
+			},
+		},
+	}
+
+	// Packets of socks are carried in a Mux session for each destination by
+	// default, or in a single XUDP session with the packet encoding.
+	for _, packetEncoding := range []packetaddr.PacketAddrType{packetaddr.PacketAddrType_None, packetaddr.PacketAddrType_Packet} {
+		t.Run(packetEncoding.String(), func(t *testing.T) {
+			clientPort := tcp.PickPort()
+			clientConfig := &core.Config{
+				Inbound: []*core.InboundHandlerConfig{
+					{
+						ReceiverSettings: serial.ToTypedMessage(&proxyman.ReceiverConfig{
+							PortRange: net.SinglePortRange(clientPort),
+							Listen:    net.NewIPOrDomain(net.LocalHostIP),
+						}),
+						ProxySettings: serial.ToTypedMessage(&socks.ServerConfig{
+							AuthType:       socks.AuthType_NO_AUTH,
+							Address:        net.NewIPOrDomain(net.LocalHostIP),
+							UdpEnabled:     true,
+							PacketEncoding: packetEncoding,
+						}),
+					},
+				},
+				Outbound: []*core.OutboundHandlerConfig{
+					{
+						SenderSettings: serial.ToTypedMessage(&proxyman.SenderConfig{
+							MultiplexSettings: &proxyman.MultiplexingConfig{
+								Enabled:     true,
+								Concurrency: 4,
+							},
+						}),
+						ProxySettings: serial.ToTypedMessage(&outbound.Config{
+							Receiver: []*protocol.ServerEndpoint{
+								{
+									Address: net.NewIPOrDomain(net.LocalHostIP),
+									Port:    uint32(serverPort),
+									User: []*protocol.User{
+										{
+											Account: serial.ToTypedMessage(&vmess.Account{
+												Id: userID.String(),
+												SecuritySettings: &protocol.SecurityConfig{
+													Type: protocol.SecurityType_AES128_GCM,
+												},
+											}),
+										},
+									},
+								},
+							},
+						}),
+					},
+				},
+			}
+
+			servers, err := InitializeServerConfigs(serverConfig, clientConfig)
+			common.Must(err)
+			defer CloseAllServers(servers)
+
+			conn, err := net.Dial("tcp", net.TCPDestination(net.LocalHostIP, clientPort).NetAddr())
+			common.Must(err)
+			defer conn.Close()
+			relay, err := socks.ClientHandshake(&protocol.RequestHeader{
+				Command: protocol.RequestCommandUDP,
+				Address: net.AnyIP,
+				Port:    0,
+			}, conn, conn, false)
+			common.Must(err)
+
+			udpConn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: relay.Address.IP(), Port: int(relay.Port)})
+			common.Must(err)
+			defer udpConn.Close()
+
+			// The packets of the association to different destinations come
+			// back from their own destinations.
+			for i := 0; i < 4; i++ {
+				dest := dests[i%len(dests)]
+				payload := []byte("socks udp over mux")
+				request, err := socks.EncodeUDPPacketFromAddress(dest, payload)
+				common.Must(err)
+				common.Must2(udpConn.Write(request.Bytes()))
+				request.Release()
+
+				response := buf.New()
+				common.Must(udpConn.SetReadDeadline(time.Now().Add(5 * time.Second)))
+				common.Must2(response.ReadFrom(udpConn))
+				source, err := socks.DecodeUDPPacket(response)
+				common.Must(err)
+				if source.Destination() != dest {
+					t.Error("expect response from ", dest, ", but got ", source.Destination())
+				}
+				if r := cmp.Diff(response.Bytes(), xor(payload)); r != "" {
+					t.Error(r)
+				}
+				response.Release()
+			}
+		})
+	}
+}
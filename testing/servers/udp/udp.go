@@ -1,7 +1,9 @@
 package udp
 
 import (
+	"errors"
 	"fmt"
+	gonet "net"
 
 	"github.com/frogwall/f2ray-core/v5/common/net"
 )
@@ -9,7 +11,6 @@ import (
 type Server struct {
 	Port         net.Port
 	MsgProcessor func(msg []byte) []byte
-	accepting    bool
 	conn         *net.UDPConn
 }
 
@@ -33,10 +34,12 @@ func (server *Server) Start() (net.Destination, error) {
 }
 
 func (server *Server) handleConnection(conn *net.UDPConn) {
-	server.accepting = true
-	for server.accepting {
+	for {
 		buffer := make([]byte, 2*1024)
 		nBytes, addr, err := conn.ReadFromUDP(buffer)
+		if errors.Is(err, gonet.ErrClosed) {
+			return
+		}
 		if err != nil {
 			fmt.Printf("Failed to read from UDP: %v\n", err)
 			continue
@@ -50,6 +53,5 @@ func (server *Server) handleConnection(conn *net.UDPConn) {
 }
 
 func (server *Server) Close() error {
-	server.accepting = false
 	return server.conn.Close()
 }
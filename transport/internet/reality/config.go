@@ -2,10 +2,64 @@ package reality
 
 import (
 	"context"
+	"strings"
+	"time"
+
+	"github.com/xtls/reality"
 
 	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
 )
 
+// GetREALITYConfig converts the server side settings into a config for the
+// REALITY handshake library.
+func (c *Config) GetREALITYConfig() *reality.Config {
+	var dialer net.Dialer
+	config := &reality.Config{
+		DialContext: dialer.DialContext,
+
+		Show: c.Show,
+		Type: "tcp",
+		Dest: c.Dest,
+		Xver: byte(c.Xver),
+
+		PrivateKey:   c.PrivateKey,
+		MinClientVer: c.MinClientVer,
+		MaxClientVer: c.MaxClientVer,
+		MaxTimeDiff:  time.Duration(c.MaxTimeDiff) * time.Millisecond,
+
+		SessionTicketsDisabled: true,
+	}
+	if strings.HasPrefix(c.Dest, "/") || strings.HasPrefix(c.Dest, "@") {
+		config.Type = "unix"
+	}
+	config.ServerNames = make(map[string]bool, len(c.ServerNames))
+	for _, serverName := range c.ServerNames {
+		config.ServerNames[serverName] = true
+	}
+	config.ShortIds = make(map[[8]byte]bool, len(c.ShortIds))
+	for _, shortID := range c.ShortIds {
+		var id [8]byte
+		copy(id[:], shortID)
+		config.ShortIds[id] = true
+	}
+	return config
+}
+
+// ConfigFromStreamSettings returns the REALITY config in the stream settings,
+// or nil if the stream does not use REALITY.
+func ConfigFromStreamSettings(settings *internet.MemoryStreamConfig) *Config {
+	if settings == nil {
+		return nil
+	}
+	config, ok := settings.SecuritySettings.(*Config)
+	if !ok {
+		return nil
+	}
+	return config
+}
+
 func init() {
 	// Register so security.CreateSecurityEngineFromSettings can instantiate engine from generated *Config
 	common.Must(common.RegisterConfig((*Config)(nil), func(ctx context.Context, config interface{}) (interface{}, error) {
@@ -24,8 +24,14 @@ type Config struct {
 	Show        bool                   `protobuf:"varint,5,opt,name=show,proto3" json:"show,omitempty"`                           // verbose for debugging
 	SpiderX     string                 `protobuf:"bytes,6,opt,name=spider_x,json=spiderX,proto3" json:"spider_x,omitempty"`       // optional client-provided path (compat)
 	// Server-only fields
-	PrivateKey    []byte   `protobuf:"bytes,7,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`    // X25519 private key bytes (server)
-	ServerNames   []string `protobuf:"bytes,8,rep,name=server_names,json=serverNames,proto3" json:"server_names,omitempty"` // Allowed server names
+	PrivateKey    []byte   `protobuf:"bytes,7,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"`          // X25519 private key bytes (server)
+	ServerNames   []string `protobuf:"bytes,8,rep,name=server_names,json=serverNames,proto3" json:"server_names,omitempty"`       // Allowed server names
+	Dest          string   `protobuf:"bytes,9,opt,name=dest,proto3" json:"dest,omitempty"`                                        // decoy target for unauthenticated clients, host:port or unix path
+	ShortIds      [][]byte `protobuf:"bytes,10,rep,name=short_ids,json=shortIds,proto3" json:"short_ids,omitempty"`               // accepted short ids, up to 8 bytes each
+	MaxTimeDiff   uint64   `protobuf:"varint,11,opt,name=max_time_diff,json=maxTimeDiff,proto3" json:"max_time_diff,omitempty"`   // allowed client clock skew in milliseconds, 0 disables the check
+	MinClientVer  []byte   `protobuf:"bytes,12,opt,name=min_client_ver,json=minClientVer,proto3" json:"min_client_ver,omitempty"` // minimum accepted client version, 3 bytes
+	MaxClientVer  []byte   `protobuf:"bytes,13,opt,name=max_client_ver,json=maxClientVer,proto3" json:"max_client_ver,omitempty"` // maximum accepted client version, 3 bytes
+	Xver          uint32   `protobuf:"varint,14,opt,name=xver,proto3" json:"xver,omitempty"`                                      // PROXY protocol version sent to dest, 0 disables it
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -116,11 +122,53 @@ func (x *Config) GetServerNames() []string {
 	return nil
 }
 
+func (x *Config) GetDest() string {
+	if x != nil {
+		return x.Dest
+	}
+	return ""
+}
+
+func (x *Config) GetShortIds() [][]byte {
+	if x != nil {
+		return x.ShortIds
+	}
+	return nil
+}
+
+func (x *Config) GetMaxTimeDiff() uint64 {
+	if x != nil {
+		return x.MaxTimeDiff
+	}
+	return 0
+}
+
+func (x *Config) GetMinClientVer() []byte {
+	if x != nil {
+		return x.MinClientVer
+	}
+	return nil
+}
+
+func (x *Config) GetMaxClientVer() []byte {
+	if x != nil {
+		return x.MaxClientVer
+	}
+	return nil
+}
+
+func (x *Config) GetXver() uint32 {
+	if x != nil {
+		return x.Xver
+	}
+	return 0
+}
+
 var File_transport_internet_reality_config_proto protoreflect.FileDescriptor
 
 const file_transport_internet_reality_config_proto_rawDesc = "" +
 	"\n" +
-	"'transport/internet/reality/config.proto\x12%v2ray.core.transport.internet.reality\"\xf8\x01\n" +
+	"'transport/internet/reality/config.proto\x12%v2ray.core.transport.internet.reality\"\xad\x03\n" +
 	"\x06Config\x12\x1f\n" +
 	"\vserver_name\x18\x01 \x01(\tR\n" +
 	"serverName\x12\x1d\n" +
@@ -132,7 +180,14 @@ const file_transport_internet_reality_config_proto_rawDesc = "" +
 	"\bspider_x\x18\x06 \x01(\tR\aspiderX\x12\x1f\n" +
 	"\vprivate_key\x18\a \x01(\fR\n" +
 	"privateKey\x12!\n" +
-	"\fserver_names\x18\b \x03(\tR\vserverNamesB\x93\x01\n" +
+	"\fserver_names\x18\b \x03(\tR\vserverNames\x12\x12\n" +
+	"\x04dest\x18\t \x01(\tR\x04dest\x12\x1b\n" +
+	"\tshort_ids\x18\n" +
+	" \x03(\fR\bshortIds\x12\"\n" +
+	"\rmax_time_diff\x18\v \x01(\x04R\vmaxTimeDiff\x12$\n" +
+	"\x0emin_client_ver\x18\f \x01(\fR\fminClientVer\x12$\n" +
+	"\x0emax_client_ver\x18\r \x01(\fR\fmaxClientVer\x12\x12\n" +
+	"\x04xver\x18\x0e \x01(\rR\x04xverB\x93\x01\n" +
 	")com.v2ray.core.transport.internet.realityP\x01Z<github.com/frogwall/f2ray-core/v5/transport/internet/reality\xaa\x02%V2Ray.Core.Transport.Internet.Realityb\x06proto3"
 
 var (
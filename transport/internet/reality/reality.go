@@ -17,6 +17,7 @@ import (
 	coreCrypto "github.com/frogwall/f2ray-core/v5/common/crypto"
 	coreNet "github.com/frogwall/f2ray-core/v5/common/net"
 	utls "github.com/refraction-networking/utls"
+	"github.com/xtls/reality"
 	"golang.org/x/crypto/hkdf"
 )
 
@@ -34,18 +35,22 @@ func (c *UConn) NetConn() coreNet.Conn {
 	return c.UConn.NetConn()
 }
 
-// Server handles incoming REALITY connections
-// NOTE: Full server implementation would require decrypting the SessionId
-// from ClientHello, extracting ShortId, and performing verification.
-// This is a placeholder that returns the connection as-is.
-func Server(c coreNet.Conn, _ *Config) (coreNet.Conn, error) {
-	// TODO: Implement full REALITY server-side handshake
-	// - Read ClientHello
-	// - Decrypt SessionId[0:16] to extract ShortId
-	// - Verify ShortId matches expected value
-	// - Extract ephemeral key and derive AuthKey
-	// - Perform certificate validation with derived AuthKey
-	return c, nil
+// Conn is a server side REALITY connection.
+type Conn struct {
+	*reality.Conn
+}
+
+// Server handles incoming REALITY connections. The ClientHello is parsed and
+// its SessionId decrypted with the configured private key, then the version,
+// time and ShortId are verified. Authenticated clients complete the handshake
+// with a temporary certificate bound to the derived AuthKey, anything else is
+// relayed to the configured dest so that probes only ever see the decoy site.
+func Server(c coreNet.Conn, config *reality.Config) (coreNet.Conn, error) {
+	realityConn, err := reality.Server(context.Background(), c, config)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Conn: realityConn}, nil
 }
 
 // UClient wraps an outbound TCP connection with REALITY handshake.
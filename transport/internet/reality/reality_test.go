@@ -0,0 +1,139 @@
+package reality_test
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	gotls "crypto/tls"
+	"io"
+	"testing"
+	"time"
+
+	goreality "github.com/xtls/reality"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol/tls/cert"
+	. "github.com/frogwall/f2ray-core/v5/transport/internet/reality"
+)
+
+const decoyGreeting = "decoy"
+
+// startDecoy starts a TLS server, which greets every client that completes the
+// handshake.
+func startDecoy(t *testing.T) net.Listener {
+	certificate, err := gotls.X509KeyPair(cert.MustGenerate(nil, cert.CommonName("www.v2fly.org"), cert.DNSNames("www.v2fly.org")).ToPEM())
+	common.Must(err)
+	listener, err := gotls.Listen("tcp", "127.0.0.1:0", &gotls.Config{
+		Certificates: []gotls.Certificate{certificate},
+		MinVersion:   gotls.VersionTLS13,
+	})
+	common.Must(err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.Write([]byte(decoyGreeting))
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+// startServer starts a REALITY server, which echoes data of authenticated
+// clients.
+func startServer(t *testing.T, config *Config) net.Listener {
+	realityConfig := config.GetREALITYConfig()
+	go goreality.DetectPostHandshakeRecordsLens(realityConfig)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	common.Must(err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn, err := Server(conn, realityConfig)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+func TestRealityHandshake(t *testing.T) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	common.Must(err)
+	decoy := startDecoy(t)
+	server := startServer(t, &Config{
+		PrivateKey:  privateKey.Bytes(),
+		ServerNames: []string{"www.v2fly.org"},
+		Dest:        decoy.Addr().String(),
+		ShortIds:    [][]byte{{1, 2, 3, 4, 5, 6, 7, 8}},
+		MaxTimeDiff: 60000,
+	})
+
+	dial := func(shortID []byte) (net.Conn, error) {
+		conn, err := net.Dial("tcp", server.Addr().String())
+		common.Must(err)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		return UClient(ctx, conn, &Config{
+			ServerName:  "www.v2fly.org",
+			PublicKey:   privateKey.PublicKey().Bytes(),
+			ShortId:     shortID,
+			Fingerprint: "chrome",
+		})
+	}
+
+	t.Run("authenticated", func(t *testing.T) {
+		conn, err := dial([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+		common.Must(err)
+		defer conn.Close()
+		if !conn.(*UConn).Verified {
+			t.Fatal("expect the certificate of the REALITY server")
+		}
+		payload := []byte("hello reality")
+		common.Must2(conn.Write(payload))
+		response := make([]byte, len(payload))
+		common.Must2(io.ReadFull(conn, response))
+		if string(response) != string(payload) {
+			t.Error("unexpected echo: ", string(response))
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		// With an unknown short id, the client is relayed to the decoy, whose
+		// certificate is not signed with the auth key.
+		conn, err := dial([]byte{8, 7, 6, 5, 4, 3, 2, 1})
+		if err == nil {
+			conn.Close()
+			t.Fatal("expect the certificate of the decoy to be rejected")
+		}
+
+		// A plain TLS client talks to the decoy through the REALITY server.
+		tlsConn, err := gotls.Dial("tcp", server.Addr().String(), &gotls.Config{
+			ServerName:         "www.v2fly.org",
+			InsecureSkipVerify: true,
+		})
+		common.Must(err)
+		defer tlsConn.Close()
+		greeting := make([]byte, len(decoyGreeting))
+		common.Must2(io.ReadFull(tlsConn, greeting))
+		if string(greeting) != decoyGreeting {
+			t.Error("unexpected greeting: ", string(greeting))
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package internet
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+// ListenSystemPing opens an unprivileged ICMP socket, aka. a ping socket, to
+// send ICMP echo requests of IPv4 or IPv6. The group of the process must be in
+// the range of the sysctl net.ipv4.ping_group_range.
+func ListenSystemPing(ctx context.Context, ipv6 bool, sockopt *SocketConfig) (net.PacketConn, error) {
+	family, proto := syscall.AF_INET, syscall.IPPROTO_ICMP
+	var sockaddr syscall.Sockaddr = &syscall.SockaddrInet4{}
+	if ipv6 {
+		family, proto = syscall.AF_INET6, syscall.IPPROTO_ICMPV6
+		sockaddr = &syscall.SockaddrInet6{}
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, proto)
+	if err != nil {
+		return nil, newError("failed to open ping socket").Base(err)
+	}
+	if sockopt != nil {
+		if err := applyOutboundSocketOptions("icmp", "", uintptr(fd), sockopt); err != nil {
+			syscall.Close(fd)
+			return nil, err
+		}
+	}
+	if err := syscall.Bind(fd, sockaddr); err != nil {
+		syscall.Close(fd)
+		return nil, newError("failed to bind ping socket").Base(err)
+	}
+
+	file := os.NewFile(uintptr(fd), "ping")
+	defer file.Close()
+	conn, err := net.FilePacketConn(file)
+	if err != nil {
+		return nil, newError("failed to open ping socket").Base(err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package internet
+
+import (
+	"context"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+// ListenSystemPing opens an unprivileged ICMP socket, aka. a ping socket, to
+// send ICMP echo requests of IPv4 or IPv6.
+func ListenSystemPing(ctx context.Context, ipv6 bool, sockopt *SocketConfig) (net.PacketConn, error) {
+	return nil, newError("ping socket is not supported on this platform")
+}
@@ -6,21 +6,25 @@ import (
 	"strings"
 	"time"
 
+	goreality "github.com/xtls/reality"
+
 	"github.com/frogwall/f2ray-core/v5/common"
 	"github.com/frogwall/f2ray-core/v5/common/net"
 	"github.com/frogwall/f2ray-core/v5/common/serial"
 	"github.com/frogwall/f2ray-core/v5/common/session"
 	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/reality"
 	"github.com/frogwall/f2ray-core/v5/transport/internet/tls"
 )
 
 // Listener is an internet.Listener that listens for TCP connections.
 type Listener struct {
-	listener   net.Listener
-	tlsConfig  *gotls.Config
-	authConfig internet.ConnectionAuthenticator
-	config     *Config
-	addConn    internet.ConnHandler
+	listener      net.Listener
+	tlsConfig     *gotls.Config
+	realityConfig *goreality.Config
+	authConfig    internet.ConnectionAuthenticator
+	config        *Config
+	addConn       internet.ConnHandler
 }
 
 // ListenTCP creates a new Listener based on configurations.
@@ -64,7 +68,10 @@ func ListenTCP(ctx context.Context, address net.Address, port net.Port, streamSe
 
 	l.listener = listener
 
-	if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
+	if config := reality.ConfigFromStreamSettings(streamSettings); config != nil {
+		l.realityConfig = config.GetREALITYConfig()
+		go goreality.DetectPostHandshakeRecordsLens(l.realityConfig)
+	} else if config := tls.ConfigFromStreamSettings(streamSettings); config != nil {
 		l.tlsConfig = config.GetTLSConfig()
 	}
 
@@ -99,15 +106,29 @@ func (v *Listener) keepAccepting() {
 			continue
 		}
 
+		if v.realityConfig != nil {
+			go func() {
+				conn, err := reality.Server(conn, v.realityConfig)
+				if err != nil {
+					newError("failed to complete REALITY handshake").Base(err).AtInfo().WriteToLog()
+					return
+				}
+				v.handleConn(conn)
+			}()
+			continue
+		}
 		if v.tlsConfig != nil {
 			conn = tls.Server(conn, v.tlsConfig)
 		}
-		if v.authConfig != nil {
-			conn = v.authConfig.Server(conn)
-		}
+		v.handleConn(conn)
+	}
+}
 
-		v.addConn(internet.Connection(conn))
+func (v *Listener) handleConn(conn net.Conn) {
+	if v.authConfig != nil {
+		conn = v.authConfig.Server(conn)
 	}
+	v.addConn(internet.Connection(conn))
 }
 
 // Addr implements internet.Listener.Addr.
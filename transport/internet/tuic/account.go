@@ -0,0 +1,22 @@
+package tuic
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+)
+
+// MemoryAccount is the account of a user of the proxies served on the
+// transport, who is authenticated with a UUID and a password.
+type MemoryAccount struct {
+	UUID     uuid.UUID
+	Password string
+}
+
+// Equals implements protocol.Account.Equals().
+func (a *MemoryAccount) Equals(another protocol.Account) bool {
+	if account, ok := another.(*MemoryAccount); ok {
+		return a.UUID == account.UUID && a.Password == account.Password
+	}
+	return false
+}
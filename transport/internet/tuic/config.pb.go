@@ -0,0 +1,193 @@
+package tuic
+
+import (
+	_ "github.com/frogwall/f2ray-core/v5/common/protoext"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Config is the server side transport for QUIC based proxies which take over
+// the whole QUIC connection, such as TUIC and Juicity.
+type Config struct {
+	state                          protoimpl.MessageState `protogen:"open.v1"`
+	CongestionControl              string                 `protobuf:"bytes,1,opt,name=congestion_control,json=congestionControl,proto3" json:"congestion_control,omitempty"` // "bbr" (default) or "cubic"
+	ZeroRttHandshake               bool                   `protobuf:"varint,2,opt,name=zero_rtt_handshake,json=zeroRttHandshake,proto3" json:"zero_rtt_handshake,omitempty"` // accept 0-RTT handshakes
+	MaxIdleTimeout                 int64                  `protobuf:"varint,3,opt,name=max_idle_timeout,json=maxIdleTimeout,proto3" json:"max_idle_timeout,omitempty"`       // in seconds
+	KeepAlivePeriod                int64                  `protobuf:"varint,4,opt,name=keep_alive_period,json=keepAlivePeriod,proto3" json:"keep_alive_period,omitempty"`    // in seconds
+	InitialStreamReceiveWindow     uint64                 `protobuf:"varint,5,opt,name=initial_stream_receive_window,json=initialStreamReceiveWindow,proto3" json:"initial_stream_receive_window,omitempty"`
+	MaxStreamReceiveWindow         uint64                 `protobuf:"varint,6,opt,name=max_stream_receive_window,json=maxStreamReceiveWindow,proto3" json:"max_stream_receive_window,omitempty"`
+	InitialConnectionReceiveWindow uint64                 `protobuf:"varint,7,opt,name=initial_connection_receive_window,json=initialConnectionReceiveWindow,proto3" json:"initial_connection_receive_window,omitempty"`
+	MaxConnectionReceiveWindow     uint64                 `protobuf:"varint,8,opt,name=max_connection_receive_window,json=maxConnectionReceiveWindow,proto3" json:"max_connection_receive_window,omitempty"`
+	DisablePathMtuDiscovery        bool                   `protobuf:"varint,9,opt,name=disable_path_mtu_discovery,json=disablePathMtuDiscovery,proto3" json:"disable_path_mtu_discovery,omitempty"`
+	unknownFields                  protoimpl.UnknownFields
+	sizeCache                      protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_transport_internet_tuic_config_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_transport_internet_tuic_config_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_transport_internet_tuic_config_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Config) GetCongestionControl() string {
+	if x != nil {
+		return x.CongestionControl
+	}
+	return ""
+}
+
+func (x *Config) GetZeroRttHandshake() bool {
+	if x != nil {
+		return x.ZeroRttHandshake
+	}
+	return false
+}
+
+func (x *Config) GetMaxIdleTimeout() int64 {
+	if x != nil {
+		return x.MaxIdleTimeout
+	}
+	return 0
+}
+
+func (x *Config) GetKeepAlivePeriod() int64 {
+	if x != nil {
+		return x.KeepAlivePeriod
+	}
+	return 0
+}
+
+func (x *Config) GetInitialStreamReceiveWindow() uint64 {
+	if x != nil {
+		return x.InitialStreamReceiveWindow
+	}
+	return 0
+}
+
+func (x *Config) GetMaxStreamReceiveWindow() uint64 {
+	if x != nil {
+		return x.MaxStreamReceiveWindow
+	}
+	return 0
+}
+
+func (x *Config) GetInitialConnectionReceiveWindow() uint64 {
+	if x != nil {
+		return x.InitialConnectionReceiveWindow
+	}
+	return 0
+}
+
+func (x *Config) GetMaxConnectionReceiveWindow() uint64 {
+	if x != nil {
+		return x.MaxConnectionReceiveWindow
+	}
+	return 0
+}
+
+func (x *Config) GetDisablePathMtuDiscovery() bool {
+	if x != nil {
+		return x.DisablePathMtuDiscovery
+	}
+	return false
+}
+
+var File_transport_internet_tuic_config_proto protoreflect.FileDescriptor
+
+const file_transport_internet_tuic_config_proto_rawDesc = "" +
+	"\n" +
+	"$transport/internet/tuic/config.proto\x12\"v2ray.core.transport.internet.tuic\x1a common/protoext/extensions.proto\"\x9b\x04\n" +
+	"\x06Config\x12-\n" +
+	"\x12congestion_control\x18\x01 \x01(\tR\x11congestionControl\x12,\n" +
+	"\x12zero_rtt_handshake\x18\x02 \x01(\bR\x10zeroRttHandshake\x12(\n" +
+	"\x10max_idle_timeout\x18\x03 \x01(\x03R\x0emaxIdleTimeout\x12*\n" +
+	"\x11keep_alive_period\x18\x04 \x01(\x03R\x0fkeepAlivePeriod\x12A\n" +
+	"\x1dinitial_stream_receive_window\x18\x05 \x01(\x04R\x1ainitialStreamReceiveWindow\x129\n" +
+	"\x19max_stream_receive_window\x18\x06 \x01(\x04R\x16maxStreamReceiveWindow\x12I\n" +
+	"!initial_connection_receive_window\x18\a \x01(\x04R\x1einitialConnectionReceiveWindow\x12A\n" +
+	"\x1dmax_connection_receive_window\x18\b \x01(\x04R\x1amaxConnectionReceiveWindow\x12;\n" +
+	"\x1adisable_path_mtu_discovery\x18\t \x01(\bR\x17disablePathMtuDiscovery:\x15\x82\xb5\x18\x11\n" +
+	"\ttransport\x12\x04tuicB\x8a\x01\n" +
+	"&com.v2ray.core.transport.internet.tuicP\x01Z9github.com/frogwall/f2ray-core/v5/transport/internet/tuic\xaa\x02\"V2Ray.Core.Transport.Internet.Tuicb\x06proto3"
+
+var (
+	file_transport_internet_tuic_config_proto_rawDescOnce sync.Once
+	file_transport_internet_tuic_config_proto_rawDescData []byte
+)
+
+func file_transport_internet_tuic_config_proto_rawDescGZIP() []byte {
+	file_transport_internet_tuic_config_proto_rawDescOnce.Do(func() {
+		file_transport_internet_tuic_config_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_transport_internet_tuic_config_proto_rawDesc), len(file_transport_internet_tuic_config_proto_rawDesc)))
+	})
+	return file_transport_internet_tuic_config_proto_rawDescData
+}
+
+var file_transport_internet_tuic_config_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_transport_internet_tuic_config_proto_goTypes = []any{
+	(*Config)(nil), // 0: v2ray.core.transport.internet.tuic.Config
+}
+var file_transport_internet_tuic_config_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_transport_internet_tuic_config_proto_init() }
+func file_transport_internet_tuic_config_proto_init() {
+	if File_transport_internet_tuic_config_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_transport_internet_tuic_config_proto_rawDesc), len(file_transport_internet_tuic_config_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_transport_internet_tuic_config_proto_goTypes,
+		DependencyIndexes: file_transport_internet_tuic_config_proto_depIdxs,
+		MessageInfos:      file_transport_internet_tuic_config_proto_msgTypes,
+	}.Build()
+	File_transport_internet_tuic_config_proto = out.File
+	file_transport_internet_tuic_config_proto_goTypes = nil
+	file_transport_internet_tuic_config_proto_depIdxs = nil
+}
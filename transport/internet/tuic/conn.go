@@ -0,0 +1,49 @@
+package tuic
+
+import (
+	"time"
+
+	"github.com/apernet/quic-go"
+
+	"github.com/frogwall/f2ray-core/v5/common/net"
+)
+
+// Conn is an accepted QUIC connection. It is handed over to the inbound as a
+// whole, the inbound takes care of its streams and datagrams.
+type Conn struct {
+	quic.EarlyConnection
+}
+
+// Read implements net.Conn. Data is only carried by the streams and datagrams
+// of the underlying QUIC connection.
+func (c *Conn) Read([]byte) (int, error) {
+	return 0, newError("reading from a QUIC connection is not supported, use its streams")
+}
+
+// Write implements net.Conn. Data is only carried by the streams and datagrams
+// of the underlying QUIC connection.
+func (c *Conn) Write([]byte) (int, error) {
+	return 0, newError("writing to a QUIC connection is not supported, use its streams")
+}
+
+// Close implements net.Conn.
+func (c *Conn) Close() error {
+	return c.CloseWithError(0, "")
+}
+
+// SetDeadline implements net.Conn.
+func (c *Conn) SetDeadline(time.Time) error {
+	return nil
+}
+
+// SetReadDeadline implements net.Conn.
+func (c *Conn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline implements net.Conn.
+func (c *Conn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+var _ net.Conn = (*Conn)(nil)
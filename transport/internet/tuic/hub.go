@@ -0,0 +1,130 @@
+package tuic
+
+import (
+	"context"
+	"time"
+
+	"github.com/apernet/quic-go"
+	"github.com/v2fly/hysteria/core/v2/international/congestion"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+	"github.com/frogwall/f2ray-core/v5/transport/internet/tls"
+)
+
+// Listener is an internet.Listener that accepts QUIC connections.
+type Listener struct {
+	rawConn    net.PacketConn
+	listener   *quic.EarlyListener
+	congestion string
+	done       *done.Instance
+	addConn    internet.ConnHandler
+}
+
+func (l *Listener) keepAccepting() {
+	for {
+		conn, err := l.listener.Accept(context.Background())
+		if err != nil {
+			if l.done.Done() {
+				break
+			}
+			newError("failed to accept QUIC connections").Base(err).WriteToLog()
+			time.Sleep(time.Second)
+			continue
+		}
+		// Cubic is the congestion control of quic-go, which is kept as is.
+		if l.congestion == "bbr" {
+			congestion.UseBBR(conn)
+		}
+		l.addConn(&Conn{EarlyConnection: conn})
+	}
+}
+
+// Addr implements internet.Listener.Addr.
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// Close implements internet.Listener.Close.
+func (l *Listener) Close() error {
+	l.done.Close()
+	l.listener.Close()
+	return l.rawConn.Close()
+}
+
+// Listen creates a new Listener based on configurations.
+func Listen(ctx context.Context, address net.Address, port net.Port, streamSettings *internet.MemoryStreamConfig, handler internet.ConnHandler) (internet.Listener, error) {
+	if address.Family().IsDomain() {
+		return nil, newError("domain address is not allowed for listening TUIC")
+	}
+
+	config := tls.ConfigFromStreamSettings(streamSettings)
+	if config == nil {
+		return nil, newError("TUIC is based on QUIC that requires TLS")
+	}
+	tlsConfig := config.GetTLSConfig(tls.WithNextProto("h3"))
+	// QUIC servers always issue a session ticket after the handshake, which
+	// 0-RTT handshakes are resumed with, and quic-go fails without one.
+	tlsConfig.SessionTicketsDisabled = false
+
+	transportConfig := streamSettings.ProtocolSettings.(*Config)
+	switch transportConfig.CongestionControl {
+	case "", "bbr", "cubic":
+	default:
+		return nil, newError("unsupported congestion control: ", transportConfig.CongestionControl)
+	}
+	quicConfig := &quic.Config{
+		HandshakeIdleTimeout:           time.Second * 8,
+		MaxIdleTimeout:                 time.Second * 30,
+		KeepAlivePeriod:                time.Second * 10,
+		InitialStreamReceiveWindow:     transportConfig.InitialStreamReceiveWindow,
+		MaxStreamReceiveWindow:         transportConfig.MaxStreamReceiveWindow,
+		InitialConnectionReceiveWindow: transportConfig.InitialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:     transportConfig.MaxConnectionReceiveWindow,
+		MaxIncomingStreams:             1 << 10,
+		MaxIncomingUniStreams:          1 << 10,
+		DisablePathMTUDiscovery:        transportConfig.DisablePathMtuDiscovery,
+		EnableDatagrams:                true,
+		Allow0RTT:                      transportConfig.ZeroRttHandshake,
+	}
+	if transportConfig.MaxIdleTimeout > 0 {
+		quicConfig.MaxIdleTimeout = time.Duration(transportConfig.MaxIdleTimeout) * time.Second
+	}
+	if transportConfig.KeepAlivePeriod > 0 {
+		quicConfig.KeepAlivePeriod = time.Duration(transportConfig.KeepAlivePeriod) * time.Second
+	}
+
+	rawConn, err := internet.ListenSystemPacket(context.Background(), &net.UDPAddr{
+		IP:   address.IP(),
+		Port: int(port),
+	}, streamSettings.SocketSettings)
+	if err != nil {
+		return nil, newError("failed to listen UDP on ", address, ":", port).Base(err)
+	}
+	listener, err := quic.ListenEarly(rawConn, tlsConfig, quicConfig)
+	if err != nil {
+		rawConn.Close()
+		return nil, newError("failed to listen QUIC on ", address, ":", port).Base(err)
+	}
+	newError("listening TUIC on ", address, ":", port).WriteToLog(session.ExportIDToError(ctx))
+
+	l := &Listener{
+		rawConn:    rawConn,
+		listener:   listener,
+		congestion: transportConfig.CongestionControl,
+		done:       done.New(),
+		addConn:    handler,
+	}
+	if l.congestion == "" {
+		l.congestion = "bbr"
+	}
+	go l.keepAccepting()
+	return l, nil
+}
+
+func init() {
+	common.Must(internet.RegisterTransportListener(protocolName, Listen))
+}
@@ -0,0 +1,246 @@
+package tuic
+
+import (
+	"context"
+	"crypto/hmac"
+	"sync"
+	"time"
+
+	"github.com/apernet/quic-go"
+	"github.com/google/uuid"
+
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/common/buf"
+	"github.com/frogwall/f2ray-core/v5/common/log"
+	"github.com/frogwall/f2ray-core/v5/common/net"
+	"github.com/frogwall/f2ray-core/v5/common/protocol"
+	"github.com/frogwall/f2ray-core/v5/common/session"
+	"github.com/frogwall/f2ray-core/v5/common/signal"
+	"github.com/frogwall/f2ray-core/v5/common/signal/done"
+	"github.com/frogwall/f2ray-core/v5/common/task"
+	"github.com/frogwall/f2ray-core/v5/features/policy"
+	"github.com/frogwall/f2ray-core/v5/features/routing"
+)
+
+// TokenLength is the length of the TLS keying material, which clients send
+// as the token of their UUID to authenticate.
+const TokenLength = 32
+
+// Session is the server side state of a QUIC connection, which is shared by
+// the proxies served on the transport. The connection is authenticated once
+// by any of its streams, and the other streams wait for it.
+type Session struct {
+	Ctx           context.Context
+	Conn          *Conn
+	Dispatcher    routing.Dispatcher
+	Inbound       *session.Inbound
+	PolicyManager policy.Manager
+	Validator     *Validator
+
+	authOnce      sync.Once
+	authenticated *done.Instance
+	user          *protocol.MemoryUser
+}
+
+// NewSession creates a Session for a connection accepted by the transport.
+func NewSession(ctx context.Context, conn *Conn, dispatcher routing.Dispatcher, policyManager policy.Manager, validator *Validator) *Session {
+	inbound := session.InboundFromContext(ctx)
+	if inbound == nil {
+		panic("no inbound metadata")
+	}
+	return &Session{
+		Ctx:           ctx,
+		Conn:          conn,
+		Dispatcher:    dispatcher,
+		Inbound:       inbound,
+		PolicyManager: policyManager,
+		Validator:     validator,
+		authenticated: done.New(),
+	}
+}
+
+// Serve waits until the client is authenticated in authTimeout, and then
+// until the connection is closed.
+func (s *Session) Serve(authTimeout time.Duration) error {
+	authTimer := time.NewTimer(authTimeout)
+	defer authTimer.Stop()
+
+	select {
+	case <-s.authenticated.Wait():
+	case <-authTimer.C:
+		s.Conn.CloseWithError(0, "authentication timeout")
+		return newError("authentication timeout from ", s.Inbound.Source).AtInfo()
+	case <-s.Conn.Context().Done():
+		return newError("connection closed before authentication").Base(context.Cause(s.Conn.Context()))
+	}
+	newError("user ", s.user.Email, " authenticated").AtDebug().WriteToLog(session.ExportIDToError(s.Ctx))
+
+	<-s.Conn.Context().Done()
+	return nil
+}
+
+// WaitForAuth waits until the client is authenticated, or the connection is
+// closed.
+func (s *Session) WaitForAuth() bool {
+	select {
+	case <-s.authenticated.Wait():
+		return true
+	case <-s.Conn.Context().Done():
+		return false
+	}
+}
+
+// Authenticate checks the token of a user, which is the TLS keying material
+// exported with the UUID as label and the password as context. The
+// connection is closed if it fails.
+func (s *Session) Authenticate(id [16]byte, token []byte) error {
+	<-s.Conn.HandshakeComplete()
+
+	if err := s.checkToken(id, token); err != nil {
+		s.Conn.CloseWithError(0, "authentication failed")
+		return newError("authentication failed from ", s.Inbound.Source).Base(err).AtWarning()
+	}
+	return nil
+}
+
+func (s *Session) checkToken(id [16]byte, token []byte) error {
+	user := s.Validator.Get(uuid.UUID(id))
+	if user == nil {
+		return newError("unknown user ", uuid.UUID(id))
+	}
+	account := user.Account.(*MemoryAccount)
+	state := s.Conn.ConnectionState().TLS
+	expected, err := state.ExportKeyingMaterial(string(id[:]), []byte(account.Password), TokenLength)
+	if err != nil {
+		return newError("failed to export keying material").Base(err)
+	}
+	if !hmac.Equal(expected, token) {
+		return newError("invalid token for user ", uuid.UUID(id))
+	}
+
+	s.authOnce.Do(func() {
+		s.user = user
+		s.authenticated.Close()
+	})
+	return nil
+}
+
+// AcceptUniStreams handles each unidirectional stream of the connection with
+// handle in its own goroutine, until the connection is closed.
+func (s *Session) AcceptUniStreams(handle func(stream quic.ReceiveStream) error) {
+	for {
+		stream, err := s.Conn.AcceptUniStream(s.Ctx)
+		if err != nil {
+			return
+		}
+		go func() {
+			if err := handle(stream); err != nil {
+				newError("failed to handle uni stream").Base(err).WriteToLog(session.ExportIDToError(s.Ctx))
+				stream.CancelRead(0)
+			}
+		}()
+	}
+}
+
+// AcceptStreams handles each bidirectional stream of the connection with
+// handle in its own goroutine, until the connection is closed. Each stream is
+// handled with a context of its own request.
+func (s *Session) AcceptStreams(handle func(ctx context.Context, stream quic.Stream) error) {
+	for {
+		stream, err := s.Conn.AcceptStream(s.Ctx)
+		if err != nil {
+			return
+		}
+		go func() {
+			ctx := s.streamContext()
+			if err := handle(ctx, stream); err != nil {
+				newError("stream ends").Base(err).WriteToLog(session.ExportIDToError(ctx))
+				stream.CancelRead(0)
+				stream.CancelWrite(0)
+				return
+			}
+			stream.Close()
+		}()
+	}
+}
+
+// streamContext returns a context for a request carried by the connection,
+// each request has its own session ID and content.
+func (s *Session) streamContext() context.Context {
+	ctx := session.ContextWithID(s.Ctx, session.NewID())
+	inbound := *s.Inbound
+	inbound.Conn = nil
+	inbound.CanSpliceCopy = 3
+	ctx = session.ContextWithInbound(ctx, &inbound)
+	if content := session.ContentFromContext(s.Ctx); content != nil {
+		ctx = session.ContextWithContent(ctx, &session.Content{
+			SniffingRequest: content.SniffingRequest,
+		})
+	}
+	return ctx
+}
+
+// User returns the authenticated user. It must be called after WaitForAuth
+// returns true.
+func (s *Session) User() *protocol.MemoryUser {
+	return s.user
+}
+
+// RefreshUser sets the authenticated user to the inbound of a request, whose
+// context is created before the authentication is done.
+func (s *Session) RefreshUser(ctx context.Context) *session.Inbound {
+	inbound := session.InboundFromContext(ctx)
+	inbound.User = s.user
+	return inbound
+}
+
+// RelayStream relays a stream to the destination of its TCP request, after
+// the client is authenticated.
+func (s *Session) RelayStream(ctx context.Context, stream quic.Stream, destination net.Destination) error {
+	inbound := s.RefreshUser(ctx)
+	ctx = log.ContextWithAccessMessage(ctx, &log.AccessMessage{
+		From:   inbound.Source,
+		To:     destination,
+		Status: log.AccessAccepted,
+		Reason: "",
+		Email:  inbound.User.Email,
+	})
+	newError("received request for ", destination).WriteToLog(session.ExportIDToError(ctx))
+
+	sessionPolicy := s.PolicyManager.ForLevel(inbound.User.Level)
+	ctx, cancel := context.WithCancel(ctx)
+	timer := signal.CancelAfterInactivity(ctx, cancel, sessionPolicy.Timeouts.ConnectionIdle)
+	ctx = policy.ContextWithBufferPolicy(ctx, sessionPolicy.Buffer)
+
+	link, err := s.Dispatcher.Dispatch(ctx, destination)
+	if err != nil {
+		return newError("failed to dispatch request to ", destination).Base(err)
+	}
+
+	requestDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.DownlinkOnly)
+
+		if err := buf.Copy(buf.NewReader(stream), link.Writer, buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to transfer request").Base(err)
+		}
+		return nil
+	}
+
+	responseDone := func() error {
+		defer timer.SetTimeout(sessionPolicy.Timeouts.UplinkOnly)
+
+		if err := buf.Copy(link.Reader, buf.NewWriter(stream), buf.UpdateActivity(timer)); err != nil {
+			return newError("failed to write response").Base(err)
+		}
+		return nil
+	}
+
+	requestDonePost := task.OnSuccess(requestDone, task.Close(link.Writer))
+	if err := task.Run(ctx, requestDonePost, responseDone); err != nil {
+		common.Must(common.Interrupt(link.Reader))
+		common.Must(common.Interrupt(link.Writer))
+		return newError("connection ends").Base(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package tuic
+
+import (
+	"github.com/frogwall/f2ray-core/v5/common"
+	"github.com/frogwall/f2ray-core/v5/transport/internet"
+)
+
+//go:generate go run github.com/frogwall/f2ray-core/v5/common/errors/errorgen
+
+const (
+	protocolName = "tuic"
+)
+
+func init() {
+	common.Must(internet.RegisterProtocolConfigCreator(protocolName, func() interface{} {
+		return new(Config)
+	}))
+}
@@ -99,6 +99,11 @@ type Instance struct {
 	running            bool
 	env                environment.RootEnvironment
 
+	// config is the config the instance runs with, and apps are the
+	// features created from config.App, in the same order.
+	config *Config
+	apps   []features.Feature
+
 	ctx context.Context
 }
 
@@ -229,11 +234,13 @@ func initInstanceWithConfig(config *Config, server *Instance) (bool, error) {
 		if err != nil {
 			return true, err
 		}
-		if feature, ok := obj.(features.Feature); ok {
+		feature, ok := obj.(features.Feature)
+		if ok {
 			if err := server.AddFeature(feature); err != nil {
 				return true, err
 			}
 		}
+		server.apps = append(server.apps, feature)
 	}
 
 	essentialFeatures := []struct {
@@ -271,6 +278,7 @@ func initInstanceWithConfig(config *Config, server *Instance) (bool, error) {
 	if err := addOutboundHandlers(server, config.Outbound); err != nil {
 		return true, err
 	}
+	server.config = config
 	return false, nil
 }
 